@@ -56,6 +56,30 @@ var Commands = []cli.Command{
 		Action: internal.CmdAttackSurface,
 		Flags:  internal.CmdAttackSurfaceFlags,
 	},
+	{
+		Name:   "rules",
+		Usage:  "Runs rules against an inzure JSON and outputs the resulting findings",
+		Action: internal.CmdRules,
+		Flags:  internal.CmdRulesFlags,
+	},
+	{
+		Name:   "reach",
+		Usage:  "Determines whether traffic can reach a VM, network interface, or subnet through all of its NSGs",
+		Action: internal.CmdReach,
+		Flags:  internal.CmdReachFlags,
+	},
+	{
+		Name:  "export",
+		Usage: "Exports inzure JSON files to other formats",
+		Subcommands: []cli.Command{
+			{
+				Name:   "sqlite",
+				Usage:  "Exports one or more inzure JSON files into a SQLite database",
+				Action: internal.CmdExportSQLite,
+				Flags:  internal.CmdExportSQLiteFlags,
+			},
+		},
+	},
 	{
 		Name:   "pipeqs",
 		Usage:  "Reads standard input for RawIDs and coverts them to query strings",
@@ -56,4 +56,22 @@ func SetupAutoCompletions() {
 			"o": autocomplete.FileAutoComplete,
 		},
 	)
+
+	autocomplete.AddCompletions(
+		"reach",
+		map[string]autocomplete.CompleteFunc{
+			"f":                        autocomplete.InzureJSONAutoComplete("-f"),
+			"o":                        autocomplete.FileAutoComplete,
+			autocomplete.Positional(1): autocomplete.IQSAutoComplete,
+		},
+	)
+
+	autocomplete.AddCompletions(
+		"rules",
+		map[string]autocomplete.CompleteFunc{
+			"f": autocomplete.InzureJSONAutoComplete("-f"),
+			"o": autocomplete.FileAutoComplete,
+			"r": autocomplete.FileAutoComplete,
+		},
+	)
 }
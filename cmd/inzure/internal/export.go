@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/ivision-research/inzure/pkg/inzure"
+	"github.com/urfave/cli"
+	_ "modernc.org/sqlite"
+)
+
+var ExportFiles cli.StringSlice
+
+var CmdExportSQLiteFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "f",
+		Usage: "Input inzure JSON file. Can be given multiple times to load several subscriptions into one database",
+		Value: &ExportFiles,
+	},
+	cli.StringFlag{
+		Name:        "o",
+		Usage:       "Output SQLite database. Existing databases are added to",
+		Destination: &OutputFile,
+	},
+	BatchFlag,
+}
+
+// CmdExportSQLite loads every given inzure JSON into a single SQLite
+// database. Subscriptions are loaded one at a time so only one has to be in
+// memory.
+func CmdExportSQLite(c *cli.Context) {
+	if OutputFile == "" {
+		exitError(1, "Need to set -o option")
+	}
+	files := []string(ExportFiles)
+	if Batch {
+		fromEnv, err := inzure.BatchFilesFromEnv()
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		files = append(files, fromEnv...)
+	}
+	if len(files) == 0 {
+		exitError(1, "need to pass at least one input file with -f or -b")
+	}
+	db, err := sql.Open("sqlite", OutputFile)
+	if err != nil {
+		exitError(1, "failed to open %s: %v", OutputFile, err)
+	}
+	defer db.Close()
+	var password []byte
+	for _, fname := range files {
+		if password == nil && strings.HasSuffix(fname, inzure.EncryptedFileExtension) {
+			password = getEncryptPassword(c)
+		}
+		sub := getSubscriptionForFile(c, fname, password)
+		if err := inzure.ExportSQLite(context.Background(), db, sub); err != nil {
+			db.Close()
+			exitError(1, "failed to export %s: %v", fname, err)
+		}
+	}
+}
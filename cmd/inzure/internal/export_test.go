@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivision-research/inzure/pkg/inzure"
+)
+
+const (
+	testSubID = "d707bd34-56bd-4ff3-ba5d-fd3b1c3d71d6"
+	testRG    = "/subscriptions/" + testSubID + "/resourceGroups/rgA"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inzure.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func sqliteCount(t *testing.T, db *sql.DB, q string, args ...interface{}) int {
+	var n int
+	if err := db.QueryRow(q, args...).Scan(&n); err != nil {
+		t.Fatalf("query `%s` failed: %v", q, err)
+	}
+	return n
+}
+
+func testStorageAccount(id string) *inzure.StorageAccount {
+	sa := inzure.NewEmptyStorageAccount()
+	sa.Meta.FromID(id)
+	return sa
+}
+
+func TestExportSQLite(t *testing.T) {
+	sub := inzure.NewSubscription(testSubID)
+	rg := inzure.NewEmptyResourceGroup()
+
+	good := testStorageAccount(testRG + "/providers/Microsoft.Storage/storageAccounts/good")
+	good.HTTPSOnly = inzure.BoolTrue
+	bad := testStorageAccount(testRG + "/providers/Microsoft.Storage/storageAccounts/bad")
+	bad.HTTPSOnly = inzure.BoolFalse
+	bad.Containers = append(bad.Containers,
+		inzure.Container{Name: "private", StorageAccount: bad.Meta, Access: inzure.ContainerPermissionPrivate},
+		inzure.Container{Name: "public", StorageAccount: bad.Meta, Access: inzure.ContainerPermissionBlob},
+	)
+	rg.StorageAccounts = append(rg.StorageAccounts, good, bad)
+
+	srv := inzure.NewEmptySQLServer()
+	srv.Meta.FromID(testRG + "/providers/Microsoft.Sql/servers/sql")
+	srv.Firewall = append(srv.Firewall, inzure.FirewallRule{
+		Name:           "all",
+		IPRange:        inzure.NewAzureIPv4FromAzure("10.0.0.0/8"),
+		AllowsAllAzure: inzure.BoolFalse,
+	})
+	srv.Subnets = append(srv.Subnets, inzure.ResourceID{RawID: "/subnet"})
+	rg.SQLServers = append(rg.SQLServers, srv)
+	sub.ResourceGroups["rgA"] = rg
+
+	db := openTestSQLite(t)
+	ctx := context.Background()
+	if err := inzure.ExportSQLite(ctx, db, &sub); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM storage_accounts`); n != 2 {
+		t.Fatalf("expected 2 storage accounts but got %d", n)
+	}
+	var https string
+	err := db.QueryRow(`SELECT https_only FROM storage_accounts WHERE id = ?`, bad.Meta.RawID).Scan(&https)
+	if err != nil || https != "BoolFalse" {
+		t.Fatalf("bad https_only %s: %v", https, err)
+	}
+	n := sqliteCount(
+		t, db,
+		`SELECT COUNT(*) FROM storage_accounts_containers WHERE parent_id = ? AND access = ?`,
+		bad.Meta.RawID, inzure.ContainerPermissionBlob.String(),
+	)
+	if n != 1 {
+		t.Fatalf("expected 1 public container but got %d", n)
+	}
+
+	var ipRange, subnets string
+	err = db.QueryRow(
+		`SELECT f.ip_range, s.subnets FROM sql_servers s
+		JOIN sql_servers_firewall f ON f.parent_id = s.id WHERE s.name = 'sql'`,
+	).Scan(&ipRange, &subnets)
+	if err != nil {
+		t.Fatalf("failed to query firewall: %v", err)
+	}
+	if ipRange != "10.0.0.0/8" || subnets != `["/subnet"]` {
+		t.Fatalf("bad firewall values: %s %s", ipRange, subnets)
+	}
+
+	// A second subscription goes in alongside the first
+	other := inzure.NewSubscription("other")
+	otherRG := inzure.NewEmptyResourceGroup()
+	otherRG.StorageAccounts = append(otherRG.StorageAccounts,
+		testStorageAccount("/subscriptions/other/resourceGroups/rgB/providers/Microsoft.Storage/storageAccounts/sa"),
+	)
+	other.ResourceGroups["rgB"] = otherRG
+	if err := inzure.ExportSQLite(ctx, db, &other); err != nil {
+		t.Fatalf("failed to export second subscription: %v", err)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM subscriptions`); n != 2 {
+		t.Fatalf("expected 2 subscriptions but got %d", n)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM storage_accounts`); n != 3 {
+		t.Fatalf("expected 3 storage accounts but got %d", n)
+	}
+
+	// Exporting the first subscription again replaces its rows
+	rg.StorageAccounts = rg.StorageAccounts[:1]
+	if err := inzure.ExportSQLite(ctx, db, &sub); err != nil {
+		t.Fatalf("failed to export again: %v", err)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM storage_accounts WHERE subscription = ?`, testSubID); n != 1 {
+		t.Fatalf("expected 1 storage account after the second export but got %d", n)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM storage_accounts_containers`); n != 0 {
+		t.Fatalf("expected the old containers to be removed but got %d", n)
+	}
+}
+
+func TestExportSQLiteRoleDefinitions(t *testing.T) {
+	sub := inzure.NewSubscription(testSubID)
+	rd := inzure.NewEmptyRoleDefinition()
+	rd.Meta.FromID("/subscriptions/" + testSubID + "/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c")
+	rd.RoleName = "Contributor"
+	rd.Permissions = append(rd.Permissions, inzure.RolePermission{
+		Actions:     []string{"*"},
+		NotActions:  []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+		DataActions: []string{},
+	})
+	sub.RoleDefinitions = append(sub.RoleDefinitions, rd)
+	ra := inzure.NewEmptyRoleAssignment()
+	ra.Meta.FromID("/subscriptions/" + testSubID + "/providers/Microsoft.Authorization/roleAssignments/a")
+	ra.RoleDefinition = rd.Meta
+	ra.PrincipalID = "principal"
+	sub.RoleAssignments = append(sub.RoleAssignments, ra)
+
+	db := openTestSQLite(t)
+	if err := inzure.ExportSQLite(context.Background(), db, &sub); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	var role string
+	err := db.QueryRow(
+		`SELECT d.role_name FROM role_assignments a
+		JOIN role_definitions d ON d.id = a.role_definition WHERE a.principal_id = 'principal'`,
+	).Scan(&role)
+	if err != nil || role != "Contributor" {
+		t.Fatalf("failed to join role assignments to definitions %s: %v", role, err)
+	}
+	n := sqliteCount(
+		t, db,
+		`SELECT COUNT(*) FROM role_definitions d
+		JOIN role_definitions_permissions p ON p.parent_id = d.id
+		JOIN role_definitions_permissions_not_actions na ON na.parent_id = p.id
+		WHERE d.role_name = 'Contributor' AND na.value LIKE 'Microsoft.Authorization/%'`,
+	)
+	if n != 2 {
+		t.Fatalf("expected 2 not actions but got %d", n)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM role_definitions_permissions_actions WHERE value = '*'`); n != 1 {
+		t.Fatalf("expected 1 action but got %d", n)
+	}
+	if n := sqliteCount(t, db, `SELECT COUNT(*) FROM role_definitions_permissions_data_actions`); n != 0 {
+		t.Fatalf("expected no data actions but got %d", n)
+	}
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ivision-research/inzure/pkg/inzure"
+	"github.com/urfave/cli"
+)
+
+var (
+	ReachSource     string
+	ReachSourcePort string
+	ReachPort       string
+	ReachProtocol   string
+)
+
+var CmdReachFlags = []cli.Flag{
+	InputFileFlag,
+	OutputFileFlag,
+	cli.StringFlag{
+		Name:        "src",
+		Usage:       "Source IP of the traffic",
+		Value:       "Internet",
+		Destination: &ReachSource,
+	},
+	cli.StringFlag{
+		Name:        "src-port",
+		Usage:       "Source port of the traffic. Rules restricting source ports are uncertain if this isn't given",
+		Destination: &ReachSourcePort,
+	},
+	cli.StringFlag{
+		Name:        "port",
+		Usage:       "Destination port of the traffic",
+		Value:       "*",
+		Destination: &ReachPort,
+	},
+	cli.StringFlag{
+		Name:        "proto",
+		Usage:       "Protocol of the traffic: tcp, udp, or tcp/udp",
+		Value:       "tcp",
+		Destination: &ReachProtocol,
+	},
+}
+
+// CmdReach takes a resource ID or query string for virtual machines, network
+// interfaces, or subnets and reports whether the given traffic makes it
+// through every NSG in front of them.
+func CmdReach(c *cli.Context) {
+	args := c.Args()
+	if len(args) == 0 {
+		exitError(1, "need to pass a target resource ID or query string")
+	}
+	requiresInputFile()
+	var proto inzure.SecurityRuleProtocol
+	if err := json.Unmarshal([]byte(fmt.Sprintf("%q", ReachProtocol)), &proto); err != nil {
+		exitError(1, "bad protocol %s: %v", ReachProtocol, err)
+	}
+	src := inzure.NewAzureIPv4FromAzure(ReachSource)
+	srcPort := inzure.NewPortFromAzure(ReachSourcePort)
+	port := inzure.NewPortFromAzure(ReachPort)
+	sub := getSubscription(c)
+
+	results := make([]inzure.Reachability, 0)
+	for _, target := range getReachTargets(sub, args[0]) {
+		res, err := sub.InboundReachability(target, src, srcPort, port, proto)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		results = append(results, res...)
+	}
+	if err := json.NewEncoder(getOutputWriter("")).Encode(results); err != nil {
+		exitError(1, err.Error())
+	}
+}
+
+func getReachTargets(sub *inzure.Subscription, target string) []inzure.ResourceID {
+	if inzure.StringLooksLikeResourceID(target) {
+		var id inzure.ResourceID
+		id.FromID(target)
+		return []inzure.ResourceID{id}
+	}
+	v, err := sub.ReflectFromQueryString(target)
+	if err != nil {
+		exitError(1, "failed to get %s: %v", target, err)
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	ids := make([]inzure.ResourceID, 0)
+	addID := func(e reflect.Value) {
+		for e.Kind() == reflect.Ptr {
+			e = e.Elem()
+		}
+		meta := e.FieldByName("Meta")
+		if !meta.IsValid() {
+			exitError(1, "%v doesn't have a resource ID", e.Type())
+		}
+		ids = append(ids, meta.Interface().(inzure.ResourceID))
+	}
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			addID(v.Index(i))
+		}
+	} else {
+		addID(v)
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ivision-research/inzure/pkg/inzure"
+	"github.com/urfave/cli"
+)
+
+var (
+	RulePaths cli.StringSlice
+	RulePacks cli.StringSlice
+	RuleSARIF = false
+)
+
+var CmdRulesFlags = []cli.Flag{
+	InputFileFlag,
+	OutputFileFlag,
+	cli.StringSliceFlag{
+		Name:  "r",
+		Usage: "Rule file or directory of rule files (YAML or JSON). Can be given multiple times",
+		Value: &RulePaths,
+	},
+	cli.StringSliceFlag{
+		Name:  "pack",
+		Usage: fmt.Sprintf("Built in rule pack to run. Can be given multiple times. Available: %s", strings.Join(inzure.BuiltinRulePacks(), ", ")),
+		Value: &RulePacks,
+	},
+	cli.BoolFlag{
+		Name:        "sarif",
+		Usage:       "Output the findings as SARIF",
+		Destination: &RuleSARIF,
+	},
+}
+
+// CmdRules runs the given rules against an inzure JSON and outputs the
+// findings as JSON or SARIF.
+func CmdRules(c *cli.Context) {
+	requiresInputFile()
+	if len(RulePaths) == 0 && len(RulePacks) == 0 {
+		exitError(1, "need to pass at least one rule file or directory with -r or a rule pack with -pack")
+	}
+	rules := loadRules(RulePaths)
+	for _, name := range RulePacks {
+		pack, err := inzure.LoadBuiltinRulePack(name)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		rules = append(rules, pack.Rules...)
+	}
+	engine, err := inzure.NewRuleEngine(rules...)
+	if err != nil {
+		exitError(1, err.Error())
+	}
+	sub := getSubscription(c)
+	findings, err := engine.Run(sub)
+	if err != nil {
+		exitError(1, err.Error())
+	}
+	var out interface{} = findings
+	if RuleSARIF {
+		out = inzure.NewSARIFLog(engine.Rules(), findings)
+	}
+	if err := json.NewEncoder(getOutputWriter("")).Encode(out); err != nil {
+		exitError(1, err.Error())
+	}
+}
+
+func loadRules(paths []string) []inzure.Rule {
+	rules := make([]inzure.Rule, 0)
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		var loaded []inzure.Rule
+		if fi.IsDir() {
+			loaded, err = inzure.LoadRulesFromDir(p)
+		} else {
+			loaded, err = inzure.LoadRulesFromFile(p)
+		}
+		if err != nil {
+			exitError(1, "failed to load rules: %v", err)
+		}
+		rules = append(rules, loaded...)
+	}
+	return rules
+}
@@ -2,10 +2,13 @@ package internal
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"reflect"
+	"strings"
 
 	"github.com/ivision-research/inzure/pkg/inzure"
 	"github.com/urfave/cli"
@@ -15,6 +18,9 @@ var (
 	SearchIQS         string
 	SubscriptionFiles cli.StringSlice
 	NoLegacy          = false
+	SearchSARIF       = false
+	SearchSeverity    = "medium"
+	SearchCSV         = false
 )
 
 var CmdSearchFlags = []cli.Flag{
@@ -29,6 +35,22 @@ var CmdSearchFlags = []cli.Flag{
 		Usage:       "Do not fall back to legacy output when only one file is provided. This standardizes the command output",
 		Destination: &NoLegacy,
 	},
+	cli.BoolFlag{
+		Name:        "sarif",
+		Usage:       "Output the results as SARIF with every returned resource as a result",
+		Destination: &SearchSARIF,
+	},
+	cli.BoolFlag{
+		Name:        "csv",
+		Usage:       "Output the results as CSV. Requires a projection such as -> {.Meta.Name}",
+		Destination: &SearchCSV,
+	},
+	cli.StringFlag{
+		Name:        "severity",
+		Usage:       "Severity of the results when using -sarif: info, low, medium, high, or critical",
+		Value:       SearchSeverity,
+		Destination: &SearchSeverity,
+	},
 	OutputFileFlag,
 	BatchFlag,
 }
@@ -57,6 +79,16 @@ func CmdSearch(ctx *cli.Context) {
 	SearchIQS = args[0]
 	subscriptionFiles := SubscriptionFiles
 
+	if SearchSARIF {
+		cmdSearchSARIF(ctx, subscriptionFiles)
+		return
+	}
+
+	if SearchCSV {
+		cmdSearchCSV(ctx, subscriptionFiles)
+		return
+	}
+
 	if !NoLegacy && len(subscriptionFiles) == 1 {
 		cmdSearchSingleFile(ctx, subscriptionFiles[0])
 		return
@@ -90,16 +122,19 @@ func doSearch(ctx *cli.Context, inputFile string, out io.Writer) string {
 	}
 	sub := getSubscriptionForFile(ctx, inputFile, nil)
 
-	v, err := sub.ReflectFromQueryString(SearchIQS)
+	qs := parseSearchIQS()
+	v, err := sub.ReflectFromParsedQueryString(qs)
 	if err != nil {
-		le, is := err.(inzure.LexError)
-		if is {
-			exitError(1, le.ErrorWithHint())
-		}
 		exitError(1, err.Error())
 	}
 
-	if v.Kind() == reflect.Ptr && (v.IsNil() || v.Elem().IsNil()) {
+	if len(qs.Projection) > 0 {
+		records, err := qs.Project(v)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		err = json.NewEncoder(out).Encode(records)
+	} else if isNilResult(v) {
 		_, err = out.Write([]byte("[]\n"))
 	} else {
 		err = json.NewEncoder(out).Encode(v.Interface())
@@ -132,3 +167,128 @@ func cmdSearchSingleFile(ctx *cli.Context, inputFile string) {
 	}
 	doSearch(ctx, inputFile, out)
 }
+
+func cmdSearchSARIF(ctx *cli.Context, subscriptionFiles []string) {
+	var sev inzure.Severity
+	if err := sev.UnmarshalText([]byte(SearchSeverity)); err != nil {
+		exitError(1, err.Error())
+	}
+	rule := inzure.Rule{
+		ID:       SearchIQS,
+		Severity: sev,
+		Queries:  []string{SearchIQS},
+	}
+	if err := rule.Validate(); err != nil {
+		exitError(1, err.Error())
+	}
+	findings := make([]inzure.Finding, 0)
+	for _, subFile := range subscriptionFiles {
+		sub := getSubscriptionForFile(ctx, subFile, nil)
+		found, err := rule.Evaluate(sub)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		findings = append(findings, found...)
+	}
+	out := getOutputFile()
+	if f, is := out.(*os.File); is {
+		defer f.Close()
+	}
+	err := json.NewEncoder(out).Encode(inzure.NewSARIFLog([]inzure.Rule{rule}, findings))
+	if err != nil {
+		exitError(1, err.Error())
+	}
+}
+
+// isNilResult checks for a nil pointer or a pointer to a nil slice or
+// resource. Counts are never nil.
+func isNilResult(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr {
+		return false
+	}
+	if v.IsNil() {
+		return true
+	}
+	switch v.Elem().Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return v.Elem().IsNil()
+	}
+	return false
+}
+
+func parseSearchIQS() *inzure.QueryString {
+	qs := new(inzure.QueryString)
+	if err := qs.Parse(SearchIQS); err != nil {
+		le, is := err.(inzure.LexError)
+		if is {
+			exitError(1, le.ErrorWithHint())
+		}
+		exitError(1, err.Error())
+	}
+	return qs
+}
+
+// cmdSearchCSV writes one row per projected record. When searching more than
+// one subscription the first column is the subscription ID.
+func cmdSearchCSV(ctx *cli.Context, subscriptionFiles []string) {
+	qs := parseSearchIQS()
+	if len(qs.Projection) == 0 {
+		exitError(1, "-csv requires a projection such as %s -> {.Meta.Name}", SearchIQS)
+	}
+	withSub := len(subscriptionFiles) > 1
+	names := qs.ProjectionNames()
+	out := getOutputFile()
+	if f, is := out.(*os.File); is {
+		defer f.Close()
+	}
+	w := csv.NewWriter(out)
+	header := names
+	if withSub {
+		header = append([]string{"Subscription"}, names...)
+	}
+	if err := w.Write(header); err != nil {
+		exitError(1, err.Error())
+	}
+	for _, subFile := range subscriptionFiles {
+		sub := getSubscriptionForFile(ctx, subFile, nil)
+		v, err := sub.ReflectFromParsedQueryString(qs)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		records, err := qs.Project(v)
+		if err != nil {
+			exitError(1, err.Error())
+		}
+		for _, rec := range records {
+			row := make([]string, 0, len(header))
+			if withSub {
+				row = append(row, sub.ID)
+			}
+			for _, name := range names {
+				row = append(row, csvValue(rec[name]))
+			}
+			if err := w.Write(row); err != nil {
+				exitError(1, err.Error())
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		exitError(1, err.Error())
+	}
+}
+
+func csvValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		s := make([]string, len(val))
+		for i, e := range val {
+			s[i] = csvValue(e)
+		}
+		return strings.Join(s, ";")
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -37,6 +37,7 @@ func CmdWhitelist(c *cli.Context) {
 	sqlWhitelist(sub, tf.Whitelist)
 	cosmosWhitelist(sub, tf.Whitelist)
 	postgresWhitelist(sub, tf.Whitelist)
+	mysqlWhitelist(sub, tf.Whitelist)
 	redisWhitelist(sub, tf.Whitelist)
 	json.NewEncoder(os.Stdout).Encode(&tf)
 }
@@ -117,6 +118,12 @@ func cosmosWhitelist(sub *inzure.Subscription, whitelist map[string][]string) {
 
 func postgresWhitelist(sub *inzure.Subscription, whitelist map[string][]string) {
 	genericFirewallWhitelist(sub, "/PostgresServers", whitelist)
+	genericFirewallWhitelist(sub, "/PostgresFlexibleServers", whitelist)
+}
+
+func mysqlWhitelist(sub *inzure.Subscription, whitelist map[string][]string) {
+	genericFirewallWhitelist(sub, "/MySQLServers", whitelist)
+	genericFirewallWhitelist(sub, "/MySQLFlexibleServers", whitelist)
 }
 
 func sqlWhitelist(sub *inzure.Subscription, whitelist map[string][]string) {
@@ -30,6 +30,7 @@ type APIService struct {
 	SignupEnabled UnknownBool
 	Backends      []*APIBackend
 	Products      []*APIServiceProduct
+	Identity      ManagedIdentity
 }
 
 func NewEmptyAPIService() *APIService {
@@ -42,6 +43,7 @@ func NewEmptyAPIService() *APIService {
 		HostnameConfigs:  make([]APIServiceHostnameConfig, 0),
 	}
 	s.SubnetRef.setupEmpty()
+	s.Identity.setupEmpty()
 	return s
 }
 
@@ -50,6 +52,7 @@ func (as *APIService) FromAzure(az *armapimanagement.ServiceResource) {
 		return
 	}
 	as.Meta.fromID(*az.ID)
+	as.Identity.FromAzureAPIManagement(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -0,0 +1,570 @@
+package inzure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+//go:generate go run gen/enum.go -prefix ApplicationGatewayWAFMode -values Detection,Prevention -azure-type ApplicationGatewayFirewallMode -azure-values ApplicationGatewayFirewallModeDetection,ApplicationGatewayFirewallModePrevention -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8
+
+// ApplicationGateway is an Azure Application Gateway, the L7 counterpart to
+// a LoadBalancer.
+type ApplicationGateway struct {
+	Meta            ResourceID
+	SKU             string
+	Tier            string
+	HTTP2Enabled    UnknownBool
+	FrontendIPs     []ApplicationGatewayFrontendIP
+	Listeners       []ApplicationGatewayListener
+	BackendPools    []ApplicationGatewayBackendPool
+	BackendSettings []ApplicationGatewayBackendSettings
+	RoutingRules    []ApplicationGatewayRoutingRule
+	SSLPolicy       ApplicationGatewaySSLPolicy
+	WAF             ApplicationGatewayWAF
+	Identity        ManagedIdentity
+}
+
+func NewEmptyApplicationGateway() *ApplicationGateway {
+	var rid ResourceID
+	rid.setupEmpty()
+	ag := &ApplicationGateway{
+		Meta:            rid,
+		FrontendIPs:     make([]ApplicationGatewayFrontendIP, 0),
+		Listeners:       make([]ApplicationGatewayListener, 0),
+		BackendPools:    make([]ApplicationGatewayBackendPool, 0),
+		BackendSettings: make([]ApplicationGatewayBackendSettings, 0),
+		RoutingRules:    make([]ApplicationGatewayRoutingRule, 0),
+		SSLPolicy: ApplicationGatewaySSLPolicy{
+			CipherSuites: make([]string, 0),
+		},
+	}
+	ag.WAF.Policy.setupEmpty()
+	ag.Identity.setupEmpty()
+	return ag
+}
+
+func (ag *ApplicationGateway) FromAzure(az *armnetwork.ApplicationGateway) {
+	if az.ID == nil {
+		return
+	}
+	ag.Meta.FromID(*az.ID)
+	ag.Identity.FromAzureNetwork(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.SKU != nil {
+		if props.SKU.Name != nil {
+			ag.SKU = string(*props.SKU.Name)
+		}
+		if props.SKU.Tier != nil {
+			ag.Tier = string(*props.SKU.Tier)
+		}
+	}
+	ag.HTTP2Enabled.FromBoolPtr(props.EnableHTTP2)
+
+	gSliceFromPtrSetterPtrs(&ag.FrontendIPs, &props.FrontendIPConfigurations, func(fip *ApplicationGatewayFrontendIP, az *armnetwork.ApplicationGatewayFrontendIPConfiguration) {
+		fip.FromAzure(az)
+	})
+
+	// Listeners reference frontend ports by ID, so resolve those up front
+	// to keep the actual port on the listener.
+	ports := make(map[string]int32, len(props.FrontendPorts))
+	for _, p := range props.FrontendPorts {
+		if p != nil && p.ID != nil && p.Properties != nil && p.Properties.Port != nil {
+			ports[strings.ToLower(*p.ID)] = *p.Properties.Port
+		}
+	}
+	gSliceFromPtrSetterPtrs(&ag.Listeners, &props.HTTPListeners, func(l *ApplicationGatewayListener, az *armnetwork.ApplicationGatewayHTTPListener) {
+		l.FromAzure(az, ports)
+	})
+
+	gSliceFromPtrSetterPtrs(&ag.BackendPools, &props.BackendAddressPools, func(bp *ApplicationGatewayBackendPool, az *armnetwork.ApplicationGatewayBackendAddressPool) {
+		bp.FromAzure(az)
+	})
+	gSliceFromPtrSetterPtrs(&ag.BackendSettings, &props.BackendHTTPSettingsCollection, func(bs *ApplicationGatewayBackendSettings, az *armnetwork.ApplicationGatewayBackendHTTPSettings) {
+		bs.FromAzure(az)
+	})
+
+	pathMaps := make(map[string]*armnetwork.ApplicationGatewayURLPathMap, len(props.URLPathMaps))
+	for _, pm := range props.URLPathMaps {
+		if pm != nil && pm.ID != nil {
+			pathMaps[strings.ToLower(*pm.ID)] = pm
+		}
+	}
+	gSliceFromPtrSetterPtrs(&ag.RoutingRules, &props.RequestRoutingRules, func(r *ApplicationGatewayRoutingRule, az *armnetwork.ApplicationGatewayRequestRoutingRule) {
+		r.FromAzure(az, pathMaps)
+	})
+
+	ag.SSLPolicy.FromAzure(props.SSLPolicy, props.DefaultPredefinedSSLPolicy)
+	ag.WAF.FromAzure(props.WebApplicationFirewallConfiguration)
+	if props.FirewallPolicy != nil {
+		gValFromPtrFromAzure(&ag.WAF.Policy, props.FirewallPolicy.ID)
+	}
+}
+
+// AttackSurface gives the public frontends of the gateway, the backend
+// addresses, and the paths from public listeners to backends. Keys are the
+// listener's host name (or frontend address) and port, with the URL path
+// for path based rules. Values are every address:port of the backend pool.
+func (ag *ApplicationGateway) AttackSurface() LoadBalancerAttackSurface {
+	as := LoadBalancerAttackSurface{
+		Frontends: make([]string, 0, len(ag.FrontendIPs)),
+		Backends:  make([]string, 0),
+		Paths:     make(map[string]string),
+	}
+	for _, fip := range ag.FrontendIPs {
+		if front := fip.PublicIP.address(); front != "" {
+			as.Frontends = append(as.Frontends, front)
+		}
+	}
+	for _, bp := range ag.BackendPools {
+		as.Backends = append(as.Backends, bp.Addresses...)
+	}
+	for _, rule := range ag.RoutingRules {
+		l := ag.listener(&rule.Listener)
+		if l == nil {
+			continue
+		}
+		fip := ag.frontendIP(&l.FrontendIP)
+		if fip == nil {
+			continue
+		}
+		front := fip.PublicIP.address()
+		if front == "" {
+			continue
+		}
+		hosts := l.HostNames
+		if len(hosts) == 0 {
+			hosts = []string{front}
+		}
+		for _, tgt := range rule.Targets {
+			back := ag.backendAddresses(&tgt)
+			if back == "" {
+				continue
+			}
+			paths := tgt.Paths
+			if len(paths) == 0 {
+				paths = []string{""}
+			}
+			for _, host := range hosts {
+				for _, path := range paths {
+					as.Paths[fmt.Sprintf("%s:%d%s", host, l.Port, path)] = back
+				}
+			}
+		}
+	}
+	return as
+}
+
+func (ag *ApplicationGateway) backendAddresses(tgt *ApplicationGatewayRouteTarget) string {
+	bp := ag.backendPool(&tgt.BackendPool)
+	if bp == nil || len(bp.Addresses) == 0 {
+		return ""
+	}
+	port := ""
+	if bs := ag.backendSettings(&tgt.BackendSettings); bs != nil && bs.Port > 0 {
+		port = fmt.Sprintf(":%d", bs.Port)
+	}
+	addrs := make([]string, 0, len(bp.Addresses))
+	for _, addr := range bp.Addresses {
+		addrs = append(addrs, addr+port)
+	}
+	return strings.Join(addrs, ",")
+}
+
+func (ag *ApplicationGateway) listener(id *ResourceID) *ApplicationGatewayListener {
+	if id.RawID == "" {
+		return nil
+	}
+	for i := range ag.Listeners {
+		if strings.EqualFold(ag.Listeners[i].Meta.RawID, id.RawID) {
+			return &ag.Listeners[i]
+		}
+	}
+	return nil
+}
+
+func (ag *ApplicationGateway) frontendIP(id *ResourceID) *ApplicationGatewayFrontendIP {
+	if id.RawID == "" {
+		return nil
+	}
+	for i := range ag.FrontendIPs {
+		if strings.EqualFold(ag.FrontendIPs[i].Meta.RawID, id.RawID) {
+			return &ag.FrontendIPs[i]
+		}
+	}
+	return nil
+}
+
+func (ag *ApplicationGateway) backendPool(id *ResourceID) *ApplicationGatewayBackendPool {
+	if id.RawID == "" {
+		return nil
+	}
+	for i := range ag.BackendPools {
+		if strings.EqualFold(ag.BackendPools[i].Meta.RawID, id.RawID) {
+			return &ag.BackendPools[i]
+		}
+	}
+	return nil
+}
+
+func (ag *ApplicationGateway) backendSettings(id *ResourceID) *ApplicationGatewayBackendSettings {
+	if id.RawID == "" {
+		return nil
+	}
+	for i := range ag.BackendSettings {
+		if strings.EqualFold(ag.BackendSettings[i].Meta.RawID, id.RawID) {
+			return &ag.BackendSettings[i]
+		}
+	}
+	return nil
+}
+
+// ApplicationGatewayFrontendIP is a frontend IP configuration. The PublicIP
+// is only a reference until it is filled in with a separate request.
+type ApplicationGatewayFrontendIP struct {
+	Meta      ResourceID
+	PublicIP  PublicIP
+	PrivateIP string
+	Subnet    ResourceID
+}
+
+func (fip *ApplicationGatewayFrontendIP) FromAzure(az *armnetwork.ApplicationGatewayFrontendIPConfiguration) {
+	fip.Meta.setupEmpty()
+	fip.PublicIP.setupEmpty()
+	fip.Subnet.setupEmpty()
+	if az == nil || az.ID == nil {
+		return
+	}
+	fip.Meta.FromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&fip.PrivateIP, props.PrivateIPAddress)
+	if props.PublicIPAddress != nil {
+		gValFromPtrFromAzure(&fip.PublicIP.Meta, props.PublicIPAddress.ID)
+	}
+	if props.Subnet != nil {
+		gValFromPtrFromAzure(&fip.Subnet, props.Subnet.ID)
+	}
+}
+
+// ApplicationGatewayListener is an HTTP(S) listener.
+type ApplicationGatewayListener struct {
+	Meta       ResourceID
+	Name       string
+	Protocol   string
+	FrontendIP ResourceID
+	Port       int32
+	// HostNames are only set for multi site listeners
+	HostNames  []string
+	RequireSNI UnknownBool
+	// WAFPolicy is a listener specific WAF policy that overrides the one on
+	// the gateway.
+	WAFPolicy ResourceID
+}
+
+func (l *ApplicationGatewayListener) FromAzure(az *armnetwork.ApplicationGatewayHTTPListener, ports map[string]int32) {
+	l.Meta.setupEmpty()
+	l.FrontendIP.setupEmpty()
+	l.WAFPolicy.setupEmpty()
+	l.HostNames = make([]string, 0)
+	if az == nil || az.ID == nil {
+		return
+	}
+	l.Meta.FromID(*az.ID)
+	gValFromPtr(&l.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.Protocol != nil {
+		l.Protocol = string(*props.Protocol)
+	}
+	if props.FrontendIPConfiguration != nil {
+		gValFromPtrFromAzure(&l.FrontendIP, props.FrontendIPConfiguration.ID)
+	}
+	if props.FrontendPort != nil && props.FrontendPort.ID != nil {
+		l.Port = ports[strings.ToLower(*props.FrontendPort.ID)]
+	}
+	if props.HostName != nil && *props.HostName != "" {
+		l.HostNames = append(l.HostNames, *props.HostName)
+	}
+	for _, h := range props.HostNames {
+		if h != nil && *h != "" {
+			l.HostNames = append(l.HostNames, *h)
+		}
+	}
+	l.RequireSNI.FromBoolPtr(props.RequireServerNameIndication)
+	if props.FirewallPolicy != nil {
+		gValFromPtrFromAzure(&l.WAFPolicy, props.FirewallPolicy.ID)
+	}
+}
+
+// ApplicationGatewayBackendPool is a backend address pool.
+type ApplicationGatewayBackendPool struct {
+	Meta ResourceID
+	Name string
+	// Addresses are the FQDNs and IPs given directly to the pool
+	Addresses []string
+	// IPConfigurations are network interface IP configurations in the pool
+	IPConfigurations []ResourceID
+}
+
+func (bp *ApplicationGatewayBackendPool) FromAzure(az *armnetwork.ApplicationGatewayBackendAddressPool) {
+	bp.Meta.setupEmpty()
+	bp.Addresses = make([]string, 0)
+	bp.IPConfigurations = make([]ResourceID, 0)
+	if az == nil || az.ID == nil {
+		return
+	}
+	bp.Meta.FromID(*az.ID)
+	gValFromPtr(&bp.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	for _, addr := range props.BackendAddresses {
+		if addr == nil {
+			continue
+		}
+		if addr.Fqdn != nil && *addr.Fqdn != "" {
+			bp.Addresses = append(bp.Addresses, *addr.Fqdn)
+		} else if addr.IPAddress != nil && *addr.IPAddress != "" {
+			bp.Addresses = append(bp.Addresses, *addr.IPAddress)
+		}
+	}
+	for _, ipc := range props.BackendIPConfigurations {
+		if ipc != nil && ipc.ID != nil {
+			var id ResourceID
+			id.FromID(*ipc.ID)
+			bp.IPConfigurations = append(bp.IPConfigurations, id)
+		}
+	}
+}
+
+// ApplicationGatewayBackendSettings is how the gateway talks to a backend.
+type ApplicationGatewayBackendSettings struct {
+	Meta     ResourceID
+	Name     string
+	Protocol string
+	Port     int32
+	HostName string
+}
+
+func (bs *ApplicationGatewayBackendSettings) FromAzure(az *armnetwork.ApplicationGatewayBackendHTTPSettings) {
+	bs.Meta.setupEmpty()
+	if az == nil || az.ID == nil {
+		return
+	}
+	bs.Meta.FromID(*az.ID)
+	gValFromPtr(&bs.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.Protocol != nil {
+		bs.Protocol = string(*props.Protocol)
+	}
+	gValFromPtr(&bs.Port, props.Port)
+	gValFromPtr(&bs.HostName, props.HostName)
+}
+
+// ApplicationGatewayRoutingRule connects a listener to backends or to a
+// redirect.
+type ApplicationGatewayRoutingRule struct {
+	Meta     ResourceID
+	Name     string
+	RuleType string
+	Priority int32
+	Listener ResourceID
+	Redirect ResourceID
+	// Targets are where the rule sends traffic. Basic rules have a single
+	// target while path based rules have one per path rule plus the default
+	// with no Paths.
+	Targets []ApplicationGatewayRouteTarget
+}
+
+// ApplicationGatewayRouteTarget is a backend pool and the settings used to
+// reach it.
+type ApplicationGatewayRouteTarget struct {
+	Paths           []string
+	BackendPool     ResourceID
+	BackendSettings ResourceID
+}
+
+func newApplicationGatewayRouteTarget(pool *armnetwork.SubResource, settings *armnetwork.SubResource) (tgt ApplicationGatewayRouteTarget, ok bool) {
+	tgt.Paths = make([]string, 0)
+	tgt.BackendPool.setupEmpty()
+	tgt.BackendSettings.setupEmpty()
+	if pool == nil || pool.ID == nil {
+		return tgt, false
+	}
+	tgt.BackendPool.FromID(*pool.ID)
+	if settings != nil {
+		gValFromPtrFromAzure(&tgt.BackendSettings, settings.ID)
+	}
+	return tgt, true
+}
+
+func (r *ApplicationGatewayRoutingRule) FromAzure(az *armnetwork.ApplicationGatewayRequestRoutingRule, pathMaps map[string]*armnetwork.ApplicationGatewayURLPathMap) {
+	r.Meta.setupEmpty()
+	r.Listener.setupEmpty()
+	r.Redirect.setupEmpty()
+	r.Targets = make([]ApplicationGatewayRouteTarget, 0)
+	if az == nil || az.ID == nil {
+		return
+	}
+	r.Meta.FromID(*az.ID)
+	gValFromPtr(&r.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.RuleType != nil {
+		r.RuleType = string(*props.RuleType)
+	}
+	gValFromPtr(&r.Priority, props.Priority)
+	if props.HTTPListener != nil {
+		gValFromPtrFromAzure(&r.Listener, props.HTTPListener.ID)
+	}
+	if props.RedirectConfiguration != nil {
+		gValFromPtrFromAzure(&r.Redirect, props.RedirectConfiguration.ID)
+	}
+	if tgt, ok := newApplicationGatewayRouteTarget(props.BackendAddressPool, props.BackendHTTPSettings); ok {
+		r.Targets = append(r.Targets, tgt)
+	}
+	if props.URLPathMap == nil || props.URLPathMap.ID == nil {
+		return
+	}
+	pm := pathMaps[strings.ToLower(*props.URLPathMap.ID)]
+	if pm == nil || pm.Properties == nil {
+		return
+	}
+	if tgt, ok := newApplicationGatewayRouteTarget(pm.Properties.DefaultBackendAddressPool, pm.Properties.DefaultBackendHTTPSettings); ok {
+		r.Targets = append(r.Targets, tgt)
+	}
+	for _, pr := range pm.Properties.PathRules {
+		if pr == nil || pr.Properties == nil {
+			continue
+		}
+		tgt, ok := newApplicationGatewayRouteTarget(pr.Properties.BackendAddressPool, pr.Properties.BackendHTTPSettings)
+		if !ok {
+			continue
+		}
+		for _, p := range pr.Properties.Paths {
+			if p != nil {
+				tgt.Paths = append(tgt.Paths, *p)
+			}
+		}
+		r.Targets = append(r.Targets, tgt)
+	}
+}
+
+// ApplicationGatewaySSLPolicy is the TLS configuration of the gateway's
+// listeners.
+type ApplicationGatewaySSLPolicy struct {
+	PolicyType         string
+	PolicyName         string
+	MinProtocolVersion TLSVersion
+	CipherSuites       []string
+}
+
+// appGatewayPredefinedMinTLS is the minimum protocol version of each of the
+// predefined SSL policies.
+var appGatewayPredefinedMinTLS = map[armnetwork.ApplicationGatewaySSLPolicyName]TLSVersion{
+	armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20150501:  TLSVersionOneZero,
+	armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20170401:  TLSVersionOneOne,
+	armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20170401S: TLSVersionOneTwo,
+	armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20220101:  TLSVersionOneTwo,
+	armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20220101S: TLSVersionOneTwo,
+}
+
+// FromAzure loads the SSL policy. If no policy is configured the gateway
+// uses its default predefined policy, which Azure reports separately.
+func (p *ApplicationGatewaySSLPolicy) FromAzure(az *armnetwork.ApplicationGatewaySSLPolicy, def *armnetwork.ApplicationGatewaySSLPolicyName) {
+	if az == nil || (az.PolicyType == nil && az.PolicyName == nil && az.MinProtocolVersion == nil) {
+		if def != nil {
+			p.PolicyType = string(armnetwork.ApplicationGatewaySSLPolicyTypePredefined)
+			p.PolicyName = string(*def)
+			p.MinProtocolVersion = appGatewayPredefinedMinTLS[*def]
+		}
+		return
+	}
+	if az.PolicyType != nil {
+		p.PolicyType = string(*az.PolicyType)
+	}
+	p.MinProtocolVersion.FromAzureAppGateway(az.MinProtocolVersion)
+	if az.PolicyName != nil {
+		p.PolicyName = string(*az.PolicyName)
+		if p.MinProtocolVersion.IsUnknown() {
+			p.MinProtocolVersion = appGatewayPredefinedMinTLS[*az.PolicyName]
+		}
+	}
+	for _, cs := range az.CipherSuites {
+		if cs != nil {
+			p.CipherSuites = append(p.CipherSuites, string(*cs))
+		}
+	}
+}
+
+// ApplicationGatewayWAF is the web application firewall configuration. It
+// comes either from the legacy configuration on the gateway itself or from
+// an associated WAF policy, which takes precedence when present.
+type ApplicationGatewayWAF struct {
+	Enabled        UnknownBool
+	Mode           ApplicationGatewayWAFMode
+	RuleSetType    string
+	RuleSetVersion string
+	// Policy is the associated WAF policy, if any
+	Policy ResourceID
+}
+
+func (w *ApplicationGatewayWAF) FromAzure(az *armnetwork.ApplicationGatewayWebApplicationFirewallConfiguration) {
+	if az == nil {
+		w.Enabled = BoolFalse
+		return
+	}
+	w.Enabled.FromBoolPtr(az.Enabled)
+	w.Mode.FromAzure(az.FirewallMode)
+	gValFromPtr(&w.RuleSetType, az.RuleSetType)
+	gValFromPtr(&w.RuleSetVersion, az.RuleSetVersion)
+}
+
+// FromAzurePolicy overwrites the configuration with that of the associated
+// WAF policy.
+func (w *ApplicationGatewayWAF) FromAzurePolicy(az *armnetwork.WebApplicationFirewallPolicy) {
+	if az.Properties == nil {
+		return
+	}
+	props := az.Properties
+	if ps := props.PolicySettings; ps != nil {
+		if ps.State != nil {
+			w.Enabled = ubFromRhsPtr(armnetwork.WebApplicationFirewallEnabledStateEnabled, ps.State)
+		}
+		if ps.Mode != nil {
+			switch *ps.Mode {
+			case armnetwork.WebApplicationFirewallModeDetection:
+				w.Mode = ApplicationGatewayWAFModeDetection
+			case armnetwork.WebApplicationFirewallModePrevention:
+				w.Mode = ApplicationGatewayWAFModePrevention
+			default:
+				w.Mode = ApplicationGatewayWAFModeUnknown
+			}
+		}
+	}
+	if props.ManagedRules != nil {
+		for _, rs := range props.ManagedRules.ManagedRuleSets {
+			if rs == nil {
+				continue
+			}
+			gValFromPtr(&w.RuleSetType, rs.RuleSetType)
+			gValFromPtr(&w.RuleSetVersion, rs.RuleSetVersion)
+			break
+		}
+	}
+}
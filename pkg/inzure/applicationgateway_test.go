@@ -0,0 +1,174 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+const agwID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/applicationGateways/agw"
+
+func agwSub(kind string, name string) *armnetwork.SubResource {
+	return &armnetwork.SubResource{ID: ptr(agwID + "/" + kind + "/" + name)}
+}
+
+func TestApplicationGatewayFromAzure(t *testing.T) {
+	https := armnetwork.ApplicationGatewayProtocolHTTPS
+	minTLS := armnetwork.ApplicationGatewaySSLProtocolTLSv12
+	prevention := armnetwork.ApplicationGatewayFirewallModePrevention
+	az := &armnetwork.ApplicationGateway{
+		ID: ptr(agwID),
+		Properties: &armnetwork.ApplicationGatewayPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.ApplicationGatewayFrontendIPConfiguration{
+				{
+					ID: agwSub("frontendIPConfigurations", "public").ID,
+					Properties: &armnetwork.ApplicationGatewayFrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &armnetwork.SubResource{
+							ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/agw-ip"),
+						},
+					},
+				},
+			},
+			FrontendPorts: []*armnetwork.ApplicationGatewayFrontendPort{
+				{
+					ID:         agwSub("frontendPorts", "443").ID,
+					Properties: &armnetwork.ApplicationGatewayFrontendPortPropertiesFormat{Port: ptr[int32](443)},
+				},
+			},
+			HTTPListeners: []*armnetwork.ApplicationGatewayHTTPListener{
+				{
+					ID:   agwSub("httpListeners", "https").ID,
+					Name: ptr("https"),
+					Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
+						Protocol:                &https,
+						FrontendIPConfiguration: agwSub("frontendIPConfigurations", "public"),
+						FrontendPort:            agwSub("frontendPorts", "443"),
+						HostNames:               []*string{ptr("app.example.com")},
+					},
+				},
+			},
+			BackendAddressPools: []*armnetwork.ApplicationGatewayBackendAddressPool{
+				{
+					ID: agwSub("backendAddressPools", "web").ID,
+					Properties: &armnetwork.ApplicationGatewayBackendAddressPoolPropertiesFormat{
+						BackendAddresses: []*armnetwork.ApplicationGatewayBackendAddress{
+							{IPAddress: ptr("10.0.0.4")},
+						},
+					},
+				},
+				{
+					ID: agwSub("backendAddressPools", "api").ID,
+					Properties: &armnetwork.ApplicationGatewayBackendAddressPoolPropertiesFormat{
+						BackendAddresses: []*armnetwork.ApplicationGatewayBackendAddress{
+							{Fqdn: ptr("api.internal")},
+						},
+					},
+				},
+			},
+			BackendHTTPSettingsCollection: []*armnetwork.ApplicationGatewayBackendHTTPSettings{
+				{
+					ID:         agwSub("backendHttpSettingsCollection", "http").ID,
+					Properties: &armnetwork.ApplicationGatewayBackendHTTPSettingsPropertiesFormat{Port: ptr[int32](8080)},
+				},
+			},
+			URLPathMaps: []*armnetwork.ApplicationGatewayURLPathMap{
+				{
+					ID: agwSub("urlPathMaps", "paths").ID,
+					Properties: &armnetwork.ApplicationGatewayURLPathMapPropertiesFormat{
+						DefaultBackendAddressPool:  agwSub("backendAddressPools", "web"),
+						DefaultBackendHTTPSettings: agwSub("backendHttpSettingsCollection", "http"),
+						PathRules: []*armnetwork.ApplicationGatewayPathRule{
+							{
+								Properties: &armnetwork.ApplicationGatewayPathRulePropertiesFormat{
+									Paths:               []*string{ptr("/api/*")},
+									BackendAddressPool:  agwSub("backendAddressPools", "api"),
+									BackendHTTPSettings: agwSub("backendHttpSettingsCollection", "http"),
+								},
+							},
+						},
+					},
+				},
+			},
+			RequestRoutingRules: []*armnetwork.ApplicationGatewayRequestRoutingRule{
+				{
+					ID: agwSub("requestRoutingRules", "rule").ID,
+					Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
+						HTTPListener: agwSub("httpListeners", "https"),
+						URLPathMap:   agwSub("urlPathMaps", "paths"),
+					},
+				},
+			},
+			SSLPolicy: &armnetwork.ApplicationGatewaySSLPolicy{MinProtocolVersion: &minTLS},
+			WebApplicationFirewallConfiguration: &armnetwork.ApplicationGatewayWebApplicationFirewallConfiguration{
+				Enabled:        ptr(true),
+				FirewallMode:   &prevention,
+				RuleSetType:    ptr("OWASP"),
+				RuleSetVersion: ptr("3.2"),
+			},
+		},
+	}
+
+	ag := NewEmptyApplicationGateway()
+	ag.FromAzure(az)
+	if ag.Meta.Tag != ApplicationGatewayT || ag.Meta.Name != "agw" {
+		t.Fatalf("bad meta: %+v", ag.Meta)
+	}
+	if len(ag.Listeners) != 1 || ag.Listeners[0].Port != 443 || ag.Listeners[0].Protocol != "Https" {
+		t.Fatalf("bad listeners: %+v", ag.Listeners)
+	}
+	if len(ag.RoutingRules) != 1 || len(ag.RoutingRules[0].Targets) != 2 {
+		t.Fatalf("bad routing rules: %+v", ag.RoutingRules)
+	}
+	if ag.SSLPolicy.MinProtocolVersion != TLSVersionOneTwo {
+		t.Fatalf("bad min TLS version: %v", ag.SSLPolicy.MinProtocolVersion)
+	}
+	if !ag.WAF.Enabled.True() || !ag.WAF.Mode.IsPrevention().True() || ag.WAF.RuleSetVersion != "3.2" {
+		t.Fatalf("bad WAF: %+v", ag.WAF)
+	}
+
+	// The public IP is only a reference until it is filled in
+	ag.FrontendIPs[0].PublicIP.IP = "20.1.2.3"
+
+	as := ag.AttackSurface()
+	if len(as.Frontends) != 1 || as.Frontends[0] != "20.1.2.3" {
+		t.Fatalf("bad frontends: %v", as.Frontends)
+	}
+	if back := as.Paths["app.example.com:443"]; back != "10.0.0.4:8080" {
+		t.Fatalf("bad default path: %v", as.Paths)
+	}
+	if back := as.Paths["app.example.com:443/api/*"]; back != "api.internal:8080" {
+		t.Fatalf("bad api path: %v", as.Paths)
+	}
+}
+
+func TestApplicationGatewayPredefinedSSLPolicy(t *testing.T) {
+	var p ApplicationGatewaySSLPolicy
+	def := armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20150501
+	p.FromAzure(nil, &def)
+	if p.MinProtocolVersion != TLSVersionOneZero || p.PolicyName != string(def) {
+		t.Fatalf("bad default policy: %+v", p)
+	}
+}
+
+func TestApplicationGatewayWAFPolicy(t *testing.T) {
+	var w ApplicationGatewayWAF
+	w.FromAzure(nil)
+	if !w.Enabled.False() {
+		t.Fatal("WAF should be disabled without configuration")
+	}
+	enabled := armnetwork.WebApplicationFirewallEnabledStateEnabled
+	detection := armnetwork.WebApplicationFirewallModeDetection
+	w.FromAzurePolicy(&armnetwork.WebApplicationFirewallPolicy{
+		Properties: &armnetwork.WebApplicationFirewallPolicyPropertiesFormat{
+			PolicySettings: &armnetwork.PolicySettings{State: &enabled, Mode: &detection},
+			ManagedRules: &armnetwork.ManagedRulesDefinition{
+				ManagedRuleSets: []*armnetwork.ManagedRuleSet{
+					{RuleSetType: ptr("Microsoft_DefaultRuleSet"), RuleSetVersion: ptr("2.1")},
+				},
+			},
+		},
+	})
+	if !w.Enabled.True() || !w.Mode.IsDetection().True() || w.RuleSetVersion != "2.1" {
+		t.Fatalf("bad WAF policy: %+v", w)
+	}
+}
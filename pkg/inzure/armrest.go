@@ -0,0 +1,142 @@
+package inzure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// armRESTClient talks directly to the ARM REST API for resource providers
+// that we don't pull in an SDK module for. It goes through the same pipeline
+// as the SDK clients so credentials, proxies, and retries all behave the
+// same.
+//
+// This is a stopgap. Every provider that uses it has a typed arm* module
+// (armservicebus, armeventhub, armmysql, armmysqlflexibleservers,
+// armpostgresqlflexibleservers, armcdn, armfrontdoor, and armtrafficmanager)
+// that isn't a dependency of this module yet. Collectors should move to
+// those and handlePager as they're added, and this file should go away with
+// the last of them.
+//
+// The endpoint doesn't have to be ARM; data plane APIs that share the ARM
+// conventions (such as Key Vault) can use it too.
+type armRESTClient struct {
+	pipeline   runtime.Pipeline
+	endpoint   string
+	apiVersion string
+}
+
+// armListResult is the shape of every ARM list response.
+type armListResult[T any] struct {
+	Value    []*T    `json:"value"`
+	NextLink *string `json:"nextLink"`
+}
+
+func (impl *azureImpl) newARMRESTClient(apiVersion string) (*armRESTClient, error) {
+	client, err := arm.NewClient("inzure", "v0.0.0", impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &armRESTClient{
+		pipeline:   client.Pipeline(),
+		endpoint:   client.Endpoint(),
+		apiVersion: apiVersion,
+	}, nil
+}
+
+func (c *armRESTClient) do(ctx context.Context, endpoint string, into any) error {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return err
+	}
+	// nextLinks already have the query string set
+	if req.Raw().URL.Query().Get("api-version") == "" {
+		q := req.Raw().URL.Query()
+		q.Set("api-version", c.apiVersion)
+		req.Raw().URL.RawQuery = q.Encode()
+	}
+	req.Raw().Header["Accept"] = []string{"application/json"}
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, into)
+}
+
+// armRESTGet gets a single resource by its ID or path relative to the
+// endpoint.
+func armRESTGet[T any](ctx context.Context, c *armRESTClient, path string, into *T) error {
+	endpoint, err := url.JoinPath(c.endpoint, path)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, endpoint, into)
+}
+
+// armRESTPager lists resources at the given path relative to the endpoint,
+// such as
+// /subscriptions/{sub}/resourceGroups/{rg}/providers/{provider}/{type}
+func armRESTPager[T any](c *armRESTClient, path string) *runtime.Pager[armListResult[T]] {
+	return runtime.NewPager(runtime.PagingHandler[armListResult[T]]{
+		More: func(page armListResult[T]) bool {
+			return page.NextLink != nil && len(*page.NextLink) > 0
+		},
+		Fetcher: func(ctx context.Context, page *armListResult[T]) (armListResult[T], error) {
+			var res armListResult[T]
+			var endpoint string
+			var err error
+			if page == nil {
+				endpoint, err = url.JoinPath(c.endpoint, path)
+				if err != nil {
+					return res, err
+				}
+			} else {
+				endpoint = *page.NextLink
+			}
+			err = c.do(ctx, endpoint, &res)
+			return res, err
+		},
+	})
+}
+
+// armRESTList collects every resource at the given path. This is meant for
+// child resources that are needed to fill in their parent.
+func armRESTList[T any](ctx context.Context, c *armRESTClient, path string, errTransform func(error) error, ec chan<- error) []*T {
+	items := make([]*T, 0)
+	found := handlePager(ctx,
+		func() (*runtime.Pager[armListResult[T]], error) {
+			return armRESTPager[T](c, path), nil
+		},
+		func(az armListResult[T], out chan<- *T) (bool, error) {
+			for _, v := range az.Value {
+				if v == nil {
+					continue
+				}
+				if !sendChan(ctx, v, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		errTransform,
+		ec,
+	)
+	for v := range found {
+		items = append(items, v)
+	}
+	return items
+}
+
+// resourceGroupProviderPath is the path to all resources of the given type in
+// the resource group.
+func resourceGroupProviderPath(sub string, rg string, resourceType string) string {
+	return "/subscriptions/" + url.PathEscape(sub) +
+		"/resourceGroups/" + url.PathEscape(rg) +
+		"/providers/" + resourceType
+}
@@ -1,26 +1,63 @@
 package inzure
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate go run gen/enum.go -type-name EndpointExposure -prefix Exposure -values Public,PrivateOnly,PublicAndPrivate
 
 // AttackSurface contains a collection of IP addresses and domain names
 // that may POTENTIALLY be exposed. Note that there is no evaluation of
 // firewalls at this point!
 type AttackSurface struct {
-	WebApps           []string
-	Functions         []string
-	LoadBalancers     []LoadBalancerAttackSurface
-	VirtualMachines   []string
-	MSQL              []string
-	Redis             []string
-	PostgreSQL        []string
-	CosmosDBs         []string
-	DataLakeAnalytics []string
-	DataLakeStores    []string
-	KeyVaults         []string
-	PublicContainers  []string
-	BastionHosts      []string
-	Grafanas          []string
-	APIServices       []APIServiceAttackSurface
+	WebApps       []string
+	Functions     []string
+	LoadBalancers []LoadBalancerAttackSurface
+	// ApplicationGateways are public frontends of Application Gateways and
+	// the paths from their listeners to backend pools
+	ApplicationGateways []LoadBalancerAttackSurface
+	VirtualMachines     []string
+	// VirtualMachineScaleSets are the public addresses of scale set instances
+	VirtualMachineScaleSets []string
+	MSQL                    []string
+	Redis                   []string
+	PostgreSQL              []string
+	MySQL                   []string
+	CosmosDBs               []string
+	DataLakeAnalytics       []string
+	DataLakeStores          []string
+	KeyVaults               []string
+	PublicContainers        []string
+	BastionHosts            []string
+	Grafanas                []string
+	// KubernetesClusters are the FQDNs of API servers that aren't private
+	KubernetesClusters []string
+	// ContainerRegistries are the login servers of registries that allow
+	// public network access
+	ContainerRegistries []string
+	// ServiceBusNamespaces and EventHubNamespaces are the FQDNs of namespaces
+	// that allow public network access
+	ServiceBusNamespaces []string
+	EventHubNamespaces   []string
+	APIServices          []APIServiceAttackSurface
+	// PublicIPs are all of the allocated public IP addresses in the
+	// subscription. This is the authoritative inventory and includes
+	// addresses bound to gateways and firewalls that aren't listed elsewhere.
+	PublicIPs []string
+	// UnattachedPublicIPs are allocated addresses that aren't used by any
+	// resource. These are often left over from deleted resources.
+	UnattachedPublicIPs []string
+	// Exposure says how each endpoint of a resource that supports private
+	// endpoints can be reached. Endpoints that are only reachable through a
+	// private endpoint are in here but not in the lists above.
+	Exposure map[string]EndpointExposure
+	// FrontDoors and CDNEndpoints are the edge endpoints of Front Door and
+	// CDN profiles mapped to their origins
+	FrontDoors   []EdgeAttackSurface
+	CDNEndpoints []EdgeAttackSurface
+	// TrafficManagers map Traffic Manager DNS names to their endpoints
+	TrafficManagers []EdgeAttackSurface
 }
 
 // LoadBalancerAttackSurface provides both a list of frontend IPs, backend IPs,
@@ -31,6 +68,19 @@ type LoadBalancerAttackSurface struct {
 	Paths     map[string]string
 }
 
+// EdgeAttackSurface maps the public host names of a Front Door, CDN, or
+// Traffic Manager endpoint to the origins behind it.
+type EdgeAttackSurface struct {
+	// Hostnames are the Azure provided and custom host names of the edge
+	Hostnames []string
+	Origins   []string
+	// WAFPolicy is the ID of the WAF policy in front of the origins, if any
+	WAFPolicy string
+	// BypassableOrigins are origins that can be reached directly because
+	// their firewall doesn't restrict traffic to the Front Door
+	BypassableOrigins []string
+}
+
 // APIServiceAttackSurface is the attack surface presented by managed APIs.
 // Note that, since we have read access to all API Management Services, we can
 // sometimes even get direct backend URLs as well.
@@ -41,33 +91,169 @@ type APIServiceAttackSurface struct {
 
 func NewEmptyAttackSurface() AttackSurface {
 	return AttackSurface{
-		WebApps:           make([]string, 0),
-		Functions:         make([]string, 0),
-		LoadBalancers:     make([]LoadBalancerAttackSurface, 0),
-		VirtualMachines:   make([]string, 0),
-		MSQL:              make([]string, 0),
-		Redis:             make([]string, 0),
-		PostgreSQL:        make([]string, 0),
-		CosmosDBs:         make([]string, 0),
-		DataLakeAnalytics: make([]string, 0),
-		DataLakeStores:    make([]string, 0),
-		KeyVaults:         make([]string, 0),
-		PublicContainers:  make([]string, 0),
-		BastionHosts:      make([]string, 0),
-		Grafanas:          make([]string, 0),
-		APIServices:       make([]APIServiceAttackSurface, 0),
+		WebApps:                 make([]string, 0),
+		Functions:               make([]string, 0),
+		LoadBalancers:           make([]LoadBalancerAttackSurface, 0),
+		ApplicationGateways:     make([]LoadBalancerAttackSurface, 0),
+		VirtualMachines:         make([]string, 0),
+		VirtualMachineScaleSets: make([]string, 0),
+		MSQL:                    make([]string, 0),
+		Redis:                   make([]string, 0),
+		PostgreSQL:              make([]string, 0),
+		MySQL:                   make([]string, 0),
+		CosmosDBs:               make([]string, 0),
+		DataLakeAnalytics:       make([]string, 0),
+		DataLakeStores:          make([]string, 0),
+		KeyVaults:               make([]string, 0),
+		PublicContainers:        make([]string, 0),
+		BastionHosts:            make([]string, 0),
+		Grafanas:                make([]string, 0),
+		KubernetesClusters:      make([]string, 0),
+		ContainerRegistries:     make([]string, 0),
+		ServiceBusNamespaces:    make([]string, 0),
+		EventHubNamespaces:      make([]string, 0),
+		APIServices:             make([]APIServiceAttackSurface, 0),
+		PublicIPs:               make([]string, 0),
+		UnattachedPublicIPs:     make([]string, 0),
+		Exposure:                make(map[string]EndpointExposure),
+		FrontDoors:              make([]EdgeAttackSurface, 0),
+		CDNEndpoints:            make([]EdgeAttackSurface, 0),
+		TrafficManagers:         make([]EdgeAttackSurface, 0),
+	}
+}
+
+// privateEndpointTargets is the set of lower cased resource IDs that have an
+// approved private endpoint connection.
+type privateEndpointTargets map[string]struct{}
+
+func (s *Subscription) privateEndpointTargets() privateEndpointTargets {
+	targets := make(privateEndpointTargets)
+	for _, rg := range s.ResourceGroups {
+		for _, pe := range rg.PrivateEndpoints {
+			for _, c := range pe.Connections {
+				if c.Approved() && c.Target.RawID != "" {
+					targets[strings.ToLower(c.Target.RawID)] = struct{}{}
+				}
+			}
+		}
+		for _, sa := range rg.StorageAccounts {
+			if len(sa.PrivateEndpoints) > 0 {
+				targets[strings.ToLower(sa.Meta.RawID)] = struct{}{}
+			}
+		}
+		for _, c := range rg.CosmosDBs {
+			if len(c.PrivateEndpoints) > 0 {
+				targets[strings.ToLower(c.Meta.RawID)] = struct{}{}
+			}
+		}
+		for _, gf := range rg.Grafanas {
+			for _, c := range gf.PrivateEndpointConnections {
+				if c.Connected.True() {
+					targets[strings.ToLower(gf.Meta.RawID)] = struct{}{}
+				}
+			}
+		}
 	}
+	return targets
+}
+
+// exposure combines a resource's public network access setting with whether
+// it has a private endpoint. Resources where public access is unknown are
+// assumed to be public. The second return is false if the resource can't be
+// reached at all.
+func (t privateEndpointTargets) exposure(public UnknownBool, id *ResourceID) (EndpointExposure, bool) {
+	_, private := t[strings.ToLower(id.RawID)]
+	switch {
+	case public.False() && private:
+		return ExposurePrivateOnly, true
+	case public.False():
+		return ExposureUnknown, false
+	case private:
+		return ExposurePublicAndPrivate, true
+	default:
+		return ExposurePublic, true
+	}
+}
+
+// webAppPublicAccess is the WebApp's public network access. Apps with a
+// private endpoint and no known setting are assumed to only be private.
+func (t privateEndpointTargets) webAppPublicAccess(wa *WebApp) UnknownBool {
+	if _, private := t[strings.ToLower(wa.Meta.RawID)]; private && wa.PublicNetworkAccess.Unknown() {
+		return BoolFalse
+	}
+	return wa.PublicNetworkAccess
+}
+
+// publiclyReachable is whether the resource can be reached without going
+// through a private endpoint.
+func (t privateEndpointTargets) publiclyReachable(public UnknownBool, id *ResourceID) bool {
+	e, reachable := t.exposure(public, id)
+	return reachable && e != ExposurePrivateOnly
+}
+
+// addEndpoint records the exposure of the endpoint and adds it to the list if
+// it is publicly reachable.
+func (as *AttackSurface) addEndpoint(list *[]string, endpoint string, targets privateEndpointTargets, public UnknownBool, id *ResourceID) {
+	if endpoint == "" {
+		return
+	}
+	e, reachable := targets.exposure(public, id)
+	if !reachable {
+		return
+	}
+	as.Exposure[endpoint] = e
+	if e != ExposurePrivateOnly {
+		*list = append(*list, endpoint)
+	}
+}
+
+// publicIPInventory maps lower cased resource IDs to the collected
+// PublicIPAddresses.
+type publicIPInventory map[string]*PublicIPAddress
+
+func (s *Subscription) publicIPInventory() publicIPInventory {
+	inv := make(publicIPInventory)
+	for _, rg := range s.ResourceGroups {
+		for _, pip := range rg.PublicIPAddresses {
+			inv[strings.ToLower(pip.Meta.RawID)] = pip
+		}
+	}
+	return inv
+}
+
+// address is the address of the embedded PublicIP. If the PublicIP is only a
+// reference it is filled in from the inventory.
+func (inv publicIPInventory) address(p *PublicIP) string {
+	if addr := p.address(); addr != "" {
+		return addr
+	}
+	if pip, ok := inv[strings.ToLower(p.Meta.RawID)]; ok {
+		return pip.Address()
+	}
+	return ""
 }
 
 func (s *Subscription) GetAttackSurface() AttackSurface {
 	as := NewEmptyAttackSurface()
+	targets := s.privateEndpointTargets()
+	pips := s.publicIPInventory()
 	for _, rg := range s.ResourceGroups {
+		for _, pip := range rg.PublicIPAddresses {
+			if pip.IP == "" {
+				continue
+			}
+			as.PublicIPs = append(as.PublicIPs, pip.IP)
+			if !pip.IsAttached() {
+				as.UnattachedPublicIPs = append(as.UnattachedPublicIPs, pip.IP)
+			}
+		}
+
 		for _, wa := range rg.WebApps {
 
 			if wa.Enabled.False() {
 				continue
 			}
-			as.WebApps = append(as.WebApps, wa.DefaultHostname)
+			as.addEndpoint(&as.WebApps, wa.DefaultHostname, targets, targets.webAppPublicAccess(wa), &wa.Meta)
 
 			for _, f := range wa.Functions {
 				if f.IsDisabled.False() || f.IsDisabled.Unknown() {
@@ -88,34 +274,59 @@ func (s *Subscription) GetAttackSurface() AttackSurface {
 		}
 
 		for _, rs := range rg.RedisServers {
-			as.Redis = append(as.Redis, rs.Host)
+			as.addEndpoint(&as.Redis, rs.Host, targets, rs.PublicNetworkAccess, &rs.Meta)
 		}
 
 		for _, psql := range rg.PostgresServers {
-			as.PostgreSQL = append(as.PostgreSQL, psql.FQDN)
+			as.addEndpoint(&as.PostgreSQL, psql.FQDN, targets, BoolUnknown, &psql.Meta)
+		}
+
+		for _, psql := range rg.PostgresFlexibleServers {
+			as.addEndpoint(&as.PostgreSQL, psql.FQDN, targets, psql.IsPublic(), &psql.Meta)
+		}
+
+		for _, mysql := range rg.MySQLServers {
+			as.addEndpoint(&as.MySQL, mysql.FQDN, targets, mysql.PublicNetworkAccess, &mysql.Meta)
+		}
+
+		for _, mysql := range rg.MySQLFlexibleServers {
+			as.addEndpoint(&as.MySQL, mysql.FQDN, targets, mysql.IsPublic(), &mysql.Meta)
 		}
 
 		for _, msql := range rg.SQLServers {
-			as.MSQL = append(as.MSQL, msql.FQDN)
+			as.addEndpoint(&as.MSQL, msql.FQDN, targets, msql.PublicNetworkAccess, &msql.Meta)
 		}
 
 		for _, kv := range rg.KeyVaults {
-			as.KeyVaults = append(as.KeyVaults, kv.URL)
+			as.addEndpoint(&as.KeyVaults, kv.URL, targets, kv.Firewall.PublicNetworkAccess, &kv.Meta)
 		}
 
 		for _, gf := range rg.Grafanas {
-			if gf.Endpoint != "" {
-				as.Grafanas = append(as.Grafanas, gf.Endpoint)
+			as.addEndpoint(&as.Grafanas, gf.Endpoint, targets, gf.PublicNetworkAccess, &gf.Meta)
+		}
+
+		for _, kc := range rg.KubernetesClusters {
+			if kc.FQDN != "" && kc.PublicAPIServer().TrueOrUnknown() {
+				as.KubernetesClusters = append(as.KubernetesClusters, kc.FQDN)
 			}
+		}
 
+		for _, ns := range rg.ServiceBusNamespaces {
+			as.addEndpoint(&as.ServiceBusNamespaces, ns.FQDN, targets, ns.Firewall.PublicNetworkAccess, &ns.Meta)
+		}
+
+		for _, ns := range rg.EventHubNamespaces {
+			as.addEndpoint(&as.EventHubNamespaces, ns.FQDN, targets, ns.Firewall.PublicNetworkAccess, &ns.Meta)
+		}
+
+		for _, cr := range rg.ContainerRegistries {
+			as.addEndpoint(&as.ContainerRegistries, cr.LoginServer, targets, cr.Firewall.PublicNetworkAccess, &cr.Meta)
 		}
 
 		for _, bh := range rg.BastionHosts {
 			for _, ipc := range bh.IPConfigurations {
-				if ipc.PublicIP.FQDN != "" {
-					as.BastionHosts = append(as.BastionHosts, ipc.PublicIP.FQDN)
-				} else if ipc.PublicIP.IP != "" {
-					as.BastionHosts = append(as.BastionHosts, ipc.PublicIP.IP)
+				if addr := pips.address(&ipc.PublicIP); addr != "" {
+					as.BastionHosts = append(as.BastionHosts, addr)
 				}
 			}
 		}
@@ -123,7 +334,7 @@ func (s *Subscription) GetAttackSurface() AttackSurface {
 		for _, sa := range rg.StorageAccounts {
 			for _, c := range sa.Containers {
 				if c.Access.IsBlob().True() || c.Access.IsContainer().True() {
-					as.PublicContainers = append(as.PublicContainers, c.URL)
+					as.addEndpoint(&as.PublicContainers, c.URL, targets, sa.Firewall.PublicNetworkAccess, &sa.Meta)
 				}
 			}
 		}
@@ -135,19 +346,15 @@ func (s *Subscription) GetAttackSurface() AttackSurface {
 				Paths:     make(map[string]string),
 			}
 			for _, fip := range lb.FrontendIPs {
-				if fip.PublicIP.FQDN != "" {
-					lbas.Frontends = append(lbas.Frontends, fip.PublicIP.FQDN)
-				} else if fip.PublicIP.IP != "" {
-					lbas.Frontends = append(lbas.Frontends, fip.PublicIP.IP)
+				if addr := pips.address(&fip.PublicIP); addr != "" {
+					lbas.Frontends = append(lbas.Frontends, addr)
 				}
 			}
 
 			for _, b := range lb.Backends {
 				for _, ipc := range b.IPConfigurations {
-					if ipc.PublicIP.FQDN != "" {
-						lbas.Backends = append(lbas.Backends, ipc.PublicIP.FQDN)
-					} else if ipc.PublicIP.IP != "" {
-						lbas.Backends = append(lbas.Backends, ipc.PublicIP.IP)
+					if addr := pips.address(&ipc.PublicIP); addr != "" {
+						lbas.Backends = append(lbas.Backends, addr)
 					}
 				}
 			}
@@ -167,23 +374,64 @@ func (s *Subscription) GetAttackSurface() AttackSurface {
 			}
 		}
 
+		for _, ag := range rg.ApplicationGateways {
+			agas := ag.AttackSurface()
+			if len(agas.Frontends) > 0 || len(agas.Paths) > 0 {
+				as.ApplicationGateways = append(as.ApplicationGateways, agas)
+			}
+		}
+
 		for _, cdb := range rg.CosmosDBs {
-			as.CosmosDBs = append(as.CosmosDBs, cdb.Endpoint)
+			as.addEndpoint(&as.CosmosDBs, cdb.Endpoint, targets, cdb.Firewall.PublicNetworkAllowed, &cdb.Meta)
+		}
+
+		for _, vmss := range rg.VirtualMachineScaleSets {
+			as.VirtualMachineScaleSets = append(as.VirtualMachineScaleSets, vmss.PublicAddresses()...)
 		}
 
 		for _, vm := range rg.VirtualMachines {
 			for _, nic := range vm.NetworkInterfaces {
 				for _, ipc := range nic.IPConfigurations {
-					pip := ipc.PublicIP
-					if pip.FQDN != "" {
-						as.VirtualMachines = append(as.VirtualMachines, pip.FQDN)
-					} else if pip.IP != "" {
-						as.VirtualMachines = append(as.VirtualMachines, pip.IP)
+					if addr := pips.address(&ipc.PublicIP); addr != "" {
+						as.VirtualMachines = append(as.VirtualMachines, addr)
 					}
 				}
 			}
 		}
 
+		for _, p := range rg.CDNProfiles {
+			list := &as.CDNEndpoints
+			if p.IsFrontDoor() {
+				list = &as.FrontDoors
+			}
+			for i := range p.Endpoints {
+				if p.Endpoints[i].Enabled.False() {
+					continue
+				}
+				*list = append(*list, s.edgeAttackSurface(&p.Endpoints[i], targets, p.IsFrontDoor(), p.FrontDoorID))
+			}
+		}
+
+		for _, fd := range rg.FrontDoors {
+			for i := range fd.Endpoints {
+				if fd.Endpoints[i].Enabled.False() {
+					continue
+				}
+				as.FrontDoors = append(as.FrontDoors, s.edgeAttackSurface(&fd.Endpoints[i], targets, true, fd.FrontDoorID))
+			}
+		}
+
+		for _, tm := range rg.TrafficManagerProfiles {
+			if tm.FQDN == "" || tm.Enabled.False() {
+				continue
+			}
+			as.TrafficManagers = append(as.TrafficManagers, EdgeAttackSurface{
+				Hostnames:         []string{tm.FQDN},
+				Origins:           tm.Targets(),
+				BypassableOrigins: make([]string, 0),
+			})
+		}
+
 		/**
 		 * This will be a little more complicated. We can actually build full
 		 * URLs from this. information. We could also potentially find the
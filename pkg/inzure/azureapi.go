@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -21,19 +22,25 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dashboard/armdashboard"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datalake-analytics/armdatalakeanalytics"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datalake-store/armdatalakestore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/redis/armredis"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sqlvirtualmachine/armsqlvirtualmachine"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
 	"github.com/Azure/go-autorest/autorest/azure"
 
 	"github.com/Azure/azure-sdk-for-go/services/classic/management"
@@ -101,12 +108,27 @@ type AzureAPI interface {
 	// NetworkInterface structs only contain the ResourceID and need to be
 	// fully populated via results from other API calls.
 	GetVirtualMachines(ctx context.Context, sub string, ec chan<- error) <-chan *VirtualMachine
+	// GetVirtualMachineScaleSets gets the VM Scale Sets in the given resource
+	// group along with each of their instances. Each instance's network
+	// interfaces and public IPs are fully populated.
+	GetVirtualMachineScaleSets(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *VirtualMachineScaleSet
 	// GetLoadBalancers gets all LoadBalancers in a given resource group. If rg
 	// is an empty string, it gets all of them regardless of resource group.
 	GetLoadBalancers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *LoadBalancer
 	GetDataLakeStores(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *DataLakeStore
 	GetDataLakeAnalytics(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *DataLakeAnalytics
 	GetPostgresServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PostgresServer
+	// GetPostgresFlexibleServers gets all PostgreSQL Flexible Servers in the
+	// given resource group with their firewall rules, AAD admins, and TLS
+	// server parameters.
+	GetPostgresFlexibleServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PostgresFlexibleServer
+	// GetMySQLServers gets all MySQL single servers in the given resource
+	// group.
+	GetMySQLServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *MySQLServer
+	// GetMySQLFlexibleServers gets all MySQL Flexible Servers in the given
+	// resource group with their firewall rules, AAD admins, and TLS server
+	// parameters.
+	GetMySQLFlexibleServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *MySQLFlexibleServer
 	GetSQLServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *SQLServer
 	GetCosmosDBs(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *CosmosDB
 	GetNetworkInterfaces(ctx context.Context, sub string, ec chan<- error) <-chan *NetworkInterface
@@ -116,16 +138,74 @@ type AzureAPI interface {
 	// specifically about those network interfaces and subnets, that info can
 	// be gathered from the VirtualNetworks structs.
 	GetNetworkSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *NetworkSecurityGroup
+	// GetRoleDefinitions gets all of the RBAC role definitions, both built in
+	// and custom, that are assignable in the subscription.
+	GetRoleDefinitions(ctx context.Context, sub string, ec chan<- error) <-chan *RoleDefinition
+	// GetRoleAssignments gets all of the RBAC role assignments that apply to
+	// the subscription. This includes assignments at the subscription,
+	// resource group, and resource scopes as well as those inherited from
+	// above the subscription.
+	GetRoleAssignments(ctx context.Context, sub string, ec chan<- error) <-chan *RoleAssignment
 	GetApplicationSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *ApplicationSecurityGroup
+	// GetRouteTables gets all of the route tables in the subscription along
+	// with their routes and associated subnets.
+	GetRouteTables(ctx context.Context, sub string, ec chan<- error) <-chan *RouteTable
+	// GetNATGateways gets all of the NAT gateways in the subscription.
+	GetNATGateways(ctx context.Context, sub string, ec chan<- error) <-chan *NATGateway
+	// GetPublicIPAddresses gets all of the public IP addresses in the
+	// subscription whether or not they are attached to anything.
+	GetPublicIPAddresses(ctx context.Context, sub string, ec chan<- error) <-chan *PublicIPAddress
+	// GetVirtualNetworkGateways gets the VPN and ExpressRoute gateways in the
+	// given resource group along with their connections.
+	GetVirtualNetworkGateways(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *VirtualNetworkGateway
 	GetWebApps(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *WebApp
 	GetAPIs(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *APIService
 	GetStorageAccounts(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *StorageAccount
 	GetRedisServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *RedisServer
 	GetKeyVaults(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *KeyVault
+	// GetUserAssignedIdentities gets the user assigned managed identities in
+	// the given resource group along with their federated credentials.
+	GetUserAssignedIdentities(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *UserAssignedIdentity
+	// GetPrivateEndpoints gets the private endpoints in the given resource
+	// group. Their network interfaces are fetched so the private IPs are
+	// known.
+	GetPrivateEndpoints(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PrivateEndpoint
+	// GetPrivateDNSZones gets the private DNS zones in the given resource
+	// group along with their virtual network links.
+	GetPrivateDNSZones(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PrivateDNSZone
 
 	GetBastionHosts(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *BastionHost
 	GetGrafanas(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *Grafana
 	GetSQLVirtualMachines(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *SQLVirtualMachine
+	// GetKubernetesClusters gets all AKS managed clusters in the given
+	// resource group.
+	GetKubernetesClusters(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *KubernetesCluster
+	GetContainerRegistries(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ContainerRegistry
+	// GetApplicationGateways gets all Application Gateways in the given
+	// resource group including their frontend public IPs and any associated
+	// WAF policy.
+	GetApplicationGateways(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ApplicationGateway
+	GetAzureFirewalls(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *AzureFirewall
+	// GetFirewallPolicies gets all Azure Firewall Policies in the given
+	// resource group along with all of their rule collection groups.
+	GetFirewallPolicies(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *FirewallPolicy
+	// GetServiceBusNamespaces gets all Service Bus namespaces in the given
+	// resource group along with their authorization rules and network rule
+	// set.
+	GetServiceBusNamespaces(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ServiceBusNamespace
+	// GetEventHubNamespaces gets all Event Hubs namespaces in the given
+	// resource group along with their authorization rules and network rule
+	// set.
+	GetEventHubNamespaces(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *EventHubNamespace
+	// GetCDNProfiles gets all Front Door Standard/Premium and classic CDN
+	// profiles in the given resource group along with their endpoints,
+	// origins, custom domains, and WAF policies.
+	GetCDNProfiles(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *CDNProfile
+	// GetFrontDoors gets all classic Front Doors in the given resource group.
+	GetFrontDoors(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *FrontDoor
+	// GetTrafficManagerProfiles gets all Traffic Manager profiles in the
+	// given resource group along with their endpoints.
+	GetTrafficManagerProfiles(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *TrafficManagerProfile
 
 	// The following methods deal with classic accounts
 
@@ -1026,6 +1106,305 @@ func (impl *azureImpl) GetPostgresServers(ctx context.Context, sub string, rg st
 
 }
 
+func (impl *azureImpl) GetPostgresFlexibleServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PostgresFlexibleServer {
+	client, err := impl.newARMRESTClient(postgresFlexibleAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, PostgresFlexibleServerT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armListResult[azDBServer]], error) {
+		return armRESTPager[azDBServer](client, resourceGroupProviderPath(sub, rg, postgresFlexibleProvider)), nil
+	}
+
+	handler := func(az armListResult[azDBServer], out chan<- *PostgresFlexibleServer) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, srv := range az.Value {
+			it := NewEmptyPostgresFlexibleServer()
+			it.fromAzure(srv)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				impl.fillPostgresFlexibleServer(ctx, client, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, PostgresFlexibleServerT, "ListServers"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) fillPostgresFlexibleServer(ctx context.Context, client *armRESTClient, srv *PostgresFlexibleServer, ec chan<- error) {
+	var wg sync.WaitGroup
+
+	sub := srv.Meta.Subscription
+	id := srv.Meta.RawID
+
+	fwRules := impl.getDBFirewallRules(ctx, sub, client, PostgresFlexibleServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs((*[]FirewallRule)(&srv.Firewall), fwRules, &wg)
+
+	admins := impl.getDBAdmins(ctx, sub, client, PostgresFlexibleServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs(&srv.AADAdmins, admins, &wg)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := impl.getDBConfiguration(ctx, client, id, dbRequireSecureTransportSetting)
+		if err != nil {
+			sendErr(ctx, genericError(sub, PostgresFlexibleServerT, "GetConfiguration", err), ec)
+			return
+		}
+		srv.SSLEnforced = dbOnOffString(v)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := impl.getDBConfiguration(ctx, client, id, postgresMinTLSVersionSetting)
+		if err != nil {
+			sendErr(ctx, genericError(sub, PostgresFlexibleServerT, "GetConfiguration", err), ec)
+			return
+		}
+		srv.MinimumTLSVersion.FromAzureServerParameter(v)
+	}()
+
+	wg.Wait()
+}
+
+func (impl *azureImpl) GetMySQLServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *MySQLServer {
+	client, err := impl.newARMRESTClient(mysqlAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, MySQLServerT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armListResult[azDBServer]], error) {
+		return armRESTPager[azDBServer](client, resourceGroupProviderPath(sub, rg, mysqlProvider)), nil
+	}
+
+	handler := func(az armListResult[azDBServer], out chan<- *MySQLServer) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, srv := range az.Value {
+			it := NewEmptyMySQLServer()
+			it.fromAzure(srv)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				impl.fillMySQLServer(ctx, client, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, MySQLServerT, "ListServers"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) fillMySQLServer(ctx context.Context, client *armRESTClient, srv *MySQLServer, ec chan<- error) {
+	var wg sync.WaitGroup
+
+	sub := srv.Meta.Subscription
+	id := srv.Meta.RawID
+
+	fwRules := impl.getDBFirewallRules(ctx, sub, client, MySQLServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs((*[]FirewallRule)(&srv.Firewall), fwRules, &wg)
+
+	admins := impl.getDBAdmins(ctx, sub, client, MySQLServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs(&srv.AADAdmins, admins, &wg)
+
+	subnets := handlePager(ctx,
+		func() (*runtime.Pager[armListResult[azDBVirtualNetworkRule]], error) {
+			return armRESTPager[azDBVirtualNetworkRule](client, id+"/virtualNetworkRules"), nil
+		},
+		func(az armListResult[azDBVirtualNetworkRule], out chan<- *ResourceID) (bool, error) {
+			for _, v := range az.Value {
+				if v.Properties == nil || v.Properties.VirtualNetworkSubnetID == nil {
+					continue
+				}
+				var rid ResourceID
+				rid.FromID(*v.Properties.VirtualNetworkSubnetID)
+				if !sendChan(ctx, &rid, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		genericErrorTransform(sub, MySQLServerT, "ListVirtualNetworkRules"),
+		ec,
+	)
+	wg.Add(1)
+	go chanToSlicePtrs(&srv.Subnets, subnets, &wg)
+
+	wg.Wait()
+}
+
+func (impl *azureImpl) GetMySQLFlexibleServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *MySQLFlexibleServer {
+	client, err := impl.newARMRESTClient(mysqlFlexibleAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, MySQLFlexibleServerT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armListResult[azDBServer]], error) {
+		return armRESTPager[azDBServer](client, resourceGroupProviderPath(sub, rg, mysqlFlexibleProvider)), nil
+	}
+
+	handler := func(az armListResult[azDBServer], out chan<- *MySQLFlexibleServer) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, srv := range az.Value {
+			it := NewEmptyMySQLFlexibleServer()
+			it.fromAzure(srv)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				impl.fillMySQLFlexibleServer(ctx, client, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, MySQLFlexibleServerT, "ListServers"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) fillMySQLFlexibleServer(ctx context.Context, client *armRESTClient, srv *MySQLFlexibleServer, ec chan<- error) {
+	var wg sync.WaitGroup
+
+	sub := srv.Meta.Subscription
+	id := srv.Meta.RawID
+
+	fwRules := impl.getDBFirewallRules(ctx, sub, client, MySQLFlexibleServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs((*[]FirewallRule)(&srv.Firewall), fwRules, &wg)
+
+	admins := impl.getDBAdmins(ctx, sub, client, MySQLFlexibleServerT, id, ec)
+	wg.Add(1)
+	go chanToSlicePtrs(&srv.AADAdmins, admins, &wg)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := impl.getDBConfiguration(ctx, client, id, dbRequireSecureTransportSetting)
+		if err != nil {
+			sendErr(ctx, genericError(sub, MySQLFlexibleServerT, "GetConfiguration", err), ec)
+			return
+		}
+		srv.SSLEnforced = dbOnOffString(v)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := impl.getDBConfiguration(ctx, client, id, mysqlTLSVersionSetting)
+		if err != nil {
+			sendErr(ctx, genericError(sub, MySQLFlexibleServerT, "GetConfiguration", err), ec)
+			return
+		}
+		srv.MinimumTLSVersion.FromAzureServerParameter(v)
+	}()
+
+	wg.Wait()
+}
+
+// getDBFirewallRules lists the firewall rules of a MySQL or PostgreSQL
+// server given its ID.
+func (impl *azureImpl) getDBFirewallRules(ctx context.Context, sub string, client *armRESTClient, tag AzureResourceTag, id string, ec chan<- error) <-chan *FirewallRule {
+	getter := func() (*runtime.Pager[armListResult[azDBFirewallRule]], error) {
+		return armRESTPager[azDBFirewallRule](client, id+"/firewallRules"), nil
+	}
+
+	handler := func(az armListResult[azDBFirewallRule], out chan<- *FirewallRule) (bool, error) {
+		for _, v := range az.Value {
+			it := new(FirewallRule)
+			it.fromAzureDB(v)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, tag, "ListFirewallRules"), ec)
+}
+
+// getDBAdmins lists the AAD administrators of a MySQL or PostgreSQL server
+// given its ID.
+func (impl *azureImpl) getDBAdmins(ctx context.Context, sub string, client *armRESTClient, tag AzureResourceTag, id string, ec chan<- error) <-chan *DatabaseAADAdmin {
+	getter := func() (*runtime.Pager[armListResult[azDBAdministrator]], error) {
+		return armRESTPager[azDBAdministrator](client, id+"/administrators"), nil
+	}
+
+	handler := func(az armListResult[azDBAdministrator], out chan<- *DatabaseAADAdmin) (bool, error) {
+		for _, v := range az.Value {
+			it := new(DatabaseAADAdmin)
+			it.fromAzure(v)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, tag, "ListAdministrators"), ec)
+}
+
+// getDBConfiguration gets the value of a single server parameter of a
+// flexible server.
+func (impl *azureImpl) getDBConfiguration(ctx context.Context, client *armRESTClient, id string, name string) (*string, error) {
+	var conf azDBConfiguration
+	if err := armRESTGet(ctx, client, id+"/configurations/"+name, &conf); err != nil {
+		return nil, err
+	}
+	return conf.value(), nil
+}
+
 func (impl *azureImpl) fillSQLServer(ctx context.Context, srv *SQLServer, out chan<- *SQLServer, ec chan<- error) {
 	var wg sync.WaitGroup
 
@@ -1474,78 +1853,356 @@ func (impl *azureImpl) GetSQLVirtualMachines(ctx context.Context, sub string, rg
 
 }
 
-func (impl *azureImpl) GetGrafanas(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *Grafana {
+func (impl *azureImpl) GetKubernetesClusters(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *KubernetesCluster {
 
-	client, err := armdashboard.NewGrafanaClient(sub, impl.tokenCredential, impl.clientOptions)
+	client, err := armcontainerservice.NewManagedClustersClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(ctx, genericError(sub, GrafanaT, "GetGrafanaClient", err), ec)
+		sendErr(ctx, genericError(sub, KubernetesClusterT, "GetManagedClustersClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armdashboard.ManagedGrafanaListResponse], error) {
+	getter := func() (*runtime.Pager[armcontainerservice.ManagedClusterListResult], error) {
 		base := client.NewListByResourceGroupPager(rg, nil)
 		return wrapPager(base,
-			func(in armdashboard.GrafanaClientListByResourceGroupResponse) armdashboard.ManagedGrafanaListResponse {
-				return in.ManagedGrafanaListResponse
+			func(in armcontainerservice.ManagedClustersClientListByResourceGroupResponse) armcontainerservice.ManagedClusterListResult {
+				return in.ManagedClusterListResult
 			},
-			func(page armdashboard.ManagedGrafanaListResponse) *string { return page.NextLink },
+			func(page armcontainerservice.ManagedClusterListResult) *string { return page.NextLink },
 		), nil
 	}
 
 	handler := func(
-		az armdashboard.ManagedGrafanaListResponse,
-		out chan<- *Grafana,
+		az armcontainerservice.ManagedClusterListResult,
+		out chan<- *KubernetesCluster,
 	) (bool, error) {
 		for _, v := range az.Value {
-			it := NewEmptyGrafana()
+			it := NewEmptyKubernetesCluster()
 			it.FromAzure(v)
 			sendChan(ctx, it, out)
 		}
 		return true, nil
 	}
 
-	return handlePager(ctx, getter, handler, genericErrorTransform(sub, GrafanaT, "ListGrafanas"), ec)
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, KubernetesClusterT, "ListManagedClusters"), ec)
 
 }
 
-func (impl *azureImpl) GetBastionHosts(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *BastionHost {
+// containerRegistryPage is a page of registries along with the anonymous pull
+// settings from the raw response.
+type containerRegistryPage struct {
+	armcontainerregistry.RegistryListResult
+	anonymousPull map[string]bool
+}
 
-	client, err := armnetwork.NewBastionHostsClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) GetContainerRegistries(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ContainerRegistry {
+
+	client, err := armcontainerregistry.NewRegistriesClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(ctx, genericError(sub, BastionHostT, "GetBastionHostClient", err), ec)
+		sendErr(ctx, genericError(sub, ContainerRegistryT, "GetRegistriesClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[containerRegistryPage], error) {
+		base := client.NewListByResourceGroupPager(rg, nil)
+		return runtime.NewPager(
+			runtime.PagingHandler[containerRegistryPage]{
+				More: func(page containerRegistryPage) bool {
+					return page.NextLink != nil && len(*page.NextLink) != 0
+				},
+				Fetcher: func(ctx context.Context, _ *containerRegistryPage) (containerRegistryPage, error) {
+					var raw *http.Response
+					res, err := base.NextPage(runtime.WithCaptureResponse(ctx, &raw))
+					page := containerRegistryPage{RegistryListResult: res.RegistryListResult}
+					if err == nil && raw != nil {
+						if b, err := runtime.Payload(raw); err == nil {
+							page.anonymousPull = containerRegistryAnonymousPull(b)
+						}
+					}
+					return page, err
+				},
+			},
+		), nil
+	}
+
+	handler := func(
+		az containerRegistryPage,
+		out chan<- *ContainerRegistry,
+	) (bool, error) {
+		for _, v := range az.Value {
+			it := NewEmptyContainerRegistry()
+			it.FromAzure(v)
+			if anon, has := az.anonymousPull[strings.ToLower(it.Meta.RawID)]; has {
+				it.AnonymousPullEnabled.FromBool(anon)
+			}
+			sendChan(ctx, it, out)
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, ContainerRegistryT, "ListRegistries"), ec)
+
+}
+
+func (impl *azureImpl) GetApplicationGateways(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ApplicationGateway {
+	client, err := armnetwork.NewApplicationGatewaysClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, ApplicationGatewayT, "GetApplicationGatewayClient", err), ec)
 		return nil
 	}
 
 	ipclient, err := armnetwork.NewPublicIPAddressesClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(ctx, genericError(sub, BastionHostT, "GetPublicIPClient", err), ec)
+		sendErr(ctx, genericError(sub, ApplicationGatewayT, "GetPublicIPClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armnetwork.BastionHostListResult], error) {
-		base := client.NewListByResourceGroupPager(rg, nil)
+	wafclient, err := armnetwork.NewWebApplicationFirewallPoliciesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, ApplicationGatewayT, "GetWAFPolicyClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.ApplicationGatewayListResult], error) {
+		base := client.NewListPager(rg, nil)
 		return wrapPager(base,
-			func(in armnetwork.BastionHostsClientListByResourceGroupResponse) armnetwork.BastionHostListResult {
-				return in.BastionHostListResult
+			func(in armnetwork.ApplicationGatewaysClientListResponse) armnetwork.ApplicationGatewayListResult {
+				return in.ApplicationGatewayListResult
 			},
-			func(page armnetwork.BastionHostListResult) *string { return page.NextLink },
+			func(page armnetwork.ApplicationGatewayListResult) *string { return page.NextLink },
 		), nil
 	}
 
 	handler := func(
-		az armnetwork.BastionHostListResult,
-		out chan<- *BastionHost,
+		az armnetwork.ApplicationGatewayListResult,
+		out chan<- *ApplicationGateway,
 	) (bool, error) {
 
 		var wg sync.WaitGroup
 
 		for _, v := range az.Value {
-			it := NewEmptyBastionHost()
+			it := NewEmptyApplicationGateway()
 			it.FromAzure(v)
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				impl.fillBastionHost(ctx, sub, ipclient, it, out, ec)
+				impl.fillApplicationGateway(ctx, sub, ipclient, wafclient, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, ApplicationGatewayT, "ListApplicationGateways"), ec)
+}
+
+func (impl *azureImpl) fillApplicationGateway(ctx context.Context, sub string, ipclient *armnetwork.PublicIPAddressesClient, wafclient *armnetwork.WebApplicationFirewallPoliciesClient, ag *ApplicationGateway, ec chan<- error) {
+	for i := range ag.FrontendIPs {
+		ipm := ag.FrontendIPs[i].PublicIP.Meta
+		if ipm.Tag == ResourceUnsetT {
+			continue
+		}
+		res, err := ipclient.Get(ctx, ipm.ResourceGroupName, ipm.Name, nil)
+		if err != nil {
+			sendErr(ctx, genericError(sub, PublicIPT, "Get", err), ec)
+			continue
+		}
+		ag.FrontendIPs[i].PublicIP.FromAzure(&res.PublicIPAddress)
+	}
+
+	if pm := ag.WAF.Policy; pm.Tag != ResourceUnsetT {
+		res, err := wafclient.Get(ctx, pm.ResourceGroupName, pm.Name, nil)
+		if err != nil {
+			sendErr(ctx, genericError(sub, ApplicationGatewayT, "GetWAFPolicy", err), ec)
+			return
+		}
+		ag.WAF.FromAzurePolicy(&res.WebApplicationFirewallPolicy)
+	}
+}
+
+func (impl *azureImpl) GetAzureFirewalls(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *AzureFirewall {
+	client, err := armnetwork.NewAzureFirewallsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, AzureFirewallT, "GetAzureFirewallsClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.AzureFirewallListResult], error) {
+		base := client.NewListPager(rg, nil)
+		return wrapPager(base,
+			func(in armnetwork.AzureFirewallsClientListResponse) armnetwork.AzureFirewallListResult {
+				return in.AzureFirewallListResult
+			},
+			func(page armnetwork.AzureFirewallListResult) *string { return page.NextLink },
+		), nil
+	}
+
+	handler := func(
+		az armnetwork.AzureFirewallListResult,
+		out chan<- *AzureFirewall,
+	) (bool, error) {
+		for _, v := range az.Value {
+			it := NewEmptyAzureFirewall()
+			it.FromAzure(v)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, AzureFirewallT, "ListAzureFirewalls"), ec)
+}
+
+func (impl *azureImpl) GetFirewallPolicies(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *FirewallPolicy {
+	client, err := armnetwork.NewFirewallPoliciesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, FirewallPolicyT, "GetFirewallPoliciesClient", err), ec)
+		return nil
+	}
+
+	groupClient, err := armnetwork.NewFirewallPolicyRuleCollectionGroupsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, FirewallPolicyT, "GetRuleCollectionGroupsClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.FirewallPolicyListResult], error) {
+		base := client.NewListPager(rg, nil)
+		return wrapPager(base,
+			func(in armnetwork.FirewallPoliciesClientListResponse) armnetwork.FirewallPolicyListResult {
+				return in.FirewallPolicyListResult
+			},
+			func(page armnetwork.FirewallPolicyListResult) *string { return page.NextLink },
+		), nil
+	}
+
+	handler := func(
+		az armnetwork.FirewallPolicyListResult,
+		out chan<- *FirewallPolicy,
+	) (bool, error) {
+
+		var wg sync.WaitGroup
+
+		for _, v := range az.Value {
+			it := NewEmptyFirewallPolicy()
+			it.FromAzure(v)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl.fillFirewallPolicy(ctx, sub, groupClient, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, FirewallPolicyT, "ListFirewallPolicies"), ec)
+}
+
+func (impl *azureImpl) fillFirewallPolicy(ctx context.Context, sub string, client *armnetwork.FirewallPolicyRuleCollectionGroupsClient, fp *FirewallPolicy, ec chan<- error) {
+	getter := func() (*runtime.Pager[armnetwork.FirewallPolicyRuleCollectionGroupsClientListResponse], error) {
+		return client.NewListPager(fp.Meta.ResourceGroupName, fp.Meta.Name, nil), nil
+	}
+
+	handler := func(az armnetwork.FirewallPolicyRuleCollectionGroupsClientListResponse, out chan<- *armnetwork.FirewallPolicyRuleCollectionGroup) (bool, error) {
+		for _, v := range az.Value {
+			if !sendChan(ctx, v, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	groups := handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, FirewallPolicyT, "ListRuleCollectionGroups"),
+		ec,
+	)
+
+	for group := range groups {
+		fp.AddRuleCollectionGroup(group)
+	}
+}
+
+func (impl *azureImpl) GetGrafanas(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *Grafana {
+
+	client, err := armdashboard.NewGrafanaClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, GrafanaT, "GetGrafanaClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armdashboard.ManagedGrafanaListResponse], error) {
+		base := client.NewListByResourceGroupPager(rg, nil)
+		return wrapPager(base,
+			func(in armdashboard.GrafanaClientListByResourceGroupResponse) armdashboard.ManagedGrafanaListResponse {
+				return in.ManagedGrafanaListResponse
+			},
+			func(page armdashboard.ManagedGrafanaListResponse) *string { return page.NextLink },
+		), nil
+	}
+
+	handler := func(
+		az armdashboard.ManagedGrafanaListResponse,
+		out chan<- *Grafana,
+	) (bool, error) {
+		for _, v := range az.Value {
+			it := NewEmptyGrafana()
+			it.FromAzure(v)
+			sendChan(ctx, it, out)
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, GrafanaT, "ListGrafanas"), ec)
+
+}
+
+func (impl *azureImpl) GetBastionHosts(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *BastionHost {
+
+	client, err := armnetwork.NewBastionHostsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, BastionHostT, "GetBastionHostClient", err), ec)
+		return nil
+	}
+
+	ipclient, err := armnetwork.NewPublicIPAddressesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, BastionHostT, "GetPublicIPClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.BastionHostListResult], error) {
+		base := client.NewListByResourceGroupPager(rg, nil)
+		return wrapPager(base,
+			func(in armnetwork.BastionHostsClientListByResourceGroupResponse) armnetwork.BastionHostListResult {
+				return in.BastionHostListResult
+			},
+			func(page armnetwork.BastionHostListResult) *string { return page.NextLink },
+		), nil
+	}
+
+	handler := func(
+		az armnetwork.BastionHostListResult,
+		out chan<- *BastionHost,
+	) (bool, error) {
+
+		var wg sync.WaitGroup
+
+		for _, v := range az.Value {
+			it := NewEmptyBastionHost()
+			it.FromAzure(v)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl.fillBastionHost(ctx, sub, ipclient, it, out, ec)
 				sendChan(ctx, it, out)
 			}()
 		}
@@ -1859,72 +2516,68 @@ func (impl *azureImpl) GetVirtualMachines(ctx context.Context, sub string, ec ch
 
 }
 
-func (impl *azureImpl) GetKeyVaults(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *KeyVault {
-	client, err := armkeyvault.NewVaultsClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) GetServiceBusNamespaces(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *ServiceBusNamespace {
+	client, err := impl.newARMRESTClient(serviceBusAPIVersion)
 	if err != nil {
-		sendErr(
-			ctx,
-			genericError(sub, KeyVaultT, "GetClient", err),
-			ec,
-		)
+		sendErr(ctx, genericError(sub, ServiceBusT, "GetClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armkeyvault.VaultsClientListByResourceGroupResponse], error) {
-		return client.NewListByResourceGroupPager(rg, nil), nil
+	getter := func() (*runtime.Pager[armListResult[azMessagingNamespace]], error) {
+		return armRESTPager[azMessagingNamespace](client, resourceGroupProviderPath(sub, rg, "Microsoft.ServiceBus/namespaces")), nil
 	}
 
-	handler := func(az armkeyvault.VaultsClientListByResourceGroupResponse, out chan<- *KeyVault) (bool, error) {
-		for _, kv := range az.Value {
-			it := NewEmptyKeyVault()
-			it.FromAzure(kv)
-			if !sendChan(ctx, it, out) {
-				return false, nil
+	handler := func(az armListResult[azMessagingNamespace], out chan<- *ServiceBusNamespace) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, v := range az.Value {
+			it := NewEmptyServiceBusNamespace()
+			it.fromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
 			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl.fillMessagingNamespace(ctx, sub, client, ServiceBusT, it.Meta.RawID, &it.AuthorizationRules, &it.Firewall, ec)
+				sendChan(ctx, it, out)
+			}()
 		}
+
+		wg.Wait()
+
 		return true, nil
 	}
 
-	return handlePager(ctx,
-		getter,
-		handler,
-		genericErrorTransform(sub, KeyVaultT, "ListKeyVaults"),
-		ec,
-	)
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, ServiceBusT, "ListNamespaces"), ec)
 }
 
-func (impl *azureImpl) GetWebApps(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *WebApp {
-	client, err := armappservice.NewWebAppsClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) GetEventHubNamespaces(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *EventHubNamespace {
+	client, err := impl.newARMRESTClient(eventHubAPIVersion)
 	if err != nil {
-		sendErr(
-			ctx,
-			genericError(sub, WebAppT, "GetClient", err),
-			ec,
-		)
+		sendErr(ctx, genericError(sub, EventHubNamespaceT, "GetClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armappservice.WebAppsClientListByResourceGroupResponse], error) {
-		return client.NewListByResourceGroupPager(rg, nil), nil
+	getter := func() (*runtime.Pager[armListResult[azMessagingNamespace]], error) {
+		return armRESTPager[azMessagingNamespace](client, resourceGroupProviderPath(sub, rg, "Microsoft.EventHub/namespaces")), nil
 	}
 
-	handler := func(az armappservice.WebAppsClientListByResourceGroupResponse, out chan<- *WebApp) (bool, error) {
+	handler := func(az armListResult[azMessagingNamespace], out chan<- *EventHubNamespace) (bool, error) {
 		var wg sync.WaitGroup
-		for _, azwa := range az.Value {
-			if azwa == nil {
-				continue
-			}
-			it := NewEmptyWebApp()
-			it.FromAzure(azwa)
 
-			// Don't look for functions in non function apps. Wish this wasn't a string
-			// comparison but oh well.
-			if azwa.Kind != nil && !strings.Contains(strings.ToLower(*azwa.Kind), "functionapp") {
+		for _, v := range az.Value {
+			it := NewEmptyEventHubNamespace()
+			it.fromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
 				continue
 			}
-
 			wg.Add(1)
-			go impl.getWebAppFunctions(ctx, client, it, out, ec, &wg)
+			go func() {
+				defer wg.Done()
+				impl.fillMessagingNamespace(ctx, sub, client, EventHubNamespaceT, it.Meta.RawID, &it.AuthorizationRules, &it.Firewall, ec)
+				sendChan(ctx, it, out)
+			}()
 		}
 
 		wg.Wait()
@@ -1932,32 +2585,625 @@ func (impl *azureImpl) GetWebApps(ctx context.Context, sub string, rg string, ec
 		return true, nil
 	}
 
-	return handlePager(ctx,
-		getter,
-		handler,
-		genericErrorTransform(sub, WebAppT, "ListWebApps"),
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, EventHubNamespaceT, "ListNamespaces"), ec)
+}
+
+// fillMessagingNamespace gets the authorization rules and network rule set
+// for a Service Bus or Event Hubs namespace since they share the same API.
+func (impl *azureImpl) fillMessagingNamespace(
+	ctx context.Context,
+	sub string,
+	client *armRESTClient,
+	tag AzureResourceTag,
+	id string,
+	rules *[]MessagingAuthorizationRule,
+	fw *MessagingFirewall,
+	ec chan<- error,
+) {
+	azRules := handlePager(ctx,
+		func() (*runtime.Pager[armListResult[azMessagingAuthorizationRule]], error) {
+			return armRESTPager[azMessagingAuthorizationRule](client, id+"/authorizationRules"), nil
+		},
+		func(az armListResult[azMessagingAuthorizationRule], out chan<- *azMessagingAuthorizationRule) (bool, error) {
+			for _, v := range az.Value {
+				if !sendChan(ctx, v, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		genericErrorTransform(sub, tag, "ListAuthorizationRules"),
 		ec,
 	)
+	for az := range azRules {
+		var rule MessagingAuthorizationRule
+		rule.fromAzure(az)
+		*rules = append(*rules, rule)
+	}
 
+	var rs azMessagingNetworkRuleSet
+	if err := armRESTGet(ctx, client, id+"/networkRuleSets/default", &rs); err != nil {
+		sendErr(ctx, genericError(sub, tag, "GetNetworkRuleSet", err), ec)
+		return
+	}
+	fw.fromAzure(&rs)
 }
 
-func (impl *azureImpl) getWebAppFunctions(ctx context.Context, client *armappservice.WebAppsClient, wa *WebApp, out chan<- *WebApp, ec chan<- error, wg *sync.WaitGroup) {
-
-	defer wg.Done()
+func (impl *azureImpl) GetCDNProfiles(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *CDNProfile {
+	client, err := impl.newARMRESTClient(cdnAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, CDNProfileT, "GetClient", err), ec)
+		return nil
+	}
 
-	getter := func() (*runtime.Pager[armappservice.WebAppsClientListFunctionsResponse], error) {
-		return client.NewListFunctionsPager(wa.Meta.ResourceGroupName, wa.Meta.Name, nil), nil
+	getter := func() (*runtime.Pager[armListResult[azCDNProfile]], error) {
+		return armRESTPager[azCDNProfile](client, resourceGroupProviderPath(sub, rg, "Microsoft.Cdn/profiles")), nil
 	}
 
-	handler := func(az armappservice.WebAppsClientListFunctionsResponse, handlerOut chan<- *Function) (bool, error) {
-		for _, azf := range az.Value {
-			if azf == nil {
+	handler := func(az armListResult[azCDNProfile], out chan<- *CDNProfile) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, v := range az.Value {
+			it := NewEmptyCDNProfile()
+			it.fromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
 				continue
 			}
-			it := NewEmptyFunction()
-			it.FromAzure(azf)
-			if !sendChan(ctx, it, handlerOut) {
-				return false, nil
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if it.IsFrontDoor() {
+					impl.fillAFDProfile(ctx, sub, client, it, ec)
+				} else {
+					impl.fillCDNProfile(ctx, sub, client, it, ec)
+				}
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, CDNProfileT, "ListProfiles"), ec)
+}
+
+func (impl *azureImpl) fillAFDProfile(ctx context.Context, sub string, client *armRESTClient, p *CDNProfile, ec chan<- error) {
+	id := p.Meta.RawID
+	parts := newAFDProfileParts()
+	for _, cd := range armRESTList[azAFDCustomDomain](ctx, client, id+"/customDomains", genericErrorTransform(sub, CDNProfileT, "ListCustomDomains"), ec) {
+		parts.addCustomDomain(cd)
+	}
+	for _, sp := range armRESTList[azAFDSecurityPolicy](ctx, client, id+"/securityPolicies", genericErrorTransform(sub, CDNProfileT, "ListSecurityPolicies"), ec) {
+		parts.addSecurityPolicy(sp)
+	}
+	for _, og := range armRESTList[azAFDOriginGroup](ctx, client, id+"/originGroups", genericErrorTransform(sub, CDNProfileT, "ListOriginGroups"), ec) {
+		if og.ID == nil {
+			continue
+		}
+		for _, o := range armRESTList[azAFDOrigin](ctx, client, *og.ID+"/origins", genericErrorTransform(sub, CDNProfileT, "ListOrigins"), ec) {
+			parts.addOrigin(*og.ID, o)
+		}
+	}
+	for _, e := range armRESTList[azAFDEndpoint](ctx, client, id+"/afdEndpoints", genericErrorTransform(sub, CDNProfileT, "ListEndpoints"), ec) {
+		if e.ID == nil {
+			continue
+		}
+		routes := armRESTList[azAFDRoute](ctx, client, *e.ID+"/routes", genericErrorTransform(sub, CDNProfileT, "ListRoutes"), ec)
+		p.Endpoints = append(p.Endpoints, parts.endpoint(e, routes))
+	}
+}
+
+func (impl *azureImpl) fillCDNProfile(ctx context.Context, sub string, client *armRESTClient, p *CDNProfile, ec chan<- error) {
+	for _, e := range armRESTList[azCDNEndpoint](ctx, client, p.Meta.RawID+"/endpoints", genericErrorTransform(sub, CDNProfileT, "ListEndpoints"), ec) {
+		if e.ID == nil {
+			continue
+		}
+		domains := armRESTList[azCDNCustomDomain](ctx, client, *e.ID+"/customDomains", genericErrorTransform(sub, CDNProfileT, "ListCustomDomains"), ec)
+		p.Endpoints = append(p.Endpoints, cdnEndpoint(e, domains))
+	}
+}
+
+func (impl *azureImpl) GetFrontDoors(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *FrontDoor {
+	client, err := impl.newARMRESTClient(frontDoorAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, FrontDoorT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armListResult[azFrontDoor]], error) {
+		return armRESTPager[azFrontDoor](client, resourceGroupProviderPath(sub, rg, "Microsoft.Network/frontDoors")), nil
+	}
+
+	handler := func(az armListResult[azFrontDoor], out chan<- *FrontDoor) (bool, error) {
+		for _, v := range az.Value {
+			if v == nil {
+				continue
+			}
+			it := NewEmptyFrontDoor()
+			it.fromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, FrontDoorT, "ListFrontDoors"), ec)
+}
+
+func (impl *azureImpl) GetTrafficManagerProfiles(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *TrafficManagerProfile {
+	client, err := impl.newARMRESTClient(trafficManagerAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, TrafficManagerProfileT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armListResult[azTrafficManagerProfile]], error) {
+		return armRESTPager[azTrafficManagerProfile](client, resourceGroupProviderPath(sub, rg, "Microsoft.Network/trafficManagerProfiles")), nil
+	}
+
+	handler := func(az armListResult[azTrafficManagerProfile], out chan<- *TrafficManagerProfile) (bool, error) {
+		for _, v := range az.Value {
+			if v == nil {
+				continue
+			}
+			it := NewEmptyTrafficManagerProfile()
+			it.fromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, TrafficManagerProfileT, "ListProfiles"), ec)
+}
+
+func (impl *azureImpl) GetVirtualMachineScaleSets(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *VirtualMachineScaleSet {
+	client, err := armcompute.NewVirtualMachineScaleSetsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, VirtualMachineScaleSetT, "GetClient", err), ec)
+		return nil
+	}
+
+	vmClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, VirtualMachineScaleSetT, "GetVMsClient", err), ec)
+		return nil
+	}
+
+	ifaceClient, err := armnetwork.NewInterfacesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, VirtualMachineScaleSetT, "GetInterfacesClient", err), ec)
+		return nil
+	}
+
+	ipClient, err := armnetwork.NewPublicIPAddressesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, VirtualMachineScaleSetT, "GetPublicIPClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armcompute.VirtualMachineScaleSetsClientListResponse], error) {
+		return client.NewListPager(rg, nil), nil
+	}
+
+	handler := func(az armcompute.VirtualMachineScaleSetsClientListResponse, out chan<- *VirtualMachineScaleSet) (bool, error) {
+		var wg sync.WaitGroup
+
+		for _, v := range az.Value {
+			it := NewEmptyVirtualMachineScaleSet()
+			it.FromAzure(v)
+			if it.Meta.Tag == ResourceUnsetT {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl.fillVirtualMachineScaleSet(ctx, sub, vmClient, ifaceClient, ipClient, it, ec)
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, VirtualMachineScaleSetT, "ListVirtualMachineScaleSets"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) fillVirtualMachineScaleSet(
+	ctx context.Context,
+	sub string,
+	vmClient *armcompute.VirtualMachineScaleSetVMsClient,
+	ifaceClient *armnetwork.InterfacesClient,
+	ipClient *armnetwork.PublicIPAddressesClient,
+	vmss *VirtualMachineScaleSet,
+	ec chan<- error,
+) {
+	rg := vmss.Meta.ResourceGroupName
+	name := vmss.Meta.Name
+
+	instances := handlePager(ctx,
+		func() (*runtime.Pager[armcompute.VirtualMachineScaleSetVMsClientListResponse], error) {
+			return vmClient.NewListPager(rg, name, nil), nil
+		},
+		func(az armcompute.VirtualMachineScaleSetVMsClientListResponse, out chan<- *VMSSInstance) (bool, error) {
+			for _, v := range az.Value {
+				var inst VMSSInstance
+				inst.FromAzure(v)
+				if !sendChan(ctx, &inst, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		genericErrorTransform(sub, VirtualMachineScaleSetT, "ListVMs"),
+		ec,
+	)
+	for inst := range instances {
+		vmss.Instances = append(vmss.Instances, *inst)
+	}
+
+	ifaces := handlePager(ctx,
+		func() (*runtime.Pager[armnetwork.InterfacesClientListVirtualMachineScaleSetNetworkInterfacesResponse], error) {
+			return ifaceClient.NewListVirtualMachineScaleSetNetworkInterfacesPager(rg, name, nil), nil
+		},
+		func(az armnetwork.InterfacesClientListVirtualMachineScaleSetNetworkInterfacesResponse, out chan<- *NetworkInterface) (bool, error) {
+			for _, v := range az.Value {
+				it := NewEmptyNetworkInterface()
+				it.FromAzure(v)
+				if !sendChan(ctx, it, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		genericErrorTransform(sub, VirtualMachineScaleSetT, "ListNetworkInterfaces"),
+		ec,
+	)
+	for iface := range ifaces {
+		vmss.SetNetworkInterface(iface)
+	}
+
+	ips := handlePager(ctx,
+		func() (*runtime.Pager[armnetwork.PublicIPAddressesClientListVirtualMachineScaleSetPublicIPAddressesResponse], error) {
+			return ipClient.NewListVirtualMachineScaleSetPublicIPAddressesPager(rg, name, nil), nil
+		},
+		func(az armnetwork.PublicIPAddressesClientListVirtualMachineScaleSetPublicIPAddressesResponse, out chan<- *PublicIP) (bool, error) {
+			for _, v := range az.Value {
+				var pip PublicIP
+				pip.setupEmpty()
+				pip.FromAzure(v)
+				if !sendChan(ctx, &pip, out) {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		genericErrorTransform(sub, VirtualMachineScaleSetT, "ListPublicIPs"),
+		ec,
+	)
+	for pip := range ips {
+		vmss.SetPublicIP(pip)
+	}
+}
+
+func (impl *azureImpl) GetKeyVaults(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *KeyVault {
+	client, err := armkeyvault.NewVaultsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, KeyVaultT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armkeyvault.VaultsClientListByResourceGroupResponse], error) {
+		return client.NewListByResourceGroupPager(rg, nil), nil
+	}
+
+	handler := func(az armkeyvault.VaultsClientListByResourceGroupResponse, out chan<- *KeyVault) (bool, error) {
+		var wg sync.WaitGroup
+		for _, kv := range az.Value {
+			if kv == nil {
+				continue
+			}
+			it := NewEmptyKeyVault()
+			it.FromAzure(kv)
+			wg.Add(1)
+			go impl.getKeyVaultElements(ctx, it, out, ec, &wg)
+		}
+		wg.Wait()
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, KeyVaultT, "ListKeyVaults"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) getKeyVaultElements(ctx context.Context, kv *KeyVault, out chan<- *KeyVault, ec chan<- error, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Certificates and rotation policies come from the data plane which can
+	// only be reached through the vault's URL.
+	var dataClient *armRESTClient
+	var keysClient *azkeys.Client
+	if kv.URL != "" {
+		var err error
+		dataClient, err = impl.newKeyVaultRESTClient(kv.URL)
+		if err != nil {
+			sendErr(ctx, genericError(kv.Meta.Subscription, KeyVaultT, "GetDataClient", err), ec)
+			dataClient = nil
+		}
+		var opts azkeys.ClientOptions
+		if impl.clientOptions != nil {
+			opts.ClientOptions = impl.clientOptions.ClientOptions
+		}
+		keysClient, err = azkeys.NewClient(kv.URL, impl.tokenCredential, &opts)
+		if err != nil {
+			sendErr(ctx, genericError(kv.Meta.Subscription, KeyVaultT, "GetKeysClient", err), ec)
+			keysClient = nil
+		}
+	}
+
+	var innerWg sync.WaitGroup
+
+	innerWg.Add(1)
+	go func() {
+		defer innerWg.Done()
+		for k := range impl.getKeyVaultKeys(ctx, kv, ec) {
+			kv.Keys = append(kv.Keys, *k)
+		}
+		if keysClient != nil {
+			impl.getKeyVaultKeyRotationPolicies(ctx, keysClient, kv, ec)
+		}
+	}()
+
+	innerWg.Add(1)
+	go func() {
+		defer innerWg.Done()
+		for s := range impl.getKeyVaultSecrets(ctx, kv, ec) {
+			kv.Secrets = append(kv.Secrets, *s)
+		}
+	}()
+
+	if dataClient != nil {
+		innerWg.Add(1)
+		go func() {
+			defer innerWg.Done()
+			for c := range impl.getKeyVaultCertificates(ctx, dataClient, kv, ec) {
+				kv.Certificates = append(kv.Certificates, *c)
+			}
+		}()
+	}
+
+	innerWg.Wait()
+
+	sendChan(ctx, kv, out)
+}
+
+func (impl *azureImpl) getKeyVaultKeys(ctx context.Context, kv *KeyVault, ec chan<- error) <-chan *KeyVaultKey {
+	sub := kv.Meta.Subscription
+	client, err := armkeyvault.NewKeysClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, KeyVaultT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+	getter := func() (*runtime.Pager[armkeyvault.KeysClientListResponse], error) {
+		return client.NewListPager(kv.Meta.ResourceGroupName, kv.Meta.Name, nil), nil
+	}
+
+	handler := func(az armkeyvault.KeysClientListResponse, out chan<- *KeyVaultKey) (bool, error) {
+		for _, azk := range az.Value {
+			if azk == nil {
+				continue
+			}
+			it := new(KeyVaultKey)
+			it.KeyVault = kv.Meta
+			it.FromAzure(azk)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, KeyVaultT, "ListKeys"), ec)
+}
+
+func (impl *azureImpl) getKeyVaultKeyRotationPolicies(ctx context.Context, client *azkeys.Client, kv *KeyVault, ec chan<- error) {
+	reported := false
+	for i := range kv.Keys {
+		if ctx.Err() != nil {
+			return
+		}
+		k := &kv.Keys[i]
+		resp, err := client.GetKeyRotationPolicy(ctx, k.Name, nil)
+		if err != nil {
+			// A vault we can't reach fails for every key, so only report the
+			// first failure but still try the rest.
+			if !reported {
+				sendErr(ctx, genericError(kv.Meta.Subscription, KeyVaultT, "GetKeyRotationPolicy", err), ec)
+				reported = true
+			}
+			continue
+		}
+		k.RotationPolicy.fromAzure(&resp.KeyRotationPolicy)
+	}
+}
+
+func (impl *azureImpl) getKeyVaultSecrets(ctx context.Context, kv *KeyVault, ec chan<- error) <-chan *KeyVaultSecret {
+	sub := kv.Meta.Subscription
+	client, err := armkeyvault.NewSecretsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, KeyVaultT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+	getter := func() (*runtime.Pager[armkeyvault.SecretsClientListResponse], error) {
+		return client.NewListPager(kv.Meta.ResourceGroupName, kv.Meta.Name, nil), nil
+	}
+
+	handler := func(az armkeyvault.SecretsClientListResponse, out chan<- *KeyVaultSecret) (bool, error) {
+		for _, azs := range az.Value {
+			if azs == nil {
+				continue
+			}
+			it := new(KeyVaultSecret)
+			it.KeyVault = kv.Meta
+			it.FromAzure(azs)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, KeyVaultT, "ListSecrets"), ec)
+}
+
+func (impl *azureImpl) getKeyVaultCertificates(ctx context.Context, client *armRESTClient, kv *KeyVault, ec chan<- error) <-chan *KeyVaultCertificate {
+	sub := kv.Meta.Subscription
+	getter := func() (*runtime.Pager[armListResult[azKeyVaultCertificateItem]], error) {
+		return armRESTPager[azKeyVaultCertificateItem](client, "/certificates"), nil
+	}
+
+	handler := func(az armListResult[azKeyVaultCertificateItem], out chan<- *KeyVaultCertificate) (bool, error) {
+		for _, azc := range az.Value {
+			if azc == nil {
+				continue
+			}
+			it := new(KeyVaultCertificate)
+			it.KeyVault = kv.Meta
+			it.fromAzure(azc)
+			var pol azKeyVaultCertificatePolicy
+			err := armRESTGet(ctx, client, "/certificates/"+url.PathEscape(it.Name)+"/policy", &pol)
+			if err != nil {
+				sendErr(ctx, genericError(sub, KeyVaultT, "GetCertificatePolicy", err), ec)
+			} else {
+				it.fromAzurePolicy(&pol)
+			}
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, KeyVaultT, "ListCertificates"), ec)
+}
+
+func (impl *azureImpl) GetWebApps(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *WebApp {
+	client, err := armappservice.NewWebAppsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, WebAppT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+
+	restClient, err := impl.newARMRESTClient(webAppConfigAPIVersion)
+	if err != nil {
+		sendErr(ctx, genericError(sub, WebAppT, "GetConfigClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armappservice.WebAppsClientListByResourceGroupResponse], error) {
+		return client.NewListByResourceGroupPager(rg, nil), nil
+	}
+
+	handler := func(az armappservice.WebAppsClientListByResourceGroupResponse, out chan<- *WebApp) (bool, error) {
+		var wg sync.WaitGroup
+		for _, azwa := range az.Value {
+			if azwa == nil {
+				continue
+			}
+			it := NewEmptyWebApp()
+			it.FromAzure(azwa)
+
+			// Don't look for functions in non function apps. Wish this wasn't a string
+			// comparison but oh well.
+			isFunctionApp := azwa.Kind != nil && strings.Contains(strings.ToLower(*azwa.Kind), "functionapp")
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				impl.getWebAppConfig(ctx, restClient, it, ec)
+				if isFunctionApp {
+					impl.getWebAppFunctions(ctx, client, it, ec)
+				}
+				sendChan(ctx, it, out)
+			}()
+		}
+
+		wg.Wait()
+
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, WebAppT, "ListWebApps"),
+		ec,
+	)
+
+}
+
+// getWebAppConfig fills in the site config. The list response doesn't have
+// all of it, and the SDK doesn't have the firewall default actions.
+func (impl *azureImpl) getWebAppConfig(ctx context.Context, client *armRESTClient, wa *WebApp, ec chan<- error) {
+	var az azWebAppConfig
+	err := armRESTGet(ctx, client, wa.Meta.RawID+"/config/web", &az)
+	if err == nil {
+		err = wa.fromAzureConfig(&az)
+	}
+	if err != nil {
+		sendErr(ctx, genericError(wa.Meta.Subscription, WebAppT, "GetConfiguration", err), ec)
+	}
+}
+
+func (impl *azureImpl) getWebAppFunctions(ctx context.Context, client *armappservice.WebAppsClient, wa *WebApp, ec chan<- error) {
+
+	getter := func() (*runtime.Pager[armappservice.WebAppsClientListFunctionsResponse], error) {
+		return client.NewListFunctionsPager(wa.Meta.ResourceGroupName, wa.Meta.Name, nil), nil
+	}
+
+	handler := func(az armappservice.WebAppsClientListFunctionsResponse, handlerOut chan<- *Function) (bool, error) {
+		for _, azf := range az.Value {
+			if azf == nil {
+				continue
+			}
+			it := NewEmptyFunction()
+			it.FromAzure(azf)
+			if !sendChan(ctx, it, handlerOut) {
+				return false, nil
 			}
 
 		}
@@ -1973,10 +3219,9 @@ func (impl *azureImpl) getWebAppFunctions(ctx context.Context, client *armappser
 	)
 
 	for f := range funcs {
+		f.Identity = wa.Identity
 		wa.Functions = append(wa.Functions, *f)
 	}
-
-	sendChan(ctx, wa, out)
 }
 
 func (impl *azureImpl) GetRedisServers(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *RedisServer {
@@ -1990,131 +3235,510 @@ func (impl *azureImpl) GetRedisServers(ctx context.Context, sub string, rg strin
 		return nil
 	}
 
-	fwClient, err := armredis.NewFirewallRulesClient(sub, impl.tokenCredential, impl.clientOptions)
+	fwClient, err := armredis.NewFirewallRulesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, RedisServerT, "GetFWClient", err),
+			ec,
+		)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armredis.ClientListByResourceGroupResponse], error) {
+		return client.NewListByResourceGroupPager(rg, nil), nil
+	}
+
+	handler := func(az armredis.ClientListByResourceGroupResponse, out chan<- *RedisServer) (bool, error) {
+		var wg sync.WaitGroup
+		for _, rs := range az.Value {
+			it := NewEmptyRedisServer()
+			it.FromAzure(rs)
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				impl.getRedisServerFirewall(ctx, fwClient, sub, rg, it.Meta.Name, it, out, ec)
+			}()
+
+			wg.Wait()
+
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, RedisServerT, "ListRediss"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) getRedisServerFirewall(ctx context.Context, client *armredis.FirewallRulesClient, sub string, rg string, serverName string, server *RedisServer, out chan<- *RedisServer, ec chan<- error) {
+
+	getter := func() (*runtime.Pager[armredis.FirewallRulesClientListResponse], error) {
+		return client.NewListPager(rg, serverName, nil), nil
+	}
+
+	handler := func(az armredis.FirewallRulesClientListResponse, out chan<- *FirewallRule) (bool, error) {
+		for _, v := range az.Value {
+			fw := new(FirewallRule)
+			fw.FromAzureRedis(v)
+			if !sendChan(ctx, fw, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	firewalls := handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, ApiT, "ListProducts"),
+		ec,
+	)
+
+	for rule := range firewalls {
+		server.Firewall = append(server.Firewall, *rule)
+	}
+
+	sendChan(ctx, server, out)
+}
+
+func (impl *azureImpl) GetApplicationSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *ApplicationSecurityGroup {
+	client, err := armnetwork.NewApplicationSecurityGroupsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, ApplicationSecurityGroupT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.ApplicationSecurityGroupsClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
+	}
+
+	handler := func(az armnetwork.ApplicationSecurityGroupsClientListAllResponse, out chan<- *ApplicationSecurityGroup) (bool, error) {
+		for _, asg := range az.Value {
+			it := NewEmptyASG()
+			it.FromAzure(asg)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, ApplicationSecurityGroupT, "ListApplicationSecurityGroups"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) GetRouteTables(ctx context.Context, sub string, ec chan<- error) <-chan *RouteTable {
+	client, err := armnetwork.NewRouteTablesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, RouteTableT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.RouteTablesClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
+	}
+
+	handler := func(az armnetwork.RouteTablesClientListAllResponse, out chan<- *RouteTable) (bool, error) {
+		for _, rt := range az.Value {
+			if rt == nil {
+				continue
+			}
+			it := NewEmptyRouteTable()
+			it.FromAzure(rt)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, RouteTableT, "ListRouteTables"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) GetNATGateways(ctx context.Context, sub string, ec chan<- error) <-chan *NATGateway {
+	client, err := armnetwork.NewNatGatewaysClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, NATGatewayT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.NatGatewaysClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
+	}
+
+	handler := func(az armnetwork.NatGatewaysClientListAllResponse, out chan<- *NATGateway) (bool, error) {
+		for _, ng := range az.Value {
+			if ng == nil {
+				continue
+			}
+			it := NewEmptyNATGateway()
+			it.FromAzure(ng)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, NATGatewayT, "ListNATGateways"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) GetPublicIPAddresses(ctx context.Context, sub string, ec chan<- error) <-chan *PublicIPAddress {
+	client, err := armnetwork.NewPublicIPAddressesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, PublicIPT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.PublicIPAddressesClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
+	}
+
+	handler := func(az armnetwork.PublicIPAddressesClientListAllResponse, out chan<- *PublicIPAddress) (bool, error) {
+		for _, azp := range az.Value {
+			if azp == nil {
+				continue
+			}
+			it := NewEmptyPublicIPAddress()
+			it.FromAzure(azp)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, PublicIPT, "ListPublicIPAddresses"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) GetVirtualNetworkGateways(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *VirtualNetworkGateway {
+	client, err := armnetwork.NewVirtualNetworkGatewaysClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, VirtualNetworkGatewayT, "GetClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.VirtualNetworkGatewaysClientListResponse], error) {
+		return client.NewListPager(rg, nil), nil
+	}
+
+	handler := func(az armnetwork.VirtualNetworkGatewaysClientListResponse, out chan<- *VirtualNetworkGateway) (bool, error) {
+		var wg sync.WaitGroup
+		for _, azgw := range az.Value {
+			if azgw == nil {
+				continue
+			}
+			it := NewEmptyVirtualNetworkGateway()
+			it.FromAzure(azgw)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range impl.getVirtualNetworkGatewayConnections(ctx, client, it, ec) {
+					it.Connections = append(it.Connections, *c)
+				}
+				sendChan(ctx, it, out)
+			}()
+		}
+		wg.Wait()
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, VirtualNetworkGatewayT, "ListVirtualNetworkGateways"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) getVirtualNetworkGatewayConnections(ctx context.Context, client *armnetwork.VirtualNetworkGatewaysClient, gw *VirtualNetworkGateway, ec chan<- error) <-chan *VirtualNetworkGatewayConnection {
+	getter := func() (*runtime.Pager[armnetwork.VirtualNetworkGatewaysClientListConnectionsResponse], error) {
+		return client.NewListConnectionsPager(gw.Meta.ResourceGroupName, gw.Meta.Name, nil), nil
+	}
+
+	handler := func(az armnetwork.VirtualNetworkGatewaysClientListConnectionsResponse, out chan<- *VirtualNetworkGatewayConnection) (bool, error) {
+		for _, azc := range az.Value {
+			if azc == nil {
+				continue
+			}
+			it := new(VirtualNetworkGatewayConnection)
+			it.FromAzure(azc)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(gw.Meta.Subscription, VirtualNetworkGatewayT, "ListConnections"), ec)
+}
+
+func (impl *azureImpl) GetNetworkSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *NetworkSecurityGroup {
+	client, err := armnetwork.NewSecurityGroupsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, NetworkSecurityGroupT, "GetClient", err),
+			ec,
+		)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.SecurityGroupsClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
+	}
+
+	handler := func(az armnetwork.SecurityGroupsClientListAllResponse, out chan<- *NetworkSecurityGroup) (bool, error) {
+		for _, nsg := range az.Value {
+			it := NewEmptyNSG()
+			it.FromAzure(nsg)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, NetworkSecurityGroupT, "ListNetworkSecurityGroups"),
+		ec,
+	)
+}
+
+func (impl *azureImpl) GetNetworks(ctx context.Context, sub string, ec chan<- error) <-chan *VirtualNetwork {
+	client, err := armnetwork.NewVirtualNetworksClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
 		sendErr(
 			ctx,
-			genericError(sub, RedisServerT, "GetFWClient", err),
+			genericError(sub, VirtualNetworkT, "GetClient", err),
 			ec,
 		)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armredis.ClientListByResourceGroupResponse], error) {
-		return client.NewListByResourceGroupPager(rg, nil), nil
+	getter := func() (*runtime.Pager[armnetwork.VirtualNetworksClientListAllResponse], error) {
+		return client.NewListAllPager(nil), nil
 	}
 
-	handler := func(az armredis.ClientListByResourceGroupResponse, out chan<- *RedisServer) (bool, error) {
+	handler := func(az armnetwork.VirtualNetworksClientListAllResponse, out chan<- *VirtualNetwork) (bool, error) {
 		var wg sync.WaitGroup
-		for _, rs := range az.Value {
-			it := NewEmptyRedisServer()
-			it.FromAzure(rs)
-
+		for _, vn := range az.Value {
+			it := NewEmptyVirtualNetwork()
+			it.FromAzure(vn)
 			wg.Add(1)
-
 			go func() {
 				defer wg.Done()
-				impl.getRedisServerFirewall(ctx, fwClient, sub, rg, it.Meta.Name, it, out, ec)
+				impl.fillVirtualNetwork(ctx, it, out, ec)
 			}()
+		}
 
-			wg.Wait()
+		wg.Wait()
 
-		}
 		return true, nil
 	}
 
 	return handlePager(ctx,
 		getter,
 		handler,
-		genericErrorTransform(sub, RedisServerT, "ListRediss"),
+		genericErrorTransform(sub, VirtualNetworkT, "ListNetworkVirtualNetworks"),
 		ec,
 	)
+
 }
 
-func (impl *azureImpl) getRedisServerFirewall(ctx context.Context, client *armredis.FirewallRulesClient, sub string, rg string, serverName string, server *RedisServer, out chan<- *RedisServer, ec chan<- error) {
+func (impl *azureImpl) GetRoleDefinitions(ctx context.Context, sub string, ec chan<- error) <-chan *RoleDefinition {
+	client, err := armauthorization.NewRoleDefinitionsClient(impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, RoleDefinitionT, "GetClient", err), ec)
+		return nil
+	}
 
-	getter := func() (*runtime.Pager[armredis.FirewallRulesClientListResponse], error) {
-		return client.NewListPager(rg, serverName, nil), nil
+	getter := func() (*runtime.Pager[armauthorization.RoleDefinitionsClientListResponse], error) {
+		return client.NewListPager("/subscriptions/"+sub, nil), nil
 	}
 
-	handler := func(az armredis.FirewallRulesClientListResponse, out chan<- *FirewallRule) (bool, error) {
-		for _, v := range az.Value {
-			fw := new(FirewallRule)
-			fw.FromAzureRedis(v)
-			if !sendChan(ctx, fw, out) {
+	handler := func(az armauthorization.RoleDefinitionsClientListResponse, out chan<- *RoleDefinition) (bool, error) {
+		for _, rd := range az.Value {
+			it := NewEmptyRoleDefinition()
+			it.FromAzure(rd)
+			if !sendChan(ctx, it, out) {
 				return false, nil
 			}
 		}
 		return true, nil
 	}
 
-	firewalls := handlePager(ctx,
+	return handlePager(ctx,
 		getter,
 		handler,
-		genericErrorTransform(sub, ApiT, "ListProducts"),
+		genericErrorTransform(sub, RoleDefinitionT, "ListRoleDefinitions"),
 		ec,
 	)
+}
 
-	for rule := range firewalls {
-		server.Firewall = append(server.Firewall, *rule)
+func (impl *azureImpl) GetRoleAssignments(ctx context.Context, sub string, ec chan<- error) <-chan *RoleAssignment {
+	client, err := armauthorization.NewRoleAssignmentsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, RoleAssignmentT, "GetClient", err), ec)
+		return nil
 	}
 
-	sendChan(ctx, server, out)
+	getter := func() (*runtime.Pager[armauthorization.RoleAssignmentsClientListForSubscriptionResponse], error) {
+		return client.NewListForSubscriptionPager(nil), nil
+	}
+
+	handler := func(az armauthorization.RoleAssignmentsClientListForSubscriptionResponse, out chan<- *RoleAssignment) (bool, error) {
+		for _, ra := range az.Value {
+			it := NewEmptyRoleAssignment()
+			it.FromAzure(ra)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx,
+		getter,
+		handler,
+		genericErrorTransform(sub, RoleAssignmentT, "ListRoleAssignments"),
+		ec,
+	)
 }
 
-func (impl *azureImpl) GetApplicationSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *ApplicationSecurityGroup {
-	client, err := armnetwork.NewApplicationSecurityGroupsClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) GetUserAssignedIdentities(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *UserAssignedIdentity {
+	client, err := armmsi.NewUserAssignedIdentitiesClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(
-			ctx,
-			genericError(sub, ApplicationSecurityGroupT, "GetClient", err),
-			ec,
-		)
+		sendErr(ctx, genericError(sub, UserAssignedIdentityT, "GetClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armnetwork.ApplicationSecurityGroupsClientListAllResponse], error) {
-		return client.NewListAllPager(nil), nil
+	getter := func() (*runtime.Pager[armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse], error) {
+		return client.NewListByResourceGroupPager(rg, nil), nil
 	}
 
-	handler := func(az armnetwork.ApplicationSecurityGroupsClientListAllResponse, out chan<- *ApplicationSecurityGroup) (bool, error) {
-		for _, asg := range az.Value {
-			it := NewEmptyASG()
-			it.FromAzure(asg)
-			if !sendChan(ctx, it, out) {
-				return false, nil
+	handler := func(az armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse, out chan<- *UserAssignedIdentity) (bool, error) {
+		var wg sync.WaitGroup
+		for _, azi := range az.Value {
+			if azi == nil {
+				continue
 			}
+			it := NewEmptyUserAssignedIdentity()
+			it.FromAzure(azi)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for fic := range impl.getFederatedIdentityCredentials(ctx, it, ec) {
+					it.FederatedCredentials = append(it.FederatedCredentials, *fic)
+				}
+				sendChan(ctx, it, out)
+			}()
 		}
+		wg.Wait()
 		return true, nil
 	}
 
 	return handlePager(ctx,
 		getter,
 		handler,
-		genericErrorTransform(sub, ApplicationSecurityGroupT, "ListApplicationSecurityGroups"),
+		genericErrorTransform(sub, UserAssignedIdentityT, "ListUserAssignedIdentities"),
 		ec,
 	)
 }
 
-func (impl *azureImpl) GetNetworkSecurityGroups(ctx context.Context, sub string, ec chan<- error) <-chan *NetworkSecurityGroup {
-	client, err := armnetwork.NewSecurityGroupsClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) getFederatedIdentityCredentials(ctx context.Context, uai *UserAssignedIdentity, ec chan<- error) <-chan *FederatedIdentityCredential {
+	sub := uai.Meta.Subscription
+	client, err := armmsi.NewFederatedIdentityCredentialsClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(
-			ctx,
-			genericError(sub, NetworkSecurityGroupT, "GetClient", err),
-			ec,
-		)
+		sendErr(ctx, genericError(sub, UserAssignedIdentityT, "GetClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armnetwork.SecurityGroupsClientListAllResponse], error) {
-		return client.NewListAllPager(nil), nil
+	getter := func() (*runtime.Pager[armmsi.FederatedIdentityCredentialsClientListResponse], error) {
+		return client.NewListPager(uai.Meta.ResourceGroupName, uai.Meta.Name, nil), nil
 	}
 
-	handler := func(az armnetwork.SecurityGroupsClientListAllResponse, out chan<- *NetworkSecurityGroup) (bool, error) {
-		for _, nsg := range az.Value {
-			it := NewEmptyNSG()
-			it.FromAzure(nsg)
+	handler := func(az armmsi.FederatedIdentityCredentialsClientListResponse, out chan<- *FederatedIdentityCredential) (bool, error) {
+		for _, azf := range az.Value {
+			if azf == nil {
+				continue
+			}
+			it := new(FederatedIdentityCredential)
+			it.Identity = uai.Meta
+			it.FromAzure(azf)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return handlePager(ctx, getter, handler, genericErrorTransform(sub, UserAssignedIdentityT, "ListFederatedIdentityCredentials"), ec)
+}
+
+func (impl *azureImpl) GetPrivateEndpoints(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PrivateEndpoint {
+	client, err := armnetwork.NewPrivateEndpointsClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, PrivateEndpointT, "GetClient", err), ec)
+		return nil
+	}
+	ifaceClient, err := armnetwork.NewInterfacesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, PrivateEndpointT, "GetInterfacesClient", err), ec)
+		return nil
+	}
+
+	getter := func() (*runtime.Pager[armnetwork.PrivateEndpointsClientListResponse], error) {
+		return client.NewListPager(rg, nil), nil
+	}
+
+	handler := func(az armnetwork.PrivateEndpointsClientListResponse, out chan<- *PrivateEndpoint) (bool, error) {
+		for _, azpe := range az.Value {
+			if azpe == nil {
+				continue
+			}
+			it := NewEmptyPrivateEndpoint()
+			it.FromAzure(azpe)
+			for i := range it.NetworkInterfaces {
+				ni := &it.NetworkInterfaces[i]
+				res, err := ifaceClient.Get(ctx, ni.Meta.ResourceGroupName, ni.Meta.Name, nil)
+				if err != nil {
+					sendErr(ctx, genericError(sub, PrivateEndpointT, "GetNetworkInterface", err), ec)
+					continue
+				}
+				ni.FromAzure(&res.Interface)
+			}
 			if !sendChan(ctx, it, out) {
 				return false, nil
 			}
@@ -2125,50 +3749,77 @@ func (impl *azureImpl) GetNetworkSecurityGroups(ctx context.Context, sub string,
 	return handlePager(ctx,
 		getter,
 		handler,
-		genericErrorTransform(sub, NetworkSecurityGroupT, "ListNetworkSecurityGroups"),
+		genericErrorTransform(sub, PrivateEndpointT, "ListPrivateEndpoints"),
 		ec,
 	)
 }
 
-func (impl *azureImpl) GetNetworks(ctx context.Context, sub string, ec chan<- error) <-chan *VirtualNetwork {
-	client, err := armnetwork.NewVirtualNetworksClient(sub, impl.tokenCredential, impl.clientOptions)
+func (impl *azureImpl) GetPrivateDNSZones(ctx context.Context, sub string, rg string, ec chan<- error) <-chan *PrivateDNSZone {
+	client, err := armprivatedns.NewPrivateZonesClient(sub, impl.tokenCredential, impl.clientOptions)
 	if err != nil {
-		sendErr(
-			ctx,
-			genericError(sub, VirtualNetworkT, "GetClient", err),
-			ec,
-		)
+		sendErr(ctx, genericError(sub, PrivateDNSZoneT, "GetClient", err), ec)
+		return nil
+	}
+	linkClient, err := armprivatedns.NewVirtualNetworkLinksClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(ctx, genericError(sub, PrivateDNSZoneT, "GetLinksClient", err), ec)
 		return nil
 	}
 
-	getter := func() (*runtime.Pager[armnetwork.VirtualNetworksClientListAllResponse], error) {
-		return client.NewListAllPager(nil), nil
+	getter := func() (*runtime.Pager[armprivatedns.PrivateZonesClientListByResourceGroupResponse], error) {
+		return client.NewListByResourceGroupPager(rg, nil), nil
 	}
 
-	handler := func(az armnetwork.VirtualNetworksClientListAllResponse, out chan<- *VirtualNetwork) (bool, error) {
+	handler := func(az armprivatedns.PrivateZonesClientListByResourceGroupResponse, out chan<- *PrivateDNSZone) (bool, error) {
 		var wg sync.WaitGroup
-		for _, vn := range az.Value {
-			it := NewEmptyVirtualNetwork()
-			it.FromAzure(vn)
+		for _, azz := range az.Value {
+			if azz == nil {
+				continue
+			}
+			it := NewEmptyPrivateDNSZone()
+			it.FromAzure(azz)
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				impl.fillVirtualNetwork(ctx, it, out, ec)
+				for l := range impl.getPrivateDNSZoneLinks(ctx, linkClient, it, ec) {
+					it.VirtualNetworkLinks = append(it.VirtualNetworkLinks, *l)
+				}
+				sendChan(ctx, it, out)
 			}()
 		}
-
 		wg.Wait()
-
 		return true, nil
 	}
 
 	return handlePager(ctx,
 		getter,
 		handler,
-		genericErrorTransform(sub, VirtualNetworkT, "ListNetworkVirtualNetworks"),
+		genericErrorTransform(sub, PrivateDNSZoneT, "ListPrivateDNSZones"),
 		ec,
 	)
+}
+
+func (impl *azureImpl) getPrivateDNSZoneLinks(ctx context.Context, client *armprivatedns.VirtualNetworkLinksClient, z *PrivateDNSZone, ec chan<- error) <-chan *PrivateDNSZoneVNetLink {
+	getter := func() (*runtime.Pager[armprivatedns.VirtualNetworkLinksClientListResponse], error) {
+		return client.NewListPager(z.Meta.ResourceGroupName, z.Meta.Name, nil), nil
+	}
+
+	handler := func(az armprivatedns.VirtualNetworkLinksClientListResponse, out chan<- *PrivateDNSZoneVNetLink) (bool, error) {
+		for _, azl := range az.Value {
+			if azl == nil {
+				continue
+			}
+			it := new(PrivateDNSZoneVNetLink)
+			it.Zone = z.Meta
+			it.FromAzure(azl)
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
 
+	return handlePager(ctx, getter, handler, genericErrorTransform(z.Meta.Subscription, PrivateDNSZoneT, "ListVirtualNetworkLinks"), ec)
 }
 
 func (impl *azureImpl) fillVirtualNetwork(ctx context.Context, vn *VirtualNetwork, out chan<- *VirtualNetwork, ec chan<- error) {
@@ -2324,6 +3975,28 @@ func (impl *azureImpl) getStorageAccountElements(ctx context.Context, sa *Storag
 		}
 	}()
 
+	innerWg.Add(1)
+	go func() {
+		defer innerWg.Done()
+		for q := range impl.getQueues(ctx, sa, ec) {
+			sa.Queues = append(sa.Queues, *q)
+		}
+	}()
+
+	innerWg.Add(1)
+	go func() {
+		defer innerWg.Done()
+		for t := range impl.getTables(ctx, sa, ec) {
+			sa.Tables = append(sa.Tables, *t)
+		}
+	}()
+
+	innerWg.Add(1)
+	go func() {
+		defer innerWg.Done()
+		impl.getBlobServiceProperties(ctx, sa, ec)
+	}()
+
 	innerWg.Wait()
 
 	sendChan(ctx, sa, out)
@@ -2363,6 +4036,95 @@ func (impl *azureImpl) getFileShares(ctx context.Context, sa *StorageAccount, ec
 	return handlePager(ctx, getter, handler, ignoreUnsupportedErrorTransform(sub, FileShareT, "ListFileShares"), ec)
 }
 
+func (impl *azureImpl) getQueues(ctx context.Context, sa *StorageAccount, ec chan<- error) <-chan *Queue {
+	sub := sa.Meta.Subscription
+	client, err := armstorage.NewQueueClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, QueueT, "GetClient", err),
+			ec,
+		)
+
+		return nil
+	}
+	getter := func() (*runtime.Pager[armstorage.QueueClientListResponse], error) {
+		return client.NewListPager(sa.Meta.ResourceGroupName, sa.Meta.Name, nil), nil
+	}
+
+	handler := func(az armstorage.QueueClientListResponse, out chan<- *Queue) (bool, error) {
+		for _, azq := range az.Value {
+			if azq == nil {
+				continue
+			}
+			it := new(Queue)
+			it.FromAzure(azq)
+			it.SetURL(sa)
+			it.StorageAccount = sa.Meta
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return handlePager(ctx, getter, handler, ignoreUnsupportedErrorTransform(sub, QueueT, "ListQueues"), ec)
+}
+
+func (impl *azureImpl) getTables(ctx context.Context, sa *StorageAccount, ec chan<- error) <-chan *Table {
+	sub := sa.Meta.Subscription
+	client, err := armstorage.NewTableClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, TableT, "GetClient", err),
+			ec,
+		)
+
+		return nil
+	}
+	getter := func() (*runtime.Pager[armstorage.TableClientListResponse], error) {
+		return client.NewListPager(sa.Meta.ResourceGroupName, sa.Meta.Name, nil), nil
+	}
+
+	handler := func(az armstorage.TableClientListResponse, out chan<- *Table) (bool, error) {
+		for _, azt := range az.Value {
+			if azt == nil {
+				continue
+			}
+			it := new(Table)
+			it.FromAzure(azt)
+			it.SetURL(sa)
+			it.StorageAccount = sa.Meta
+			if !sendChan(ctx, it, out) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return handlePager(ctx, getter, handler, ignoreUnsupportedErrorTransform(sub, TableT, "ListTables"), ec)
+}
+
+func (impl *azureImpl) getBlobServiceProperties(ctx context.Context, sa *StorageAccount, ec chan<- error) {
+	sub := sa.Meta.Subscription
+	client, err := armstorage.NewBlobServicesClient(sub, impl.tokenCredential, impl.clientOptions)
+	if err != nil {
+		sendErr(
+			ctx,
+			genericError(sub, StorageAccountT, "GetClient", err),
+			ec,
+		)
+		return
+	}
+	resp, err := client.GetServiceProperties(ctx, sa.Meta.ResourceGroupName, sa.Meta.Name, nil)
+	if err != nil {
+		if err = ignoreUnsupportedErrorTransform(sub, StorageAccountT, "GetBlobServiceProperties")(err); err != nil {
+			sendErr(ctx, err, ec)
+		}
+		return
+	}
+	sa.FromAzureBlobService(&resp.BlobServiceProperties)
+}
+
 func (impl *azureImpl) getContainers(ctx context.Context, sa *StorageAccount, ec chan<- error) <-chan *Container {
 	sub := sa.Meta.Subscription
 	client, err := armstorage.NewBlobContainersClient(sub, impl.tokenCredential, impl.clientOptions)
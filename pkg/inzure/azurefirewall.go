@@ -0,0 +1,843 @@
+package inzure
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+//go:generate go run gen/enum.go -prefix AzureFirewallThreatIntelMode -values Off,Alert,Deny -azure-type AzureFirewallThreatIntelMode -azure-values AzureFirewallThreatIntelModeOff,AzureFirewallThreatIntelModeAlert,AzureFirewallThreatIntelModeDeny -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8
+//go:generate go run gen/enum.go -prefix AzureFirewallIDPSMode -values Off,Alert,Deny -azure-type FirewallPolicyIntrusionDetectionStateType -azure-values FirewallPolicyIntrusionDetectionStateTypeOff,FirewallPolicyIntrusionDetectionStateTypeAlert,FirewallPolicyIntrusionDetectionStateTypeDeny -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8
+//go:generate go run gen/enum.go -prefix AzureFirewallAction -values Allow,Deny,DNAT
+
+// AzureFirewall is an Azure Firewall. Firewalls either have their own
+// (classic) rules or are managed by a FirewallPolicy, in which case the
+// Policy is set and the rules live on the policy.
+type AzureFirewall struct {
+	Meta             ResourceID
+	SKUName          string
+	SKUTier          string
+	ThreatIntelMode  AzureFirewallThreatIntelMode
+	Policy           ResourceID
+	IPConfigurations []AzureFirewallIPConfiguration
+	Rules            AzureFirewallRules
+}
+
+func NewEmptyAzureFirewall() *AzureFirewall {
+	fw := &AzureFirewall{
+		IPConfigurations: make([]AzureFirewallIPConfiguration, 0),
+		Rules:            newEmptyAzureFirewallRules(),
+	}
+	fw.Meta.setupEmpty()
+	fw.Policy.setupEmpty()
+	return fw
+}
+
+func (fw *AzureFirewall) FromAzure(az *armnetwork.AzureFirewall) {
+	if az.ID == nil {
+		return
+	}
+	fw.Meta.FromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.SKU != nil {
+		if props.SKU.Name != nil {
+			fw.SKUName = string(*props.SKU.Name)
+		}
+		if props.SKU.Tier != nil {
+			fw.SKUTier = string(*props.SKU.Tier)
+		}
+	}
+	fw.ThreatIntelMode.FromAzure(props.ThreatIntelMode)
+	if props.FirewallPolicy != nil {
+		gValFromPtrFromAzure(&fw.Policy, props.FirewallPolicy.ID)
+	}
+	gSliceFromPtrSetterPtrs(&fw.IPConfigurations, &props.IPConfigurations, func(ipc *AzureFirewallIPConfiguration, az *armnetwork.AzureFirewallIPConfiguration) {
+		ipc.FromAzure(az)
+	})
+
+	for _, c := range props.NatRuleCollections {
+		if c == nil || c.Properties == nil {
+			continue
+		}
+		var rc AzureFirewallRuleCollection
+		rc.setupEmpty()
+		gValFromPtr(&rc.Name, c.Name)
+		gValFromPtr(&rc.Priority, c.Properties.Priority)
+		rc.Action = AzureFirewallActionDNAT
+		gSliceFromPtrSetterPtrs(&rc.Rules, &c.Properties.Rules, func(r *AzureFirewallNetworkRule, az *armnetwork.AzureFirewallNatRule) {
+			r.FromAzureNAT(az)
+		})
+		fw.Rules.NATCollections = append(fw.Rules.NATCollections, rc)
+	}
+	for _, c := range props.NetworkRuleCollections {
+		if c == nil || c.Properties == nil {
+			continue
+		}
+		var rc AzureFirewallRuleCollection
+		rc.setupEmpty()
+		gValFromPtr(&rc.Name, c.Name)
+		gValFromPtr(&rc.Priority, c.Properties.Priority)
+		if c.Properties.Action != nil {
+			rc.Action.fromAzureRC(c.Properties.Action.Type)
+		}
+		gSliceFromPtrSetterPtrs(&rc.Rules, &c.Properties.Rules, func(r *AzureFirewallNetworkRule, az *armnetwork.AzureFirewallNetworkRule) {
+			r.FromAzure(az)
+		})
+		fw.Rules.NetworkCollections = append(fw.Rules.NetworkCollections, rc)
+	}
+	for _, c := range props.ApplicationRuleCollections {
+		if c == nil || c.Properties == nil {
+			continue
+		}
+		var rc AzureFirewallApplicationRuleCollection
+		rc.Rules = make([]AzureFirewallApplicationRule, 0)
+		gValFromPtr(&rc.Name, c.Name)
+		gValFromPtr(&rc.Priority, c.Properties.Priority)
+		if c.Properties.Action != nil {
+			rc.Action.fromAzureRC(c.Properties.Action.Type)
+		}
+		gSliceFromPtrSetterPtrs(&rc.Rules, &c.Properties.Rules, func(r *AzureFirewallApplicationRule, az *armnetwork.AzureFirewallApplicationRule) {
+			r.FromAzure(az)
+		})
+		fw.Rules.ApplicationCollections = append(fw.Rules.ApplicationCollections, rc)
+	}
+}
+
+// AzureFirewallIPConfiguration is one of the IP configurations of an
+// AzureFirewall.
+type AzureFirewallIPConfiguration struct {
+	Meta      ResourceID
+	PrivateIP string
+	PublicIP  ResourceID
+	Subnet    ResourceID
+}
+
+func (ipc *AzureFirewallIPConfiguration) FromAzure(az *armnetwork.AzureFirewallIPConfiguration) {
+	ipc.Meta.setupEmpty()
+	ipc.PublicIP.setupEmpty()
+	ipc.Subnet.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtrFromAzure(&ipc.Meta, az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ipc.PrivateIP, props.PrivateIPAddress)
+	if props.PublicIPAddress != nil {
+		gValFromPtrFromAzure(&ipc.PublicIP, props.PublicIPAddress.ID)
+	}
+	if props.Subnet != nil {
+		gValFromPtrFromAzure(&ipc.Subnet, props.Subnet.ID)
+	}
+}
+
+// FirewallPolicy is an Azure Firewall Policy. The Rules here are only the
+// policy's own rules; rules from the BasePolicy are also applied to the
+// firewalls using this policy.
+type FirewallPolicy struct {
+	Meta            ResourceID
+	SKUTier         string
+	BasePolicy      ResourceID
+	ThreatIntelMode AzureFirewallThreatIntelMode
+	IDPSMode        AzureFirewallIDPSMode
+	Firewalls       []ResourceID
+	ChildPolicies   []ResourceID
+	Rules           AzureFirewallRules
+	Identity        ManagedIdentity
+}
+
+func NewEmptyFirewallPolicy() *FirewallPolicy {
+	fp := &FirewallPolicy{
+		Firewalls:     make([]ResourceID, 0),
+		ChildPolicies: make([]ResourceID, 0),
+		Rules:         newEmptyAzureFirewallRules(),
+	}
+	fp.Meta.setupEmpty()
+	fp.BasePolicy.setupEmpty()
+	fp.Identity.setupEmpty()
+	return fp
+}
+
+func (fp *FirewallPolicy) FromAzure(az *armnetwork.FirewallPolicy) {
+	if az.ID == nil {
+		return
+	}
+	fp.Meta.FromID(*az.ID)
+	fp.Identity.FromAzureNetwork(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.SKU != nil && props.SKU.Tier != nil {
+		fp.SKUTier = string(*props.SKU.Tier)
+	}
+	if props.BasePolicy != nil {
+		gValFromPtrFromAzure(&fp.BasePolicy, props.BasePolicy.ID)
+	}
+	fp.ThreatIntelMode.FromAzure(props.ThreatIntelMode)
+	if props.IntrusionDetection != nil {
+		fp.IDPSMode.FromAzure(props.IntrusionDetection.Mode)
+	} else {
+		fp.IDPSMode = AzureFirewallIDPSModeOff
+	}
+	gSliceFromPtrSetterPtrs(&fp.Firewalls, &props.Firewalls, func(id *ResourceID, az *armnetwork.SubResource) {
+		id.setupEmpty()
+		gValFromPtrFromAzure(id, az.ID)
+	})
+	gSliceFromPtrSetterPtrs(&fp.ChildPolicies, &props.ChildPolicies, func(id *ResourceID, az *armnetwork.SubResource) {
+		id.setupEmpty()
+		gValFromPtrFromAzure(id, az.ID)
+	})
+}
+
+// AddRuleCollectionGroup adds all rule collections in the given group to the
+// policy's Rules.
+func (fp *FirewallPolicy) AddRuleCollectionGroup(az *armnetwork.FirewallPolicyRuleCollectionGroup) {
+	if az == nil || az.Properties == nil {
+		return
+	}
+	var group string
+	var groupPriority int32
+	gValFromPtr(&group, az.Name)
+	gValFromPtr(&groupPriority, az.Properties.Priority)
+	for _, c := range az.Properties.RuleCollections {
+		switch rc := c.(type) {
+		case *armnetwork.FirewallPolicyNatRuleCollection:
+			var col AzureFirewallRuleCollection
+			col.setupEmpty()
+			col.Group = group
+			col.GroupPriority = groupPriority
+			gValFromPtr(&col.Name, rc.Name)
+			gValFromPtr(&col.Priority, rc.Priority)
+			col.Action = AzureFirewallActionDNAT
+			for _, r := range rc.Rules {
+				if nat, ok := r.(*armnetwork.NatRule); ok {
+					var rule AzureFirewallNetworkRule
+					rule.FromAzurePolicyNAT(nat)
+					col.Rules = append(col.Rules, rule)
+				}
+			}
+			fp.Rules.NATCollections = append(fp.Rules.NATCollections, col)
+		case *armnetwork.FirewallPolicyFilterRuleCollection:
+			// Filter collections can mix network and application rules, so
+			// split them into a collection of each kind.
+			var net AzureFirewallRuleCollection
+			net.setupEmpty()
+			net.Group = group
+			net.GroupPriority = groupPriority
+			gValFromPtr(&net.Name, rc.Name)
+			gValFromPtr(&net.Priority, rc.Priority)
+			if rc.Action != nil && rc.Action.Type != nil {
+				switch *rc.Action.Type {
+				case armnetwork.FirewallPolicyFilterRuleCollectionActionTypeAllow:
+					net.Action = AzureFirewallActionAllow
+				case armnetwork.FirewallPolicyFilterRuleCollectionActionTypeDeny:
+					net.Action = AzureFirewallActionDeny
+				}
+			}
+			app := AzureFirewallApplicationRuleCollection{
+				Name:          net.Name,
+				Group:         group,
+				GroupPriority: groupPriority,
+				Priority:      net.Priority,
+				Action:        net.Action,
+				Rules:         make([]AzureFirewallApplicationRule, 0),
+			}
+			for _, r := range rc.Rules {
+				switch rule := r.(type) {
+				case *armnetwork.Rule:
+					var nr AzureFirewallNetworkRule
+					nr.FromAzurePolicy(rule)
+					net.Rules = append(net.Rules, nr)
+				case *armnetwork.ApplicationRule:
+					var ar AzureFirewallApplicationRule
+					ar.FromAzurePolicy(rule)
+					app.Rules = append(app.Rules, ar)
+				}
+			}
+			if len(net.Rules) > 0 {
+				fp.Rules.NetworkCollections = append(fp.Rules.NetworkCollections, net)
+			}
+			if len(app.Rules) > 0 {
+				fp.Rules.ApplicationCollections = append(fp.Rules.ApplicationCollections, app)
+			}
+		}
+	}
+}
+
+func (a *AzureFirewallAction) fromAzureRC(az *armnetwork.AzureFirewallRCActionType) {
+	if az == nil {
+		*a = AzureFirewallActionUnknown
+		return
+	}
+	switch *az {
+	case armnetwork.AzureFirewallRCActionTypeAllow:
+		*a = AzureFirewallActionAllow
+	case armnetwork.AzureFirewallRCActionTypeDeny:
+		*a = AzureFirewallActionDeny
+	default:
+		*a = AzureFirewallActionUnknown
+	}
+}
+
+// AzureFirewallRuleCollection is a prioritized collection of either network
+// or DNAT rules that all share an Action.
+type AzureFirewallRuleCollection struct {
+	Name string
+	// Group is the name of the rule collection group for collections in a
+	// FirewallPolicy. Classic collections have no group.
+	Group         string
+	GroupPriority int32
+	Priority      int32
+	Action        AzureFirewallAction
+	Rules         []AzureFirewallNetworkRule
+}
+
+func (rc *AzureFirewallRuleCollection) setupEmpty() {
+	rc.Rules = make([]AzureFirewallNetworkRule, 0)
+}
+
+// AzureFirewallApplicationRuleCollection is a prioritized collection of
+// application rules.
+type AzureFirewallApplicationRuleCollection struct {
+	Name          string
+	Group         string
+	GroupPriority int32
+	Priority      int32
+	Action        AzureFirewallAction
+	Rules         []AzureFirewallApplicationRule
+}
+
+// AzureFirewallNetworkRule is a single network rule or, in a DNAT
+// collection, a DNAT rule. For DNAT rules the destinations are the
+// firewall's public addresses and the Translated fields give where the
+// traffic is actually sent.
+type AzureFirewallNetworkRule struct {
+	Name        string
+	Description string
+	// Protocols are any of TCP, UDP, ICMP or Any
+	Protocols []string
+	SourceIPs IPCollection
+	// SourceIPGroups are referenced IP Groups. Their addresses aren't
+	// collected, so each one is also in SourceIPs as a special IP that is
+	// never known to contain anything.
+	SourceIPGroups    []ResourceID
+	DestIPs           IPCollection
+	DestIPGroups      []ResourceID
+	DestFQDNs         []string
+	DestPorts         PortCollection
+	TranslatedAddress string
+	TranslatedFQDN    string
+	TranslatedPort    string
+}
+
+func (r *AzureFirewallNetworkRule) setupEmpty() {
+	r.Protocols = make([]string, 0)
+	r.SourceIPs = make(IPCollection, 0)
+	r.SourceIPGroups = make([]ResourceID, 0)
+	r.DestIPs = make(IPCollection, 0)
+	r.DestIPGroups = make([]ResourceID, 0)
+	r.DestFQDNs = make([]string, 0)
+	r.DestPorts = make(PortCollection, 0)
+}
+
+func (r *AzureFirewallNetworkRule) FromAzure(az *armnetwork.AzureFirewallNetworkRule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	for _, p := range az.Protocols {
+		if p != nil {
+			r.Protocols = append(r.Protocols, string(*p))
+		}
+	}
+	r.setAddresses(az.SourceAddresses, az.SourceIPGroups, az.DestinationAddresses, az.DestinationIPGroups)
+	r.setPorts(az.DestinationPorts)
+	appendStrings(&r.DestFQDNs, az.DestinationFqdns)
+}
+
+func (r *AzureFirewallNetworkRule) FromAzureNAT(az *armnetwork.AzureFirewallNatRule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	for _, p := range az.Protocols {
+		if p != nil {
+			r.Protocols = append(r.Protocols, string(*p))
+		}
+	}
+	r.setAddresses(az.SourceAddresses, az.SourceIPGroups, az.DestinationAddresses, nil)
+	r.setPorts(az.DestinationPorts)
+	gValFromPtr(&r.TranslatedAddress, az.TranslatedAddress)
+	gValFromPtr(&r.TranslatedFQDN, az.TranslatedFqdn)
+	gValFromPtr(&r.TranslatedPort, az.TranslatedPort)
+}
+
+func (r *AzureFirewallNetworkRule) FromAzurePolicy(az *armnetwork.Rule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	for _, p := range az.IPProtocols {
+		if p != nil {
+			r.Protocols = append(r.Protocols, string(*p))
+		}
+	}
+	r.setAddresses(az.SourceAddresses, az.SourceIPGroups, az.DestinationAddresses, az.DestinationIPGroups)
+	r.setPorts(az.DestinationPorts)
+	appendStrings(&r.DestFQDNs, az.DestinationFqdns)
+}
+
+func (r *AzureFirewallNetworkRule) FromAzurePolicyNAT(az *armnetwork.NatRule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	for _, p := range az.IPProtocols {
+		if p != nil {
+			r.Protocols = append(r.Protocols, string(*p))
+		}
+	}
+	r.setAddresses(az.SourceAddresses, az.SourceIPGroups, az.DestinationAddresses, nil)
+	r.setPorts(az.DestinationPorts)
+	gValFromPtr(&r.TranslatedAddress, az.TranslatedAddress)
+	gValFromPtr(&r.TranslatedFQDN, az.TranslatedFqdn)
+	gValFromPtr(&r.TranslatedPort, az.TranslatedPort)
+}
+
+func (r *AzureFirewallNetworkRule) setAddresses(src []*string, srcGroups []*string, dst []*string, dstGroups []*string) {
+	appendIPs(&r.SourceIPs, src)
+	appendIPGroups(&r.SourceIPGroups, &r.SourceIPs, srcGroups)
+	appendIPs(&r.DestIPs, dst)
+	appendIPGroups(&r.DestIPGroups, &r.DestIPs, dstGroups)
+}
+
+func (r *AzureFirewallNetworkRule) setPorts(ports []*string) {
+	for _, p := range ports {
+		if p != nil && len(*p) > 0 {
+			r.DestPorts = append(r.DestPorts, NewPortFromAzure(*p))
+		}
+	}
+}
+
+// protocol folds the rule's protocols into a single SecurityRuleProtocol.
+// ICMP only rules are ProtocolUnknown.
+func (r *AzureFirewallNetworkRule) protocol() SecurityRuleProtocol {
+	tcp, udp := false, false
+	for _, p := range r.Protocols {
+		switch strings.ToLower(p) {
+		case "any":
+			return ProtocolAll
+		case "tcp":
+			tcp = true
+		case "udp":
+			udp = true
+		}
+	}
+	switch {
+	case tcp && udp:
+		return ProtocolAll
+	case tcp:
+		return ProtocolTCP
+	case udp:
+		return ProtocolUDP
+	}
+	return ProtocolUnknown
+}
+
+func (r *AzureFirewallNetworkRule) sourceContains(ip AzureIPv4) UnknownBool {
+	var ret UnknownBool = BoolFalse
+	for _, src := range r.SourceIPs {
+		contains := IPContains(src, ip)
+		if contains.True() {
+			return BoolTrue
+		} else if contains.Unknown() {
+			ret = BoolUnknown
+		}
+	}
+	return ret
+}
+
+func (r *AzureFirewallNetworkRule) containsPort(port AzurePort) bool {
+	for _, p := range r.DestPorts {
+		if PortContains(p, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// packetRoute is where traffic matching this rule is sent. For DNAT rules
+// that is the translated address and port rather than the destinations.
+func (r *AzureFirewallNetworkRule) packetRoute(dnat bool) PacketRoute {
+	if dnat {
+		pr := PacketRoute{
+			IPs:      make([]AzureIPv4, 0, 1),
+			Ports:    make([]AzurePort, 0, 1),
+			Protocol: r.protocol(),
+		}
+		if r.TranslatedAddress != "" {
+			pr.IPs = append(pr.IPs, NewAzureIPv4FromAzure(r.TranslatedAddress))
+		} else if r.TranslatedFQDN != "" {
+			pr.IPs = append(pr.IPs, NewAzureIPv4FromAzure(r.TranslatedFQDN))
+		}
+		if r.TranslatedPort != "" {
+			pr.Ports = append(pr.Ports, NewPortFromAzure(r.TranslatedPort))
+		}
+		return pr
+	}
+	pr := PacketRoute{
+		IPs:      make([]AzureIPv4, len(r.DestIPs)),
+		Ports:    make([]AzurePort, len(r.DestPorts)),
+		Protocol: r.protocol(),
+	}
+	for i, ip := range r.DestIPs {
+		pr.IPs[i] = NewAzureIPv4FromAzure(ip.String())
+	}
+	for i, port := range r.DestPorts {
+		pr.Ports[i] = NewPortFromAzure(port.String())
+	}
+	return pr
+}
+
+// AzureFirewallApplicationRule is a single application rule. These match on
+// FQDNs and URLs rather than addresses so they don't factor into the
+// Firewall implementation.
+type AzureFirewallApplicationRule struct {
+	Name           string
+	Description    string
+	SourceIPs      IPCollection
+	SourceIPGroups []ResourceID
+	// Protocols are of the form Type:Port, for example Https:443
+	Protocols     []string
+	TargetFQDNs   []string
+	TargetURLs    []string
+	FQDNTags      []string
+	WebCategories []string
+	TerminateTLS  UnknownBool
+}
+
+func (r *AzureFirewallApplicationRule) setupEmpty() {
+	r.SourceIPs = make(IPCollection, 0)
+	r.SourceIPGroups = make([]ResourceID, 0)
+	r.Protocols = make([]string, 0)
+	r.TargetFQDNs = make([]string, 0)
+	r.TargetURLs = make([]string, 0)
+	r.FQDNTags = make([]string, 0)
+	r.WebCategories = make([]string, 0)
+}
+
+func (r *AzureFirewallApplicationRule) FromAzure(az *armnetwork.AzureFirewallApplicationRule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	appendIPs(&r.SourceIPs, az.SourceAddresses)
+	appendIPGroups(&r.SourceIPGroups, &r.SourceIPs, az.SourceIPGroups)
+	for _, p := range az.Protocols {
+		if p != nil && p.ProtocolType != nil {
+			r.Protocols = append(r.Protocols, applicationRuleProtocol(string(*p.ProtocolType), p.Port))
+		}
+	}
+	appendStrings(&r.TargetFQDNs, az.TargetFqdns)
+	appendStrings(&r.FQDNTags, az.FqdnTags)
+	// Classic rules can't terminate TLS
+	r.TerminateTLS = BoolFalse
+}
+
+func (r *AzureFirewallApplicationRule) FromAzurePolicy(az *armnetwork.ApplicationRule) {
+	r.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&r.Name, az.Name)
+	gValFromPtr(&r.Description, az.Description)
+	appendIPs(&r.SourceIPs, az.SourceAddresses)
+	appendIPGroups(&r.SourceIPGroups, &r.SourceIPs, az.SourceIPGroups)
+	for _, p := range az.Protocols {
+		if p != nil && p.ProtocolType != nil {
+			r.Protocols = append(r.Protocols, applicationRuleProtocol(string(*p.ProtocolType), p.Port))
+		}
+	}
+	appendStrings(&r.TargetFQDNs, az.TargetFqdns)
+	appendStrings(&r.TargetURLs, az.TargetUrls)
+	appendStrings(&r.FQDNTags, az.FqdnTags)
+	appendStrings(&r.WebCategories, az.WebCategories)
+	r.TerminateTLS.FromBoolPtr(az.TerminateTLS)
+}
+
+func applicationRuleProtocol(ty string, port *int32) string {
+	if port == nil {
+		return ty
+	}
+	return ty + ":" + strconv.Itoa(int(*port))
+}
+
+// AzureFirewallRules are the rule collections of either an AzureFirewall
+// with classic rules or a FirewallPolicy.
+//
+// Azure Firewall processes DNAT rules first, then network rules, then
+// application rules. Within each kind collections are processed by rule
+// collection group priority and then collection priority, and the first
+// matching rule wins. Traffic that doesn't match any rule is denied.
+//
+// AzureFirewallRules implements Firewall using the DNAT and network rules.
+// Application rules are matched on FQDNs so they are ignored.
+type AzureFirewallRules struct {
+	NATCollections         []AzureFirewallRuleCollection
+	NetworkCollections     []AzureFirewallRuleCollection
+	ApplicationCollections []AzureFirewallApplicationRuleCollection
+}
+
+func newEmptyAzureFirewallRules() AzureFirewallRules {
+	return AzureFirewallRules{
+		NATCollections:         make([]AzureFirewallRuleCollection, 0),
+		NetworkCollections:     make([]AzureFirewallRuleCollection, 0),
+		ApplicationCollections: make([]AzureFirewallApplicationRuleCollection, 0),
+	}
+}
+
+// orderedAzureFirewallRule is a network or DNAT rule along with the action
+// of its collection.
+type orderedAzureFirewallRule struct {
+	rule   *AzureFirewallNetworkRule
+	allows bool
+	dnat   bool
+}
+
+func (r orderedAzureFirewallRule) packetRoute() PacketRoute {
+	return r.rule.packetRoute(r.dnat)
+}
+
+func sortedAzureFirewallRuleCollections(cols []AzureFirewallRuleCollection) []*AzureFirewallRuleCollection {
+	sorted := make([]*AzureFirewallRuleCollection, len(cols))
+	for i := range cols {
+		sorted[i] = &cols[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].GroupPriority != sorted[j].GroupPriority {
+			return sorted[i].GroupPriority < sorted[j].GroupPriority
+		}
+		return sorted[i].Priority < sorted[j].Priority
+	})
+	return sorted
+}
+
+// ordered gives every DNAT and network rule in processing order.
+func (fr *AzureFirewallRules) ordered() []orderedAzureFirewallRule {
+	rules := make([]orderedAzureFirewallRule, 0)
+	for _, col := range sortedAzureFirewallRuleCollections(fr.NATCollections) {
+		for i := range col.Rules {
+			rules = append(rules, orderedAzureFirewallRule{
+				rule:   &col.Rules[i],
+				allows: true,
+				dnat:   true,
+			})
+		}
+	}
+	for _, col := range sortedAzureFirewallRuleCollections(fr.NetworkCollections) {
+		for i := range col.Rules {
+			rules = append(rules, orderedAzureFirewallRule{
+				rule:   &col.Rules[i],
+				allows: col.Action == AzureFirewallActionAllow,
+			})
+		}
+	}
+	return rules
+}
+
+// allows checks whether the IP can get through the firewall. A port can be
+// reached over either TCP or UDP, so port checks go through the rules once
+// per protocol and only use the rules that carry that protocol. ICMP only
+// rules never match a port.
+func (fr *AzureFirewallRules) allows(checkIP AzureIPv4, checkPort AzurePort) (UnknownBool, []PacketRoute, error) {
+	if checkPort == nil {
+		return fr.allowsProtocol(checkIP, nil, ProtocolAll)
+	}
+	var ret UnknownBool = BoolFalse
+	var allowedDestinations []PacketRoute = nil
+	for _, proto := range []SecurityRuleProtocol{ProtocolTCP, ProtocolUDP} {
+		allowed, routes, err := fr.allowsProtocol(checkIP, checkPort, proto)
+		if err != nil {
+			return BoolUnknown, nil, err
+		}
+		if allowed.True() {
+			return BoolTrue, routes, nil
+		} else if allowed.Unknown() {
+			ret = BoolUnknown
+			// Rules for both protocols show up in both passes
+			for i := range routes {
+				if !packetRoutesContain(allowedDestinations, &routes[i]) {
+					allowedDestinations = append(allowedDestinations, routes[i])
+				}
+			}
+		}
+	}
+	return ret, allowedDestinations, nil
+}
+
+func packetRoutesContain(routes []PacketRoute, route *PacketRoute) bool {
+	for i := range routes {
+		if routes[i].Equals(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// carriesProtocol is whether the rule applies to traffic using the protocol.
+// Any protocol is given as ProtocolAll.
+func (r *AzureFirewallNetworkRule) carriesProtocol(proto SecurityRuleProtocol) bool {
+	if proto == ProtocolAll {
+		return true
+	}
+	rp := r.protocol()
+	return rp == ProtocolAll || rp == proto
+}
+
+func (fr *AzureFirewallRules) allowsProtocol(checkIP AzureIPv4, checkPort AzurePort, proto SecurityRuleProtocol) (UnknownBool, []PacketRoute, error) {
+	var allowedDestinations []PacketRoute = nil
+	for _, rule := range fr.ordered() {
+		if !rule.rule.carriesProtocol(proto) {
+			continue
+		}
+		if checkPort != nil && !rule.rule.containsPort(checkPort) {
+			continue
+		}
+		contains := rule.rule.sourceContains(checkIP)
+		if contains.Unknown() {
+			if !rule.allows {
+				return BoolUnknown, allowedDestinations, nil
+			}
+			allowedDestinations = append(allowedDestinations, rule.packetRoute())
+		} else if contains.True() {
+			if rule.allows {
+				return BoolTrue, []PacketRoute{rule.packetRoute()}, nil
+			}
+			// This means we had some uncertain allows, let them know
+			if len(allowedDestinations) > 0 {
+				return BoolUnknown, allowedDestinations, nil
+			}
+			return BoolFalse, nil, nil
+		}
+	}
+	// Azure Firewall denies anything that isn't explicitly allowed
+	if len(allowedDestinations) > 0 {
+		return BoolUnknown, allowedDestinations, nil
+	}
+	return BoolFalse, nil, nil
+}
+
+// AllowsIP is implementing Firewall for AzureFirewallRules
+func (fr *AzureFirewallRules) AllowsIP(checkIP AzureIPv4) (UnknownBool, []PacketRoute, error) {
+	return fr.allows(checkIP, nil)
+}
+
+func (fr *AzureFirewallRules) AllowsIPString(ip string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPFromString(fr, ip)
+}
+
+// AllowsIPToPort is implementing Firewall for AzureFirewallRules
+func (fr *AzureFirewallRules) AllowsIPToPort(checkIP AzureIPv4, checkPort AzurePort) (UnknownBool, []PacketRoute, error) {
+	return fr.allows(checkIP, checkPort)
+}
+
+func (fr *AzureFirewallRules) AllowsIPToPortString(ip, port string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPToPortFromString(fr, ip, port)
+}
+
+// RespectsAllowlist for AzureFirewallRules is NOT port agnostic. This means
+// you'll never get a BoolNotApplicable from this and the only time an error
+// is returned is when both AllPorts and PortMap are not defined.
+func (fr *AzureFirewallRules) RespectsAllowlist(wl FirewallAllowlist) (UnknownBool, []IPPort, error) {
+	if wl.AllPorts == nil && wl.PortMap == nil {
+		return BoolUnknown, nil, BadAllowlist
+	}
+	failed := false
+	failedUncertain := false
+	extras := make([]IPPort, 0)
+	for _, rule := range fr.ordered() {
+		// ICMP only rules don't open any ports
+		if !rule.allows || rule.rule.protocol() == ProtocolUnknown {
+			continue
+		}
+		for _, allowedIP := range rule.rule.SourceIPs {
+			if wl.IPPassesStar(allowedIP).True() {
+				continue
+			}
+			for _, port := range rule.rule.DestPorts {
+				passes := wl.IPPassesAny(port, allowedIP)
+				if passes.False() {
+					extras = append(extras, IPPort{
+						IP:   allowedIP,
+						Port: port,
+					})
+					failed = true
+				} else if passes.Unknown() {
+					extras = append(extras, IPPort{
+						IP:   allowedIP,
+						Port: port,
+					})
+					failedUncertain = true
+				}
+			}
+		}
+	}
+	if !failed && !failedUncertain {
+		return BoolTrue, nil, nil
+	} else if failedUncertain {
+		return BoolUnknown, extras, nil
+	}
+	return BoolFalse, extras, nil
+}
+
+// ipGroupIPName is the name used for the special AzureIPv4 that stands in
+// for an IP Group in a rule.
+func ipGroupIPName(group ResourceID) string {
+	return strings.Replace(group.Name, "-", "_", -1)
+}
+
+func appendIPs(into *IPCollection, from []*string) {
+	for _, ip := range from {
+		if ip != nil && len(*ip) > 0 {
+			*into = append(*into, NewAzureIPv4FromAzure(*ip))
+		}
+	}
+}
+
+func appendIPGroups(into *[]ResourceID, ips *IPCollection, from []*string) {
+	for _, id := range from {
+		if id == nil || len(*id) == 0 {
+			continue
+		}
+		var group ResourceID
+		group.FromID(*id)
+		*into = append(*into, group)
+		*ips = append(*ips, NewAzureIPv4FromAzure(ipGroupIPName(group)))
+	}
+}
+
+func appendStrings(into *[]string, from []*string) {
+	for _, s := range from {
+		if s != nil && len(*s) > 0 {
+			*into = append(*into, *s)
+		}
+	}
+}
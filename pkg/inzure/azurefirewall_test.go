@@ -0,0 +1,261 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+const fwPolicyID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/firewallPolicies/policy"
+
+func testFirewallPolicy() *FirewallPolicy {
+	tcp := armnetwork.FirewallPolicyRuleNetworkProtocolTCP
+	allow := armnetwork.FirewallPolicyFilterRuleCollectionActionTypeAllow
+	deny := armnetwork.FirewallPolicyFilterRuleCollectionActionTypeDeny
+	idpsDeny := armnetwork.FirewallPolicyIntrusionDetectionStateTypeDeny
+	fp := NewEmptyFirewallPolicy()
+	fp.FromAzure(&armnetwork.FirewallPolicy{
+		ID: ptr(fwPolicyID),
+		Properties: &armnetwork.FirewallPolicyPropertiesFormat{
+			IntrusionDetection: &armnetwork.FirewallPolicyIntrusionDetection{Mode: &idpsDeny},
+		},
+	})
+	// The group with the allows has a higher priority number so the deny in
+	// the other group is processed first.
+	fp.AddRuleCollectionGroup(&armnetwork.FirewallPolicyRuleCollectionGroup{
+		Name: ptr("allows"),
+		Properties: &armnetwork.FirewallPolicyRuleCollectionGroupProperties{
+			Priority: ptr[int32](200),
+			RuleCollections: []armnetwork.FirewallPolicyRuleCollectionClassification{
+				&armnetwork.FirewallPolicyFilterRuleCollection{
+					Name:     ptr("allow-ssh"),
+					Priority: ptr[int32](100),
+					Action:   &armnetwork.FirewallPolicyFilterRuleCollectionAction{Type: &allow},
+					Rules: []armnetwork.FirewallPolicyRuleClassification{
+						&armnetwork.Rule{
+							Name:                 ptr("ssh"),
+							IPProtocols:          []*armnetwork.FirewallPolicyRuleNetworkProtocol{&tcp},
+							SourceAddresses:      []*string{ptr("10.0.0.0/8")},
+							DestinationAddresses: []*string{ptr("10.1.0.4")},
+							DestinationPorts:     []*string{ptr("22")},
+						},
+						&armnetwork.ApplicationRule{
+							Name:            ptr("web"),
+							SourceAddresses: []*string{ptr("*")},
+							TargetFqdns:     []*string{ptr("example.com")},
+						},
+					},
+				},
+				&armnetwork.FirewallPolicyNatRuleCollection{
+					Name:     ptr("dnat"),
+					Priority: ptr[int32](300),
+					Rules: []armnetwork.FirewallPolicyRuleClassification{
+						&armnetwork.NatRule{
+							Name:                 ptr("rdp"),
+							IPProtocols:          []*armnetwork.FirewallPolicyRuleNetworkProtocol{&tcp},
+							SourceAddresses:      []*string{ptr("*")},
+							DestinationAddresses: []*string{ptr("20.1.2.3")},
+							DestinationPorts:     []*string{ptr("3389")},
+							TranslatedAddress:    ptr("10.1.0.5"),
+							TranslatedPort:       ptr("3389"),
+						},
+					},
+				},
+			},
+		},
+	})
+	fp.AddRuleCollectionGroup(&armnetwork.FirewallPolicyRuleCollectionGroup{
+		Name: ptr("denies"),
+		Properties: &armnetwork.FirewallPolicyRuleCollectionGroupProperties{
+			Priority: ptr[int32](100),
+			RuleCollections: []armnetwork.FirewallPolicyRuleCollectionClassification{
+				&armnetwork.FirewallPolicyFilterRuleCollection{
+					Name:     ptr("deny-bad"),
+					Priority: ptr[int32](1000),
+					Action:   &armnetwork.FirewallPolicyFilterRuleCollectionAction{Type: &deny},
+					Rules: []armnetwork.FirewallPolicyRuleClassification{
+						&armnetwork.Rule{
+							Name:                 ptr("bad"),
+							IPProtocols:          []*armnetwork.FirewallPolicyRuleNetworkProtocol{&tcp},
+							SourceAddresses:      []*string{ptr("10.6.6.0/24")},
+							DestinationAddresses: []*string{ptr("*")},
+							DestinationPorts:     []*string{ptr("*")},
+						},
+					},
+				},
+			},
+		},
+	})
+	return fp
+}
+
+func TestFirewallPolicyFromAzure(t *testing.T) {
+	fp := testFirewallPolicy()
+	if fp.Meta.Tag != FirewallPolicyT || fp.Meta.Name != "policy" {
+		t.Fatalf("bad meta: %+v", fp.Meta)
+	}
+	if !fp.IDPSMode.IsDeny().True() {
+		t.Fatalf("bad IDPS mode: %v", fp.IDPSMode)
+	}
+	rules := fp.Rules
+	if len(rules.NATCollections) != 1 || len(rules.NetworkCollections) != 2 || len(rules.ApplicationCollections) != 1 {
+		t.Fatalf("bad collections: %+v", rules)
+	}
+	if rules.ApplicationCollections[0].Group != "allows" || rules.ApplicationCollections[0].Rules[0].TargetFQDNs[0] != "example.com" {
+		t.Fatalf("bad application collection: %+v", rules.ApplicationCollections[0])
+	}
+}
+
+func TestAzureFirewallRulesFirewall(t *testing.T) {
+	var fw Firewall = &testFirewallPolicy().Rules
+
+	allowed, routes, err := fw.AllowsIPToPortString("10.2.3.4", "22")
+	if err != nil || !allowed.True() {
+		t.Fatalf("expected ssh to be allowed: %v %v", allowed, err)
+	}
+	if len(routes) != 1 || routes[0].IPs[0].String() != "10.1.0.4" {
+		t.Fatalf("bad routes: %v", routes)
+	}
+
+	// Denied by the higher priority group
+	if allowed, _, _ := fw.AllowsIPToPortString("10.6.6.6", "22"); !allowed.False() {
+		t.Fatalf("expected 10.6.6.6 to be denied: %v", allowed)
+	}
+
+	// Nothing matches, so the default deny applies
+	if allowed, _, _ := fw.AllowsIPToPortString("10.2.3.4", "80"); !allowed.False() {
+		t.Fatalf("expected port 80 to be denied: %v", allowed)
+	}
+
+	// DNAT is processed before network rules and goes to the translated
+	// address
+	allowed, routes, _ = fw.AllowsIPToPortString("10.6.6.6", "3389")
+	if !allowed.True() || len(routes) != 1 || routes[0].IPs[0].String() != "10.1.0.5" {
+		t.Fatalf("expected DNAT to allow rdp: %v %v", allowed, routes)
+	}
+
+	wl := FirewallAllowlist{
+		AllPorts: []AzureIPv4{NewAzureIPv4FromAzure("10.0.0.0/8")},
+	}
+	respects, extras, err := fw.RespectsAllowlist(wl)
+	if err != nil || !respects.False() {
+		t.Fatalf("expected the DNAT rule to break the allowlist: %v %v", respects, err)
+	}
+	if len(extras) != 1 || extras[0].Port.String() != "3389" {
+		t.Fatalf("bad extras: %v", extras)
+	}
+}
+
+func TestAzureFirewallFromAzure(t *testing.T) {
+	anyProto := armnetwork.AzureFirewallNetworkRuleProtocolAny
+	allow := armnetwork.AzureFirewallRCActionTypeAllow
+	alert := armnetwork.AzureFirewallThreatIntelModeAlert
+	fw := NewEmptyAzureFirewall()
+	fw.FromAzure(&armnetwork.AzureFirewall{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/azureFirewalls/fw"),
+		Properties: &armnetwork.AzureFirewallPropertiesFormat{
+			ThreatIntelMode: &alert,
+			NetworkRuleCollections: []*armnetwork.AzureFirewallNetworkRuleCollection{
+				{
+					Name: ptr("classic"),
+					Properties: &armnetwork.AzureFirewallNetworkRuleCollectionPropertiesFormat{
+						Priority: ptr[int32](100),
+						Action:   &armnetwork.AzureFirewallRCAction{Type: &allow},
+						Rules: []*armnetwork.AzureFirewallNetworkRule{
+							{
+								Name:                 ptr("groups"),
+								Protocols:            []*armnetwork.AzureFirewallNetworkRuleProtocol{&anyProto},
+								SourceIPGroups:       []*string{ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/ipGroups/office-ips")},
+								DestinationAddresses: []*string{ptr("*")},
+								DestinationPorts:     []*string{ptr("443")},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if fw.Meta.Tag != AzureFirewallT || !fw.ThreatIntelMode.IsAlert().True() {
+		t.Fatalf("bad firewall: %+v", fw)
+	}
+	rule := fw.Rules.NetworkCollections[0].Rules[0]
+	if len(rule.SourceIPGroups) != 1 || rule.SourceIPGroups[0].Name != "office-ips" {
+		t.Fatalf("bad IP groups: %+v", rule.SourceIPGroups)
+	}
+	// IP Group contents aren't known
+	if allowed, routes, _ := fw.Rules.AllowsIPToPortString("8.8.8.8", "443"); !allowed.Unknown() || len(routes) != 1 {
+		t.Fatalf("expected IP groups to be uncertain: %v %v", allowed, routes)
+	}
+}
+
+func TestAzureFirewallRulesProtocols(t *testing.T) {
+	rule := func(name string, proto string, src string, port string) AzureFirewallNetworkRule {
+		return AzureFirewallNetworkRule{
+			Name:      name,
+			Protocols: []string{proto},
+			SourceIPs: createIPs(src),
+			DestIPs:   createIPs("*"),
+			DestPorts: createPorts(port),
+		}
+	}
+	rules := &AzureFirewallRules{
+		NetworkCollections: []AzureFirewallRuleCollection{
+			{
+				Name:     "deny",
+				Priority: 100,
+				Action:   AzureFirewallActionDeny,
+				Rules: []AzureFirewallNetworkRule{
+					rule("udp-dns", "UDP", "10.0.0.0/8", "53"),
+					rule("tcp-web", "TCP", "10.0.0.0/8", "8080"),
+				},
+			},
+			{
+				Name:     "allow",
+				Priority: 200,
+				Action:   AzureFirewallActionAllow,
+				Rules: []AzureFirewallNetworkRule{
+					rule("tcp-dns", "TCP", "*", "53"),
+					rule("ping", "ICMP", "*", "*"),
+					rule("syslog", "UDP", "10.0.0.0/8", "514"),
+					rule("web", "Any", "*", "8080"),
+				},
+			},
+		},
+	}
+	var fw Firewall = rules
+
+	// The UDP deny doesn't shadow the TCP allow
+	allowed, routes, _ := fw.AllowsIPToPortString("10.2.3.4", "53")
+	if !allowed.True() || len(routes) != 1 || routes[0].Protocol != ProtocolTCP {
+		t.Fatalf("expected TCP dns to be allowed: %v %v", allowed, routes)
+	}
+	// ICMP doesn't carry ports
+	if allowed, _, _ := fw.AllowsIPToPortString("10.2.3.4", "22"); !allowed.False() {
+		t.Fatalf("expected the ICMP rule to not allow port 22: %v", allowed)
+	}
+	// UDP only allows are reported with their protocol
+	allowed, routes, _ = fw.AllowsIPToPortString("10.2.3.4", "514")
+	if !allowed.True() || len(routes) != 1 || routes[0].Protocol != ProtocolUDP {
+		t.Fatalf("expected UDP syslog to be allowed: %v %v", allowed, routes)
+	}
+	if allowed, _, _ := fw.AllowsIPToPortString("20.1.2.3", "514"); !allowed.False() {
+		t.Fatalf("expected syslog from outside to be denied: %v", allowed)
+	}
+	// TCP is denied but UDP still gets through the Any rule
+	allowed, routes, _ = fw.AllowsIPToPortString("10.2.3.4", "8080")
+	if !allowed.True() || len(routes) != 1 || routes[0].Protocol != ProtocolAll {
+		t.Fatalf("expected UDP 8080 to be allowed: %v %v", allowed, routes)
+	}
+
+	wl := FirewallAllowlist{
+		AllPorts: []AzureIPv4{NewAzureIPv4FromAzure("10.0.0.0/8")},
+		PortMap: map[string][]AzureIPv4{
+			"53":   {NewAzureIPv4FromAzure("*")},
+			"8080": {NewAzureIPv4FromAzure("*")},
+		},
+	}
+	respects, extras, err := fw.RespectsAllowlist(wl)
+	if err != nil || !respects.True() {
+		t.Fatalf("expected the ICMP rule to be ignored by the allowlist: %v %v %v", respects, extras, err)
+	}
+}
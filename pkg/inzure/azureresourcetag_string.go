@@ -51,11 +51,35 @@ func _() {
 	_ = x[PostgresServerT-40]
 	_ = x[PostgresDBT-41]
 	_ = x[BastionHostT-42]
+	_ = x[GrafanaT-43]
+	_ = x[PrivateEndpointConnectionT-44]
+	_ = x[SQLVirtualMachineT-45]
+	_ = x[KubernetesClusterT-46]
+	_ = x[ContainerRegistryT-47]
+	_ = x[ApplicationGatewayT-48]
+	_ = x[AzureFirewallT-49]
+	_ = x[FirewallPolicyT-50]
+	_ = x[EventHubNamespaceT-51]
+	_ = x[PostgresFlexibleServerT-52]
+	_ = x[MySQLServerT-53]
+	_ = x[MySQLFlexibleServerT-54]
+	_ = x[RoleDefinitionT-55]
+	_ = x[RoleAssignmentT-56]
+	_ = x[UserAssignedIdentityT-57]
+	_ = x[PrivateEndpointT-58]
+	_ = x[PrivateDNSZoneT-59]
+	_ = x[RouteTableT-60]
+	_ = x[NATGatewayT-61]
+	_ = x[VirtualNetworkGatewayT-62]
+	_ = x[VirtualNetworkPeeringT-63]
+	_ = x[CDNProfileT-64]
+	_ = x[FrontDoorT-65]
+	_ = x[TrafficManagerProfileT-66]
 }
 
-const _AzureResourceTag_name = "ResourceUnsetTResourceUnknownTResourceGroupTStorageAccountTContainerTQueueTFileShareTTableTProviderTNetworkSecurityGroupTVirtualNetworkTVirtualMachineTSubnetTNetworkInterfaceTIPConfigurationTPublicIPTWebAppTFunctionTDataLakeTDataLakeStoreTDataLakeAnalyticsTSQLServerTWebAppSlotTRedisServerTRecommendationTSQLDatabaseTVirtualMachineScaleSetTApiTApiServiceTApiOperationTApiBackendTApiServiceProductTServiceBusTServiceFabricTApiSchemaTLoadBalancerTFrontendIPConfigurationTApplicationSecurityGroupTKeyVaultTCosmosDBTPostgresServerTPostgresDBTBastionHostT"
+const _AzureResourceTag_name = "ResourceUnsetTResourceUnknownTResourceGroupTStorageAccountTContainerTQueueTFileShareTTableTProviderTNetworkSecurityGroupTVirtualNetworkTVirtualMachineTSubnetTNetworkInterfaceTIPConfigurationTPublicIPTWebAppTFunctionTDataLakeTDataLakeStoreTDataLakeAnalyticsTSQLServerTWebAppSlotTRedisServerTRecommendationTSQLDatabaseTVirtualMachineScaleSetTApiTApiServiceTApiOperationTApiBackendTApiServiceProductTServiceBusTServiceFabricTApiSchemaTLoadBalancerTFrontendIPConfigurationTApplicationSecurityGroupTKeyVaultTCosmosDBTPostgresServerTPostgresDBTBastionHostTGrafanaTPrivateEndpointConnectionTSQLVirtualMachineTKubernetesClusterTContainerRegistryTApplicationGatewayTAzureFirewallTFirewallPolicyTEventHubNamespaceTPostgresFlexibleServerTMySQLServerTMySQLFlexibleServerTRoleDefinitionTRoleAssignmentTUserAssignedIdentityTPrivateEndpointTPrivateDNSZoneTRouteTableTNATGatewayTVirtualNetworkGatewayTVirtualNetworkPeeringTCDNProfileTFrontDoorTTrafficManagerProfileT"
 
-var _AzureResourceTag_index = [...]uint16{0, 14, 30, 44, 59, 69, 75, 85, 91, 100, 121, 136, 151, 158, 175, 191, 200, 207, 216, 225, 239, 257, 267, 278, 290, 305, 317, 340, 344, 355, 368, 379, 397, 408, 422, 432, 445, 469, 494, 503, 512, 527, 538, 550}
+var _AzureResourceTag_index = [...]uint16{0, 14, 30, 44, 59, 69, 75, 85, 91, 100, 121, 136, 151, 158, 175, 191, 200, 207, 216, 225, 239, 257, 267, 278, 290, 305, 317, 340, 344, 355, 368, 379, 397, 408, 422, 432, 445, 469, 494, 503, 512, 527, 538, 550, 558, 584, 602, 620, 638, 657, 671, 686, 704, 727, 739, 759, 774, 789, 810, 826, 841, 852, 863, 885, 907, 918, 928, 950}
 
 func (i AzureResourceTag) String() string {
 	idx := int(i) - 0
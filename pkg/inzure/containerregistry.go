@@ -0,0 +1,214 @@
+package inzure
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+)
+
+//go:generate go run gen/enum.go -prefix ContainerRegistrySKU -values Basic,Classic,Premium,Standard -azure-type SKUName -azure-values SKUNameBasic,SKUNameClassic,SKUNamePremium,SKUNameStandard -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry
+
+// ContainerRegistry is an Azure Container Registry.
+type ContainerRegistry struct {
+	Meta        ResourceID
+	LoginServer string
+	SKU         ContainerRegistrySKU
+	// AdminUserEnabled is whether the registry has a single admin account
+	// with a username and password.
+	AdminUserEnabled UnknownBool
+	// AnonymousPullEnabled is whether anyone can pull images without
+	// authenticating.
+	AnonymousPullEnabled UnknownBool
+	DataEndpointEnabled  UnknownBool
+	Firewall             ContainerRegistryFirewall
+	Encryption           ContainerRegistryEncryption
+	// TrustPolicyEnabled is whether content trust (signed images) is
+	// enforced.
+	TrustPolicyEnabled      UnknownBool
+	QuarantinePolicyEnabled UnknownBool
+	RetentionPolicyEnabled  UnknownBool
+	RetentionDays           int32
+	ExportPolicyEnabled     UnknownBool
+	Identity                ManagedIdentity
+}
+
+func NewEmptyContainerRegistry() *ContainerRegistry {
+	var rid ResourceID
+	rid.setupEmpty()
+	cr := &ContainerRegistry{
+		Meta: rid,
+		Firewall: ContainerRegistryFirewall{
+			IPRules: make(IPCollection, 0),
+		},
+	}
+	cr.Identity.setupEmpty()
+	return cr
+}
+
+func (cr *ContainerRegistry) FromAzure(az *armcontainerregistry.Registry) {
+	if az.ID == nil {
+		return
+	}
+	cr.Meta.FromID(*az.ID)
+	cr.Identity.FromAzureContainerRegistry(az.Identity)
+	if az.SKU != nil {
+		cr.SKU.FromAzure(az.SKU.Name)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&cr.LoginServer, props.LoginServer)
+	cr.AdminUserEnabled.FromBoolPtr(props.AdminUserEnabled)
+	cr.DataEndpointEnabled.FromBoolPtr(props.DataEndpointEnabled)
+	cr.Firewall.FromAzure(props)
+	cr.Encryption.FromAzure(props.Encryption)
+
+	if pol := props.Policies; pol != nil {
+		if pol.TrustPolicy != nil {
+			cr.TrustPolicyEnabled = ubFromRhsPtr(armcontainerregistry.PolicyStatusEnabled, pol.TrustPolicy.Status)
+		}
+		if pol.QuarantinePolicy != nil {
+			cr.QuarantinePolicyEnabled = ubFromRhsPtr(armcontainerregistry.PolicyStatusEnabled, pol.QuarantinePolicy.Status)
+		}
+		if pol.RetentionPolicy != nil {
+			cr.RetentionPolicyEnabled = ubFromRhsPtr(armcontainerregistry.PolicyStatusEnabled, pol.RetentionPolicy.Status)
+			gValFromPtr(&cr.RetentionDays, pol.RetentionPolicy.Days)
+		}
+		if pol.ExportPolicy != nil {
+			cr.ExportPolicyEnabled = ubFromRhsPtr(armcontainerregistry.ExportPolicyStatusEnabled, pol.ExportPolicy.Status)
+		}
+	}
+}
+
+// containerRegistryAnonymousPull gets the anonymousPullEnabled property out
+// of a raw list of registries keyed by lowercase ID. The stable SDK doesn't
+// know about this property so we have to look at the JSON ourselves.
+func containerRegistryAnonymousPull(b []byte) map[string]bool {
+	var list struct {
+		Value []struct {
+			ID         string `json:"id"`
+			Properties struct {
+				AnonymousPullEnabled *bool `json:"anonymousPullEnabled"`
+			} `json:"properties"`
+		} `json:"value"`
+	}
+	found := make(map[string]bool)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return found
+	}
+	for _, v := range list.Value {
+		if v.Properties.AnonymousPullEnabled != nil {
+			found[strings.ToLower(v.ID)] = *v.Properties.AnonymousPullEnabled
+		}
+	}
+	return found
+}
+
+type ContainerRegistryEncryption struct {
+	// Enabled is whether a customer managed key is used
+	Enabled       UnknownBool
+	KeyIdentifier string
+}
+
+func (e *ContainerRegistryEncryption) FromAzure(az *armcontainerregistry.EncryptionProperty) {
+	if az == nil {
+		return
+	}
+	e.Enabled = ubFromRhsPtr(armcontainerregistry.EncryptionStatusEnabled, az.Status)
+	if az.KeyVaultProperties != nil {
+		gValFromPtr(&e.KeyIdentifier, az.KeyVaultProperties.KeyIdentifier)
+	}
+}
+
+// ContainerRegistryFirewall is the network access configuration of a
+// registry. Network rules are only supported on Premium registries, others
+// allow everything unless public network access is disabled.
+type ContainerRegistryFirewall struct {
+	PublicNetworkAccess UnknownBool
+	DefaultAllow        UnknownBool
+	// AllowAzureServices is whether trusted Azure services bypass the rules
+	AllowAzureServices UnknownBool
+	IPRules            IPCollection
+}
+
+func (f *ContainerRegistryFirewall) FromAzure(props *armcontainerregistry.RegistryProperties) {
+	if props.PublicNetworkAccess != nil {
+		f.PublicNetworkAccess = ubFromRhsPtr(armcontainerregistry.PublicNetworkAccessEnabled, props.PublicNetworkAccess)
+	}
+	if props.NetworkRuleBypassOptions != nil {
+		f.AllowAzureServices = ubFromRhsPtr(armcontainerregistry.NetworkRuleBypassOptionsAzureServices, props.NetworkRuleBypassOptions)
+	}
+	rs := props.NetworkRuleSet
+	// Given no rules we are letting all traffic in by default
+	if rs == nil {
+		f.DefaultAllow = BoolTrue
+		return
+	}
+	f.DefaultAllow = ubFromRhsPtr(armcontainerregistry.DefaultActionAllow, rs.DefaultAction)
+	for _, rule := range rs.IPRules {
+		if rule == nil || rule.IPAddressOrRange == nil {
+			continue
+		}
+		f.IPRules = append(f.IPRules, NewAzureIPv4FromAzure(*rule.IPAddressOrRange))
+	}
+}
+
+func (f ContainerRegistryFirewall) AllowsIPToPortString(ip, port string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPToPortFromString(f, ip, port)
+}
+
+func (f ContainerRegistryFirewall) AllowsIPString(ip string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPFromString(f, ip)
+}
+
+func (f ContainerRegistryFirewall) AllowsIP(chk AzureIPv4) (UnknownBool, []PacketRoute, error) {
+	if f.PublicNetworkAccess.False() {
+		return BoolFalse, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+	}
+	uncertain := false
+	for _, ip := range f.IPRules {
+		contains := IPContains(ip, chk)
+		if contains.True() {
+			return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+		} else if contains.Unknown() {
+			uncertain = true
+		}
+	}
+	if uncertain || !f.DefaultAllow.False() {
+		return BoolUnknown, nil, nil
+	}
+	return BoolFalse, nil, nil
+}
+
+// AllowsIPToPort is the same as AllowsIP since registries only listen on
+// HTTPS.
+func (f ContainerRegistryFirewall) AllowsIPToPort(ip AzureIPv4, _ AzurePort) (UnknownBool, []PacketRoute, error) {
+	return f.AllowsIP(ip)
+}
+
+func (f ContainerRegistryFirewall) RespectsAllowlist(wl FirewallAllowlist) (UnknownBool, []IPPort, error) {
+	if wl.AllPorts == nil {
+		return BoolUnknown, nil, BadAllowlist
+	}
+	if f.PublicNetworkAccess.False() {
+		return BoolTrue, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolFalse, []IPPort{{
+			IP:   NewAzureIPv4FromAzure("*"),
+			Port: NewPortFromAzure("*"),
+		}}, nil
+	}
+	if len(f.IPRules) == 0 {
+		if f.DefaultAllow.False() {
+			return BoolTrue, nil, nil
+		}
+		return BoolUnknown, nil, nil
+	}
+	return f.IPRules.RespectsAllowlist(wl)
+}
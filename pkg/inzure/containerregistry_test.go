@@ -0,0 +1,109 @@
+package inzure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+)
+
+const acrID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.ContainerRegistry/registries/acr"
+
+func TestContainerRegistryFromAzure(t *testing.T) {
+	sku := armcontainerregistry.SKUNamePremium
+	deny := armcontainerregistry.DefaultActionDeny
+	enabled := armcontainerregistry.PolicyStatusEnabled
+	disabled := armcontainerregistry.PolicyStatusDisabled
+	public := armcontainerregistry.PublicNetworkAccessEnabled
+	az := &armcontainerregistry.Registry{
+		ID:  ptr(acrID),
+		SKU: &armcontainerregistry.SKU{Name: &sku},
+		Properties: &armcontainerregistry.RegistryProperties{
+			LoginServer:         ptr("acr.azurecr.io"),
+			AdminUserEnabled:    ptr(true),
+			PublicNetworkAccess: &public,
+			NetworkRuleSet: &armcontainerregistry.NetworkRuleSet{
+				DefaultAction: &deny,
+				IPRules: []*armcontainerregistry.IPRule{
+					{IPAddressOrRange: ptr("10.0.0.0/8")},
+				},
+			},
+			Policies: &armcontainerregistry.Policies{
+				TrustPolicy:      &armcontainerregistry.TrustPolicy{Status: &enabled},
+				QuarantinePolicy: &armcontainerregistry.QuarantinePolicy{Status: &disabled},
+			},
+		},
+	}
+	cr := NewEmptyContainerRegistry()
+	cr.FromAzure(az)
+	if cr.Meta.Tag != ContainerRegistryT || cr.Meta.Name != "acr" {
+		t.Fatalf("bad meta: %+v", cr.Meta)
+	}
+	if cr.SKU != ContainerRegistrySKUPremium || cr.LoginServer != "acr.azurecr.io" || !cr.AdminUserEnabled.True() {
+		t.Fatalf("bad registry: %+v", cr)
+	}
+	if !cr.TrustPolicyEnabled.True() || !cr.QuarantinePolicyEnabled.False() || !cr.RetentionPolicyEnabled.Unknown() {
+		t.Fatalf("bad policies: %+v", cr)
+	}
+	if !cr.AnonymousPullEnabled.Unknown() {
+		t.Fatalf("anonymous pull should be unknown without the raw response")
+	}
+
+	var fw Firewall = cr.Firewall
+	if allowed, _, _ := fw.AllowsIPString("10.1.2.3"); !allowed.True() {
+		t.Fatal("expected 10.1.2.3 to be allowed")
+	}
+	if allowed, _, _ := fw.AllowsIPString("8.8.8.8"); !allowed.False() {
+		t.Fatal("expected 8.8.8.8 to be denied")
+	}
+
+	cr.Firewall.PublicNetworkAccess = BoolFalse
+	if allowed, _, _ := cr.Firewall.AllowsIPString("10.1.2.3"); !allowed.False() {
+		t.Fatal("expected everything to be denied without public network access")
+	}
+}
+
+func TestContainerRegistryAnonymousPull(t *testing.T) {
+	raw := `{"value": [
+		{"id": "` + acrID + `", "properties": {"anonymousPullEnabled": true}},
+		{"id": "/other", "properties": {}}
+	]}`
+	found := containerRegistryAnonymousPull([]byte(raw))
+	if len(found) != 1 {
+		t.Fatalf("expected a single registry: %v", found)
+	}
+	if anon, has := found[strings.ToLower(acrID)]; !has || !anon {
+		t.Fatalf("expected anonymous pull to be enabled: %v", found)
+	}
+}
+
+func TestContainerRegistryAttackSurface(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+
+	public := NewEmptyContainerRegistry()
+	public.Meta = newResourceId(rgAName, ContainerRegistryT, "public")
+	public.LoginServer = "public.azurecr.io"
+	public.Firewall.PublicNetworkAccess = BoolTrue
+
+	private := NewEmptyContainerRegistry()
+	private.Meta = newResourceId(rgAName, ContainerRegistryT, "private")
+	private.LoginServer = "private.azurecr.io"
+	private.Firewall.PublicNetworkAccess = BoolFalse
+
+	rg.ContainerRegistries = append(rg.ContainerRegistries, public, private)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.ContainerRegistries) != 1 || as.ContainerRegistries[0] != public.LoginServer {
+		t.Fatalf("expected only the public registry: %v", as.ContainerRegistries)
+	}
+
+	var registries []*ContainerRegistry
+	if err := sub.FromQueryString("/ContainerRegistries[.Firewall.PublicNetworkAccess == BoolFalse]", &registries); err != nil {
+		t.Fatalf("query string failed: %v", err)
+	}
+	if len(registries) != 1 || registries[0].Meta.Name != "private" {
+		t.Fatalf("bad query string results: %v", registries)
+	}
+}
@@ -10,6 +10,9 @@ type CosmosDB struct {
 	Meta     ResourceID
 	Endpoint string
 	Firewall CosmosDBFirewall
+	Identity ManagedIdentity
+	// PrivateEndpoints have an approved connection to the account
+	PrivateEndpoints []ResourceID
 }
 
 func (c *CosmosDB) FromAzure(az *armcosmos.DatabaseAccountGetResults) {
@@ -17,6 +20,7 @@ func (c *CosmosDB) FromAzure(az *armcosmos.DatabaseAccountGetResults) {
 		return
 	}
 	c.Meta.FromID(*az.ID)
+	c.Identity.FromAzureCosmos(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -61,6 +65,17 @@ func (c *CosmosDB) FromAzure(az *armcosmos.DatabaseAccountGetResults) {
 		}
 	}
 
+	for _, pec := range props.PrivateEndpointConnections {
+		if pec == nil || pec.Properties == nil || pec.Properties.PrivateEndpoint == nil {
+			continue
+		}
+		var status string
+		if cs := pec.Properties.PrivateLinkServiceConnectionState; cs != nil {
+			gValFromPtr(&status, cs.Status)
+		}
+		appendApprovedPrivateEndpoint(&c.PrivateEndpoints, pec.Properties.PrivateEndpoint.ID, status)
+	}
+
 	c.Firewall.VNetEnabled.FromBoolPtr(props.IsVirtualNetworkFilterEnabled)
 	vnrs := props.VirtualNetworkRules
 	if vnrs != nil && len(vnrs) > 0 {
@@ -78,14 +93,17 @@ func (c *CosmosDB) FromAzure(az *armcosmos.DatabaseAccountGetResults) {
 func NewEmptyCosmosDB() *CosmosDB {
 	var rid ResourceID
 	rid.setupEmpty()
-	return &CosmosDB{
-		Meta: rid,
+	c := &CosmosDB{
+		Meta:             rid,
+		PrivateEndpoints: make([]ResourceID, 0),
 		Firewall: CosmosDBFirewall{
 			AllowedResources: make([]ResourceID, 0),
 			IPs:              make([]AzureIPv4, 0),
 			VNetRules:        make([]ResourceID, 0),
 		},
 	}
+	c.Identity.setupEmpty()
+	return c
 }
 
 type CosmosDBFirewall struct {
@@ -71,18 +71,21 @@ type DataLakeStore struct {
 	Firewall           DataLakeFirewall
 	TrustedIDProviders []string
 	TrustIDProviders   UnknownBool
+	Identity           ManagedIdentity
 }
 
 func NewEmptyDataLakeStore() *DataLakeStore {
 	var id ResourceID
 	id.setupEmpty()
-	return &DataLakeStore{
+	dl := &DataLakeStore{
 		Meta:               id,
 		TrustedIDProviders: make([]string, 0),
 		Firewall: DataLakeFirewall{
 			Rules: make(FirewallRules, 0),
 		},
 	}
+	dl.Identity.setupEmpty()
+	return dl
 }
 
 func (dl *DataLakeStore) FromAzure(az *armdatalakestore.Account) {
@@ -90,6 +93,7 @@ func (dl *DataLakeStore) FromAzure(az *armdatalakestore.Account) {
 		return
 	}
 	dl.Meta.fromID(*az.ID)
+	dl.Identity.FromAzureDataLakeStore(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -0,0 +1,158 @@
+package inzure
+
+import (
+	"strings"
+)
+
+// The MySQL and PostgreSQL Flexible Server resource providers aren't covered
+// by an SDK module in use here. These are the pieces of their REST APIs that
+// we care about. Most of them are shared between the providers.
+//
+// TODO: Switch to armmysql, armmysqlflexibleservers, and
+// armpostgresqlflexibleservers once they're dependencies, the same way the
+// armpostgresql collector works.
+
+const (
+	mysqlAPIVersion                 = "2017-12-01"
+	mysqlFlexibleAPIVersion         = "2023-12-30"
+	postgresFlexibleAPIVersion      = "2022-12-01"
+	mysqlProvider                   = "Microsoft.DBforMySQL/servers"
+	mysqlFlexibleProvider           = "Microsoft.DBforMySQL/flexibleServers"
+	postgresFlexibleProvider        = "Microsoft.DBforPostgreSQL/flexibleServers"
+	dbRequireSecureTransportSetting = "require_secure_transport"
+	mysqlTLSVersionSetting          = "tls_version"
+	postgresMinTLSVersionSetting    = "ssl_min_protocol_version"
+)
+
+type azDBServer struct {
+	ID         *string            `json:"id"`
+	Identity   *azManagedIdentity `json:"identity"`
+	Properties *struct {
+		Version                  *string `json:"version"`
+		FullyQualifiedDomainName *string `json:"fullyQualifiedDomainName"`
+		AdministratorLogin       *string `json:"administratorLogin"`
+		// Single server only
+		SSLEnforcement      *string `json:"sslEnforcement"`
+		MinimalTLSVersion   *string `json:"minimalTlsVersion"`
+		PublicNetworkAccess *string `json:"publicNetworkAccess"`
+		// Flexible server only
+		Network *struct {
+			PublicNetworkAccess       *string `json:"publicNetworkAccess"`
+			DelegatedSubnetResourceID *string `json:"delegatedSubnetResourceId"`
+		} `json:"network"`
+		AuthConfig *struct {
+			ActiveDirectoryAuth *string `json:"activeDirectoryAuth"`
+			PasswordAuth        *string `json:"passwordAuth"`
+		} `json:"authConfig"`
+	} `json:"properties"`
+}
+
+type azDBFirewallRule struct {
+	Name       *string `json:"name"`
+	Properties *struct {
+		StartIPAddress *string `json:"startIpAddress"`
+		EndIPAddress   *string `json:"endIpAddress"`
+	} `json:"properties"`
+}
+
+type azDBVirtualNetworkRule struct {
+	Properties *struct {
+		VirtualNetworkSubnetID *string `json:"virtualNetworkSubnetId"`
+	} `json:"properties"`
+}
+
+// azDBAdministrator covers both the MySQL (login/sid) and PostgreSQL
+// (principalName/objectId) administrator shapes.
+type azDBAdministrator struct {
+	Properties *struct {
+		Login             *string `json:"login"`
+		SID               *string `json:"sid"`
+		AdministratorType *string `json:"administratorType"`
+		PrincipalName     *string `json:"principalName"`
+		ObjectID          *string `json:"objectId"`
+		PrincipalType     *string `json:"principalType"`
+	} `json:"properties"`
+}
+
+type azDBConfiguration struct {
+	Properties *struct {
+		Value *string `json:"value"`
+	} `json:"properties"`
+}
+
+func (c *azDBConfiguration) value() *string {
+	if c.Properties == nil {
+		return nil
+	}
+	return c.Properties.Value
+}
+
+// DatabaseAADAdmin is an Azure AD (Entra ID) administrator of a database
+// server.
+type DatabaseAADAdmin struct {
+	Login    string
+	ObjectID string
+	// PrincipalType is User, Group, or ServicePrincipal when Azure gives it
+	PrincipalType string
+}
+
+func (a *DatabaseAADAdmin) fromAzure(az *azDBAdministrator) {
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&a.Login, props.Login)
+	if a.Login == "" {
+		gValFromPtr(&a.Login, props.PrincipalName)
+	}
+	gValFromPtr(&a.ObjectID, props.SID)
+	if a.ObjectID == "" {
+		gValFromPtr(&a.ObjectID, props.ObjectID)
+	}
+	gValFromPtr(&a.PrincipalType, props.PrincipalType)
+}
+
+// dbEnabledString handles the "Enabled"/"Disabled" strings used all over
+// the database APIs.
+func dbEnabledString(s *string) UnknownBool {
+	if s == nil {
+		return BoolUnknown
+	}
+	switch strings.ToLower(*s) {
+	case "enabled":
+		return BoolTrue
+	case "disabled":
+		return BoolFalse
+	default:
+		return BoolUnknown
+	}
+}
+
+// dbOnOffString handles server parameters which are either ON or OFF
+func dbOnOffString(s *string) UnknownBool {
+	if s == nil {
+		return BoolUnknown
+	}
+	switch strings.ToLower(*s) {
+	case "on":
+		return BoolTrue
+	case "off":
+		return BoolFalse
+	default:
+		return BoolUnknown
+	}
+}
+
+// flexibleServerNetwork loads the network settings that are common to all
+// flexible servers. Servers with a delegated subnet are only reachable from
+// inside the VNet.
+func flexibleServerNetwork(az *azDBServer, publicNetworkAccess *UnknownBool, subnet *ResourceID) {
+	if az.Properties == nil || az.Properties.Network == nil {
+		return
+	}
+	net := az.Properties.Network
+	*publicNetworkAccess = dbEnabledString(net.PublicNetworkAccess)
+	if net.DelegatedSubnetResourceID != nil && *net.DelegatedSubnetResourceID != "" {
+		subnet.FromID(*net.DelegatedSubnetResourceID)
+	}
+}
@@ -11,6 +11,7 @@ const (
     TLSVersionOneZero TLSVersion = 1
     TLSVersionOneOne TLSVersion = 2
     TLSVersionOneTwo TLSVersion = 3
+    TLSVersionOneThree TLSVersion = 4
 )
 
 func (it TLSVersion) IsUnknown() bool {
@@ -42,4 +43,11 @@ func (it TLSVersion) IsOneTwo() UnknownBool {
 	return UnknownFromBool(it == TLSVersionOneTwo)
 }
 
+func (it TLSVersion) IsOneThree() UnknownBool {
+	if it == TLSVersionUnknown {
+		return BoolUnknown
+	}
+	return UnknownFromBool(it == TLSVersionOneThree)
+}
+
 
@@ -191,3 +191,21 @@ func (fw *FirewallRule) FromAzurePostgres(az *armpostgresql.FirewallRule) {
 	}
 
 }
+
+// fromAzureDB handles firewall rules on MySQL and PostgreSQL Flexible Servers
+func (fw *FirewallRule) fromAzureDB(az *azDBFirewallRule) {
+	gValFromPtr(&fw.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.StartIPAddress != nil && props.EndIPAddress != nil {
+		fw.IPRange = NewAzureIPv4FromRange(*props.StartIPAddress, *props.EndIPAddress)
+		is, start, end := fw.IPRange.ContinuousRangeUint32()
+		if is.True() && start == 0 && end == 0 {
+			fw.AllowsAllAzure = BoolTrue
+		} else {
+			fw.AllowsAllAzure = BoolFalse
+		}
+	}
+}
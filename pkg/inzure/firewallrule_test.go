@@ -203,3 +203,50 @@ func TestFirewallRuleRespectsAllowlistDoesntRespect(t *testing.T) {
 	}
 	doFirewallRuleAllowlistTest(t, wl, rules, BoolFalse, allowed)
 }
+
+func TestFirewallRuleIPv6(t *testing.T) {
+	rules := []FirewallRule{
+		{
+			Name:           "Test",
+			IPRange:        NewAzureIPv4FromRange("2001:db8::", "2001:db8::ffff"),
+			AllowsAllAzure: BoolFalse,
+		},
+	}
+	doFirewallRuleTest(t, "2001:db8::12", rules, BoolTrue, []PacketRoute{
+		{
+			IPs:      createIPs("*"),
+			Ports:    createPorts("*"),
+			Protocol: ProtocolAll,
+		},
+	})
+	doFirewallRuleTest(t, "2001:db8::1:0", rules, BoolFalse, nil)
+	doFirewallRuleTest(t, "10.0.0.1", rules, BoolFalse, nil)
+}
+
+func TestFirewallRuleRespectsAllowlistIPv6(t *testing.T) {
+	wl := FirewallAllowlist{
+		AllPorts: []AzureIPv4{
+			NewAzureIPv4FromAzure("10.0.0.0/8"),
+			NewAzureIPv4FromAzure("2001:db8::/32"),
+		},
+	}
+	rules := []FirewallRule{
+		{
+			Name:           "Test1",
+			IPRange:        NewAzureIPv4FromAzure("2001:db8:1::/48"),
+			AllowsAllAzure: BoolFalse,
+		},
+		{
+			Name:           "Test2",
+			IPRange:        NewAzureIPv4FromAzure("2001:db9::1"),
+			AllowsAllAzure: BoolFalse,
+		},
+	}
+	allowed := []IPPort{
+		{
+			IP:   NewAzureIPv4FromAzure("2001:db9::1"),
+			Port: NewPortFromAzure("*"),
+		},
+	}
+	doFirewallRuleAllowlistTest(t, wl, rules, BoolFalse, allowed)
+}
@@ -0,0 +1,655 @@
+package inzure
+
+import (
+	"strings"
+)
+
+// Front Door Standard/Premium and classic CDN both live under
+// Microsoft.Cdn/profiles while classic Front Door is
+// Microsoft.Network/frontDoors. There isn't an SDK module for any of them in
+// use here so these are the pieces of the REST API that we care about.
+//
+// TODO: Switch to armcdn and armfrontdoor once they're dependencies.
+
+const (
+	cdnAPIVersion       = "2024-02-01"
+	frontDoorAPIVersion = "2021-06-01"
+)
+
+type azResourceRef struct {
+	ID *string `json:"id"`
+}
+
+type azCDNProfile struct {
+	ID  *string `json:"id"`
+	SKU *struct {
+		Name *string `json:"name"`
+	} `json:"sku"`
+	Properties *struct {
+		FrontDoorID *string `json:"frontDoorId"`
+	} `json:"properties"`
+}
+
+type azAFDEndpoint struct {
+	ID         *string `json:"id"`
+	Name       *string `json:"name"`
+	Properties *struct {
+		HostName     *string `json:"hostName"`
+		EnabledState *string `json:"enabledState"`
+	} `json:"properties"`
+}
+
+type azAFDRoute struct {
+	Properties *struct {
+		CustomDomains      []*azResourceRef `json:"customDomains"`
+		OriginGroup        *azResourceRef   `json:"originGroup"`
+		SupportedProtocols []*string        `json:"supportedProtocols"`
+		HTTPSRedirect      *string          `json:"httpsRedirect"`
+		EnabledState       *string          `json:"enabledState"`
+	} `json:"properties"`
+}
+
+type azAFDOriginGroup struct {
+	ID *string `json:"id"`
+}
+
+type azAFDOrigin struct {
+	Name       *string `json:"name"`
+	Properties *struct {
+		HostName         *string        `json:"hostName"`
+		OriginHostHeader *string        `json:"originHostHeader"`
+		EnabledState     *string        `json:"enabledState"`
+		AzureOrigin      *azResourceRef `json:"azureOrigin"`
+	} `json:"properties"`
+}
+
+type azAFDCustomDomain struct {
+	ID         *string `json:"id"`
+	Properties *struct {
+		HostName *string `json:"hostName"`
+	} `json:"properties"`
+}
+
+type azAFDSecurityPolicy struct {
+	Properties *struct {
+		Parameters *struct {
+			Type         *string        `json:"type"`
+			WAFPolicy    *azResourceRef `json:"wafPolicy"`
+			Associations []*struct {
+				Domains []*azResourceRef `json:"domains"`
+			} `json:"associations"`
+		} `json:"parameters"`
+	} `json:"properties"`
+}
+
+type azCDNEndpoint struct {
+	ID         *string `json:"id"`
+	Name       *string `json:"name"`
+	Properties *struct {
+		HostName                         *string        `json:"hostName"`
+		OriginHostHeader                 *string        `json:"originHostHeader"`
+		IsHTTPAllowed                    *bool          `json:"isHttpAllowed"`
+		ResourceState                    *string        `json:"resourceState"`
+		WebApplicationFirewallPolicyLink *azResourceRef `json:"webApplicationFirewallPolicyLink"`
+		Origins                          []*struct {
+			Name       *string `json:"name"`
+			Properties *struct {
+				HostName *string `json:"hostName"`
+				Enabled  *bool   `json:"enabled"`
+			} `json:"properties"`
+		} `json:"origins"`
+	} `json:"properties"`
+}
+
+type azCDNCustomDomain struct {
+	Properties *struct {
+		HostName *string `json:"hostName"`
+	} `json:"properties"`
+}
+
+type azFrontDoor struct {
+	ID         *string `json:"id"`
+	Properties *struct {
+		FrontDoorID       *string `json:"frontdoorId"`
+		EnabledState      *string `json:"enabledState"`
+		FrontendEndpoints []*struct {
+			ID         *string `json:"id"`
+			Name       *string `json:"name"`
+			Properties *struct {
+				HostName                         *string        `json:"hostName"`
+				WebApplicationFirewallPolicyLink *azResourceRef `json:"webApplicationFirewallPolicyLink"`
+			} `json:"properties"`
+		} `json:"frontendEndpoints"`
+		BackendPools []*struct {
+			ID         *string `json:"id"`
+			Properties *struct {
+				Backends []*struct {
+					Address           *string `json:"address"`
+					BackendHostHeader *string `json:"backendHostHeader"`
+					EnabledState      *string `json:"enabledState"`
+				} `json:"backends"`
+			} `json:"properties"`
+		} `json:"backendPools"`
+		RoutingRules []*struct {
+			Properties *struct {
+				FrontendEndpoints  []*azResourceRef `json:"frontendEndpoints"`
+				AcceptedProtocols  []*string        `json:"acceptedProtocols"`
+				EnabledState       *string          `json:"enabledState"`
+				RouteConfiguration *struct {
+					BackendPool *azResourceRef `json:"backendPool"`
+				} `json:"routeConfiguration"`
+			} `json:"properties"`
+		} `json:"routingRules"`
+	} `json:"properties"`
+}
+
+// EdgeOrigin is where an edge endpoint sends traffic
+type EdgeOrigin struct {
+	Name     string
+	Hostname string
+	// HostHeader is the Host header sent to the origin. If it is empty the
+	// origin's Hostname is used.
+	HostHeader string
+	Enabled    UnknownBool
+	// Resource is the Azure resource of the origin when Azure knows it
+	Resource ResourceID
+}
+
+// EdgeEndpoint is a public endpoint of a Front Door or CDN profile and the
+// origins it forwards to.
+type EdgeEndpoint struct {
+	Name string
+	// Hostname is the Azure provided host name, such as
+	// example.azurefd.net or example.azureedge.net
+	Hostname      string
+	CustomDomains []string
+	Enabled       UnknownBool
+	// HTTPAllowed is whether plain HTTP is served instead of redirected
+	HTTPAllowed UnknownBool
+	// WAFPolicy is empty if no WAF policy is associated with the endpoint
+	WAFPolicy ResourceID
+	Origins   []EdgeOrigin
+}
+
+func (e *EdgeEndpoint) setupEmpty() {
+	e.CustomDomains = make([]string, 0)
+	e.Origins = make([]EdgeOrigin, 0)
+	e.WAFPolicy.setupEmpty()
+}
+
+// Hostnames are all of the names the endpoint is reachable at
+func (e *EdgeEndpoint) Hostnames() []string {
+	names := make([]string, 0, len(e.CustomDomains)+1)
+	if e.Hostname != "" {
+		names = append(names, e.Hostname)
+	}
+	return append(names, e.CustomDomains...)
+}
+
+func (e *EdgeEndpoint) addOrigin(o EdgeOrigin) {
+	for _, have := range e.Origins {
+		if have.Name == o.Name && strings.EqualFold(have.Hostname, o.Hostname) {
+			return
+		}
+	}
+	e.Origins = append(e.Origins, o)
+}
+
+// CDNProfile is a Microsoft.Cdn profile which is either an Azure Front Door
+// Standard/Premium profile or a classic CDN profile.
+type CDNProfile struct {
+	Meta ResourceID
+	SKU  string
+	// FrontDoorID is sent to origins in the X-Azure-FDID header. Origins
+	// should check it to only accept traffic from this profile.
+	FrontDoorID string
+	Endpoints   []EdgeEndpoint
+}
+
+func NewEmptyCDNProfile() *CDNProfile {
+	p := &CDNProfile{
+		Endpoints: make([]EdgeEndpoint, 0),
+	}
+	p.Meta.setupEmpty()
+	return p
+}
+
+func (p *CDNProfile) fromAzure(az *azCDNProfile) {
+	if az.ID == nil {
+		return
+	}
+	p.Meta.fromID(*az.ID)
+	if az.SKU != nil {
+		gValFromPtr(&p.SKU, az.SKU.Name)
+	}
+	if az.Properties != nil {
+		gValFromPtr(&p.FrontDoorID, az.Properties.FrontDoorID)
+	}
+}
+
+// IsFrontDoor is whether this is a Front Door Standard/Premium profile
+// rather than a classic CDN profile.
+func (p *CDNProfile) IsFrontDoor() bool {
+	return strings.HasSuffix(strings.ToLower(p.SKU), "_azurefrontdoor")
+}
+
+// afdProfileParts holds the pieces of a Front Door Standard/Premium profile
+// that are needed to build its EdgeEndpoints.
+type afdProfileParts struct {
+	// customDomains maps lower cased custom domain IDs to host names
+	customDomains map[string]string
+	// origins maps lower cased origin group IDs to their origins
+	origins map[string][]EdgeOrigin
+	// wafPolicies maps lower cased endpoint or custom domain IDs to the WAF
+	// policy protecting them
+	wafPolicies map[string]string
+}
+
+func newAFDProfileParts() *afdProfileParts {
+	return &afdProfileParts{
+		customDomains: make(map[string]string),
+		origins:       make(map[string][]EdgeOrigin),
+		wafPolicies:   make(map[string]string),
+	}
+}
+
+func (parts *afdProfileParts) addCustomDomain(az *azAFDCustomDomain) {
+	if az.ID == nil || az.Properties == nil || az.Properties.HostName == nil {
+		return
+	}
+	parts.customDomains[strings.ToLower(*az.ID)] = *az.Properties.HostName
+}
+
+func (parts *afdProfileParts) addOrigin(group string, az *azAFDOrigin) {
+	var o EdgeOrigin
+	o.Resource.setupEmpty()
+	gValFromPtr(&o.Name, az.Name)
+	if props := az.Properties; props != nil {
+		gValFromPtr(&o.Hostname, props.HostName)
+		gValFromPtr(&o.HostHeader, props.OriginHostHeader)
+		o.Enabled = edgeEnabledState(props.EnabledState)
+		if props.AzureOrigin != nil && props.AzureOrigin.ID != nil {
+			o.Resource.fromID(*props.AzureOrigin.ID)
+		}
+	}
+	group = strings.ToLower(group)
+	parts.origins[group] = append(parts.origins[group], o)
+}
+
+func (parts *afdProfileParts) addSecurityPolicy(az *azAFDSecurityPolicy) {
+	if az.Properties == nil || az.Properties.Parameters == nil {
+		return
+	}
+	params := az.Properties.Parameters
+	if params.WAFPolicy == nil || params.WAFPolicy.ID == nil {
+		return
+	}
+	for _, assoc := range params.Associations {
+		if assoc == nil {
+			continue
+		}
+		for _, d := range assoc.Domains {
+			if d != nil && d.ID != nil {
+				parts.wafPolicies[strings.ToLower(*d.ID)] = *params.WAFPolicy.ID
+			}
+		}
+	}
+}
+
+// endpoint builds the EdgeEndpoint for a Front Door Standard/Premium
+// endpoint from its routes.
+func (parts *afdProfileParts) endpoint(az *azAFDEndpoint, routes []*azAFDRoute) EdgeEndpoint {
+	var e EdgeEndpoint
+	e.setupEmpty()
+	gValFromPtr(&e.Name, az.Name)
+	if az.Properties != nil {
+		gValFromPtr(&e.Hostname, az.Properties.HostName)
+		e.Enabled = edgeEnabledState(az.Properties.EnabledState)
+	}
+	wafIDs := make([]string, 0)
+	if az.ID != nil {
+		wafIDs = append(wafIDs, strings.ToLower(*az.ID))
+	}
+	e.HTTPAllowed = BoolFalse
+	for _, r := range routes {
+		if r == nil || r.Properties == nil {
+			continue
+		}
+		props := r.Properties
+		if edgeEnabledState(props.EnabledState).False() {
+			continue
+		}
+		for _, cd := range props.CustomDomains {
+			if cd == nil || cd.ID == nil {
+				continue
+			}
+			id := strings.ToLower(*cd.ID)
+			wafIDs = append(wafIDs, id)
+			if host, ok := parts.customDomains[id]; ok {
+				e.CustomDomains = append(e.CustomDomains, host)
+			}
+		}
+		if props.OriginGroup != nil && props.OriginGroup.ID != nil {
+			for _, o := range parts.origins[strings.ToLower(*props.OriginGroup.ID)] {
+				e.addOrigin(o)
+			}
+		}
+		redirects := props.HTTPSRedirect != nil && strings.EqualFold(*props.HTTPSRedirect, "Enabled")
+		if !redirects && edgeProtocolsContain(props.SupportedProtocols, "http") {
+			e.HTTPAllowed = BoolTrue
+		}
+	}
+	for _, id := range wafIDs {
+		if waf, ok := parts.wafPolicies[id]; ok {
+			e.WAFPolicy.fromID(waf)
+			break
+		}
+	}
+	return e
+}
+
+// cdnEndpoint builds the EdgeEndpoint for a classic CDN endpoint
+func cdnEndpoint(az *azCDNEndpoint, customDomains []*azCDNCustomDomain) EdgeEndpoint {
+	var e EdgeEndpoint
+	e.setupEmpty()
+	gValFromPtr(&e.Name, az.Name)
+	for _, cd := range customDomains {
+		if cd != nil && cd.Properties != nil && cd.Properties.HostName != nil {
+			e.CustomDomains = append(e.CustomDomains, *cd.Properties.HostName)
+		}
+	}
+	props := az.Properties
+	if props == nil {
+		return e
+	}
+	gValFromPtr(&e.Hostname, props.HostName)
+	if props.ResourceState != nil {
+		e.Enabled.FromBool(!strings.EqualFold(*props.ResourceState, "Stopped"))
+	}
+	e.HTTPAllowed.FromBoolPtr(props.IsHTTPAllowed)
+	if props.WebApplicationFirewallPolicyLink != nil && props.WebApplicationFirewallPolicyLink.ID != nil {
+		e.WAFPolicy.fromID(*props.WebApplicationFirewallPolicyLink.ID)
+	}
+	for _, azo := range props.Origins {
+		if azo == nil {
+			continue
+		}
+		var o EdgeOrigin
+		o.Resource.setupEmpty()
+		gValFromPtr(&o.Name, azo.Name)
+		gValFromPtr(&o.HostHeader, props.OriginHostHeader)
+		if azo.Properties != nil {
+			gValFromPtr(&o.Hostname, azo.Properties.HostName)
+			o.Enabled.FromBoolPtr(azo.Properties.Enabled)
+		}
+		e.addOrigin(o)
+	}
+	return e
+}
+
+// FrontDoor is a classic Azure Front Door. Each frontend endpoint is its own
+// EdgeEndpoint.
+type FrontDoor struct {
+	Meta ResourceID
+	// FrontDoorID is sent to backends in the X-Azure-FDID header
+	FrontDoorID string
+	Enabled     UnknownBool
+	Endpoints   []EdgeEndpoint
+}
+
+func NewEmptyFrontDoor() *FrontDoor {
+	fd := &FrontDoor{
+		Endpoints: make([]EdgeEndpoint, 0),
+	}
+	fd.Meta.setupEmpty()
+	return fd
+}
+
+func (fd *FrontDoor) fromAzure(az *azFrontDoor) {
+	if az.ID == nil {
+		return
+	}
+	fd.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&fd.FrontDoorID, props.FrontDoorID)
+	fd.Enabled = edgeEnabledState(props.EnabledState)
+
+	pools := make(map[string][]EdgeOrigin)
+	for _, pool := range props.BackendPools {
+		if pool == nil || pool.ID == nil || pool.Properties == nil {
+			continue
+		}
+		origins := make([]EdgeOrigin, 0, len(pool.Properties.Backends))
+		for _, b := range pool.Properties.Backends {
+			if b == nil {
+				continue
+			}
+			var o EdgeOrigin
+			o.Resource.setupEmpty()
+			gValFromPtr(&o.Hostname, b.Address)
+			o.Name = o.Hostname
+			gValFromPtr(&o.HostHeader, b.BackendHostHeader)
+			o.Enabled = edgeEnabledState(b.EnabledState)
+			origins = append(origins, o)
+		}
+		pools[strings.ToLower(*pool.ID)] = origins
+	}
+
+	for _, fe := range props.FrontendEndpoints {
+		if fe == nil {
+			continue
+		}
+		var e EdgeEndpoint
+		e.setupEmpty()
+		e.Enabled = fd.Enabled
+		e.HTTPAllowed = BoolFalse
+		gValFromPtr(&e.Name, fe.Name)
+		if fe.Properties != nil {
+			gValFromPtr(&e.Hostname, fe.Properties.HostName)
+			if waf := fe.Properties.WebApplicationFirewallPolicyLink; waf != nil && waf.ID != nil {
+				e.WAFPolicy.fromID(*waf.ID)
+			}
+		}
+		for _, rule := range props.RoutingRules {
+			if rule == nil || rule.Properties == nil || fe.ID == nil {
+				continue
+			}
+			rp := rule.Properties
+			if edgeEnabledState(rp.EnabledState).False() || !edgeRefsContain(rp.FrontendEndpoints, *fe.ID) {
+				continue
+			}
+			if edgeProtocolsContain(rp.AcceptedProtocols, "http") {
+				e.HTTPAllowed = BoolTrue
+			}
+			// Redirect rules don't have a backend pool
+			if rc := rp.RouteConfiguration; rc != nil && rc.BackendPool != nil && rc.BackendPool.ID != nil {
+				for _, o := range pools[strings.ToLower(*rc.BackendPool.ID)] {
+					e.addOrigin(o)
+				}
+			}
+		}
+		fd.Endpoints = append(fd.Endpoints, e)
+	}
+}
+
+func edgeEnabledState(state *string) UnknownBool {
+	if state == nil {
+		return BoolUnknown
+	}
+	return UnknownFromBool(strings.EqualFold(*state, "Enabled"))
+}
+
+func edgeProtocolsContain(protocols []*string, want string) bool {
+	for _, p := range protocols {
+		if p != nil && strings.EqualFold(*p, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func edgeRefsContain(refs []*azResourceRef, id string) bool {
+	for _, r := range refs {
+		if r != nil && r.ID != nil && strings.EqualFold(*r.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// frontDoorServiceTag is the service tag for traffic from Front Door to
+// origins.
+const frontDoorServiceTag = "AzureFrontDoor.Backend"
+
+// RestrictsToFrontDoor checks whether the firewall only allows public
+// traffic from the AzureFrontDoor.Backend service tag. If any Front Door IDs
+// are given, the Front Door rules also need to check the X-Azure-FDID header
+// for one of them; otherwise anyone's Front Door can reach the app.
+//
+// defaultAllow is the site's ipSecurityRestrictionsDefaultAction. Traffic
+// that doesn't match a rule is allowed when it is Allow, so the firewall is
+// only restricted if it is Deny or there is an explicit deny all rule.
+//
+// Allow rules for VNet subnets and private IPs don't count against this.
+func (waf WebAppIPFirewall) RestrictsToFrontDoor(defaultAllow UnknownBool, frontDoorIDs ...string) UnknownBool {
+	sawFrontDoor := false
+	for _, rule := range waf {
+		if rule.Allow.False() {
+			// Nothing after a deny all rule matters
+			if rule.IPRange != nil && ipIsAny(rule.IPRange) {
+				return UnknownFromBool(sawFrontDoor)
+			}
+			continue
+		}
+		if strings.EqualFold(rule.ServiceTag, frontDoorServiceTag) {
+			if len(frontDoorIDs) > 0 && !rule.allowsFrontDoorID(frontDoorIDs) {
+				return BoolFalse
+			}
+			sawFrontDoor = true
+			continue
+		}
+		if rule.Subnet.RawID != "" {
+			continue
+		}
+		if rule.IPRange != nil && IPIsRFC1918Private(rule.IPRange) {
+			continue
+		}
+		return BoolFalse
+	}
+	if !sawFrontDoor || defaultAllow.True() {
+		return BoolFalse
+	}
+	if defaultAllow.False() {
+		return BoolTrue
+	}
+	return BoolUnknown
+}
+
+func (ipr *WebAppIPRestriction) allowsFrontDoorID(ids []string) bool {
+	for _, have := range ipr.FrontDoorIDs {
+		for _, want := range ids {
+			if strings.EqualFold(have, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// edgeOriginWebApp finds the WebApp that an origin points to either by its
+// resource ID or its default host name.
+func (s *Subscription) edgeOriginWebApp(o *EdgeOrigin) *WebApp {
+	for _, rg := range s.ResourceGroups {
+		for _, wa := range rg.WebApps {
+			if resourceIDsMatch(o.Resource, wa.Meta) ||
+				(o.Hostname != "" && strings.EqualFold(o.Hostname, wa.DefaultHostname)) {
+				return wa
+			}
+		}
+	}
+	return nil
+}
+
+// bypassableOrigin is the WebApp an origin points to if that WebApp is
+// publicly reachable and its firewall doesn't restrict traffic to the Front
+// Door with the given ID.
+func (s *Subscription) bypassableOrigin(o *EdgeOrigin, targets privateEndpointTargets, frontDoorID string) *WebApp {
+	wa := s.edgeOriginWebApp(o)
+	if wa == nil || !targets.publiclyReachable(targets.webAppPublicAccess(wa), &wa.Meta) {
+		return nil
+	}
+	ids := make([]string, 0, 1)
+	if frontDoorID != "" {
+		ids = append(ids, frontDoorID)
+	}
+	if wa.Firewall.RestrictsToFrontDoor(wa.FirewallDefaultAllow, ids...).True() {
+		return nil
+	}
+	return wa
+}
+
+// FrontDoorOriginBypass is a WebApp behind a Front Door that can be reached
+// without going through the Front Door.
+type FrontDoorOriginBypass struct {
+	// FrontDoor is the ID of the CDNProfile or FrontDoor
+	FrontDoor ResourceID
+	Endpoint  string
+	WebApp    *WebApp
+}
+
+// FrontDoorOriginBypasses finds publicly reachable WebApps used as Front
+// Door origins whose firewall doesn't restrict traffic to that Front Door.
+func (s *Subscription) FrontDoorOriginBypasses() []FrontDoorOriginBypass {
+	bypasses := make([]FrontDoorOriginBypass, 0)
+	targets := s.privateEndpointTargets()
+	check := func(fd ResourceID, fdID string, endpoints []EdgeEndpoint) {
+		for _, e := range endpoints {
+			for i := range e.Origins {
+				if wa := s.bypassableOrigin(&e.Origins[i], targets, fdID); wa != nil {
+					bypasses = append(bypasses, FrontDoorOriginBypass{
+						FrontDoor: fd,
+						Endpoint:  e.Hostname,
+						WebApp:    wa,
+					})
+				}
+			}
+		}
+	}
+	for _, rg := range s.ResourceGroups {
+		for _, p := range rg.CDNProfiles {
+			if p.IsFrontDoor() {
+				check(p.Meta, p.FrontDoorID, p.Endpoints)
+			}
+		}
+		for _, fd := range rg.FrontDoors {
+			check(fd.Meta, fd.FrontDoorID, fd.Endpoints)
+		}
+	}
+	return bypasses
+}
+
+// edgeAttackSurface maps an edge endpoint's host names to its origins. Only
+// Front Door origins are checked for bypasses since CDN endpoints don't send
+// an identifying header.
+func (s *Subscription) edgeAttackSurface(e *EdgeEndpoint, targets privateEndpointTargets, frontDoor bool, frontDoorID string) EdgeAttackSurface {
+	eas := EdgeAttackSurface{
+		Hostnames:         e.Hostnames(),
+		Origins:           make([]string, 0, len(e.Origins)),
+		WAFPolicy:         e.WAFPolicy.RawID,
+		BypassableOrigins: make([]string, 0),
+	}
+	for i := range e.Origins {
+		o := &e.Origins[i]
+		if o.Hostname == "" || o.Enabled.False() {
+			continue
+		}
+		eas.Origins = append(eas.Origins, o.Hostname)
+		if frontDoor && s.bypassableOrigin(o, targets, frontDoorID) != nil {
+			eas.BypassableOrigins = append(eas.BypassableOrigins, o.Hostname)
+		}
+	}
+	return eas
+}
@@ -0,0 +1,337 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+)
+
+const (
+	afdProfileID  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Cdn/profiles/edge"
+	afdFrontDoor  = "7b5d3b2c-0f5a-4b8e-9c1d-2e3f4a5b6c7d"
+	afdWAFPolicy  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/frontdoorWebApplicationFirewallPolicies/waf"
+	afdOriginApp  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/origin"
+	afdOriginHost = "origin.azurewebsites.net"
+)
+
+func testUnmarshal[T any](t *testing.T, s string) *T {
+	t.Helper()
+	v := new(T)
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func testAFDProfile(t *testing.T) *CDNProfile {
+	p := NewEmptyCDNProfile()
+	p.fromAzure(testUnmarshal[azCDNProfile](t, `{
+		"id": "`+afdProfileID+`",
+		"sku": {"name": "Premium_AzureFrontDoor"},
+		"properties": {"frontDoorId": "`+afdFrontDoor+`"}
+	}`))
+
+	parts := newAFDProfileParts()
+	parts.addCustomDomain(testUnmarshal[azAFDCustomDomain](t, `{
+		"id": "`+afdProfileID+`/customDomains/www",
+		"properties": {"hostName": "www.example.com"}
+	}`))
+	parts.addSecurityPolicy(testUnmarshal[azAFDSecurityPolicy](t, `{
+		"properties": {"parameters": {
+			"type": "WebApplicationFirewall",
+			"wafPolicy": {"id": "`+afdWAFPolicy+`"},
+			"associations": [{"domains": [{"id": "`+afdProfileID+`/customDomains/www"}]}]
+		}}
+	}`))
+	parts.addOrigin(afdProfileID+"/originGroups/apps", testUnmarshal[azAFDOrigin](t, `{
+		"name": "origin",
+		"properties": {
+			"hostName": "`+afdOriginHost+`",
+			"originHostHeader": "`+afdOriginHost+`",
+			"enabledState": "Enabled",
+			"azureOrigin": {"id": "`+afdOriginApp+`"}
+		}
+	}`))
+	endpoint := testUnmarshal[azAFDEndpoint](t, `{
+		"id": "`+afdProfileID+`/afdEndpoints/app",
+		"name": "app",
+		"properties": {"hostName": "app-abc123.z01.azurefd.net", "enabledState": "Enabled"}
+	}`)
+	route := testUnmarshal[azAFDRoute](t, `{
+		"properties": {
+			"customDomains": [{"id": "`+afdProfileID+`/customdomains/WWW"}],
+			"originGroup": {"id": "`+afdProfileID+`/originGroups/apps"},
+			"supportedProtocols": ["Http", "Https"],
+			"httpsRedirect": "Enabled",
+			"enabledState": "Enabled"
+		}
+	}`)
+	p.Endpoints = append(p.Endpoints, parts.endpoint(endpoint, []*azAFDRoute{route}))
+	return p
+}
+
+func TestCDNProfileFrontDoor(t *testing.T) {
+	p := testAFDProfile(t)
+	if p.Meta.Tag != CDNProfileT || !p.IsFrontDoor() || p.FrontDoorID != afdFrontDoor {
+		t.Fatalf("bad profile: %+v", p)
+	}
+	if len(p.Endpoints) != 1 {
+		t.Fatalf("expected an endpoint: %+v", p.Endpoints)
+	}
+	e := p.Endpoints[0]
+	if e.Hostname != "app-abc123.z01.azurefd.net" || !e.Enabled.True() || !e.HTTPAllowed.False() {
+		t.Fatalf("bad endpoint: %+v", e)
+	}
+	if len(e.CustomDomains) != 1 || e.CustomDomains[0] != "www.example.com" || len(e.Hostnames()) != 2 {
+		t.Fatalf("bad custom domains: %v", e.CustomDomains)
+	}
+	if e.WAFPolicy.RawID != afdWAFPolicy {
+		t.Fatalf("WAF policy not associated through custom domain: %+v", e.WAFPolicy)
+	}
+	if len(e.Origins) != 1 || e.Origins[0].HostHeader != afdOriginHost || e.Origins[0].Resource.Tag != WebAppT {
+		t.Fatalf("bad origins: %+v", e.Origins)
+	}
+}
+
+func TestCDNProfileClassic(t *testing.T) {
+	p := NewEmptyCDNProfile()
+	p.fromAzure(testUnmarshal[azCDNProfile](t, `{
+		"id": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.Cdn/profiles/cdn",
+		"sku": {"name": "Standard_Microsoft"}
+	}`))
+	if p.IsFrontDoor() {
+		t.Fatal("classic CDN profile treated as Front Door")
+	}
+	e := cdnEndpoint(testUnmarshal[azCDNEndpoint](t, `{
+		"name": "static",
+		"properties": {
+			"hostName": "static.azureedge.net",
+			"isHttpAllowed": true,
+			"resourceState": "Running",
+			"originHostHeader": "files.blob.core.windows.net",
+			"origins": [{"name": "blob", "properties": {"hostName": "files.blob.core.windows.net", "enabled": true}}]
+		}
+	}`), []*azCDNCustomDomain{
+		testUnmarshal[azCDNCustomDomain](t, `{"properties": {"hostName": "static.example.com"}}`),
+	})
+	if e.Hostname != "static.azureedge.net" || !e.HTTPAllowed.True() || !e.Enabled.True() || e.WAFPolicy.RawID != "" {
+		t.Fatalf("bad endpoint: %+v", e)
+	}
+	if len(e.CustomDomains) != 1 || len(e.Origins) != 1 || e.Origins[0].HostHeader != "files.blob.core.windows.net" {
+		t.Fatalf("bad endpoint domains or origins: %+v", e)
+	}
+}
+
+func TestFrontDoorClassicFromAzure(t *testing.T) {
+	id := "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/frontDoors/classic"
+	fd := NewEmptyFrontDoor()
+	fd.fromAzure(testUnmarshal[azFrontDoor](t, `{
+		"id": "`+id+`",
+		"properties": {
+			"frontdoorId": "`+afdFrontDoor+`",
+			"enabledState": "Enabled",
+			"frontendEndpoints": [{
+				"id": "`+id+`/frontendEndpoints/default",
+				"name": "default",
+				"properties": {
+					"hostName": "classic.azurefd.net",
+					"webApplicationFirewallPolicyLink": {"id": "`+afdWAFPolicy+`"}
+				}
+			}],
+			"backendPools": [{
+				"id": "`+id+`/backendPools/apps",
+				"properties": {"backends": [
+					{"address": "`+afdOriginHost+`", "backendHostHeader": "`+afdOriginHost+`", "enabledState": "Enabled"},
+					{"address": "old.azurewebsites.net", "enabledState": "Disabled"}
+				]}
+			}],
+			"routingRules": [
+				{"properties": {
+					"frontendEndpoints": [{"id": "`+id+`/frontendEndpoints/default"}],
+					"acceptedProtocols": ["Https"],
+					"enabledState": "Enabled",
+					"routeConfiguration": {"backendPool": {"id": "`+id+`/backendPools/apps"}}
+				}},
+				{"properties": {
+					"frontendEndpoints": [{"id": "`+id+`/frontendEndpoints/default"}],
+					"acceptedProtocols": ["Http"],
+					"enabledState": "Enabled",
+					"routeConfiguration": {}
+				}}
+			]
+		}
+	}`))
+	if fd.Meta.Tag != FrontDoorT || fd.FrontDoorID != afdFrontDoor || !fd.Enabled.True() || len(fd.Endpoints) != 1 {
+		t.Fatalf("bad Front Door: %+v", fd)
+	}
+	e := fd.Endpoints[0]
+	if e.Hostname != "classic.azurefd.net" || e.WAFPolicy.RawID != afdWAFPolicy || !e.HTTPAllowed.True() {
+		t.Fatalf("bad frontend endpoint: %+v", e)
+	}
+	if len(e.Origins) != 2 || !e.Origins[1].Enabled.False() {
+		t.Fatalf("bad backends: %+v", e.Origins)
+	}
+}
+
+func testIPRestriction(action string, ip string, tag armappservice.IPFilterTag, fdids ...string) WebAppIPRestriction {
+	az := &armappservice.IPSecurityRestriction{
+		Action:    ptr(action),
+		IPAddress: ptr(ip),
+		Tag:       &tag,
+	}
+	if len(fdids) > 0 {
+		vals := make([]*string, 0, len(fdids))
+		for _, id := range fdids {
+			vals = append(vals, ptr(id))
+		}
+		az.Headers = map[string][]*string{"X-Azure-FDID": vals}
+	}
+	var ipr WebAppIPRestriction
+	ipr.FromAzure(az)
+	return ipr
+}
+
+func TestWebAppFirewallRestrictsToFrontDoor(t *testing.T) {
+	fdRule := testIPRestriction("Allow", frontDoorServiceTag, armappservice.IPFilterTagServiceTag, afdFrontDoor)
+	if fdRule.ServiceTag != frontDoorServiceTag || len(fdRule.FrontDoorIDs) != 1 {
+		t.Fatalf("bad service tag rule: %+v", fdRule)
+	}
+	denyAll := testIPRestriction("Deny", "Any", armappservice.IPFilterTagDefault)
+	private := testIPRestriction("Allow", "10.0.0.0/8", armappservice.IPFilterTagDefault)
+	public := testIPRestriction("Allow", "20.1.2.3/32", armappservice.IPFilterTagDefault)
+	anyFD := testIPRestriction("Allow", frontDoorServiceTag, armappservice.IPFilterTagServiceTag)
+
+	var vnet WebAppIPRestriction
+	vnet.FromAzure(&armappservice.IPSecurityRestriction{
+		Action:               ptr("Allow"),
+		VnetSubnetResourceID: ptr(routingSubnetID),
+	})
+	if vnet.Subnet.Tag != SubnetT {
+		t.Fatalf("bad VNet rule: %+v", vnet)
+	}
+
+	tests := []struct {
+		name         string
+		fw           WebAppIPFirewall
+		defaultAllow UnknownBool
+		ids          []string
+		expected     UnknownBool
+	}{
+		{"no rules", WebAppIPFirewall{}, BoolFalse, nil, BoolFalse},
+		{"front door only", WebAppIPFirewall{fdRule, denyAll}, BoolUnknown, []string{afdFrontDoor}, BoolTrue},
+		{"front door and private", WebAppIPFirewall{fdRule, private, vnet, denyAll}, BoolUnknown, nil, BoolTrue},
+		{"public rule", WebAppIPFirewall{fdRule, public, denyAll}, BoolUnknown, nil, BoolFalse},
+		{"any front door", WebAppIPFirewall{anyFD, denyAll}, BoolUnknown, nil, BoolTrue},
+		{"any front door with ID", WebAppIPFirewall{anyFD, denyAll}, BoolUnknown, []string{afdFrontDoor}, BoolFalse},
+		{"wrong front door", WebAppIPFirewall{fdRule, denyAll}, BoolUnknown, []string{"other"}, BoolFalse},
+		{"default deny", WebAppIPFirewall{fdRule}, BoolFalse, []string{afdFrontDoor}, BoolTrue},
+		{"default allow", WebAppIPFirewall{fdRule}, BoolTrue, []string{afdFrontDoor}, BoolFalse},
+		{"default allow with deny all", WebAppIPFirewall{fdRule, denyAll}, BoolTrue, []string{afdFrontDoor}, BoolTrue},
+		{"default unknown", WebAppIPFirewall{fdRule}, BoolUnknown, []string{afdFrontDoor}, BoolUnknown},
+	}
+	for _, tc := range tests {
+		if got := tc.fw.RestrictsToFrontDoor(tc.defaultAllow, tc.ids...); got != tc.expected {
+			t.Errorf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+
+	b, err := json.Marshal(fdRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded WebAppIPRestriction
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ServiceTag != frontDoorServiceTag || len(decoded.FrontDoorIDs) != 1 || !decoded.Allow.True() {
+		t.Fatalf("bad JSON round trip: %+v", decoded)
+	}
+}
+
+func TestAttackSurfaceEdge(t *testing.T) {
+	p := testAFDProfile(t)
+	wa := NewEmptyWebApp()
+	wa.Meta.fromID(afdOriginApp)
+	wa.DefaultHostname = afdOriginHost
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.CDNProfiles = append(rg.CDNProfiles, p)
+	rg.WebApps = append(rg.WebApps, wa)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.FrontDoors) != 1 || len(as.CDNEndpoints) != 0 {
+		t.Fatalf("bad edge attack surface: %+v %+v", as.FrontDoors, as.CDNEndpoints)
+	}
+	edge := as.FrontDoors[0]
+	if len(edge.Hostnames) != 2 || len(edge.Origins) != 1 || edge.Origins[0] != afdOriginHost || edge.WAFPolicy != afdWAFPolicy {
+		t.Fatalf("bad edge: %+v", edge)
+	}
+	if len(edge.BypassableOrigins) != 1 {
+		t.Fatalf("unrestricted origin not flagged: %+v", edge)
+	}
+	if bp := sub.FrontDoorOriginBypasses(); len(bp) != 1 || bp[0].WebApp != wa || bp[0].FrontDoor.Tag != CDNProfileT {
+		t.Fatalf("bad bypasses: %+v", bp)
+	}
+
+	if err := wa.fromAzureConfig(&azWebAppConfig{Properties: []byte(`{
+		"ipSecurityRestrictions": [
+			{
+				"action": "Allow",
+				"ipAddress": "` + frontDoorServiceTag + `",
+				"tag": "ServiceTag",
+				"priority": 100,
+				"headers": {"x-azure-fdid": ["` + afdFrontDoor + `"]}
+			}
+		],
+		"ipSecurityRestrictionsDefaultAction": "Allow"
+	}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(wa.Firewall) != 1 || !wa.FirewallDefaultAllow.True() || !wa.SCMFirewallDefaultAllow.Unknown() {
+		t.Fatalf("bad web app config: %+v", wa)
+	}
+	as = sub.GetAttackSurface()
+	if len(as.FrontDoors[0].BypassableOrigins) != 1 || len(sub.FrontDoorOriginBypasses()) != 1 {
+		t.Fatalf("origin with default allow not flagged: %+v", as.FrontDoors[0])
+	}
+
+	wa.FirewallDefaultAllow = BoolFalse
+	as = sub.GetAttackSurface()
+	if len(as.FrontDoors[0].BypassableOrigins) != 0 || len(sub.FrontDoorOriginBypasses()) != 0 {
+		t.Fatalf("restricted origin flagged: %+v", as.FrontDoors[0])
+	}
+
+	// Origins that can't be reached publicly can't be bypassed
+	wa.FirewallDefaultAllow = BoolTrue
+	wa.PublicNetworkAccess = BoolFalse
+	as = sub.GetAttackSurface()
+	if len(as.FrontDoors[0].BypassableOrigins) != 0 || len(sub.FrontDoorOriginBypasses()) != 0 {
+		t.Fatalf("origin without public access flagged: %+v", as.FrontDoors[0])
+	}
+	wa.PublicNetworkAccess = BoolUnknown
+	pe := NewEmptyPrivateEndpoint()
+	pe.Connections = append(pe.Connections, PrivateEndpointConnection{
+		Target: wa.Meta,
+		Status: "Approved",
+	})
+	rg.PrivateEndpoints = append(rg.PrivateEndpoints, pe)
+	as = sub.GetAttackSurface()
+	if len(as.FrontDoors[0].BypassableOrigins) != 0 || len(sub.FrontDoorOriginBypasses()) != 0 {
+		t.Fatalf("origin behind a private endpoint flagged: %+v", as.FrontDoors[0])
+	}
+	wa.PublicNetworkAccess = BoolTrue
+	if len(sub.FrontDoorOriginBypasses()) != 1 {
+		t.Fatal("origin with public access and a private endpoint not flagged")
+	}
+
+	qs, err := p.Meta.QueryString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qs != "/CDNProfiles/rga/edge" {
+		t.Fatalf("bad query string: %s", qs)
+	}
+}
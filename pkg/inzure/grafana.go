@@ -75,17 +75,20 @@ type Grafana struct {
 	PrivateEndpointConnections []GrafanaPrivateEndpointConnection
 	Version                    string
 	Plugins                    map[string]string
+	Identity                   ManagedIdentity
 }
 
 func NewEmptyGrafana() *Grafana {
 	var rid ResourceID
 	rid.setupEmpty()
-	return &Grafana{
+	it := &Grafana{
 		Meta:         rid,
 		SMTP:         GrafanaSMTP{},
 		Integrations: make([]string, 0),
 		Plugins:      make(map[string]string),
 	}
+	it.Identity.setupEmpty()
+	return it
 }
 
 func (it *Grafana) FromAzure(az *armdashboard.ManagedGrafana) {
@@ -93,6 +96,7 @@ func (it *Grafana) FromAzure(az *armdashboard.ManagedGrafana) {
 		return
 	}
 	it.Meta.FromID(*az.ID)
+	it.Identity.FromAzureGrafana(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
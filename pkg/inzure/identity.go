@@ -0,0 +1,389 @@
+package inzure
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dashboard/armdashboard"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datalake-store/armdatalakestore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/redis/armredis"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	azsqlvm "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sqlvirtualmachine/armsqlvirtualmachine"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+// ManagedIdentity is the identity block on a resource. A resource can have a
+// system assigned identity, any number of user assigned identities, or both.
+//
+// Every SDK has its own identity type so there is a FromAzure method for each
+// of them.
+type ManagedIdentity struct {
+	// Type is the raw identity type, such as "SystemAssigned, UserAssigned"
+	Type           string
+	SystemAssigned UnknownBool
+	// PrincipalID and TenantID are for the system assigned identity
+	PrincipalID  string
+	TenantID     string
+	UserAssigned []UserAssignedIdentityRef
+}
+
+// UserAssignedIdentityRef is a user assigned identity attached to a resource.
+// The principal and client IDs are only set if the resource's API returns
+// them.
+type UserAssignedIdentityRef struct {
+	ID          ResourceID
+	PrincipalID string
+	ClientID    string
+}
+
+func (mi *ManagedIdentity) setupEmpty() {
+	mi.SystemAssigned = BoolUnknown
+	mi.UserAssigned = make([]UserAssignedIdentityRef, 0)
+}
+
+// HasIdentity is whether there is any identity attached
+func (mi *ManagedIdentity) HasIdentity() bool {
+	return mi.SystemAssigned.True() || len(mi.UserAssigned) > 0
+}
+
+// PrincipalIDs returns all of the known principal IDs for the identities.
+func (mi *ManagedIdentity) PrincipalIDs() []string {
+	ids := make([]string, 0, len(mi.UserAssigned)+1)
+	if mi.PrincipalID != "" {
+		ids = append(ids, mi.PrincipalID)
+	}
+	for _, uai := range mi.UserAssigned {
+		if uai.PrincipalID != "" {
+			ids = append(ids, uai.PrincipalID)
+		}
+	}
+	return ids
+}
+
+func (mi *ManagedIdentity) setNone() {
+	mi.Type = "None"
+	mi.SystemAssigned = BoolFalse
+}
+
+func (mi *ManagedIdentity) setBase(ty string, principalID *string, tenantID *string) {
+	mi.Type = ty
+	mi.SystemAssigned.FromBool(strings.Contains(strings.ToLower(ty), "systemassigned"))
+	gValFromPtr(&mi.PrincipalID, principalID)
+	gValFromPtr(&mi.TenantID, tenantID)
+}
+
+// setUserAssigned sets the user assigned identities from the map of resource
+// ID to identity that every API uses. ids gets the principal and client IDs
+// from the SDK's value type.
+func setUserAssigned[T any](mi *ManagedIdentity, uais map[string]*T, ids func(*T) (*string, *string)) {
+	keys := make([]string, 0, len(uais))
+	for k := range uais {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	mi.UserAssigned = make([]UserAssignedIdentityRef, 0, len(keys))
+	for _, k := range keys {
+		var ref UserAssignedIdentityRef
+		ref.ID.fromID(k)
+		if v := uais[k]; v != nil {
+			principalID, clientID := ids(v)
+			gValFromPtr(&ref.PrincipalID, principalID)
+			gValFromPtr(&ref.ClientID, clientID)
+		}
+		mi.UserAssigned = append(mi.UserAssigned, ref)
+	}
+}
+
+func (mi *ManagedIdentity) FromAzureWeb(az *armappservice.ManagedServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armappservice.UserAssignedIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureVM(az *armcompute.VirtualMachineIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armcompute.UserAssignedIdentitiesValue) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureVMSS(az *armcompute.VirtualMachineScaleSetIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armcompute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureStorage(az *armstorage.Identity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armstorage.UserAssignedIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureSQL(az *armsql.ResourceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armsql.UserIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureSQLVM(az *azsqlvm.ResourceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+}
+
+func (mi *ManagedIdentity) FromAzureCosmos(az *armcosmos.ManagedServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armcosmos.Components1Jq1T4ISchemasManagedserviceidentityPropertiesUserassignedidentitiesAdditionalproperties) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureAPIManagement(az *armapimanagement.ServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armapimanagement.UserIdentityProperties) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureKubernetes(az *armcontainerservice.ManagedClusterIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armcontainerservice.ManagedServiceIdentityUserAssignedIdentitiesValue) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureContainerRegistry(az *armcontainerregistry.IdentityProperties) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armcontainerregistry.UserIdentityProperties) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureNetwork(az *armnetwork.ManagedServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armnetwork.Components1Jq1T4ISchemasManagedserviceidentityPropertiesUserassignedidentitiesAdditionalproperties) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureRedis(az *armredis.ManagedServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armredis.UserAssignedIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureGrafana(az *armdashboard.ManagedServiceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *armdashboard.UserAssignedIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+func (mi *ManagedIdentity) FromAzureDataLakeStore(az *armdatalakestore.EncryptionIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(*az.Type, az.PrincipalID, az.TenantID)
+}
+
+func (mi *ManagedIdentity) FromAzurePostgres(az *armpostgresql.ResourceIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(string(*az.Type), az.PrincipalID, az.TenantID)
+}
+
+// azManagedIdentity is the identity block for resources we get through the
+// REST API.
+type azManagedIdentity struct {
+	Type                   *string                            `json:"type"`
+	PrincipalID            *string                            `json:"principalId"`
+	TenantID               *string                            `json:"tenantId"`
+	UserAssignedIdentities map[string]*azUserAssignedIdentity `json:"userAssignedIdentities"`
+}
+
+type azUserAssignedIdentity struct {
+	PrincipalID *string `json:"principalId"`
+	ClientID    *string `json:"clientId"`
+}
+
+func (mi *ManagedIdentity) fromAzureData(az *azManagedIdentity) {
+	if az == nil || az.Type == nil {
+		mi.setNone()
+		return
+	}
+	mi.setBase(*az.Type, az.PrincipalID, az.TenantID)
+	setUserAssigned(mi, az.UserAssignedIdentities, func(v *azUserAssignedIdentity) (*string, *string) {
+		return v.PrincipalID, v.ClientID
+	})
+}
+
+// UserAssignedIdentity is a standalone managed identity that can be attached
+// to any number of resources.
+type UserAssignedIdentity struct {
+	Meta        ResourceID
+	PrincipalID string
+	ClientID    string
+	TenantID    string
+	// FederatedCredentials allow external identity providers, such as GitHub
+	// Actions or a Kubernetes cluster, to get tokens for this identity.
+	FederatedCredentials []FederatedIdentityCredential
+}
+
+func NewEmptyUserAssignedIdentity() *UserAssignedIdentity {
+	uai := &UserAssignedIdentity{
+		FederatedCredentials: make([]FederatedIdentityCredential, 0),
+	}
+	uai.Meta.setupEmpty()
+	return uai
+}
+
+func (uai *UserAssignedIdentity) FromAzure(az *armmsi.Identity) {
+	if az.ID == nil {
+		return
+	}
+	uai.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&uai.PrincipalID, props.PrincipalID)
+	gValFromPtr(&uai.ClientID, props.ClientID)
+	gValFromPtr(&uai.TenantID, props.TenantID)
+}
+
+// FederatedIdentityCredential trusts tokens from an external issuer with the
+// given subject.
+type FederatedIdentityCredential struct {
+	Name     string
+	Identity ResourceID
+	Issuer   string
+	Subject  string
+	// Audiences is usually api://AzureADTokenExchange
+	Audiences []string
+}
+
+func (fic *FederatedIdentityCredential) QueryString() string {
+	uai, err := fic.Identity.QueryString()
+	if err != nil {
+		return ""
+	}
+	return uai + "/FederatedCredentials/" + fic.Name
+}
+
+func (fic *FederatedIdentityCredential) FromAzure(az *armmsi.FederatedIdentityCredential) {
+	gValFromPtr(&fic.Name, az.Name)
+	fic.Audiences = make([]string, 0)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&fic.Issuer, props.Issuer)
+	gValFromPtr(&fic.Subject, props.Subject)
+	appendStrings(&fic.Audiences, props.Audiences)
+}
+
+// UserAssignedIdentityByID finds the collected UserAssignedIdentity with the
+// given resource ID.
+func (s *Subscription) UserAssignedIdentityByID(id *ResourceID) *UserAssignedIdentity {
+	for _, rg := range s.ResourceGroups {
+		for _, uai := range rg.UserAssignedIdentities {
+			if strings.EqualFold(uai.Meta.RawID, id.RawID) {
+				return uai
+			}
+		}
+	}
+	return nil
+}
+
+// ManagedIdentityRoles resolves the roles that are directly assigned to any
+// of the identities in the given ManagedIdentity. This is what a resource
+// with that identity can reach if it is compromised.
+//
+// If a resource's API doesn't include the principal ID of a user assigned
+// identity it is looked up from the collected UserAssignedIdentities.
+func (s *Subscription) ManagedIdentityRoles(mi *ManagedIdentity) []EffectiveRole {
+	roles := make([]EffectiveRole, 0)
+	if mi.PrincipalID != "" {
+		roles = append(roles, s.EffectiveRoles(mi.PrincipalID)...)
+	}
+	for i := range mi.UserAssigned {
+		ref := &mi.UserAssigned[i]
+		principalID := ref.PrincipalID
+		if principalID == "" {
+			if uai := s.UserAssignedIdentityByID(&ref.ID); uai != nil {
+				principalID = uai.PrincipalID
+			}
+		}
+		if principalID != "" {
+			roles = append(roles, s.EffectiveRoles(principalID)...)
+		}
+	}
+	return roles
+}
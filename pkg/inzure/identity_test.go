@@ -0,0 +1,150 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+)
+
+const (
+	uaiID          = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.ManagedIdentity/userAssignedIdentities/deployer"
+	uaiPrincipalID = "11111111-0000-0000-0000-000000000000"
+	sysPrincipalID = "22222222-0000-0000-0000-000000000000"
+)
+
+func TestManagedIdentityFromAzure(t *testing.T) {
+	ty := armappservice.ManagedServiceIdentityTypeSystemAssignedUserAssigned
+	wa := NewEmptyWebApp()
+	wa.FromAzure(&armappservice.Site{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/app"),
+		Identity: &armappservice.ManagedServiceIdentity{
+			Type:        &ty,
+			PrincipalID: ptr(sysPrincipalID),
+			TenantID:    ptr("tenant"),
+			UserAssignedIdentities: map[string]*armappservice.UserAssignedIdentity{
+				// The API doesn't always give us the principal ID
+				uaiID: {},
+			},
+		},
+	})
+	mi := wa.Identity
+	if !mi.SystemAssigned.True() || mi.PrincipalID != sysPrincipalID || len(mi.UserAssigned) != 1 {
+		t.Fatalf("bad identity: %+v", mi)
+	}
+	if mi.UserAssigned[0].ID.Tag != UserAssignedIdentityT || mi.UserAssigned[0].ID.Name != "deployer" {
+		t.Fatalf("bad user assigned identity: %+v", mi.UserAssigned[0])
+	}
+
+	vm := NewEmptyVirtualMachine()
+	vm.FromAzure(&armcompute.VirtualMachine{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Compute/virtualMachines/vm"),
+	})
+	if vm.Identity.HasIdentity() || vm.Identity.Type != "None" || !vm.Identity.SystemAssigned.False() {
+		t.Fatalf("expected no identity: %+v", vm.Identity)
+	}
+
+	var ns azMessagingNamespace
+	err := json.Unmarshal([]byte(`{
+		"id": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.ServiceBus/namespaces/bus",
+		"identity": {
+			"type": "UserAssigned",
+			"userAssignedIdentities": {
+				"`+uaiID+`": {"principalId": "`+uaiPrincipalID+`", "clientId": "client"}
+			}
+		}
+	}`), &ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb := NewEmptyServiceBusNamespace()
+	sb.fromAzure(&ns)
+	if !sb.Identity.SystemAssigned.False() || len(sb.Identity.UserAssigned) != 1 ||
+		sb.Identity.UserAssigned[0].ClientID != "client" {
+		t.Fatalf("bad REST identity: %+v", sb.Identity)
+	}
+	if ids := sb.Identity.PrincipalIDs(); len(ids) != 1 || ids[0] != uaiPrincipalID {
+		t.Fatalf("bad principal IDs: %v", ids)
+	}
+}
+
+func TestManagedIdentityRoles(t *testing.T) {
+	uai := NewEmptyUserAssignedIdentity()
+	uai.FromAzure(&armmsi.Identity{
+		ID: ptr(uaiID),
+		Properties: &armmsi.UserAssignedIdentityProperties{
+			PrincipalID: ptr(uaiPrincipalID),
+			ClientID:    ptr("client"),
+		},
+	})
+	var fic FederatedIdentityCredential
+	fic.Identity = uai.Meta
+	fic.FromAzure(&armmsi.FederatedIdentityCredential{
+		Name: ptr("github"),
+		Properties: &armmsi.FederatedIdentityCredentialProperties{
+			Issuer:    ptr("https://token.actions.githubusercontent.com"),
+			Subject:   ptr("repo:example/app:ref:refs/heads/main"),
+			Audiences: []*string{ptr("api://AzureADTokenExchange")},
+		},
+	})
+	uai.FederatedCredentials = append(uai.FederatedCredentials, fic)
+	if fic.QueryString() != "/UserAssignedIdentities/rga/deployer/FederatedCredentials/github" {
+		t.Fatalf("bad query string: %s", fic.QueryString())
+	}
+
+	ty := armappservice.ManagedServiceIdentityTypeSystemAssignedUserAssigned
+	wa := NewEmptyWebApp()
+	wa.FromAzure(&armappservice.Site{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/app"),
+		Identity: &armappservice.ManagedServiceIdentity{
+			Type:                   &ty,
+			PrincipalID:            ptr(sysPrincipalID),
+			UserAssignedIdentities: map[string]*armappservice.UserAssignedIdentity{uaiID: {}},
+		},
+	})
+
+	sub := NewSubscription(subId)
+	sub.RoleDefinitions = testRoleDefinitions()
+	for i, principalID := range []string{sysPrincipalID, uaiPrincipalID} {
+		ra := NewEmptyRoleAssignment()
+		ra.FromAzure(&armauthorization.RoleAssignment{
+			ID: ptr(rbacSubAssignment + string(rune('a'+i))),
+			Properties: &armauthorization.RoleAssignmentProperties{
+				Scope:            ptr("/subscriptions/" + subId),
+				RoleDefinitionID: ptr([]string{readerRoleID, keyReaderRoleID}[i]),
+				PrincipalID:      ptr(principalID),
+			},
+		})
+		sub.RoleAssignments = append(sub.RoleAssignments, ra)
+	}
+	rg := NewEmptyResourceGroup()
+	rg.UserAssignedIdentities = append(rg.UserAssignedIdentities, uai)
+	rg.WebApps = append(rg.WebApps, wa)
+	sub.ResourceGroups[rgAName] = rg
+
+	roles := sub.ManagedIdentityRoles(&wa.Identity)
+	if len(roles) != 2 {
+		t.Fatalf("expected 2 roles: %v", roles)
+	}
+	if roles[1].Role == nil || !roles[1].Role.AllowsAction("Microsoft.Storage/storageAccounts/listKeys/action") {
+		t.Fatalf("user assigned identity role not resolved: %+v", roles[1])
+	}
+
+	var apps []*WebApp
+	if err := sub.FromQueryString("/WebApps[.Identity.SystemAssigned == BoolTrue]", &apps); err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 1 {
+		t.Fatalf("bad query string result: %v", apps)
+	}
+	var creds []FederatedIdentityCredential
+	if err := sub.FromQueryString("/UserAssignedIdentities/*/*/FederatedCredentials", &creds); err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 1 || creds[0].Subject != "repo:example/app:ref:refs/heads/main" {
+		t.Fatalf("bad query string result: %v", creds)
+	}
+}
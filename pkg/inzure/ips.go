@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/netip"
 	"reflect"
 	"sort"
 	"strconv"
@@ -57,6 +58,36 @@ const (
 	AzureAbstractIPEmpty
 )
 
+// AzureIPFamily describes which address families an AzureIPv4 covers.
+type AzureIPFamily uint8
+
+const (
+	// AzureIPFamilyUnknown is used for special and empty IPs where we don't
+	// have concrete addresses to work with.
+	AzureIPFamilyUnknown AzureIPFamily = iota
+	AzureIPFamilyIPv4
+	AzureIPFamilyIPv6
+	// AzureIPFamilyDual is returned when an AzureIPv4 holds both IPv4 and IPv6
+	// addresses. Note that "*" is considered dual since it matches every
+	// address regardless of family.
+	AzureIPFamilyDual
+)
+
+// AddrRange is an inclusive range of addresses. Begin and End are always the
+// same address family.
+type AddrRange struct {
+	Begin netip.Addr
+	End   netip.Addr
+}
+
+func (r AddrRange) contains(a netip.Addr) bool {
+	return r.Begin.BitLen() == a.BitLen() && r.Begin.Compare(a) <= 0 && r.End.Compare(a) >= 0
+}
+
+func (r AddrRange) containsRange(o AddrRange) bool {
+	return r.contains(o.Begin) && r.contains(o.End)
+}
+
 const (
 	ipMin              = uint32(0)
 	ipMax              = ^uint32(0)
@@ -68,12 +99,20 @@ const (
 // encapsulate all of those in one type to accurately work with them. This
 // interface ensures that these types are not misused.
 //
+// Despite the name, IPv6 addresses are supported as well. The name is kept
+// for compatibility. All of the *Uint32 methods only ever consider the IPv4
+// portion of the value; use the *Addr methods to work with both families.
+//
 // Allowed formats:
 //	 - 10.0.0.0/8 - CIDR
 //	 - 10.0.0.1 - Single IP
 //	 - 10.0.0.3,10.0.1.2 - Comma separated single
 //	 - 10.0.0.0/24,10.0.1.0/24 - Comma separated CIDR
 //	 - 10.0.0.0/24,10.0.1.24 - Comma separated mixed
+//	 - 2001:db8::/32 - IPv6 CIDR
+//	 - 2001:db8::1 - Single IPv6
+//	 - 2001:db8::1-2001:db8::ff - IPv6 range
+//	 - 10.0.0.0/8,2001:db8::/32 - Comma separated mixed families
 //	 - * - Any
 // 	 - https://docs.microsoft.com/en-us/azure/virtual-network/security-overview#service-tags
 type AzureIPv4 interface {
@@ -92,10 +131,13 @@ type AzureIPv4 interface {
 	// call. If you call this you should view the given underlying value to
 	// be completely unrelated to its previous value.
 	FromAzure(string)
-	// Contains tells us if this rule contains the given IPv4 given as a string.
-	// Contains has undefined behavior if the given string is not a dot notation
-	// IPv4 address.
+	// Contains tells us if this rule contains the given IPv4 or IPv6 address
+	// given as a string. If the string is not a single IP address BoolUnknown
+	// is returned.
 	Contains(string) UnknownBool
+	// ContainsAddr is the same as Contains except for a netip.Addr. This works
+	// for both address families.
+	ContainsAddr(netip.Addr) UnknownBool
 	// ContainsUint32 is the same as Contains except for a uint32 representation
 	// of the IPv4 address
 	ContainsUint32(uint32) UnknownBool
@@ -117,16 +159,24 @@ type AzureIPv4 interface {
 	Size() uint64
 	// AllIPsGen is a generator function that returns all of the ips on the
 	// return channel. If the passed buffer parameter is <=0 then there is
-	// no buffering on the returned channel.
+	// no buffering on the returned channel. IPv6 ranges too large to
+	// enumerate are sent as a single CIDR or range string.
 	AllIPsGen(ctx context.Context, buffer int) <-chan string
 	// AllIPsUint32Gen is the uint32 equivalent of AllIPsGen
 	AllIPsUint32Gen(ctx context.Context, buffer int) <-chan uint32
 	// AllIPs returns string reprsentations of every IP contained in this
-	// AzureIPv4. Note that this could be a lot of IPs.
+	// AzureIPv4. Note that this could be a lot of IPs. Like AllIPsGen,
+	// large IPv6 ranges are returned as a single CIDR or range string.
 	AllIPs() []string
 	// AllIPsUint32 is the same as AllIPs except it returns uint32
 	// representations
 	AllIPsUint32() []uint32
+	// Family returns which address families this AzureIPv4 covers.
+	Family() AzureIPFamily
+	// AddrRanges returns every concrete range of addresses in this AzureIPv4
+	// across both families. Specials return nil since we don't know what
+	// they contain.
+	AddrRanges() []AddrRange
 	String() string
 	json.Marshaler
 	json.Unmarshaler
@@ -165,6 +215,9 @@ type ipv4Impl struct {
 	raw       string
 	abstract  AzureAbstractIPType
 	isSpecial bool
+	// wildcard is set when Azure gave us "*" or "Any". These match every
+	// address in both families, unlike 0.0.0.0/0 which is only IPv4.
+	wildcard bool
 
 	// 3 actual specifications
 
@@ -174,6 +227,10 @@ type ipv4Impl struct {
 	// Azure can sometimes represent an IP address as a comma separated list
 	// if IP addresses.
 	multiple []rangeOrSingle
+
+	// All IPv6 portions are kept separate so the IPv4 handling above can
+	// continue to work in uint32s.
+	v6 []ipv6Range
 }
 
 func (c *rangeOrSingle) size() uint64 {
@@ -190,14 +247,18 @@ func (s *ipv4Impl) Size() uint64 {
 	if s.isSpecial {
 		return 0
 	}
+	size := uint64(0)
 	if s.multiple != nil {
-		size := uint64(0)
 		for _, mip := range s.multiple {
 			size += mip.size()
 		}
-		return size
+	} else if s.hasIPv4() {
+		size = s.size()
 	}
-	return s.size()
+	for _, r := range s.v6 {
+		size = addSaturating(size, r.size())
+	}
+	return size
 }
 
 func (s *ipv4Impl) AsUint32() uint32 {
@@ -250,10 +311,103 @@ func (s *ipv4Impl) ContainsRange(begin string, end string) UnknownBool {
 	if s.isSpecial {
 		return BoolUnknown
 	}
-	if !isSingleIPv4(begin) || !isSingleIPv4(end) {
+	if isSingleIPv4(begin) && isSingleIPv4(end) {
+		return s.ContainsRangeUint32(ipv4FromString(begin), ipv4FromString(end))
+	}
+	if !isSingleIPv6(begin) || !isSingleIPv6(end) {
 		return BoolFalse
 	}
-	return s.ContainsRangeUint32(ipv4FromString(begin), ipv4FromString(end))
+	if s.isAny() {
+		return BoolTrue
+	}
+	chk := AddrRange{
+		Begin: netip.MustParseAddr(begin),
+		End:   netip.MustParseAddr(end),
+	}
+	for _, r := range s.v6 {
+		if r.asAddrRange().containsRange(chk) {
+			return BoolTrue
+		}
+	}
+	return BoolFalse
+}
+
+func (s *ipv4Impl) ContainsAddr(a netip.Addr) UnknownBool {
+	if s.abstract == AzureAbstractIPEmpty || !a.IsValid() {
+		return BoolFalse
+	}
+	if s.isSpecial {
+		return BoolUnknown
+	}
+	a = a.Unmap()
+	if a.Is4() {
+		return s.ContainsUint32(addrToUint32(a))
+	}
+	if s.isAny() {
+		return BoolTrue
+	}
+	for _, r := range s.v6 {
+		if r.asAddrRange().contains(a) {
+			return BoolTrue
+		}
+	}
+	return BoolFalse
+}
+
+func (s *ipv4Impl) Family() AzureIPFamily {
+	if s.isSpecial || s.abstract == AzureAbstractIPEmpty {
+		return AzureIPFamilyUnknown
+	}
+	if s.isAny() {
+		return AzureIPFamilyDual
+	}
+	has4 := s.hasIPv4()
+	has6 := len(s.v6) > 0
+	if has4 && has6 {
+		return AzureIPFamilyDual
+	} else if has6 {
+		return AzureIPFamilyIPv6
+	} else if has4 {
+		return AzureIPFamilyIPv4
+	}
+	return AzureIPFamilyUnknown
+}
+
+func (s *ipv4Impl) AddrRanges() []AddrRange {
+	if s.isSpecial {
+		return nil
+	}
+	if s.abstract == AzureAbstractIPEmpty {
+		return []AddrRange{}
+	}
+	if s.isAny() {
+		return []AddrRange{
+			{Begin: uint32ToAddr(ipMin), End: uint32ToAddr(ipMax)},
+			{Begin: ipv6Min, End: ipv6Max},
+		}
+	}
+	ranges := make([]AddrRange, 0, len(s.multiple)+len(s.v6)+1)
+	if s.multiple != nil {
+		for _, mip := range s.multiple {
+			ranges = append(ranges, mip.asAddrRange())
+		}
+	} else if s.hasIPv4() {
+		ranges = append(ranges, s.rangeOrSingle.asAddrRange())
+	}
+	for _, r := range s.v6 {
+		ranges = append(ranges, r.asAddrRange())
+	}
+	return ranges
+}
+
+// hasIPv4 returns whether or not any IPv4 portion is set
+func (s *ipv4Impl) hasIPv4() bool {
+	return s.multiple != nil || s.single.set || (s.begin.set && s.end.set)
+}
+
+// isAny returns whether or not this is "*"
+func (s *ipv4Impl) isAny() bool {
+	return s.wildcard
 }
 
 func (s *ipv4Impl) ContinuousRange() (UnknownBool, string, string) {
@@ -263,6 +417,12 @@ func (s *ipv4Impl) ContinuousRange() (UnknownBool, string, string) {
 	if s.abstract == AzureAbstractIPEmpty {
 		return BoolFalse, "", ""
 	}
+	if len(s.v6) > 0 {
+		if len(s.v6) == 1 && !s.hasIPv4() {
+			return BoolTrue, s.v6[0].begin.String(), s.v6[0].end.String()
+		}
+		return BoolFalse, "", ""
+	}
 	is, start, end := s.ContinuousRangeUint32()
 	if is == BoolTrue {
 		return BoolTrue, ipv4ToString(start), ipv4ToString(end)
@@ -274,7 +434,7 @@ func (s *ipv4Impl) ContinuousRangeUint32() (UnknownBool, uint32, uint32) {
 	if s.isSpecial {
 		return BoolUnknown, 0, 0
 	}
-	if s.abstract == AzureAbstractIPEmpty {
+	if s.abstract == AzureAbstractIPEmpty || len(s.v6) > 0 {
 		return BoolFalse, 0, 0
 	}
 	if s.single.set {
@@ -344,7 +504,7 @@ func (ipc *IPCollection) UnmarshalJSON(b []byte) error {
 	}
 	*ipc = make([]AzureIPv4, len(s))
 	for i, ip := range s {
-		v := new(ipv4Impl)
+		v := NewEmptyAzureIPv4()
 		err = v.UnmarshalJSON(ip)
 		if err != nil {
 			return err
@@ -456,9 +616,7 @@ func (s *ipv4Impl) MarshalJSON() ([]byte, error) {
 		return []byte("\"\""), nil
 	}
 	var mString string
-	if s.single.set || s.multiple != nil {
-		mString = s.String()
-	} else if s.begin.set && s.end.set {
+	if s.hasIPv4() || len(s.v6) > 0 {
 		mString = s.String()
 	}
 	return []byte(fmt.Sprintf("\"%s\"", mString)), nil
@@ -502,7 +660,11 @@ func (s *ipv4Impl) AllIPs() []string {
 	if s.abstract == AzureAbstractIPEmpty {
 		return []string{}
 	}
-	return mapToStrings(ipv4ToString, s.AllIPsUint32())
+	all := mapToStrings(ipv4ToString, s.AllIPsUint32())
+	for _, r := range s.v6 {
+		all = append(all, r.allIPs()...)
+	}
+	return all
 }
 
 func (s *ipv4Impl) AllIPsGen(ctx context.Context, buf int) <-chan string {
@@ -540,9 +702,7 @@ func (s *ipv4Impl) AllIPsGen(ctx context.Context, buf int) <-chan string {
 					}
 				}
 			}
-			return
-		}
-		if s.single.set {
+		} else if s.single.set {
 			select {
 			case <-ctx.Done():
 				return
@@ -557,6 +717,23 @@ func (s *ipv4Impl) AllIPsGen(ctx context.Context, buf int) <-chan string {
 				}
 			}
 		}
+		for _, r := range s.v6 {
+			if !r.enumerable() {
+				select {
+				case <-ctx.Done():
+					return
+				case c <- r.String():
+				}
+				continue
+			}
+			for v := r.begin; v.IsValid() && v.Compare(r.end) <= 0; v = v.Next() {
+				select {
+				case <-ctx.Done():
+					return
+				case c <- v.String():
+				}
+			}
+		}
 	}()
 	return c
 }
@@ -629,12 +806,23 @@ func (s *ipv4Impl) AllIPsUint32() []uint32 {
 		}
 		return u
 	}
+	if !s.hasIPv4() {
+		return []uint32{}
+	}
 	return s.allIPsUint32()
 }
 
-// NewAzureIPv4FromRange creates a new AzureIPv4 from a range of IPs
+// NewAzureIPv4FromRange creates a new AzureIPv4 from a range of IPs. Both
+// ends of the range need to be the same address family.
 func NewAzureIPv4FromRange(begin string, end string) AzureIPv4 {
 	r := new(ipv4Impl)
+	if isSingleIPv6(begin) && isSingleIPv6(end) {
+		r.abstract = AzureAbstractIPNormal
+		r.v6 = []ipv6Range{newIPv6Range(
+			netip.MustParseAddr(begin), netip.MustParseAddr(end), -1,
+		)}
+		return r
+	}
 	if !(isSingleIPv4(begin) && isSingleIPv4(end)) {
 		r.unset()
 		return r
@@ -690,6 +878,9 @@ func (c *rangeOrSingle) String() string {
 	if c.single.set {
 		return ipv4ToString(c.single.val)
 	} else if c.begin.set && c.end.set {
+		// The whole IPv4 space has always been written as "*". Keep doing that
+		// so existing output doesn't change, even though "*" is loaded back
+		// as the wildcard which also covers IPv6.
 		if c.begin.val == ipMin && c.end.val == ipMax {
 			return "*"
 		}
@@ -705,14 +896,24 @@ func (s *ipv4Impl) String() string {
 	if s.isSpecial || s.abstract == AzureAbstractIPEmpty {
 		return s.raw
 	}
+	if s.isAny() {
+		return "*"
+	}
+	if len(s.v6) == 0 && s.multiple == nil {
+		return s.rangeOrSingle.String()
+	}
+	collection := make([]string, 0, len(s.multiple)+len(s.v6)+1)
 	if s.multiple != nil {
-		collection := make([]string, 0, len(s.multiple))
 		for _, ip := range s.multiple {
 			collection = append(collection, ip.String())
 		}
-		return strings.Join(collection, ",")
+	} else if s.hasIPv4() {
+		collection = append(collection, s.rangeOrSingle.String())
+	}
+	for _, ip := range s.v6 {
+		collection = append(collection, ip.String())
 	}
-	return s.rangeOrSingle.String()
+	return strings.Join(collection, ",")
 }
 
 func (s *ipv4Impl) Contains(o string) UnknownBool {
@@ -723,7 +924,14 @@ func (s *ipv4Impl) Contains(o string) UnknownBool {
 	if s.isSpecial {
 		return BoolUnknown
 	}
-	// We have no idea what to do with this if it isn't a single IPv4
+	if strings.Contains(o, ":") {
+		a, err := netip.ParseAddr(o)
+		if err != nil {
+			return BoolUnknown
+		}
+		return s.ContainsAddr(a)
+	}
+	// We have no idea what to do with this if it isn't a single IP
 	if !isSingleIPv4(o) {
 		return BoolUnknown
 	}
@@ -742,12 +950,19 @@ func (s *ipv4Impl) Contains(o string) UnknownBool {
 func (s *ipv4Impl) unset() {
 	s.rangeOrSingle.unset()
 	s.multiple = nil
+	s.v6 = nil
 	s.isSpecial = false
+	s.wildcard = false
 	s.abstract = AzureAbstractIPEmpty
 	s.raw = ""
 }
 
 func isSingleIPv4(s string) bool {
+	// net.ParseIP will happily give us an IPv4 for IPv4-mapped IPv6 addresses
+	// which aren't in dot notation.
+	if strings.Contains(s, ":") {
+		return false
+	}
 	ip := net.ParseIP(s)
 	if ip != nil {
 		ipv4 := ip.To4()
@@ -928,8 +1143,9 @@ func (s *ipv4Impl) FromAzure(az string) {
 		return
 	}
 	s.abstract = AzureAbstractIPNormal
-	if az == "*" {
+	if az == "*" || strings.EqualFold(az, "any") {
 		s.setRange(ipMin, ipMax)
+		s.wildcard = true
 	} else if isSingleIPv4(az) {
 		s.fromSingle(az)
 	} else if isRange(az) {
@@ -938,6 +1154,8 @@ func (s *ipv4Impl) FromAzure(az string) {
 		s.fromCIDR(az)
 	} else if isMultipleIPv4(az) {
 		s.fromMultiple(az)
+	} else if isIPv6Spec(az) {
+		s.fromIPv6Spec(az)
 	} else {
 		s.raw = az
 		s.isSpecial = true
@@ -966,6 +1184,15 @@ func (s *ipv4Impl) Equals(o *ipv4Impl) bool {
 	if o == nil {
 		return false
 	}
+	if s.wildcard != o.wildcard {
+		return false
+	}
+	if !ipv6RangesEqual(s.v6, o.v6) {
+		return false
+	}
+	if !s.hasIPv4() && !o.hasIPv4() && len(s.v6) > 0 {
+		return true
+	}
 	if s.abstract == AzureAbstractIPEmpty {
 		return o.abstract == AzureAbstractIPEmpty
 	}
@@ -1052,6 +1279,12 @@ func IPsEqual(a AzureIPv4, b AzureIPv4) UnknownBool {
 		return BoolUnknown
 	}
 
+	// The uint32 methods below only deal with IPv4, so anything with IPv6 in
+	// it is compared as address ranges.
+	if a.Family() != AzureIPFamilyIPv4 || b.Family() != AzureIPFamilyIPv4 {
+		return UnknownFromBool(addrRangesEqual(a.AddrRanges(), b.AddrRanges()))
+	}
+
 	// Otherwise the process can get complicated, but maybe they're both
 	// continuous ranges which would be easy
 	aCont, aStart, aEnd := a.ContinuousRangeUint32()
@@ -1141,9 +1374,14 @@ func IPContains(in AzureIPv4, find AzureIPv4) UnknownBool {
 	}
 
 	// If it is "*" we can for sure say yes without knowing anything about
-	// find.
+	// find. The whole IPv4 space is almost as good, but it doesn't contain
+	// any IPv6 addresses.
+	if ipIsAny(in) {
+		return BoolTrue
+	}
 	inCont, inBegin, inEnd := in.ContinuousRangeUint32()
-	if inCont.True() && inBegin == ipMin && inEnd == ipMax {
+	if inCont.True() && inBegin == ipMin && inEnd == ipMax &&
+		(find.IsSpecial() || find.Family() == AzureIPFamilyIPv4) {
 		return BoolTrue
 	}
 
@@ -1156,6 +1394,12 @@ func IPContains(in AzureIPv4, find AzureIPv4) UnknownBool {
 		return BoolUnknown
 	}
 
+	// Anything involving IPv6 can't be handled with the uint32 methods.
+	// Those ranges are generally far too large to enumerate anyway.
+	if in.Family() != AzureIPFamilyIPv4 || find.Family() != AzureIPFamilyIPv4 {
+		return UnknownFromBool(addrRangesContain(in.AddrRanges(), find.AddrRanges()))
+	}
+
 	if find.Size() > in.Size() {
 		return BoolFalse
 	}
@@ -1197,6 +1441,47 @@ func IPContains(in AzureIPv4, find AzureIPv4) UnknownBool {
 	return BoolTrue
 }
 
+// ipIsAny is whether the given AzureIPv4 is "*" and matches addresses of
+// both families.
+func ipIsAny(ip AzureIPv4) bool {
+	if impl, ok := ip.(*ipv4Impl); ok {
+		return impl.isAny()
+	}
+	cont, begin, end := ip.ContinuousRangeUint32()
+	return cont.True() && begin == ipMin && end == ipMax && ip.Family() == AzureIPFamilyDual
+}
+
+// IPsOverlap checks whether there is any address contained in both of the
+// given AzureIPv4s. Like IPContains, specials can only be compared to the
+// same special or "*".
+func IPsOverlap(a AzureIPv4, b AzureIPv4) UnknownBool {
+	if a == nil || b == nil {
+		return BoolFalse
+	}
+	if a.GetType() == AzureAbstractIPEmpty || b.GetType() == AzureAbstractIPEmpty {
+		return BoolFalse
+	}
+	if a.IsSpecial() || b.IsSpecial() {
+		if a.IsSpecial() && b.IsSpecial() {
+			if a.GetType() == b.GetType() {
+				return BoolTrue
+			}
+			return BoolUnknown
+		}
+		other := a
+		if a.IsSpecial() {
+			other = b
+		}
+		// "*" overlaps everything
+		cont, begin, end := other.ContinuousRangeUint32()
+		if cont.True() && begin == ipMin && end == ipMax {
+			return BoolTrue
+		}
+		return BoolUnknown
+	}
+	return UnknownFromBool(addrRangesOverlap(a.AddrRanges(), b.AddrRanges()))
+}
+
 func commaJoinIPs(list []AzureIPv4) string {
 	s := make([]string, 0, len(list))
 	for _, ip := range list {
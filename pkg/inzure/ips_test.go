@@ -1,7 +1,9 @@
 package inzure
 
 import (
+	"context"
 	"encoding/json"
+	"math"
 	"sort"
 	"strings"
 	"testing"
@@ -475,3 +477,273 @@ func TestRFC1918IPAddresses(t *testing.T) {
 		}
 	}
 }
+
+func TestSingleAzureIPv6(t *testing.T) {
+	azure := "2001:db8::1"
+	ip := NewAzureIPv4FromAzure(azure)
+	if ip.Family() != AzureIPFamilyIPv6 {
+		t.Fatalf("expected %s to be IPv6 but got %v", ip, ip.Family())
+	}
+	if ip.Size() != 1 {
+		t.Fatalf("expected size 1 for %s but got %d", ip, ip.Size())
+	}
+	if !ip.Contains("2001:db8::1").True() {
+		t.Fatalf("%s should have contained itself", ip)
+	}
+	for _, e := range []string{"2001:db8::2", "10.0.0.1", "::"} {
+		if !ip.Contains(e).False() {
+			t.Fatalf("%s shouldn't have contained %s", ip, e)
+		}
+	}
+	is, begin, end := ip.ContinuousRange()
+	if !is.True() || begin != azure || end != azure {
+		t.Fatalf("expected %s to be a continuous range of itself but got %s %s - %s", ip, is, begin, end)
+	}
+	if is, _, _ := ip.ContinuousRangeUint32(); is.True() {
+		t.Fatalf("IPv6 %s shouldn't be a continuous uint32 range", ip)
+	}
+	if ip.String() != azure {
+		t.Fatalf("expected output string to be %s but it was %s", azure, ip.String())
+	}
+}
+
+func TestCIDRAzureIPv6(t *testing.T) {
+	ip := NewAzureIPv4FromAzure("2001:db8:1::7/48")
+	expected := "2001:db8:1::/48"
+	if ip.String() != expected {
+		t.Fatalf("expected output string to be %s but it was %s", expected, ip.String())
+	}
+	for _, e := range []string{"2001:db8:1::", "2001:db8:1:ffff:ffff:ffff:ffff:ffff"} {
+		if !ip.Contains(e).True() {
+			t.Fatalf("%s should have contained %s", ip, e)
+		}
+	}
+	for _, e := range []string{"2001:db8:2::", "2001:db8::ffff", "10.0.0.1"} {
+		if !ip.Contains(e).False() {
+			t.Fatalf("%s shouldn't have contained %s", ip, e)
+		}
+	}
+	if ip.Size() != math.MaxUint64 {
+		t.Fatalf("expected size of %s to saturate but got %d", ip, ip.Size())
+	}
+	if NewAzureIPv4FromAzure("2001:db8::/126").Size() != 4 {
+		t.Fatalf("expected a /126 to have 4 addresses")
+	}
+}
+
+func TestRangeAzureIPv6(t *testing.T) {
+	ip := NewAzureIPv4FromRange("2001:db8::10", "2001:db8::1f")
+	if ip.Size() != 16 {
+		t.Fatalf("expected size 16 for %s but got %d", ip, ip.Size())
+	}
+	if len(ip.AllIPs()) != 16 {
+		t.Fatalf("expected 16 IPs from %s but got %v", ip, ip.AllIPs())
+	}
+	if !ip.ContainsRange("2001:db8::11", "2001:db8::1e").True() {
+		t.Fatalf("%s should have contained the range", ip)
+	}
+	if !ip.ContainsRange("2001:db8::11", "2001:db8::20").False() {
+		t.Fatalf("%s shouldn't have contained the range", ip)
+	}
+	if !IPsEqual(ip, NewAzureIPv4FromAzure("2001:db8::10/124")).True() {
+		t.Fatalf("%s should have equaled 2001:db8::10/124", ip)
+	}
+}
+
+func TestMixedFamilyAzureIP(t *testing.T) {
+	azure := "10.0.0.0/8,2001:db8::/32"
+	ip := NewAzureIPv4FromAzure(azure)
+	if ip.Family() != AzureIPFamilyDual {
+		t.Fatalf("expected %s to be dual stack but got %v", ip, ip.Family())
+	}
+	if ip.String() != azure {
+		t.Fatalf("expected output string to be %s but it was %s", azure, ip.String())
+	}
+	for _, e := range []string{"10.1.2.3", "2001:db8::1"} {
+		if !ip.Contains(e).True() {
+			t.Fatalf("%s should have contained %s", ip, e)
+		}
+	}
+	for _, e := range []string{"11.0.0.0", "2001:db9::"} {
+		if !ip.Contains(e).False() {
+			t.Fatalf("%s shouldn't have contained %s", ip, e)
+		}
+	}
+	if !ip.ContainsUint32(ipv4FromString("10.0.0.1")).True() {
+		t.Fatalf("%s should have contained 10.0.0.1 as a uint32", ip)
+	}
+	if is, _, _ := ip.ContinuousRange(); is.True() {
+		t.Fatalf("%s shouldn't be a continuous range", ip)
+	}
+	if len(ip.AllIPsUint32()) != 1<<24 {
+		t.Fatalf("expected only the IPv4 portion of %s from AllIPsUint32", ip)
+	}
+}
+
+func TestIPv6JSON(t *testing.T) {
+	ipc := IPCollection{
+		NewAzureIPv4FromAzure("2001:db8::/32"),
+		NewAzureIPv4FromAzure("192.168.0.0/16,fd00::1"),
+		NewAzureIPv4FromAzure("10.0.0.1"),
+	}
+	b, err := json.Marshal(&ipc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var into IPCollection
+	if err = json.Unmarshal(b, &into); err != nil {
+		t.Fatal(err)
+	}
+	if len(into) != len(ipc) {
+		t.Fatalf("expected %d IPs but got %d", len(ipc), len(into))
+	}
+	for i := range ipc {
+		if !IPsEqual(ipc[i], into[i]).True() {
+			t.Fatalf("unmarshal error %s turned into %s", ipc[i], into[i])
+		}
+	}
+	var rule FirewallRule
+	err = json.Unmarshal([]byte(`{"Name":"v6","IPRange":"2001:db8::/48","AllowsAllAzure":0}`), &rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rule.IPRange.Contains("2001:db8::1").True() {
+		t.Fatalf("unmarshaled firewall rule %s didn't contain 2001:db8::1", rule.IPRange)
+	}
+}
+
+func TestIPContainsIPv6(t *testing.T) {
+	tests := []struct {
+		in       string
+		find     string
+		expected UnknownBool
+	}{
+		{"*", "2001:db8::1", BoolTrue},
+		{"*", "2001:db8::/32", BoolTrue},
+		{"10.0.0.0/8", "2001:db8::1", BoolFalse},
+		{"2001:db8::/32", "10.0.0.1", BoolFalse},
+		{"2001:db8::/32", "*", BoolFalse},
+		{"2001:db8::/32", "2001:db8:ffff::/48", BoolTrue},
+		{"2001:db8::/48", "2001:db8::/32", BoolFalse},
+		{"2001:db8::/33,2001:db8:8000::/33", "2001:db8::/32", BoolTrue},
+		{"10.0.0.0/8,2001:db8::/32", "10.0.0.1,2001:db8::1", BoolTrue},
+		{"10.0.0.0/8,2001:db8::/32", "11.0.0.1,2001:db8::1", BoolFalse},
+		{"VirtualNetwork", "2001:db8::1", BoolUnknown},
+		{"0.0.0.0/0", "::/0", BoolFalse},
+		{"0.0.0.0/0", "2001:db8::1", BoolFalse},
+		{"0.0.0.0/0", "10.0.0.1", BoolTrue},
+		{"Any", "2001:db8::1", BoolTrue},
+		{"*,2001:db8::/32", "fd00::1", BoolTrue},
+		{"*,2001:db8::/32", "10.0.0.1", BoolTrue},
+	}
+	for _, test := range tests {
+		in := NewAzureIPv4FromAzure(test.in)
+		find := NewAzureIPv4FromAzure(test.find)
+		if actual := IPContains(in, find); actual != test.expected {
+			t.Fatalf("expected IPContains(%s, %s) to be %s but got %s", in, find, test.expected, actual)
+		}
+	}
+	list := []AzureIPv4{
+		NewAzureIPv4FromAzure("10.0.0.0/8"),
+		NewAzureIPv4FromAzure("fd00::/8"),
+	}
+	if !IPInList(NewAzureIPv4FromAzure("fd12:3456::1"), list).True() {
+		t.Fatalf("fd12:3456::1 should have been in %v", list)
+	}
+	if !IPInList(NewAzureIPv4FromAzure("2001:db8::1"), list).False() {
+		t.Fatalf("2001:db8::1 shouldn't have been in %v", list)
+	}
+}
+
+func TestAzureIPWildcardFamily(t *testing.T) {
+	all4 := NewAzureIPv4FromAzure("0.0.0.0/0")
+	if all4.Family() != AzureIPFamilyIPv4 || len(all4.AddrRanges()) != 1 {
+		t.Fatalf("0.0.0.0/0 should only be IPv4: %d %v", all4.Family(), all4.AddrRanges())
+	}
+	if !all4.Contains("2001:db8::1").False() {
+		t.Fatalf("0.0.0.0/0 shouldn't contain IPv6: %s", all4)
+	}
+	// The whole IPv4 space is still written as "*" so existing dumps and
+	// output don't change. Loading it back gives the wildcard.
+	for _, full := range []string{"0.0.0.0/0", "0.0.0.0-255.255.255.255"} {
+		ip := NewAzureIPv4FromAzure(full)
+		b, err := json.Marshal(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ip.String() != "*" || string(b) != `"*"` {
+			t.Fatalf("expected %s to be written as * but got %s and %s", full, ip, b)
+		}
+		loaded := NewEmptyAzureIPv4()
+		if err := json.Unmarshal(b, loaded); err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Family() != AzureIPFamilyDual {
+			t.Fatalf("expected * to load as the wildcard but got %v", loaded.Family())
+		}
+	}
+	for _, any := range []string{"*", "Any"} {
+		ip := NewAzureIPv4FromAzure(any)
+		if ip.Family() != AzureIPFamilyDual || !ip.Contains("2001:db8::1").True() || ip.String() != "*" {
+			t.Fatalf("%s should match both families: %s", any, ip)
+		}
+	}
+	if IPsEqual(all4, NewAzureIPv4FromAzure("*")).True() {
+		t.Fatal("0.0.0.0/0 and * shouldn't be equal")
+	}
+	mixed := NewAzureIPv4FromAzure("*,2001:db8::/32")
+	if mixed.Family() != AzureIPFamilyDual || !mixed.Contains("fd00::1").True() {
+		t.Fatalf("* in a mixed list should match all IPv6: %s", mixed)
+	}
+}
+
+func TestAllIPsLargeIPv6(t *testing.T) {
+	ip := NewAzureIPv4FromAzure("2001:db8::/64")
+	all := ip.AllIPs()
+	if len(all) != 1 || all[0] != "2001:db8::/64" {
+		t.Fatalf("large IPv6 range should be returned as its CIDR: %v", all)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	for range ip.AllIPsGen(ctx, 0) {
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("large IPv6 range should only be sent once, got %d", n)
+	}
+	if small := NewAzureIPv4FromAzure("2001:db8::/120").AllIPs(); len(small) != 256 {
+		t.Fatalf("small IPv6 range should be enumerated: %d", len(small))
+	}
+}
+
+func TestIPsOverlap(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected UnknownBool
+	}{
+		{"10.0.0.0/8", "10.1.0.0/16", BoolTrue},
+		{"10.1.0.0/16", "10.0.0.0/8", BoolTrue},
+		{"10.0.0.0/24", "10.0.0.255-10.0.1.10", BoolTrue},
+		{"10.0.0.0/24", "10.0.1.0/24", BoolFalse},
+		{"10.0.0.1,10.0.0.5", "10.0.0.2-10.0.0.4", BoolFalse},
+		{"10.0.0.1,10.0.0.5", "10.0.0.2-10.0.0.5", BoolTrue},
+		{"*", "192.168.1.1", BoolTrue},
+		{"2001:db8::/32", "2001:db8:1::1", BoolTrue},
+		{"2001:db8::/32", "10.0.0.0/8", BoolFalse},
+		{"Internet", "Internet", BoolTrue},
+		{"Internet", "*", BoolTrue},
+		{"Internet", "10.0.0.1", BoolUnknown},
+	}
+	for _, test := range tests {
+		a := NewAzureIPv4FromAzure(test.a)
+		b := NewAzureIPv4FromAzure(test.b)
+		if actual := IPsOverlap(a, b); actual != test.expected {
+			t.Fatalf("expected IPsOverlap(%s, %s) to be %s but got %s", a, b, test.expected, actual)
+		}
+	}
+	if !IPsOverlap(NewEmptyAzureIPv4(), NewAzureIPv4FromAzure("*")).False() {
+		t.Fatal("empty IPs shouldn't overlap anything")
+	}
+}
@@ -0,0 +1,341 @@
+package inzure
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+var (
+	ipv6Min = netip.IPv6Unspecified()
+	ipv6Max = netip.AddrFrom16([16]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	})
+)
+
+// ipv6Range is the IPv6 equivalent of rangeOrSingle. IPv6 ranges don't fit
+// in a uint32, so they're kept as netip.Addrs instead.
+type ipv6Range struct {
+	begin netip.Addr
+	end   netip.Addr
+	// prefixBits is the prefix length if this came from CIDR notation and is
+	// -1 otherwise.
+	prefixBits int
+}
+
+func newIPv6Range(begin, end netip.Addr, prefixBits int) ipv6Range {
+	if begin.Compare(end) > 0 {
+		begin, end = end, begin
+	}
+	return ipv6Range{
+		begin:      begin,
+		end:        end,
+		prefixBits: prefixBits,
+	}
+}
+
+func ipv6RangeFromPrefix(p netip.Prefix) ipv6Range {
+	p = p.Masked()
+	return newIPv6Range(p.Addr(), lastAddrInPrefix(p), p.Bits())
+}
+
+func lastAddrInPrefix(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+func (r ipv6Range) isSingle() bool {
+	return r.begin == r.end
+}
+
+func (r ipv6Range) asAddrRange() AddrRange {
+	return AddrRange{Begin: r.begin, End: r.end}
+}
+
+// size returns the number of addresses in the range. Anything that won't fit
+// in a uint64 is clamped to math.MaxUint64.
+func (r ipv6Range) size() uint64 {
+	b, e := r.begin.As16(), r.end.As16()
+	lo, borrow := bits.Sub64(
+		binary.BigEndian.Uint64(e[8:]), binary.BigEndian.Uint64(b[8:]), 0,
+	)
+	hi, _ := bits.Sub64(
+		binary.BigEndian.Uint64(e[:8]), binary.BigEndian.Uint64(b[:8]), borrow,
+	)
+	if hi != 0 || lo == math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return lo + 1
+}
+
+// maxIPv6Enumeration is the largest IPv6 range that we'll list every address
+// of. Anything bigger, such as a /64, is returned in its String form instead
+// since it could never be enumerated.
+const maxIPv6Enumeration = uint64(1 << 16)
+
+func (r ipv6Range) enumerable() bool {
+	return r.size() <= maxIPv6Enumeration
+}
+
+func (r ipv6Range) allIPs() []string {
+	if !r.enumerable() {
+		return []string{r.String()}
+	}
+	s := make([]string, 0, r.size())
+	for v := r.begin; v.IsValid() && v.Compare(r.end) <= 0; v = v.Next() {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+func (r ipv6Range) String() string {
+	if r.isSingle() {
+		return r.begin.String()
+	}
+	if r.begin == ipv6Min && r.end == ipv6Max {
+		return "::/0"
+	}
+	if r.prefixBits >= 0 {
+		return netip.PrefixFrom(r.begin, r.prefixBits).String()
+	}
+	return r.begin.String() + "-" + r.end.String()
+}
+
+func (c *rangeOrSingle) asAddrRange() AddrRange {
+	if c.single.set {
+		a := uint32ToAddr(c.single.val)
+		return AddrRange{Begin: a, End: a}
+	}
+	return AddrRange{Begin: uint32ToAddr(c.begin.val), End: uint32ToAddr(c.end.val)}
+}
+
+func addrToUint32(a netip.Addr) uint32 {
+	b := a.As4()
+	return binary.BigEndian.Uint32(b[:])
+}
+
+func uint32ToAddr(v uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return netip.AddrFrom4(b)
+}
+
+func addSaturating(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// isSingleIPv6 is the IPv6 version of isSingleIPv4. IPv4-mapped addresses are
+// not considered IPv6.
+func isSingleIPv6(s string) bool {
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return false
+	}
+	return a.Is6() && !a.Is4In6()
+}
+
+// parseIPPart parses one comma separated element of an Azure IP that may
+// contain IPv6 addresses. The returned addresses are always unmapped, so
+// IPv4-mapped IPv6 addresses come back as IPv4.
+func parseIPPart(s string) (ipv6Range, bool) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return ipv6Range{}, false
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return ipv6RangeFromPrefix(p), true
+	}
+	if split := strings.Split(s, "-"); len(split) == 2 {
+		begin, err := netip.ParseAddr(split[0])
+		if err != nil {
+			return ipv6Range{}, false
+		}
+		end, err := netip.ParseAddr(split[1])
+		if err != nil {
+			return ipv6Range{}, false
+		}
+		begin, end = begin.Unmap(), end.Unmap()
+		if begin.BitLen() != end.BitLen() {
+			return ipv6Range{}, false
+		}
+		return newIPv6Range(begin, end, -1), true
+	}
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return ipv6Range{}, false
+	}
+	a = a.Unmap()
+	return newIPv6Range(a, a, -1), true
+}
+
+// isIPv6Spec returns true for any Azure IP specification that the IPv4 only
+// parsing can't handle because it has IPv6 addresses in it. This allows
+// mixing IPv4 and IPv6 elements in a comma separated list.
+func isIPv6Spec(s string) bool {
+	if !strings.Contains(s, ":") {
+		return false
+	}
+	for _, part := range strings.Split(s, ",") {
+		if part == "*" {
+			continue
+		}
+		if _, ok := parseIPPart(part); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// This function assumes isIPv6Spec returns true. Parts that aren't valid are
+// silently dropped.
+func (s *ipv4Impl) fromIPv6Spec(st string) {
+	parts := strings.Split(st, ",")
+	v4 := make([]rangeOrSingle, 0, len(parts))
+	for _, part := range parts {
+		if part == "*" {
+			// "*" is every address in both families
+			var c rangeOrSingle
+			c.setRange(ipMin, ipMax)
+			v4 = append(v4, c)
+			s.v6 = append(s.v6, newIPv6Range(ipv6Min, ipv6Max, 0))
+			continue
+		}
+		r, ok := parseIPPart(part)
+		if !ok {
+			continue
+		}
+		if r.begin.Is4() {
+			var c rangeOrSingle
+			if r.isSingle() {
+				c.single.setTo(addrToUint32(r.begin))
+			} else {
+				c.setRange(addrToUint32(r.begin), addrToUint32(r.end))
+				c.isCIDR = r.prefixBits >= 0
+			}
+			v4 = append(v4, c)
+			continue
+		}
+		s.v6 = append(s.v6, r)
+	}
+	sort.Slice(s.v6, func(i, j int) bool {
+		return s.v6[i].begin.Less(s.v6[j].begin)
+	})
+	if len(v4) == 1 {
+		s.rangeOrSingle = v4[0]
+	} else if len(v4) > 1 {
+		sort.Sort(sortable(v4))
+		s.multiple = v4
+	}
+}
+
+func ipv6RangesEqual(a, b []ipv6Range) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, r := range a {
+		found := false
+		for _, o := range b {
+			if r.begin == o.begin && r.end == o.end {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAddrRanges returns the sorted minimal set of ranges that covers the
+// same addresses as the given ranges.
+func mergeAddrRanges(ranges []AddrRange) []AddrRange {
+	sorted := make([]AddrRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Begin.Less(sorted[j].Begin)
+	})
+	merged := make([]AddrRange, 0, len(sorted))
+	for _, r := range sorted {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.Begin.BitLen() == r.Begin.BitLen() {
+				next := last.End.Next()
+				if !next.IsValid() || next.Compare(r.Begin) >= 0 {
+					if r.End.Compare(last.End) > 0 {
+						last.End = r.End
+					}
+					continue
+				}
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// addrRangesContain checks whether every range in find is covered by the
+// ranges in in.
+func addrRangesContain(in []AddrRange, find []AddrRange) bool {
+	if len(in) == 0 || len(find) == 0 {
+		return false
+	}
+	merged := mergeAddrRanges(in)
+	for _, f := range find {
+		found := false
+		for _, m := range merged {
+			if m.containsRange(f) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func addrRangesEqual(a []AddrRange, b []AddrRange) bool {
+	ma := mergeAddrRanges(a)
+	mb := mergeAddrRanges(b)
+	if len(ma) != len(mb) {
+		return false
+	}
+	for i := range ma {
+		if ma[i] != mb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addrRangesOverlap checks whether any address is in both a and b.
+func addrRangesOverlap(a []AddrRange, b []AddrRange) bool {
+	for _, ar := range a {
+		for _, br := range b {
+			if ar.Begin.BitLen() != br.Begin.BitLen() {
+				continue
+			}
+			if ar.Begin.Compare(br.End) <= 0 && br.Begin.Compare(ar.End) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package inzure
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -125,11 +126,15 @@ func TestBadQSFail(t *testing.T) {
 		"/RedisServers[.SuchField == BoolTrue]",  // field doesn't exist
 		"/PostgresServer",                        // typo, no trailing s
 		"/WeBApPs",                               // Case sensitivity
-		"/WebApps[.Meta.ResourceGroupName == 'foo']", // ' isn't valid
-		"/WebApps[.HTTPSOnly = BoolFalse]",           // = instead of ==
-		"/WebApps[.HTTPSOnly == bOOlFaLse]",          // case sensitivity
-		"/WebApps[HTTPSOnly == BoolFalse]",           // bad field selector (no .)
-		"/WebApps[.NonExistentMethod() == 1]",        // Nonexistent method
+		"/WebApps[.Meta.ResourceGroupName == 'foo']",             // ' isn't valid
+		"/WebApps[.HTTPSOnly = BoolFalse]",                       // = instead of ==
+		"/WebApps[.HTTPSOnly == bOOlFaLse]",                      // case sensitivity
+		"/WebApps[HTTPSOnly == BoolFalse]",                       // bad field selector (no .)
+		"/WebApps[.NonExistentMethod() == 1]",                    // Nonexistent method
+		"/WebApps[.HTTPSOnly == BoolTrue && (.SuchField == 1)]",  // field doesn't exist in group
+		"/WebApps[!(.HTTPSOnly == BoolTrue || .SuchField == 1)]", // field doesn't exist in negated group
+		"/WebApps[!(.HTTPSOnly == BoolTrue]",                     // no closing )
+		"/WebApps[.HTTPSOnly ! BoolTrue]",                        // ! isn't a comparison
 
 		"/NetworkSecurityGroups[.AllowsIPToPortString(\"12.34.56.78\", \"22\").Sel == 5]", // .Sel after method call
 	}
@@ -173,3 +178,349 @@ func TestQSValidOdd(t *testing.T) {
 		}
 	}
 }
+
+func TestQSGroupByPointer(t *testing.T) {
+	rules := make([]FirewallRule, 0, 3)
+	for _, ip := range []string{"10.0.0.0/8", "1.2.3.4", "10.0.0.0/8"} {
+		rules = append(rules, FirewallRule{IPRange: NewAzureIPv4FromAzure(ip)})
+	}
+	v, err := qsGroupBy(reflect.ValueOf(rules), &QSField{Name: "IPRange"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := v.Interface().([]QSGroup)
+	if len(groups) != 2 || groups[0].Count != 2 || groups[1].Count != 1 {
+		t.Fatalf("expected IP ranges to be grouped by value: %+v", groups)
+	}
+}
+
+func TestQSInterfaceMethodCall(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	for name, ip := range map[string]string{"open": "*", "closed": "10.0.0.1"} {
+		sql := NewEmptySQLServer()
+		sql.Meta = newResourceId(rgAName, SQLServerT, name)
+		sql.Firewall = append(sql.Firewall, FirewallRule{
+			Name:    name,
+			IPRange: NewAzureIPv4FromAzure(ip),
+		})
+		rg.SQLServers = append(rg.SQLServers, sql)
+	}
+	sub.ResourceGroups[rgAName] = rg
+	qs := "/SQLServers[.Firewall[ANY].IPRange.Contains(\"1.2.3.4\") == BoolTrue]"
+	into := make([]*SQLServer, 0, 1)
+	if err := sub.FromQueryString(qs, &into); err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if len(into) != 1 || into[0].Meta.Name != "open" {
+		t.Fatalf("expected only the open server for %s but got %v", qs, into)
+	}
+}
+
+func TestQSNotGroup(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	for _, c := range []struct {
+		name  string
+		https UnknownBool
+		tls   TLSVersion
+	}{
+		{"good", BoolTrue, TLSVersionOneTwo},
+		{"oldtls", BoolTrue, TLSVersionOneZero},
+		{"http", BoolFalse, TLSVersionOneTwo},
+	} {
+		wa := NewEmptyWebApp()
+		wa.Meta = newResourceId(rgAName, WebAppT, c.name)
+		wa.HTTPSOnly = c.https
+		wa.MinTLSVersion = c.tls
+		rg.WebApps = append(rg.WebApps, wa)
+	}
+	sub.ResourceGroups[rgAName] = rg
+	qs := "/WebApps[!(.HTTPSOnly == BoolTrue && .MinTLSVersion >= 3)]"
+	into := make([]*WebApp, 0, 2)
+	if err := sub.FromQueryString(qs, &into); err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if len(into) != 2 || into[0].Meta.Name != "oldtls" || into[1].Meta.Name != "http" {
+		t.Fatalf("expected oldtls and http for %s but got %v", qs, into)
+	}
+}
+
+func TestQSNetworkOps(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	for name, ips := range map[string][]string{
+		"open":   {"0.0.0.0-255.255.255.255"},
+		"split":  {"10.0.0.0/9", "10.128.0.0/9"},
+		"office": {"203.0.113.0/24"},
+		"vnet":   {"10.1.2.3"},
+	} {
+		sql := NewEmptySQLServer()
+		sql.Meta = newResourceId(rgAName, SQLServerT, name)
+		for _, ip := range ips {
+			sql.Firewall = append(sql.Firewall, FirewallRule{
+				Name:    ip,
+				IPRange: NewAzureIPv4FromAzure(ip),
+			})
+		}
+		rg.SQLServers = append(rg.SQLServers, sql)
+	}
+	nsg := NewEmptyNSG()
+	nsg.Meta = newResourceId(rgAName, NetworkSecurityGroupT, "nsg")
+	nsg.InboundRules = []SecurityRule{
+		{
+			Name:        "ssh",
+			Allows:      true,
+			Inbound:     true,
+			SourceIPs:   createIPs("10.20.0.0/16"),
+			DestPorts:   createPorts("22"),
+			DestIPs:     createIPs("*"),
+			SourcePorts: createPorts("*"),
+		},
+	}
+	rg.NetworkSecurityGroups = append(rg.NetworkSecurityGroups, nsg)
+	sub.ResourceGroups[rgAName] = rg
+
+	tests := map[string][]string{
+		"/SQLServers[.Firewall contains \"0.0.0.0/0\"]":                                   {"open"},
+		"/SQLServers[.Firewall contains \"10.0.0.0/8\"]":                                  {"open", "split"},
+		"/SQLServers[.Firewall[ANY].IPRange contains \"10.0.0.0/8\"]":                     {"open"},
+		"/SQLServers[.Firewall[ANY].IPRange in \"10.0.0.0/8\"]":                           {"split", "vnet"},
+		"/SQLServers[.Firewall overlaps \"203.0.113.128/25\"]":                            {"office", "open"},
+		"/SQLServers[!(.Firewall[ALL].IPRange in \"192.168.0.0/16\")]":                    {"office", "open", "split", "vnet"},
+		"/NetworkSecurityGroups[.InboundRules[ANY].SourceIPs in \"10.0.0.0/8\"]":          {"nsg"},
+		"/NetworkSecurityGroups[.InboundRules[ANY].SourceIPs overlaps \"10.20.30.0/24\"]": {"nsg"},
+		"/NetworkSecurityGroups[.InboundRules[ANY].SourceIPs overlaps \"10.21.0.0/16\"]":  {},
+		"/NetworkSecurityGroups[.InboundRules[ANY].DestPorts contains 22]":                {"nsg"},
+		"/NetworkSecurityGroups[.InboundRules[ANY].DestPorts in \"1-1024\"]":              {"nsg"},
+		"/NetworkSecurityGroups[.InboundRules[ANY].DestPorts overlaps \"1000-2000\"]":     {},
+	}
+	for qs, expected := range tests {
+		v, err := sub.ReflectFromQueryString(qs)
+		if err != nil {
+			t.Fatalf("Failed to execute query string %s: %v", qs, err)
+		}
+		names := make([]string, 0)
+		iqsTestSlice(v, func(i interface{}) {
+			names = append(names, reflect.ValueOf(i).Elem().FieldByName("Meta").Interface().(ResourceID).Name)
+		})
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("expected %v for %s but got %v", expected, qs, names)
+		}
+	}
+
+	bad := []string{
+		"/SQLServers[.Firewall[ANY].IPRange within \"10.0.0.0/8\"]",
+		"/SQLServers[.Firewall contains \"not an ip\"]",
+		"/SQLServers[.FQDN contains \"10.0.0.1\"]",
+	}
+	for _, qs := range bad {
+		if _, err := sub.ReflectFromQueryString(qs); err == nil {
+			t.Fatalf("qs %s should have failed but didn't", qs)
+		}
+	}
+}
+
+func TestQSProjection(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	for _, c := range []struct {
+		name  string
+		https UnknownBool
+		ips   []string
+	}{
+		{"secure", BoolTrue, nil},
+		{"plain", BoolFalse, []string{"10.0.0.1", "10.0.0.2"}},
+	} {
+		wa := NewEmptyWebApp()
+		wa.Meta = newResourceId(rgAName, WebAppT, c.name)
+		wa.HTTPSOnly = c.https
+		wa.DefaultHostname = c.name + ".azurewebsites.net"
+		wa.MinTLSVersion = TLSVersionOneTwo
+		for _, ip := range c.ips {
+			wa.OutboundIPAddresses = append(wa.OutboundIPAddresses, NewAzureIPv4FromAzure(ip))
+		}
+		rg.WebApps = append(rg.WebApps, wa)
+	}
+	sub.ResourceGroups[rgAName] = rg
+
+	qs := "/WebApps[.HTTPSOnly != BoolTrue] -> {.Meta.Name, .DefaultHostname, .MinTLSVersion, .OutboundIPAddresses[LEN], .OutboundIPAddresses[ANY].String()}"
+	var p QueryString
+	if err := p.Parse(qs); err != nil {
+		t.Fatalf("failed to parse %s: %v", qs, err)
+	}
+	expectedNames := []string{"Meta.Name", "DefaultHostname", "MinTLSVersion", "OutboundIPAddresses[LEN]", "OutboundIPAddresses[ANY].String()"}
+	if !reflect.DeepEqual(p.ProjectionNames(), expectedNames) {
+		t.Fatalf("expected names %v but got %v", expectedNames, p.ProjectionNames())
+	}
+	p.Raw = ""
+	if p.String() != qs {
+		t.Fatalf("expected %s to round trip but got %s", qs, p.String())
+	}
+
+	records, err := sub.ProjectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("failed to project %s: %v", qs, err)
+	}
+	expected := []QSRecord{
+		{
+			"Meta.Name":                         "plain",
+			"DefaultHostname":                   "plain.azurewebsites.net",
+			"MinTLSVersion":                     TLSVersionOneTwo,
+			"OutboundIPAddresses[LEN]":          2,
+			"OutboundIPAddresses[ANY].String()": []interface{}{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected %v but got %v", expected, records)
+	}
+
+	// Single resources and names with dashes work too
+	rg.WebApps[0].Meta = newResourceId(rgAName, WebAppT, "secure-app")
+	records, err = sub.ProjectFromQueryString("/WebApps/" + rgAName + "/secure-app->{.HTTPSOnly}")
+	if err != nil {
+		t.Fatalf("failed to project single resource: %v", err)
+	}
+	if len(records) != 1 || records[0]["HTTPSOnly"] != UnknownBool(BoolTrue) {
+		t.Fatalf("bad single resource projection: %v", records)
+	}
+
+	bad := []string{
+		"/WebApps",                           // no projection
+		"/WebApps -> {.NoSuchField}",         // field doesn't exist
+		"/WebApps -> {.Meta.Name",            // no closing }
+		"/WebApps -> {}",                     // empty projection
+		"/WebApps -> {.DefaultHostname.Len}", // field on a nonstruct
+	}
+	for _, qs := range bad {
+		if _, err := sub.ProjectFromQueryString(qs); err == nil {
+			t.Fatalf("qs %s should have failed but didn't", qs)
+		}
+	}
+}
+
+func TestQSStages(t *testing.T) {
+	sub := NewSubscription(subId)
+	for rgName, accounts := range map[string]map[string]int{
+		rgAName: {"a1": 3, "a2": 0, "a3": 5},
+		rgBName: {"b1": 1},
+	} {
+		rg := NewEmptyResourceGroup()
+		for name, n := range accounts {
+			sa := NewEmptyStorageAccount()
+			sa.Meta = newResourceId(rgName, StorageAccountT, name)
+			for i := 0; i < n; i++ {
+				sa.Containers = append(sa.Containers, Container{
+					Name:   fmt.Sprintf("c%d", i),
+					Access: ContainerPermissionBlob,
+				})
+			}
+			rg.StorageAccounts = append(rg.StorageAccounts, sa)
+		}
+		sub.ResourceGroups[rgName] = rg
+	}
+
+	names := func(v reflect.Value) []string {
+		s := make([]string, 0)
+		iqsTestSlice(v, func(i interface{}) {
+			s = append(s, i.(*StorageAccount).Meta.Name)
+		})
+		return s
+	}
+
+	qs := "/StorageAccounts | sort by .Containers[LEN] desc | limit 2"
+	v, err := sub.ReflectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if got := names(v); !reflect.DeepEqual(got, []string{"a3", "a1"}) {
+		t.Fatalf("expected a3 and a1 for %s but got %v", qs, got)
+	}
+
+	qs = "/StorageAccounts[.Containers[LEN] > 0] | sort by .Meta.Name"
+	v, err = sub.ReflectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if got := names(v); !reflect.DeepEqual(got, []string{"a1", "a3", "b1"}) {
+		t.Fatalf("expected sorted names for %s but got %v", qs, got)
+	}
+
+	qs = "/StorageAccounts[.Containers[LEN] > 0] | count"
+	v, err = sub.ReflectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if n, is := v.Interface().(*int); !is || *n != 3 {
+		t.Fatalf("expected a count of 3 for %s but got %v", qs, v.Interface())
+	}
+
+	qs = "/StorageAccounts | group by .Meta.ResourceGroupName | sort by .Count desc"
+	v, err = sub.ReflectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	groups, is := v.Interface().(*[]QSGroup)
+	if !is || len(*groups) != 2 {
+		t.Fatalf("expected two groups for %s but got %v", qs, v.Interface())
+	}
+	if (*groups)[0].Key != rgAName || (*groups)[0].Count != 3 || (*groups)[1].Key != rgBName || (*groups)[1].Count != 1 {
+		t.Fatalf("bad groups for %s: %+v", qs, *groups)
+	}
+	if items, is := (*groups)[1].Items.([]*StorageAccount); !is || len(items) != 1 || items[0].Meta.Name != "b1" {
+		t.Fatalf("bad group items for %s: %+v", qs, (*groups)[1].Items)
+	}
+
+	qs = "/StorageAccounts | group by .Meta.ResourceGroupName | limit 5 -> {.Key, .Count}"
+	records, err := sub.ProjectFromQueryString(qs)
+	if err != nil {
+		t.Fatalf("Failed to project %s: %v", qs, err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected two records for %s but got %v", qs, records)
+	}
+
+	// sort and limit keep the type so they can be loaded directly
+	into := make([]*StorageAccount, 0)
+	qs = "/StorageAccounts | sort by .Meta.Name desc | limit 1"
+	if err := sub.FromQueryString(qs, &into); err != nil {
+		t.Fatalf("Failed to execute query string %s: %v", qs, err)
+	}
+	if len(into) != 1 || into[0].Meta.Name != "b1" {
+		t.Fatalf("expected only b1 for %s but got %v", qs, into)
+	}
+	// The subscription isn't reordered
+	if sub.ResourceGroups[rgAName].StorageAccounts[0].Meta.Name == "b1" {
+		t.Fatal("sorting modified the subscription")
+	}
+
+	var p QueryString
+	qs = "/StorageAccounts[.HTTPSOnly != BoolTrue] | sort by .Containers[LEN] desc | limit 10 -> {.Meta.Name}"
+	if err := p.Parse(qs); err != nil {
+		t.Fatalf("failed to parse %s: %v", qs, err)
+	}
+	p.Raw = ""
+	if p.String() != qs {
+		t.Fatalf("expected %s to round trip but got %s", qs, p.String())
+	}
+
+	bad := []string{
+		"/StorageAccounts | count | limit 1",                     // count must be last
+		"/StorageAccounts | count -> {.Meta.Name}",               // can't project a count
+		"/StorageAccounts | sort by .NoSuchField",                // field doesn't exist
+		"/StorageAccounts | group by .Meta.Name -> {.Meta.Name}", // groups don't have Meta
+		"/StorageAccounts | sort .Meta.Name",                     // missing by
+		"/StorageAccounts | sort by .Meta.Name up",               // bad order
+		"/StorageAccounts | limit -1",                            // negative limit
+		"/StorageAccounts | explode",                             // unknown stage
+	}
+	for _, qs := range bad {
+		if _, err := sub.ReflectFromQueryString(qs); err == nil {
+			t.Fatalf("qs %s should have failed but didn't", qs)
+		}
+	}
+	if err := sub.FromQueryString("/StorageAccounts | count", &into); err == nil {
+		t.Fatal("shouldn't be able to load a count into a slice")
+	}
+}
@@ -12,8 +12,19 @@ type KeyVault struct {
 	EnabledForDeployment         UnknownBool
 	EnabledForDiskEncryption     UnknownBool
 	EnabledForTemplateDeployment UnknownBool
-	AccessPolicies               []KeyVaultAccessPolicy
-	Firewall                     KeyVaultFirewall
+	SoftDeleteEnabled            UnknownBool
+	SoftDeleteRetentionDays      int32
+	PurgeProtection              UnknownBool
+	// RBACAuthorization means Azure RBAC is used for data plane access and
+	// the AccessPolicies are ignored.
+	RBACAuthorization UnknownBool
+	AccessPolicies    []KeyVaultAccessPolicy
+	Firewall          KeyVaultFirewall
+
+	// Keys, Secrets, and Certificates only contain metadata, never values
+	Keys         []KeyVaultKey
+	Secrets      []KeyVaultSecret
+	Certificates []KeyVaultCertificate
 }
 
 func NewEmptyKeyVault() *KeyVault {
@@ -26,6 +37,9 @@ func NewEmptyKeyVault() *KeyVault {
 			IPRules:   make(IPCollection, 0),
 			VNetRules: make([]ResourceID, 0),
 		},
+		Keys:         make([]KeyVaultKey, 0),
+		Secrets:      make([]KeyVaultSecret, 0),
+		Certificates: make([]KeyVaultCertificate, 0),
 	}
 }
 
@@ -42,6 +56,20 @@ func (kv *KeyVault) FromAzure(az *armkeyvault.Vault) {
 	kv.EnabledForDeployment.FromBoolPtr(props.EnabledForDeployment)
 	kv.EnabledForTemplateDeployment.FromBoolPtr(props.EnabledForTemplateDeployment)
 	kv.EnabledForDiskEncryption.FromBoolPtr(props.EnabledForDiskEncryption)
+	kv.SoftDeleteEnabled.FromBoolPtr(props.EnableSoftDelete)
+	gValFromPtr(&kv.SoftDeleteRetentionDays, props.SoftDeleteRetentionInDays)
+	// Purge protection can't be disabled once set so Azure leaves it out
+	// instead of sending false.
+	if props.EnablePurgeProtection == nil {
+		kv.PurgeProtection = BoolFalse
+	} else {
+		kv.PurgeProtection.FromBool(*props.EnablePurgeProtection)
+	}
+	if props.EnableRbacAuthorization == nil {
+		kv.RBACAuthorization = BoolFalse
+	} else {
+		kv.RBACAuthorization.FromBool(*props.EnableRbacAuthorization)
+	}
 	if props.AccessPolicies != nil {
 		aps := props.AccessPolicies
 		kv.AccessPolicies = make([]KeyVaultAccessPolicy, len(aps))
@@ -50,12 +78,16 @@ func (kv *KeyVault) FromAzure(az *armkeyvault.Vault) {
 		}
 	}
 	kv.Firewall.FromAzure(props.NetworkACLs)
+	if props.PublicNetworkAccess != nil {
+		kv.Firewall.PublicNetworkAccess.FromBool(strings.EqualFold(*props.PublicNetworkAccess, "Enabled"))
+	}
 }
 
 type KeyVaultFirewall struct {
-	IPRules      IPCollection
-	DefaultAllow UnknownBool
-	VNetRules    []ResourceID
+	PublicNetworkAccess UnknownBool
+	IPRules             IPCollection
+	DefaultAllow        UnknownBool
+	VNetRules           []ResourceID
 }
 
 func (kvf *KeyVaultFirewall) FromAzure(az *armkeyvault.NetworkRuleSet) {
@@ -99,6 +131,9 @@ func (kvf KeyVaultFirewall) AllowsIPString(ip string) (UnknownBool, []PacketRout
 }
 
 func (kvf KeyVaultFirewall) AllowsIP(chk AzureIPv4) (UnknownBool, []PacketRoute, error) {
+	if kvf.PublicNetworkAccess.False() {
+		return BoolFalse, nil, nil
+	}
 	// If we allow everything by default then ezpz
 	if kvf.DefaultAllow.True() {
 		return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
@@ -134,6 +169,9 @@ func (kvf KeyVaultFirewall) AllowsIPToPort(ip AzureIPv4, _ AzurePort) (UnknownBo
 }
 
 func (kvf KeyVaultFirewall) RespectsAllowlist(wl FirewallAllowlist) (UnknownBool, []IPPort, error) {
+	if kvf.PublicNetworkAccess.False() {
+		return BoolTrue, nil, nil
+	}
 	if kvf.DefaultAllow.True() {
 		return BoolFalse, []IPPort{{
 			IP:   NewAzureIPv4FromAzure("*"),
@@ -0,0 +1,277 @@
+package inzure
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+)
+
+// Certificates are only available from the Key Vault data plane. Key
+// rotation policies come from azkeys, but there isn't an azcertificates
+// dependency yet so these are the pieces of the certificates REST API that we
+// care about.
+//
+// TODO: Switch to azcertificates once it can be added as a dependency.
+
+const keyVaultDataAPIVersion = "7.4"
+
+type azKeyVaultAttributes struct {
+	Enabled   *bool  `json:"enabled"`
+	NotBefore *int64 `json:"nbf"`
+	Expires   *int64 `json:"exp"`
+	Created   *int64 `json:"created"`
+}
+
+type azKeyVaultCertificateItem struct {
+	ID         *string               `json:"id"`
+	Thumbprint *string               `json:"x5t"`
+	Attributes *azKeyVaultAttributes `json:"attributes"`
+}
+
+type azKeyVaultCertificatePolicy struct {
+	KeyProps *struct {
+		KeyType *string `json:"kty"`
+		KeySize *int32  `json:"key_size"`
+		Curve   *string `json:"crv"`
+	} `json:"key_props"`
+	X509Props *struct {
+		Subject        *string `json:"subject"`
+		ValidityMonths *int32  `json:"validity_months"`
+	} `json:"x509_props"`
+	Issuer *struct {
+		Name *string `json:"name"`
+	} `json:"issuer"`
+}
+
+// newKeyVaultRESTClient makes a client for the certificates data plane of
+// the vault at the given URL.
+func (impl *azureImpl) newKeyVaultRESTClient(vaultURL string) (*armRESTClient, error) {
+	u, err := url.Parse(vaultURL)
+	if err != nil {
+		return nil, err
+	}
+	// The token audience is the vault DNS suffix, such as vault.azure.net
+	_, suffix, _ := strings.Cut(u.Hostname(), ".")
+	scope := "https://" + suffix + "/.default"
+	var opts policy.ClientOptions
+	if impl.clientOptions != nil {
+		opts = impl.clientOptions.ClientOptions
+	}
+	pl := runtime.NewPipeline("inzure", "v0.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			runtime.NewBearerTokenPolicy(impl.tokenCredential, []string{scope}, nil),
+		},
+	}, &opts)
+	return &armRESTClient{
+		pipeline:   pl,
+		endpoint:   u.Scheme + "://" + u.Host,
+		apiVersion: keyVaultDataAPIVersion,
+	}, nil
+}
+
+// KeyVaultItemAttributes are the attributes shared by keys, secrets, and
+// certificates. Zero times mean the attribute isn't set.
+type KeyVaultItemAttributes struct {
+	Enabled   UnknownBool
+	Created   time.Time
+	NotBefore time.Time
+	Expires   time.Time
+	// NeverExpires is true if there is no expiration set
+	NeverExpires UnknownBool
+	// Expired is whether the item was expired when it was collected
+	Expired UnknownBool
+}
+
+func (a *KeyVaultItemAttributes) setTimes(created, nbf, exp *time.Time) {
+	gValFromPtr(&a.Created, created)
+	gValFromPtr(&a.NotBefore, nbf)
+	if exp == nil {
+		a.NeverExpires = BoolTrue
+		a.Expired = BoolFalse
+		return
+	}
+	a.Expires = *exp
+	a.NeverExpires = BoolFalse
+	a.Expired.FromBool(exp.Before(time.Now()))
+}
+
+func (a *KeyVaultItemAttributes) fromAzureKey(az *armkeyvault.KeyAttributes) {
+	if az == nil {
+		return
+	}
+	a.Enabled.FromBoolPtr(az.Enabled)
+	a.setTimes(unixTimePtr(az.Created), unixTimePtr(az.NotBefore), unixTimePtr(az.Expires))
+}
+
+func (a *KeyVaultItemAttributes) fromAzureSecret(az *armkeyvault.SecretAttributes) {
+	if az == nil {
+		return
+	}
+	a.Enabled.FromBoolPtr(az.Enabled)
+	a.setTimes(az.Created, az.NotBefore, az.Expires)
+}
+
+func (a *KeyVaultItemAttributes) fromAzureData(az *azKeyVaultAttributes) {
+	if az == nil {
+		return
+	}
+	a.Enabled.FromBoolPtr(az.Enabled)
+	a.setTimes(unixTimePtr(az.Created), unixTimePtr(az.NotBefore), unixTimePtr(az.Expires))
+}
+
+func unixTimePtr(t *int64) *time.Time {
+	if t == nil {
+		return nil
+	}
+	ut := time.Unix(*t, 0).UTC()
+	return &ut
+}
+
+// KeyVaultKeyRotationPolicy is the automatic rotation policy of a key.
+// Durations are ISO 8601 durations such as P90D.
+type KeyVaultKeyRotationPolicy struct {
+	// AutoRotate is whether there is a Rotate action in the policy
+	AutoRotate         UnknownBool
+	RotateAfterCreate  string
+	RotateBeforeExpiry string
+	// ExpiresAfter is the expiry set on new versions of the key
+	ExpiresAfter string
+}
+
+func (rp *KeyVaultKeyRotationPolicy) fromAzure(az *azkeys.KeyRotationPolicy) {
+	rp.AutoRotate = BoolFalse
+	if az.Attributes != nil {
+		gValFromPtr(&rp.ExpiresAfter, az.Attributes.ExpiryTime)
+	}
+	for _, la := range az.LifetimeActions {
+		if la == nil || la.Action == nil || la.Action.Type == nil {
+			continue
+		}
+		if !strings.EqualFold(string(*la.Action.Type), string(azkeys.KeyRotationPolicyActionRotate)) {
+			continue
+		}
+		rp.AutoRotate = BoolTrue
+		if la.Trigger != nil {
+			gValFromPtr(&rp.RotateAfterCreate, la.Trigger.TimeAfterCreate)
+			gValFromPtr(&rp.RotateBeforeExpiry, la.Trigger.TimeBeforeExpiry)
+		}
+	}
+}
+
+type KeyVaultKey struct {
+	Name       string
+	KeyVault   ResourceID
+	URL        string
+	Attributes KeyVaultItemAttributes
+	// KeyType is the JSON Web Key type such as RSA, RSA-HSM, or EC
+	KeyType        string
+	KeySize        int32
+	CurveName      string
+	RotationPolicy KeyVaultKeyRotationPolicy
+}
+
+func (k *KeyVaultKey) QueryString() string {
+	kv, err := k.KeyVault.QueryString()
+	if err != nil {
+		return ""
+	}
+	return kv + "/Keys/" + k.Name
+}
+
+func (k *KeyVaultKey) FromAzure(az *armkeyvault.Key) {
+	gValFromPtr(&k.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&k.URL, props.KeyURI)
+	k.Attributes.fromAzureKey(props.Attributes)
+	if props.Kty != nil {
+		k.KeyType = string(*props.Kty)
+	}
+	gValFromPtr(&k.KeySize, props.KeySize)
+	if props.CurveName != nil {
+		k.CurveName = string(*props.CurveName)
+	}
+}
+
+type KeyVaultSecret struct {
+	Name        string
+	KeyVault    ResourceID
+	URL         string
+	Attributes  KeyVaultItemAttributes
+	ContentType string
+}
+
+func (s *KeyVaultSecret) QueryString() string {
+	kv, err := s.KeyVault.QueryString()
+	if err != nil {
+		return ""
+	}
+	return kv + "/Secrets/" + s.Name
+}
+
+func (s *KeyVaultSecret) FromAzure(az *armkeyvault.Secret) {
+	gValFromPtr(&s.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&s.URL, props.SecretURI)
+	gValFromPtr(&s.ContentType, props.ContentType)
+	s.Attributes.fromAzureSecret(props.Attributes)
+}
+
+type KeyVaultCertificate struct {
+	Name       string
+	KeyVault   ResourceID
+	URL        string
+	Attributes KeyVaultItemAttributes
+	// Thumbprint is the base64url encoded SHA-1 thumbprint
+	Thumbprint string
+	// Issuer is the issuer name from the policy, such as Self or an
+	// integrated CA.
+	Issuer         string
+	Subject        string
+	KeyType        string
+	KeySize        int32
+	CurveName      string
+	ValidityMonths int32
+}
+
+func (c *KeyVaultCertificate) QueryString() string {
+	kv, err := c.KeyVault.QueryString()
+	if err != nil {
+		return ""
+	}
+	return kv + "/Certificates/" + c.Name
+}
+
+func (c *KeyVaultCertificate) fromAzure(az *azKeyVaultCertificateItem) {
+	gValFromPtr(&c.URL, az.ID)
+	if c.URL != "" {
+		c.Name = c.URL[strings.LastIndex(c.URL, "/")+1:]
+	}
+	gValFromPtr(&c.Thumbprint, az.Thumbprint)
+	c.Attributes.fromAzureData(az.Attributes)
+}
+
+func (c *KeyVaultCertificate) fromAzurePolicy(az *azKeyVaultCertificatePolicy) {
+	if az.Issuer != nil {
+		gValFromPtr(&c.Issuer, az.Issuer.Name)
+	}
+	if kp := az.KeyProps; kp != nil {
+		gValFromPtr(&c.KeyType, kp.KeyType)
+		gValFromPtr(&c.KeySize, kp.KeySize)
+		gValFromPtr(&c.CurveName, kp.Curve)
+	}
+	if xp := az.X509Props; xp != nil {
+		gValFromPtr(&c.Subject, xp.Subject)
+		gValFromPtr(&c.ValidityMonths, xp.ValidityMonths)
+	}
+}
@@ -0,0 +1,159 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+)
+
+const keyVaultID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.KeyVault/vaults/vault"
+
+func TestKeyVaultFromAzure(t *testing.T) {
+	kv := NewEmptyKeyVault()
+	kv.FromAzure(&armkeyvault.Vault{
+		ID: ptr(keyVaultID),
+		Properties: &armkeyvault.VaultProperties{
+			VaultURI:                  ptr("https://vault.vault.azure.net/"),
+			EnableSoftDelete:          ptr(true),
+			SoftDeleteRetentionInDays: ptr[int32](90),
+			EnableRbacAuthorization:   ptr(true),
+			PublicNetworkAccess:       ptr("Disabled"),
+		},
+	})
+	if !kv.SoftDeleteEnabled.True() || kv.SoftDeleteRetentionDays != 90 ||
+		!kv.PurgeProtection.False() || !kv.RBACAuthorization.True() {
+		t.Fatalf("bad hardening settings: %+v", kv)
+	}
+	if !kv.Firewall.PublicNetworkAccess.False() {
+		t.Fatalf("expected public network access to be disabled: %+v", kv.Firewall)
+	}
+	if allowed, _, _ := kv.Firewall.AllowsIPString("8.8.8.8"); !allowed.False() {
+		t.Fatalf("expected 8.8.8.8 to be denied: %v", allowed)
+	}
+
+	impl := &azureImpl{}
+	client, err := impl.newKeyVaultRESTClient(kv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.endpoint != "https://vault.vault.azure.net" {
+		t.Fatalf("bad data plane endpoint: %s", client.endpoint)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.KeyVaults = append(rg.KeyVaults, kv)
+	sub.ResourceGroups[rgAName] = rg
+	if as := sub.GetAttackSurface(); len(as.KeyVaults) != 0 {
+		t.Fatalf("bad attack surface: %v", as.KeyVaults)
+	}
+}
+
+func TestKeyVaultItems(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).Unix()
+	rsa := armkeyvault.JSONWebKeyTypeRSA
+	var key KeyVaultKey
+	key.FromAzure(&armkeyvault.Key{
+		Name: ptr("weak"),
+		Properties: &armkeyvault.KeyProperties{
+			Kty:     &rsa,
+			KeySize: ptr[int32](1024),
+			Attributes: &armkeyvault.KeyAttributes{
+				Enabled: ptr(true),
+				Expires: &expired,
+			},
+		},
+	})
+	if key.KeyType != "RSA" || key.KeySize != 1024 || !key.Attributes.Expired.True() || !key.Attributes.NeverExpires.False() {
+		t.Fatalf("bad key: %+v", key)
+	}
+
+	var rp azkeys.KeyRotationPolicy
+	err := json.Unmarshal([]byte(`{
+		"lifetimeActions": [
+			{"trigger": {"timeBeforeExpiry": "P30D"}, "action": {"type": "Notify"}},
+			{"trigger": {"timeAfterCreate": "P90D"}, "action": {"type": "Rotate"}}
+		],
+		"attributes": {"expiryTime": "P1Y"}
+	}`), &rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.RotationPolicy.fromAzure(&rp)
+	if !key.RotationPolicy.AutoRotate.True() || key.RotationPolicy.RotateAfterCreate != "P90D" || key.RotationPolicy.ExpiresAfter != "P1Y" {
+		t.Fatalf("bad rotation policy: %+v", key.RotationPolicy)
+	}
+
+	var secret KeyVaultSecret
+	secret.FromAzure(&armkeyvault.Secret{
+		Name: ptr("password"),
+		Properties: &armkeyvault.SecretProperties{
+			SecretURI:  ptr("https://vault.vault.azure.net/secrets/password"),
+			Attributes: &armkeyvault.SecretAttributes{Enabled: ptr(true)},
+		},
+	})
+	if !secret.Attributes.NeverExpires.True() || !secret.Attributes.Expired.False() {
+		t.Fatalf("bad secret: %+v", secret)
+	}
+
+	var item azKeyVaultCertificateItem
+	err = json.Unmarshal([]byte(`{
+		"id": "https://vault.vault.azure.net/certificates/web",
+		"x5t": "fLi3U52HunIVNXubkEnf8tP6Wbo",
+		"attributes": {"enabled": true, "nbf": 1700000000, "exp": 4100000000}
+	}`), &item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pol azKeyVaultCertificatePolicy
+	err = json.Unmarshal([]byte(`{
+		"key_props": {"kty": "RSA", "key_size": 2048},
+		"x509_props": {"subject": "CN=example.com", "validity_months": 12},
+		"issuer": {"name": "Self"}
+	}`), &pol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cert KeyVaultCertificate
+	cert.fromAzure(&item)
+	cert.fromAzurePolicy(&pol)
+	if cert.Name != "web" || cert.Issuer != "Self" || cert.KeySize != 2048 || cert.Subject != "CN=example.com" {
+		t.Fatalf("bad certificate: %+v", cert)
+	}
+	if cert.Attributes.NotBefore.Unix() != 1700000000 || !cert.Attributes.Expired.False() {
+		t.Fatalf("bad certificate attributes: %+v", cert.Attributes)
+	}
+
+	kv := NewEmptyKeyVault()
+	kv.FromAzure(&armkeyvault.Vault{ID: ptr(keyVaultID)})
+	key.KeyVault = kv.Meta
+	if key.QueryString() != "/KeyVaults/rga/vault/Keys/weak" {
+		t.Fatalf("bad query string: %s", key.QueryString())
+	}
+	kv.Keys = append(kv.Keys, key)
+	kv.Secrets = append(kv.Secrets, secret)
+	kv.Certificates = append(kv.Certificates, cert)
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.KeyVaults = append(rg.KeyVaults, kv)
+	sub.ResourceGroups[rgAName] = rg
+
+	var secrets []KeyVaultSecret
+	if err := sub.FromQueryString("/KeyVaults/*/*/Secrets[.Attributes.NeverExpires == BoolTrue]", &secrets); err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "password" {
+		t.Fatalf("bad query string result: %v", secrets)
+	}
+	var keys []KeyVaultKey
+	if err := sub.FromQueryString("/KeyVaults/*/*/Keys[.KeySize < 2048]", &keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Name != "weak" {
+		t.Fatalf("bad query string result: %v", keys)
+	}
+}
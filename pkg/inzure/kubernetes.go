@@ -0,0 +1,189 @@
+package inzure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+//go:generate go run gen/enum.go -prefix KubernetesNetworkPlugin -values Azure,Kubenet,None -azure-type NetworkPlugin -azure-values NetworkPluginAzure,NetworkPluginKubenet,NetworkPluginNone -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2
+//go:generate go run gen/enum.go -prefix KubernetesNetworkPolicy -values Azure,Calico -azure-type NetworkPolicy -azure-values NetworkPolicyAzure,NetworkPolicyCalico -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2
+
+// KubernetesAADProfile is the Azure Active Directory integration of an AKS
+// cluster.
+type KubernetesAADProfile struct {
+	// Enabled is whether AAD integration is configured at all
+	Enabled UnknownBool
+	// Managed is whether this is the AKS managed AAD integration
+	Managed UnknownBool
+	// AzureRBAC is whether Azure RBAC is used for Kubernetes authorization
+	AzureRBAC           UnknownBool
+	TenantID            string
+	AdminGroupObjectIDs []string
+}
+
+func (p *KubernetesAADProfile) FromAzure(az *armcontainerservice.ManagedClusterAADProfile) {
+	if az == nil {
+		p.Enabled = BoolFalse
+		return
+	}
+	p.Enabled = BoolTrue
+	p.Managed.FromBoolPtr(az.Managed)
+	p.AzureRBAC.FromBoolPtr(az.EnableAzureRBAC)
+	gValFromPtr(&p.TenantID, az.TenantID)
+	for _, id := range az.AdminGroupObjectIDs {
+		if id != nil {
+			p.AdminGroupObjectIDs = append(p.AdminGroupObjectIDs, *id)
+		}
+	}
+}
+
+// KubernetesNodePool is a single agent pool in an AKS cluster.
+type KubernetesNodePool struct {
+	Name                string
+	Mode                string
+	OSType              string
+	VMSize              string
+	Count               int32
+	OrchestratorVersion string
+	// PublicIPs is whether every node in the pool gets its own public IP
+	PublicIPs        UnknownBool
+	PublicIPPrefix   ResourceID
+	Subnet           ResourceID
+	EncryptionAtHost UnknownBool
+}
+
+func (np *KubernetesNodePool) FromAzure(az *armcontainerservice.ManagedClusterAgentPoolProfile) {
+	np.PublicIPPrefix.setupEmpty()
+	np.Subnet.setupEmpty()
+	if az == nil {
+		return
+	}
+	gValFromPtr(&np.Name, az.Name)
+	if az.Mode != nil {
+		np.Mode = string(*az.Mode)
+	}
+	if az.OSType != nil {
+		np.OSType = string(*az.OSType)
+	}
+	gValFromPtr(&np.VMSize, az.VMSize)
+	gValFromPtr(&np.Count, az.Count)
+	gValFromPtr(&np.OrchestratorVersion, az.OrchestratorVersion)
+	np.PublicIPs.FromBoolPtr(az.EnableNodePublicIP)
+	np.EncryptionAtHost.FromBoolPtr(az.EnableEncryptionAtHost)
+	gValFromPtrFromAzure(&np.PublicIPPrefix, az.NodePublicIPPrefixID)
+	gValFromPtrFromAzure(&np.Subnet, az.VnetSubnetID)
+}
+
+// KubernetesCluster is an Azure Kubernetes Service managed cluster.
+type KubernetesCluster struct {
+	Meta              ResourceID
+	KubernetesVersion string
+	// FQDN is the API server's public FQDN. For private clusters this is
+	// empty unless a public FQDN was requested, in which case it resolves to
+	// the private address.
+	FQDN        string
+	PrivateFQDN string
+	// PrivateCluster is whether the API server is only reachable from inside
+	// the cluster's virtual network.
+	PrivateCluster      UnknownBool
+	PublicNetworkAccess UnknownBool
+	// AuthorizedIPRanges are the IP ranges allowed to reach the API server.
+	// An empty collection allows everything, which is also how an
+	// IPCollection behaves as a Firewall.
+	AuthorizedIPRanges    IPCollection
+	LocalAccountsDisabled UnknownBool
+	RBACEnabled           UnknownBool
+	AAD                   KubernetesAADProfile
+	NetworkPlugin         KubernetesNetworkPlugin
+	NetworkPolicy         KubernetesNetworkPolicy
+	NodeResourceGroup     string
+	NodePools             []KubernetesNodePool
+	Identity              ManagedIdentity
+}
+
+func NewEmptyKubernetesCluster() *KubernetesCluster {
+	var rid ResourceID
+	rid.setupEmpty()
+	kc := &KubernetesCluster{
+		Meta:               rid,
+		AuthorizedIPRanges: make(IPCollection, 0),
+		AAD: KubernetesAADProfile{
+			AdminGroupObjectIDs: make([]string, 0),
+		},
+		NodePools: make([]KubernetesNodePool, 0),
+	}
+	kc.Identity.setupEmpty()
+	return kc
+}
+
+func (kc *KubernetesCluster) FromAzure(az *armcontainerservice.ManagedCluster) {
+	if az.ID == nil {
+		return
+	}
+	kc.Meta.FromID(*az.ID)
+	kc.Identity.FromAzureKubernetes(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&kc.KubernetesVersion, props.CurrentKubernetesVersion)
+	if kc.KubernetesVersion == "" {
+		gValFromPtr(&kc.KubernetesVersion, props.KubernetesVersion)
+	}
+	gValFromPtr(&kc.FQDN, props.Fqdn)
+	gValFromPtr(&kc.PrivateFQDN, props.PrivateFQDN)
+	gValFromPtr(&kc.NodeResourceGroup, props.NodeResourceGroup)
+	kc.LocalAccountsDisabled.FromBoolPtr(props.DisableLocalAccounts)
+	kc.RBACEnabled.FromBoolPtr(props.EnableRBAC)
+	kc.AAD.FromAzure(props.AADProfile)
+	if props.PublicNetworkAccess != nil {
+		kc.PublicNetworkAccess = ubFromRhsPtr(armcontainerservice.PublicNetworkAccessEnabled, props.PublicNetworkAccess)
+	}
+
+	if ap := props.APIServerAccessProfile; ap != nil {
+		kc.PrivateCluster.FromBoolPtr(ap.EnablePrivateCluster)
+		for _, r := range ap.AuthorizedIPRanges {
+			if r != nil {
+				kc.AuthorizedIPRanges = append(kc.AuthorizedIPRanges, NewAzureIPv4FromAzure(*r))
+			}
+		}
+	} else {
+		kc.PrivateCluster = BoolFalse
+	}
+
+	if np := props.NetworkProfile; np != nil {
+		kc.NetworkPlugin.FromAzure(np.NetworkPlugin)
+		kc.NetworkPolicy.FromAzure(np.NetworkPolicy)
+	}
+
+	gSliceFromPtrSetterPtrs(&kc.NodePools, &props.AgentPoolProfiles, func(np *KubernetesNodePool, az *armcontainerservice.ManagedClusterAgentPoolProfile) {
+		np.FromAzure(az)
+	})
+}
+
+// PublicAPIServer returns whether the API server can be reached from the
+// internet. Authorized IP ranges still apply, see AuthorizedIPRanges.
+func (kc *KubernetesCluster) PublicAPIServer() UnknownBool {
+	if kc.PrivateCluster.True() || kc.PublicNetworkAccess.False() {
+		return BoolFalse
+	}
+	if kc.PrivateCluster.False() {
+		return BoolTrue
+	}
+	return BoolUnknown
+}
+
+// HasPublicNodes returns whether any node pool gives its nodes public IPs.
+func (kc *KubernetesCluster) HasPublicNodes() UnknownBool {
+	uncertain := false
+	for _, np := range kc.NodePools {
+		if np.PublicIPs.True() {
+			return BoolTrue
+		} else if np.PublicIPs.Unknown() {
+			uncertain = true
+		}
+	}
+	if uncertain {
+		return BoolUnknown
+	}
+	return BoolFalse
+}
@@ -0,0 +1,103 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+const aksID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.ContainerService/managedClusters/aks"
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestKubernetesClusterFromAzure(t *testing.T) {
+	plugin := armcontainerservice.NetworkPluginAzure
+	policy := armcontainerservice.NetworkPolicyCalico
+	az := &armcontainerservice.ManagedCluster{
+		ID: ptr(aksID),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			KubernetesVersion:    ptr("1.29"),
+			Fqdn:                 ptr("aks.hcp.eastus.azmk8s.io"),
+			DisableLocalAccounts: ptr(true),
+			EnableRBAC:           ptr(true),
+			AADProfile: &armcontainerservice.ManagedClusterAADProfile{
+				Managed:             ptr(true),
+				EnableAzureRBAC:     ptr(false),
+				AdminGroupObjectIDs: []*string{ptr("group")},
+			},
+			APIServerAccessProfile: &armcontainerservice.ManagedClusterAPIServerAccessProfile{
+				EnablePrivateCluster: ptr(false),
+				AuthorizedIPRanges:   []*string{ptr("10.0.0.0/8")},
+			},
+			NetworkProfile: &armcontainerservice.NetworkProfile{
+				NetworkPlugin: &plugin,
+				NetworkPolicy: &policy,
+			},
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: ptr("system"), Count: ptr(int32(3)), EnableNodePublicIP: ptr(false)},
+				{Name: ptr("public"), EnableNodePublicIP: ptr(true)},
+			},
+		},
+	}
+	kc := NewEmptyKubernetesCluster()
+	kc.FromAzure(az)
+	if kc.Meta.Tag != KubernetesClusterT || kc.Meta.Name != "aks" {
+		t.Fatalf("bad meta: %+v", kc.Meta)
+	}
+	if kc.KubernetesVersion != "1.29" || !kc.LocalAccountsDisabled.True() || !kc.RBACEnabled.True() {
+		t.Fatalf("bad cluster: %+v", kc)
+	}
+	if !kc.AAD.Enabled.True() || !kc.AAD.Managed.True() || !kc.AAD.AzureRBAC.False() || len(kc.AAD.AdminGroupObjectIDs) != 1 {
+		t.Fatalf("bad AAD profile: %+v", kc.AAD)
+	}
+	if kc.NetworkPlugin != KubernetesNetworkPluginAzure || kc.NetworkPolicy != KubernetesNetworkPolicyCalico {
+		t.Fatalf("bad network profile: %s %s", kc.NetworkPlugin, kc.NetworkPolicy)
+	}
+	if len(kc.NodePools) != 2 || kc.NodePools[0].Count != 3 || !kc.HasPublicNodes().True() {
+		t.Fatalf("bad node pools: %+v", kc.NodePools)
+	}
+	if !kc.PublicAPIServer().True() {
+		t.Fatalf("expected a public API server")
+	}
+
+	var fw Firewall = kc.AuthorizedIPRanges
+	if allowed, _, _ := fw.AllowsIPString("10.1.2.3"); !allowed.True() {
+		t.Fatal("expected 10.1.2.3 to be allowed")
+	}
+	if allowed, _, _ := fw.AllowsIPString("8.8.8.8"); !allowed.False() {
+		t.Fatal("expected 8.8.8.8 to be denied")
+	}
+}
+
+func TestKubernetesClusterAttackSurface(t *testing.T) {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+
+	public := NewEmptyKubernetesCluster()
+	public.Meta = newResourceId(rgAName, KubernetesClusterT, "public")
+	public.FQDN = "public.hcp.eastus.azmk8s.io"
+	public.PrivateCluster = BoolFalse
+
+	private := NewEmptyKubernetesCluster()
+	private.Meta = newResourceId(rgAName, KubernetesClusterT, "private")
+	private.FQDN = "private.hcp.eastus.azmk8s.io"
+	private.PrivateCluster = BoolTrue
+
+	rg.KubernetesClusters = append(rg.KubernetesClusters, public, private)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.KubernetesClusters) != 1 || as.KubernetesClusters[0] != public.FQDN {
+		t.Fatalf("expected only the public cluster: %v", as.KubernetesClusters)
+	}
+
+	var clusters []*KubernetesCluster
+	if err := sub.FromQueryString("/KubernetesClusters[.PrivateCluster != BoolTrue]", &clusters); err != nil {
+		t.Fatalf("query string failed: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Meta.Name != "public" {
+		t.Fatalf("bad query string results: %v", clusters)
+	}
+}
@@ -0,0 +1,98 @@
+package inzure
+
+// MySQLServer is an Azure Database for MySQL single server.
+type MySQLServer struct {
+	Meta                ResourceID
+	Version             string
+	FQDN                string
+	AdminUser           string
+	SSLEnforced         UnknownBool
+	MinimumTLSVersion   TLSVersion
+	PublicNetworkAccess UnknownBool
+	AADAdmins           []DatabaseAADAdmin
+	Firewall            FirewallRules
+	Subnets             []ResourceID
+	Identity            ManagedIdentity
+}
+
+func NewEmptyMySQLServer() *MySQLServer {
+	s := &MySQLServer{
+		AADAdmins: make([]DatabaseAADAdmin, 0),
+		Firewall:  make(FirewallRules, 0),
+		Subnets:   make([]ResourceID, 0),
+	}
+	s.Meta.setupEmpty()
+	s.Identity.setupEmpty()
+	return s
+}
+
+func (ms *MySQLServer) fromAzure(az *azDBServer) {
+	if az.ID == nil {
+		return
+	}
+	ms.Meta.FromID(*az.ID)
+	ms.Identity.fromAzureData(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ms.Version, props.Version)
+	gValFromPtr(&ms.FQDN, props.FullyQualifiedDomainName)
+	gValFromPtr(&ms.AdminUser, props.AdministratorLogin)
+	ms.SSLEnforced = dbEnabledString(props.SSLEnforcement)
+	ms.MinimumTLSVersion.FromAzureMySQL(props.MinimalTLSVersion)
+	ms.PublicNetworkAccess = dbEnabledString(props.PublicNetworkAccess)
+}
+
+// MySQLFlexibleServer is an Azure Database for MySQL Flexible Server.
+// Servers with a DelegatedSubnet are VNet integrated and the Firewall
+// doesn't apply to them.
+type MySQLFlexibleServer struct {
+	Meta      ResourceID
+	Version   string
+	FQDN      string
+	AdminUser string
+	// SSLEnforced is the require_secure_transport server parameter
+	SSLEnforced         UnknownBool
+	MinimumTLSVersion   TLSVersion
+	PublicNetworkAccess UnknownBool
+	DelegatedSubnet     ResourceID
+	AADAdmins           []DatabaseAADAdmin
+	Firewall            FirewallRules
+	Identity            ManagedIdentity
+}
+
+func NewEmptyMySQLFlexibleServer() *MySQLFlexibleServer {
+	s := &MySQLFlexibleServer{
+		AADAdmins: make([]DatabaseAADAdmin, 0),
+		Firewall:  make(FirewallRules, 0),
+	}
+	s.Meta.setupEmpty()
+	s.DelegatedSubnet.setupEmpty()
+	s.Identity.setupEmpty()
+	return s
+}
+
+func (ms *MySQLFlexibleServer) fromAzure(az *azDBServer) {
+	if az.ID == nil {
+		return
+	}
+	ms.Meta.FromID(*az.ID)
+	ms.Identity.fromAzureData(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ms.Version, props.Version)
+	gValFromPtr(&ms.FQDN, props.FullyQualifiedDomainName)
+	gValFromPtr(&ms.AdminUser, props.AdministratorLogin)
+	flexibleServerNetwork(az, &ms.PublicNetworkAccess, &ms.DelegatedSubnet)
+}
+
+// IsPublic is whether the server can be reached from the internet at all.
+func (ms *MySQLFlexibleServer) IsPublic() UnknownBool {
+	if ms.DelegatedSubnet.Tag != ResourceUnsetT {
+		return BoolFalse
+	}
+	return ms.PublicNetworkAccess
+}
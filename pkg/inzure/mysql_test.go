@@ -0,0 +1,132 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const (
+	mysqlID         = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.DBforMySQL/servers/single"
+	mysqlFlexibleID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.DBforMySQL/flexibleServers/flex"
+	pgFlexibleID    = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.DBforPostgreSQL/flexibleServers/pgflex"
+)
+
+func unmarshalDBServer(t *testing.T, s string) *azDBServer {
+	var az azDBServer
+	if err := json.Unmarshal([]byte(s), &az); err != nil {
+		t.Fatal(err)
+	}
+	return &az
+}
+
+func TestDatabaseServerTags(t *testing.T) {
+	for id, tag := range map[string]AzureResourceTag{
+		mysqlID:         MySQLServerT,
+		mysqlFlexibleID: MySQLFlexibleServerT,
+		pgFlexibleID:    PostgresFlexibleServerT,
+		"/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.DBforPostgreSQL/servers/pg": PostgresServerT,
+		"/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Sql/servers/sql":            SQLServerT,
+	} {
+		var rid ResourceID
+		rid.FromID(id)
+		if rid.Tag != tag {
+			t.Errorf("expected %s for %s, got %s", tag, id, rid.Tag)
+		}
+	}
+}
+
+func TestMySQLServerFromAzure(t *testing.T) {
+	ms := NewEmptyMySQLServer()
+	ms.fromAzure(unmarshalDBServer(t, `{
+		"id": "`+mysqlID+`",
+		"properties": {
+			"version": "5.7",
+			"fullyQualifiedDomainName": "single.mysql.database.azure.com",
+			"administratorLogin": "admin",
+			"sslEnforcement": "Disabled",
+			"minimalTlsVersion": "TLSEnforcementDisabled",
+			"publicNetworkAccess": "Enabled"
+		}
+	}`))
+	if ms.FQDN != "single.mysql.database.azure.com" || ms.AdminUser != "admin" || ms.Version != "5.7" {
+		t.Fatalf("bad server: %+v", ms)
+	}
+	if !ms.SSLEnforced.False() || ms.MinimumTLSVersion != TLSVersionOneZero || !ms.PublicNetworkAccess.True() {
+		t.Fatalf("bad security settings: %+v", ms)
+	}
+
+	var rule azDBFirewallRule
+	if err := json.Unmarshal([]byte(`{"name": "AllowAllWindowsAzureIps", "properties": {"startIpAddress": "0.0.0.0", "endIpAddress": "0.0.0.0"}}`), &rule); err != nil {
+		t.Fatal(err)
+	}
+	var fwr FirewallRule
+	fwr.fromAzureDB(&rule)
+	if !fwr.AllowsAllAzure.True() {
+		t.Fatalf("expected the rule to allow all of Azure: %+v", fwr)
+	}
+}
+
+func TestFlexibleServersFromAzure(t *testing.T) {
+	ms := NewEmptyMySQLFlexibleServer()
+	ms.fromAzure(unmarshalDBServer(t, `{
+		"id": "`+mysqlFlexibleID+`",
+		"properties": {
+			"fullyQualifiedDomainName": "flex.mysql.database.azure.com",
+			"network": {
+				"publicNetworkAccess": "Disabled",
+				"delegatedSubnetResourceId": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/vnet/subnets/mysql"
+			}
+		}
+	}`))
+	if ms.DelegatedSubnet.Tag != SubnetT || !ms.IsPublic().False() {
+		t.Fatalf("expected a VNet integrated server: %+v", ms)
+	}
+	ms.MinimumTLSVersion.FromAzureServerParameter(ptr("TLSv1.3,TLSv1.2"))
+	if ms.MinimumTLSVersion != TLSVersionOneTwo {
+		t.Fatalf("bad minimum TLS version: %v", ms.MinimumTLSVersion)
+	}
+
+	ps := NewEmptyPostgresFlexibleServer()
+	ps.fromAzure(unmarshalDBServer(t, `{
+		"id": "`+pgFlexibleID+`",
+		"properties": {
+			"version": "16",
+			"fullyQualifiedDomainName": "pgflex.postgres.database.azure.com",
+			"network": {"publicNetworkAccess": "Enabled"},
+			"authConfig": {"activeDirectoryAuth": "Enabled", "passwordAuth": "Disabled"}
+		}
+	}`))
+	if !ps.IsPublic().True() || !ps.AADAuthEnabled.True() || !ps.PasswordAuthEnabled.False() {
+		t.Fatalf("bad postgres server: %+v", ps)
+	}
+	ps.SSLEnforced = dbOnOffString(ptr("off"))
+
+	var admin azDBAdministrator
+	if err := json.Unmarshal([]byte(`{"properties": {"principalName": "dba@example.com", "objectId": "1234", "principalType": "User"}}`), &admin); err != nil {
+		t.Fatal(err)
+	}
+	var a DatabaseAADAdmin
+	a.fromAzure(&admin)
+	if a.Login != "dba@example.com" || a.ObjectID != "1234" || a.PrincipalType != "User" {
+		t.Fatalf("bad admin: %+v", a)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.MySQLFlexibleServers = append(rg.MySQLFlexibleServers, ms)
+	rg.PostgresFlexibleServers = append(rg.PostgresFlexibleServers, ps)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.MySQL) != 0 || len(as.PostgreSQL) != 1 || as.PostgreSQL[0] != ps.FQDN {
+		t.Fatalf("bad attack surface: %v %v", as.MySQL, as.PostgreSQL)
+	}
+
+	var into []*PostgresFlexibleServer
+	if err := sub.FromQueryString("/PostgresFlexibleServers", &into); err != nil {
+		t.Fatal(err)
+	}
+	if len(into) != 1 || !into[0].SSLEnforced.False() {
+		t.Fatalf("bad query string result: %v", into)
+	}
+}
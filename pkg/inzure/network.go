@@ -55,6 +55,14 @@ func (p *PublicIP) FromAzure(ap *armnetwork.PublicIPAddress) {
 	}
 }
 
+// address is the FQDN if there is one and the IP otherwise.
+func (p *PublicIP) address() string {
+	if p.FQDN != "" {
+		return p.FQDN
+	}
+	return p.IP
+}
+
 // IPConfiguration is the IPConfiguration of a NetworkInterface.
 type IPConfiguration struct {
 	Meta      ResourceID
@@ -198,6 +206,15 @@ func (s *SecurityRule) FromAzure(az *armnetwork.SecurityRule) {
 			NewAzureIPv4FromAzure(*props.SourceAddressPrefix),
 		)
 	}
+	if props.SourceApplicationSecurityGroups != nil {
+		var r ResourceID
+		for _, asg := range props.SourceApplicationSecurityGroups {
+			if asg.ID != nil {
+				r.fromID(*asg.ID)
+				s.SourceIPs = append(s.SourceIPs, NewAzureIPv4FromAzure(asgIPName(r)))
+			}
+		}
+	}
 	if props.DestinationAddressPrefixes != nil && len(props.DestinationAddressPrefixes) > 0 {
 		for _, ip := range props.DestinationAddressPrefixes {
 			if ip != nil && len(*ip) > 0 {
@@ -216,8 +233,7 @@ func (s *SecurityRule) FromAzure(az *armnetwork.SecurityRule) {
 		for _, asg := range props.DestinationApplicationSecurityGroups {
 			if asg.ID != nil {
 				r.fromID(*asg.ID)
-				name := strings.Replace(r.Name, "-", "_", -1)
-				s.DestIPs = append(s.DestIPs, NewAzureIPv4FromAzure(name))
+				s.DestIPs = append(s.DestIPs, NewAzureIPv4FromAzure(asgIPName(r)))
 			}
 		}
 	}
@@ -255,6 +271,12 @@ func (s *SecurityRule) FromAzure(az *armnetwork.SecurityRule) {
 	}
 }
 
+// asgIPName is the name used for the special AzureIPv4 that stands in for an
+// ApplicationSecurityGroup in a SecurityRule.
+func asgIPName(asg ResourceID) string {
+	return strings.Replace(asg.Name, "-", "_", -1)
+}
+
 // A VirtualNetwork holds all networking information about the subscription.
 type VirtualNetwork struct {
 	Meta                  ResourceID
@@ -262,6 +284,7 @@ type VirtualNetwork struct {
 	VMProtectionEnabled   UnknownBool
 	DDoSProtectionEnabled UnknownBool
 	Subnets               []Subnet
+	Peerings              []VirtualNetworkPeering
 }
 
 // UnmarshalJSON is used to deal with AzureIPv4s
@@ -271,12 +294,14 @@ func (v *VirtualNetwork) UnmarshalJSON(b []byte) error {
 		VMProtectionEnabled   *UnknownBool
 		DDoSProtectionEnabled *UnknownBool
 		Subnets               *[]Subnet
+		Peerings              *[]VirtualNetworkPeering
 		AddressSpaces         []string
 	}{
 		Meta:                  &v.Meta,
 		VMProtectionEnabled:   &v.VMProtectionEnabled,
 		DDoSProtectionEnabled: &v.DDoSProtectionEnabled,
 		Subnets:               &v.Subnets,
+		Peerings:              &v.Peerings,
 		AddressSpaces:         make([]string, 0),
 	}
 	err := json.Unmarshal(b, &tmp)
@@ -294,6 +319,7 @@ func NewEmptyVirtualNetwork() *VirtualNetwork {
 	vn := &VirtualNetwork{
 		AddressSpaces: make([]AzureIPv4, 0),
 		Subnets:       make([]Subnet, 0),
+		Peerings:      make([]VirtualNetworkPeering, 0),
 	}
 	vn.Meta.setupEmpty()
 	return vn
@@ -330,6 +356,11 @@ func (v *VirtualNetwork) FromAzure(az *armnetwork.VirtualNetwork) {
 			}
 		}
 	}
+	gSliceFromPtrSetterPtrs(
+		&v.Peerings,
+		&props.VirtualNetworkPeerings,
+		fromAzureSetter[armnetwork.VirtualNetworkPeering, *VirtualNetworkPeering],
+	)
 }
 
 type Subnet struct {
@@ -337,10 +368,16 @@ type Subnet struct {
 	AddressRange string
 	//VirtualNetwork string
 	IPConfigurationRefs []ResourceID
+	// RouteTable is the user defined RouteTable for the subnet, if any
+	RouteTable ResourceID
+	// NATGateway is used for outbound internet traffic from the subnet if set
+	NATGateway ResourceID
 }
 
 func (s *Subnet) setupEmpty() {
 	s.Meta.setupEmpty()
+	s.RouteTable.setupEmpty()
+	s.NATGateway.setupEmpty()
 	s.IPConfigurationRefs = make([]ResourceID, 0)
 }
 
@@ -374,9 +411,11 @@ func (s *Subnet) FromAzure(as *armnetwork.Subnet) {
 	if props.AddressPrefix != nil {
 		s.AddressRange = *props.AddressPrefix
 	}
-	// TODO: Need to figure out how to add this. Route tables could be
-	// important.
-	if props.RouteTable != nil {
+	if props.RouteTable != nil && props.RouteTable.ID != nil {
+		s.RouteTable.fromID(*props.RouteTable.ID)
+	}
+	if props.NatGateway != nil && props.NatGateway.ID != nil {
+		s.NATGateway.fromID(*props.NatGateway.ID)
 	}
 	//  TODO: Need to figure out how to deal with this.
 	if props.ServiceEndpoints != nil {
@@ -699,6 +738,17 @@ func (ippc IPPortCollection) Less(i, j int) bool {
 	if xj.IP.IsSpecial() {
 		return false
 	}
+	// The uint32 comparisons below are IPv4 only
+	if xi.IP.Family() != AzureIPFamilyIPv4 || xj.IP.Family() != AzureIPFamilyIPv4 {
+		xiRanges, xjRanges := xi.IP.AddrRanges(), xj.IP.AddrRanges()
+		if len(xiRanges) == 0 || len(xjRanges) == 0 {
+			return len(xiRanges) < len(xjRanges)
+		}
+		if xiRanges[0].Begin != xjRanges[0].Begin {
+			return xiRanges[0].Begin.Less(xjRanges[0].Begin)
+		}
+		return xi.IP.Size() < xj.IP.Size()
+	}
 	if xi.IP.Size() == 1 && xj.IP.Size() == 1 {
 		if xi.Port.Size() == 1 && xj.Port.Size() == 1 {
 			return xi.IP.AsUint32() < xj.IP.AsUint32() && xi.Port.AsUint16() < xj.Port.AsUint16()
@@ -479,3 +479,38 @@ func TestNetworkSecurityGroupAllowsIPUnknown(t *testing.T) {
 	}
 	doNSGAllowsIPTest(t, "10.1.2.5", shouldAllow, BoolUnknown, nil)
 }
+
+func TestNetworkSecurityGroupIPv6(t *testing.T) {
+	rules := []SecurityRule{
+		SecurityRule{
+			Allows:      true,
+			Inbound:     true,
+			Priority:    200,
+			Protocol:    ProtocolTCP,
+			SourceIPs:   createIPs("*"),
+			SourcePorts: createPorts("*"),
+			DestIPs:     createIPs("2001:db8:ffff::10"),
+			DestPorts:   createPorts("443"),
+		},
+		SecurityRule{
+			Allows:      false,
+			Inbound:     true,
+			Priority:    100,
+			Protocol:    ProtocolTCP,
+			SourceIPs:   createIPs("2001:db8::/32"),
+			SourcePorts: createPorts("*"),
+			DestIPs:     createIPs("2001:db8:ffff::10"),
+			DestPorts:   createPorts("443"),
+		},
+	}
+	dests := []PacketRoute{
+		PacketRoute{
+			Protocol: ProtocolTCP,
+			IPs:      createIPs("2001:db8:ffff::10"),
+			Ports:    createPorts("443"),
+		},
+	}
+	doNSGAllowsIPToPortTest(t, "2001:db8::5", "443", rules, BoolFalse, nil)
+	doNSGAllowsIPToPortTest(t, "2001:db9::5", "443", rules, BoolTrue, dests)
+	doNSGAllowsIPToPortTest(t, "10.0.0.1", "443", rules, BoolTrue, dests)
+}
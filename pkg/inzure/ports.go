@@ -601,6 +601,32 @@ func PortsEqual(a AzurePort, b AzurePort) bool {
 	return true
 }
 
+// PortsOverlap checks whether there is any port contained in both of the
+// given AzurePorts.
+func PortsOverlap(a AzurePort, b AzurePort) bool {
+	if a == nil || b == nil || a.Size() == 0 || b.Size() == 0 {
+		return false
+	}
+	if a.IsStar() || b.IsStar() {
+		return true
+	}
+	aCont, aBegin, aEnd := a.ContinuousRange()
+	bCont, bBegin, bEnd := b.ContinuousRange()
+	if aCont && bCont {
+		return aBegin <= bEnd && bBegin <= aEnd
+	}
+	// Walk the smaller of the two
+	if a.Size() > b.Size() {
+		a, b = b, a
+	}
+	for _, p := range a.AllPorts() {
+		if b.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
 func PortContains(in AzurePort, find AzurePort) bool {
 	inSize := in.Size()
 	findSize := find.Size()
@@ -139,3 +139,26 @@ func TestAsteriskAzurePort(t *testing.T) {
 	}
 	testPort(t, port, shouldContain, nil, azure, false, false, true)
 }
+
+func TestPortsOverlap(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{"80", "80", true},
+		{"80", "443", false},
+		{"1000-2000", "1500-2500", true},
+		{"1000-2000", "2001-2500", false},
+		{"22,80,443", "440-450", true},
+		{"22,80,443", "23-79", false},
+		{"*", "8080", true},
+	}
+	for _, test := range tests {
+		a := NewPortFromAzure(test.a)
+		b := NewPortFromAzure(test.b)
+		if actual := PortsOverlap(a, b); actual != test.expected {
+			t.Fatalf("expected PortsOverlap(%s, %s) to be %v but got %v", a, b, test.expected, actual)
+		}
+	}
+}
@@ -11,6 +11,7 @@ type PostgresServer struct {
 	SSLEnforced UnknownBool
 	Firewall    FirewallRules
 	Subnets     []ResourceID
+	Identity    ManagedIdentity
 }
 
 func NewEmptyPostgresServer() *PostgresServer {
@@ -20,6 +21,7 @@ func NewEmptyPostgresServer() *PostgresServer {
 		Subnets:   make([]ResourceID, 0),
 	}
 	s.Meta.setupEmpty()
+	s.Identity.setupEmpty()
 	return s
 }
 
@@ -28,6 +30,7 @@ func (ps *PostgresServer) FromAzure(az *armpostgresql.Server) {
 		return
 	}
 	ps.Meta.FromID(*az.ID)
+	ps.Identity.FromAzurePostgres(az.Identity)
 	ps.Meta.Tag = PostgresServerT
 	props := az.Properties
 	if props == nil {
@@ -51,3 +54,62 @@ func (psd *PostgresDB) FromAzure(az *armpostgresql.Database) {
 	psd.Meta.FromID(*az.ID)
 	psd.Meta.Tag = PostgresDBT
 }
+
+// PostgresFlexibleServer is an Azure Database for PostgreSQL Flexible Server.
+// Servers with a DelegatedSubnet are VNet integrated and the Firewall
+// doesn't apply to them.
+type PostgresFlexibleServer struct {
+	Meta      ResourceID
+	Version   string
+	FQDN      string
+	AdminUser string
+	// SSLEnforced is the require_secure_transport server parameter
+	SSLEnforced         UnknownBool
+	MinimumTLSVersion   TLSVersion
+	PublicNetworkAccess UnknownBool
+	DelegatedSubnet     ResourceID
+	PasswordAuthEnabled UnknownBool
+	AADAuthEnabled      UnknownBool
+	AADAdmins           []DatabaseAADAdmin
+	Firewall            FirewallRules
+	Identity            ManagedIdentity
+}
+
+func NewEmptyPostgresFlexibleServer() *PostgresFlexibleServer {
+	s := &PostgresFlexibleServer{
+		AADAdmins: make([]DatabaseAADAdmin, 0),
+		Firewall:  make(FirewallRules, 0),
+	}
+	s.Meta.setupEmpty()
+	s.DelegatedSubnet.setupEmpty()
+	s.Identity.setupEmpty()
+	return s
+}
+
+func (ps *PostgresFlexibleServer) fromAzure(az *azDBServer) {
+	if az.ID == nil {
+		return
+	}
+	ps.Meta.FromID(*az.ID)
+	ps.Identity.fromAzureData(az.Identity)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ps.Version, props.Version)
+	gValFromPtr(&ps.FQDN, props.FullyQualifiedDomainName)
+	gValFromPtr(&ps.AdminUser, props.AdministratorLogin)
+	flexibleServerNetwork(az, &ps.PublicNetworkAccess, &ps.DelegatedSubnet)
+	if props.AuthConfig != nil {
+		ps.PasswordAuthEnabled = dbEnabledString(props.AuthConfig.PasswordAuth)
+		ps.AADAuthEnabled = dbEnabledString(props.AuthConfig.ActiveDirectoryAuth)
+	}
+}
+
+// IsPublic is whether the server can be reached from the internet at all.
+func (ps *PostgresFlexibleServer) IsPublic() UnknownBool {
+	if ps.DelegatedSubnet.Tag != ResourceUnsetT {
+		return BoolFalse
+	}
+	return ps.PublicNetworkAccess
+}
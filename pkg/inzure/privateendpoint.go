@@ -0,0 +1,233 @@
+package inzure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+)
+
+// PrivateEndpoint puts a private IP for another resource, such as a storage
+// account or key vault, into a subnet.
+type PrivateEndpoint struct {
+	Meta   ResourceID
+	Subnet ResourceID
+	// NetworkInterfaces are the NICs created for the endpoint. Their private
+	// IPs are where the target resource can be reached in the VNet.
+	NetworkInterfaces []NetworkInterface
+	Connections       []PrivateEndpointConnection
+	DNSConfigs        []PrivateEndpointDNSConfig
+}
+
+func NewEmptyPrivateEndpoint() *PrivateEndpoint {
+	pe := &PrivateEndpoint{
+		NetworkInterfaces: make([]NetworkInterface, 0),
+		Connections:       make([]PrivateEndpointConnection, 0),
+		DNSConfigs:        make([]PrivateEndpointDNSConfig, 0),
+	}
+	pe.Meta.setupEmpty()
+	pe.Subnet.setupEmpty()
+	return pe
+}
+
+func (pe *PrivateEndpoint) FromAzure(az *armnetwork.PrivateEndpoint) {
+	if az.ID == nil {
+		return
+	}
+	pe.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.Subnet != nil && props.Subnet.ID != nil {
+		pe.Subnet.fromID(*props.Subnet.ID)
+	}
+	for _, nic := range props.NetworkInterfaces {
+		if nic == nil || nic.ID == nil {
+			continue
+		}
+		ni := NewEmptyNetworkInterface()
+		ni.Meta.fromID(*nic.ID)
+		pe.NetworkInterfaces = append(pe.NetworkInterfaces, *ni)
+	}
+	for _, c := range props.PrivateLinkServiceConnections {
+		pe.addConnection(c, false)
+	}
+	for _, c := range props.ManualPrivateLinkServiceConnections {
+		pe.addConnection(c, true)
+	}
+	gSliceFromPtrSetterPtrs(
+		&pe.DNSConfigs,
+		&props.CustomDNSConfigs,
+		fromAzureSetter[armnetwork.CustomDNSConfigPropertiesFormat, *PrivateEndpointDNSConfig],
+	)
+}
+
+func (pe *PrivateEndpoint) addConnection(az *armnetwork.PrivateLinkServiceConnection, manual bool) {
+	if az == nil {
+		return
+	}
+	var c PrivateEndpointConnection
+	c.FromAzure(az)
+	c.Manual = manual
+	pe.Connections = append(pe.Connections, c)
+}
+
+// PrivateIPs returns all of the private IPs of the endpoint, from both its
+// network interfaces and its DNS configs.
+func (pe *PrivateEndpoint) PrivateIPs() []string {
+	seen := make(map[string]struct{})
+	ips := make([]string, 0)
+	add := func(ip string) {
+		if _, has := seen[ip]; ip == "" || has {
+			return
+		}
+		seen[ip] = struct{}{}
+		ips = append(ips, ip)
+	}
+	for _, nic := range pe.NetworkInterfaces {
+		for _, ipc := range nic.IPConfigurations {
+			add(ipc.PrivateIP)
+		}
+	}
+	for _, dc := range pe.DNSConfigs {
+		for _, ip := range dc.IPs {
+			add(ip)
+		}
+	}
+	return ips
+}
+
+// ConnectsTo checks whether the endpoint has an approved connection to the
+// given resource.
+func (pe *PrivateEndpoint) ConnectsTo(id *ResourceID) bool {
+	for _, c := range pe.Connections {
+		if c.Approved() && strings.EqualFold(c.Target.RawID, id.RawID) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrivateEndpointConnection is the link from a PrivateEndpoint to the
+// resource it exposes.
+type PrivateEndpointConnection struct {
+	Name   string
+	Target ResourceID
+	// GroupIDs are the sub resources that are exposed, such as blob or vault
+	GroupIDs []string
+	// Status is Approved, Pending, Rejected, or Disconnected
+	Status string
+	// Manual connections need to be approved by the owner of the target
+	Manual bool
+}
+
+func (c *PrivateEndpointConnection) FromAzure(az *armnetwork.PrivateLinkServiceConnection) {
+	c.Target.setupEmpty()
+	c.GroupIDs = make([]string, 0)
+	gValFromPtr(&c.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.PrivateLinkServiceID != nil {
+		c.Target.fromID(*props.PrivateLinkServiceID)
+	}
+	appendStrings(&c.GroupIDs, props.GroupIDs)
+	if cs := props.PrivateLinkServiceConnectionState; cs != nil {
+		gValFromPtr(&c.Status, cs.Status)
+	}
+}
+
+// Approved is whether traffic can flow over the connection
+func (c *PrivateEndpointConnection) Approved() bool {
+	return strings.EqualFold(c.Status, "Approved")
+}
+
+// appendApprovedPrivateEndpoint is for resources that list their own private
+// endpoint connections. It adds the endpoint if the connection is approved.
+func appendApprovedPrivateEndpoint(ids *[]ResourceID, endpointID *string, status string) {
+	if endpointID == nil || !strings.EqualFold(status, "Approved") {
+		return
+	}
+	var id ResourceID
+	id.fromID(*endpointID)
+	*ids = append(*ids, id)
+}
+
+// PrivateEndpointDNSConfig is a name that resolves to the endpoint
+type PrivateEndpointDNSConfig struct {
+	FQDN string
+	IPs  []string
+}
+
+func (dc *PrivateEndpointDNSConfig) FromAzure(az *armnetwork.CustomDNSConfigPropertiesFormat) {
+	gValFromPtr(&dc.FQDN, az.Fqdn)
+	dc.IPs = make([]string, 0, len(az.IPAddresses))
+	appendStrings(&dc.IPs, az.IPAddresses)
+}
+
+// PrivateDNSZone is a DNS zone that is only resolvable from linked virtual
+// networks. Private endpoints usually register in zones like
+// privatelink.blob.core.windows.net.
+type PrivateDNSZone struct {
+	// Meta.Name is the name of the zone
+	Meta                ResourceID
+	RecordSets          int64
+	VirtualNetworkLinks []PrivateDNSZoneVNetLink
+}
+
+func NewEmptyPrivateDNSZone() *PrivateDNSZone {
+	z := &PrivateDNSZone{
+		VirtualNetworkLinks: make([]PrivateDNSZoneVNetLink, 0),
+	}
+	z.Meta.setupEmpty()
+	return z
+}
+
+func (z *PrivateDNSZone) FromAzure(az *armprivatedns.PrivateZone) {
+	if az.ID == nil {
+		return
+	}
+	z.Meta.fromID(*az.ID)
+	if az.Properties != nil {
+		gValFromPtr(&z.RecordSets, az.Properties.NumberOfRecordSets)
+	}
+}
+
+// PrivateDNSZoneVNetLink makes a PrivateDNSZone resolvable from a virtual
+// network.
+type PrivateDNSZoneVNetLink struct {
+	Name           string
+	Zone           ResourceID
+	VirtualNetwork ResourceID
+	// RegistrationEnabled means VMs in the network automatically get records
+	// in the zone
+	RegistrationEnabled UnknownBool
+	// State is Completed or InProgress
+	State string
+}
+
+func (l *PrivateDNSZoneVNetLink) QueryString() string {
+	z, err := l.Zone.QueryString()
+	if err != nil {
+		return ""
+	}
+	return z + "/VirtualNetworkLinks/" + l.Name
+}
+
+func (l *PrivateDNSZoneVNetLink) FromAzure(az *armprivatedns.VirtualNetworkLink) {
+	l.VirtualNetwork.setupEmpty()
+	gValFromPtr(&l.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.VirtualNetwork != nil && props.VirtualNetwork.ID != nil {
+		l.VirtualNetwork.fromID(*props.VirtualNetwork.ID)
+	}
+	l.RegistrationEnabled.FromBoolPtr(props.RegistrationEnabled)
+	if props.VirtualNetworkLinkState != nil {
+		l.State = string(*props.VirtualNetworkLinkState)
+	}
+}
@@ -0,0 +1,262 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/redis/armredis"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+)
+
+const (
+	peID        = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/privateEndpoints/vault-pe"
+	peNICID     = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/networkInterfaces/vault-pe.nic"
+	peSubnetID  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/vnet/subnets/endpoints"
+	peStorageID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Storage/storageAccounts/acct"
+	peZoneID    = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/privateDnsZones/privatelink.vaultcore.azure.net"
+)
+
+func testPrivateEndpoint() *PrivateEndpoint {
+	pe := NewEmptyPrivateEndpoint()
+	pe.FromAzure(&armnetwork.PrivateEndpoint{
+		ID: ptr(peID),
+		Properties: &armnetwork.PrivateEndpointProperties{
+			Subnet:            &armnetwork.Subnet{ID: ptr(peSubnetID)},
+			NetworkInterfaces: []*armnetwork.Interface{{ID: ptr(peNICID)}},
+			PrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{
+				{
+					Name: ptr("vault"),
+					Properties: &armnetwork.PrivateLinkServiceConnectionProperties{
+						PrivateLinkServiceID: ptr(keyVaultID),
+						GroupIDs:             []*string{ptr("vault")},
+						PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+							Status: ptr("Approved"),
+						},
+					},
+				},
+			},
+			ManualPrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{
+				{
+					Name: ptr("blob"),
+					Properties: &armnetwork.PrivateLinkServiceConnectionProperties{
+						PrivateLinkServiceID: ptr(peStorageID),
+						GroupIDs:             []*string{ptr("blob")},
+						PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+							Status: ptr("Pending"),
+						},
+					},
+				},
+			},
+			CustomDNSConfigs: []*armnetwork.CustomDNSConfigPropertiesFormat{
+				{
+					Fqdn:        ptr("vault.vault.azure.net"),
+					IPAddresses: []*string{ptr("10.0.1.4")},
+				},
+			},
+		},
+	})
+	return pe
+}
+
+func TestPrivateEndpointFromAzure(t *testing.T) {
+	pe := testPrivateEndpoint()
+	if pe.Meta.Tag != PrivateEndpointT || pe.Subnet.Name != "endpoints" || len(pe.NetworkInterfaces) != 1 {
+		t.Fatalf("bad private endpoint: %+v", pe)
+	}
+	if len(pe.Connections) != 2 {
+		t.Fatalf("expected 2 connections: %+v", pe.Connections)
+	}
+	vault, blob := pe.Connections[0], pe.Connections[1]
+	if vault.Target.Tag != KeyVaultT || vault.Manual || !vault.Approved() || vault.GroupIDs[0] != "vault" {
+		t.Fatalf("bad connection: %+v", vault)
+	}
+	if blob.Target.Tag != StorageAccountT || !blob.Manual || blob.Approved() {
+		t.Fatalf("bad manual connection: %+v", blob)
+	}
+
+	var kvID, saID ResourceID
+	kvID.fromID(keyVaultID)
+	saID.fromID(peStorageID)
+	if !pe.ConnectsTo(&kvID) || pe.ConnectsTo(&saID) {
+		t.Fatal("bad ConnectsTo")
+	}
+
+	pe.NetworkInterfaces[0].FromAzure(&armnetwork.Interface{
+		ID: ptr(peNICID),
+		Properties: &armnetwork.InterfacePropertiesFormat{
+			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+				{
+					Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+						PrivateIPAddress: ptr("10.0.1.4"),
+					},
+				},
+				{
+					Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+						PrivateIPAddress: ptr("10.0.1.5"),
+					},
+				},
+			},
+		},
+	})
+	if ips := pe.PrivateIPs(); len(ips) != 2 || ips[0] != "10.0.1.4" || ips[1] != "10.0.1.5" {
+		t.Fatalf("bad private IPs: %v", ips)
+	}
+}
+
+func TestPrivateDNSZoneFromAzure(t *testing.T) {
+	z := NewEmptyPrivateDNSZone()
+	z.FromAzure(&armprivatedns.PrivateZone{
+		ID: ptr(peZoneID),
+		Properties: &armprivatedns.PrivateZoneProperties{
+			NumberOfRecordSets: ptr[int64](2),
+		},
+	})
+	if z.Meta.Tag != PrivateDNSZoneT || z.Meta.Name != "privatelink.vaultcore.azure.net" || z.RecordSets != 2 {
+		t.Fatalf("bad private DNS zone: %+v", z)
+	}
+	state := armprivatedns.VirtualNetworkLinkStateCompleted
+	var l PrivateDNSZoneVNetLink
+	l.Zone = z.Meta
+	l.FromAzure(&armprivatedns.VirtualNetworkLink{
+		Name: ptr("hub"),
+		Properties: &armprivatedns.VirtualNetworkLinkProperties{
+			VirtualNetwork:          &armprivatedns.SubResource{ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/vnet")},
+			RegistrationEnabled:     ptr(false),
+			VirtualNetworkLinkState: &state,
+		},
+	})
+	z.VirtualNetworkLinks = append(z.VirtualNetworkLinks, l)
+	if l.VirtualNetwork.Name != "vnet" || !l.RegistrationEnabled.False() || l.State != "Completed" {
+		t.Fatalf("bad virtual network link: %+v", l)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.PrivateDNSZones = append(rg.PrivateDNSZones, z)
+	rg.PrivateEndpoints = append(rg.PrivateEndpoints, testPrivateEndpoint())
+	sub.ResourceGroups[rgAName] = rg
+	var links []PrivateDNSZoneVNetLink
+	if err := sub.FromQueryString("/PrivateDNSZones/*/*/VirtualNetworkLinks", &links); err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].QueryString() != "/PrivateDNSZones/rga/privatelink.vaultcore.azure.net/VirtualNetworkLinks/hub" {
+		t.Fatalf("bad query string result: %v", links)
+	}
+	var pes []*PrivateEndpoint
+	if err := sub.FromQueryString("/PrivateEndpoints", &pes); err != nil {
+		t.Fatal(err)
+	}
+	if len(pes) != 1 {
+		t.Fatalf("bad query string result: %v", pes)
+	}
+}
+
+func TestAttackSurfaceExposure(t *testing.T) {
+	kv := NewEmptyKeyVault()
+	kv.Meta.fromID(keyVaultID)
+	kv.URL = "https://vault.vault.azure.net/"
+	kv.Firewall.PublicNetworkAccess = BoolFalse
+
+	sqlPublic := NewEmptySQLServer()
+	enabled := armsql.ServerNetworkAccessFlagEnabled
+	sqlPublic.FromAzure(&armsql.Server{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Sql/servers/public"),
+		Properties: &armsql.ServerProperties{
+			FullyQualifiedDomainName: ptr("public.database.windows.net"),
+			PublicNetworkAccess:      &enabled,
+		},
+	})
+	sqlClosed := NewEmptySQLServer()
+	disabled := armsql.ServerNetworkAccessFlagDisabled
+	sqlClosed.FromAzure(&armsql.Server{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Sql/servers/closed"),
+		Properties: &armsql.ServerProperties{
+			FullyQualifiedDomainName: ptr("closed.database.windows.net"),
+			PublicNetworkAccess:      &disabled,
+		},
+	})
+	if !sqlPublic.PublicNetworkAccess.True() || !sqlClosed.PublicNetworkAccess.False() {
+		t.Fatal("bad SQL public network access")
+	}
+
+	redis := NewEmptyRedisServer()
+	redis.Meta.fromID("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Cache/Redis/cache")
+	redis.Host = "cache.redis.cache.windows.net"
+	redisClosed := NewEmptyRedisServer()
+	redisDisabled := armredis.PublicNetworkAccessDisabled
+	redisClosed.FromAzure(&armredis.ResourceInfo{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Cache/Redis/closed"),
+		Properties: &armredis.Properties{
+			HostName:            ptr("closed.redis.cache.windows.net"),
+			PublicNetworkAccess: &redisDisabled,
+		},
+	})
+	if !redis.PublicNetworkAccess.Unknown() || !redisClosed.PublicNetworkAccess.False() {
+		t.Fatal("bad redis public network access")
+	}
+
+	appClosed := NewEmptyWebApp()
+	appClosed.Meta.fromID("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/closed")
+	appClosed.DefaultHostname = "closed.azurewebsites.net"
+	appClosed.fillConfigInfo(&armappservice.SiteConfig{PublicNetworkAccess: ptr("Disabled")})
+	appUnset := NewEmptyWebApp()
+	appUnset.Meta.fromID("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/unset")
+	appUnset.DefaultHostname = "unset.azurewebsites.net"
+	appPublic := NewEmptyWebApp()
+	appPublic.Meta.fromID("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Web/sites/public")
+	appPublic.DefaultHostname = "public.azurewebsites.net"
+	appPublic.fillConfigInfo(&armappservice.SiteConfig{PublicNetworkAccess: ptr("Enabled")})
+	if !appClosed.PublicNetworkAccess.False() || !appUnset.PublicNetworkAccess.Unknown() || !appPublic.PublicNetworkAccess.True() {
+		t.Fatal("bad web app public network access")
+	}
+
+	pe := testPrivateEndpoint()
+	pe.Connections = append(pe.Connections, PrivateEndpointConnection{
+		Target: sqlPublic.Meta,
+		Status: "Approved",
+	})
+	pe.Connections = append(pe.Connections, PrivateEndpointConnection{
+		Target: redisClosed.Meta,
+		Status: "Approved",
+	})
+	for _, app := range []*WebApp{appClosed, appUnset, appPublic} {
+		pe.Connections = append(pe.Connections, PrivateEndpointConnection{
+			Target: app.Meta,
+			Status: "Approved",
+		})
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.KeyVaults = append(rg.KeyVaults, kv)
+	rg.SQLServers = append(rg.SQLServers, sqlPublic, sqlClosed)
+	rg.RedisServers = append(rg.RedisServers, redis, redisClosed)
+	rg.WebApps = append(rg.WebApps, appClosed, appUnset, appPublic)
+	rg.PrivateEndpoints = append(rg.PrivateEndpoints, pe)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.KeyVaults) != 0 || as.Exposure[kv.URL] != ExposurePrivateOnly {
+		t.Fatalf("expected private only key vault: %v %v", as.KeyVaults, as.Exposure)
+	}
+	if len(as.MSQL) != 1 || as.MSQL[0] != sqlPublic.FQDN || as.Exposure[sqlPublic.FQDN] != ExposurePublicAndPrivate {
+		t.Fatalf("expected public and private SQL server: %v %v", as.MSQL, as.Exposure)
+	}
+	if _, has := as.Exposure[sqlClosed.FQDN]; has {
+		t.Fatalf("unreachable SQL server in exposure: %v", as.Exposure)
+	}
+	if len(as.Redis) != 1 || as.Exposure[redis.Host] != ExposurePublic {
+		t.Fatalf("expected public redis: %v %v", as.Redis, as.Exposure)
+	}
+	if as.Exposure[redisClosed.Host] != ExposurePrivateOnly {
+		t.Fatalf("expected private only redis: %v", as.Exposure)
+	}
+	if len(as.WebApps) != 1 || as.WebApps[0] != appPublic.DefaultHostname || as.Exposure[appPublic.DefaultHostname] != ExposurePublicAndPrivate {
+		t.Fatalf("expected only the public web app: %v %v", as.WebApps, as.Exposure)
+	}
+	if as.Exposure[appClosed.DefaultHostname] != ExposurePrivateOnly || as.Exposure[appUnset.DefaultHostname] != ExposurePrivateOnly {
+		t.Fatalf("expected private only web apps: %v", as.Exposure)
+	}
+}
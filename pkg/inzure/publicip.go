@@ -0,0 +1,143 @@
+package inzure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+// PublicIPAddress is a public IP address resource. Unlike PublicIP, which is
+// embedded in whatever uses the address, these are collected on their own so
+// unattached addresses and addresses bound to gateways are also seen.
+type PublicIPAddress struct {
+	Meta ResourceID
+	// IP is empty for Dynamic addresses that aren't currently attached
+	IP              string
+	FQDN            string
+	DomainNameLabel string
+	ReverseFQDN     string
+	// Version is IPv4 or IPv6
+	Version string
+	// AllocationMethod is Static or Dynamic
+	AllocationMethod string
+	// SKU is Basic, Standard, or StandardV2. Basic addresses are open by
+	// default while Standard addresses are closed unless an NSG allows
+	// traffic.
+	SKU string
+	// SKUTier is Regional or Global
+	SKUTier string
+	// DDoSProtectionMode is Enabled, Disabled, or VirtualNetworkInherited
+	DDoSProtectionMode string
+	DDoSProtectionPlan ResourceID
+	IdleTimeoutMinutes int32
+	Prefix             ResourceID
+	// IPConfiguration is the IP configuration the address is assigned to
+	// if any. This can belong to a network interface, load balancer,
+	// gateway, or firewall among others.
+	IPConfiguration ResourceID
+	// AttachedTo is the resource that owns the IPConfiguration, or the NAT
+	// gateway using the address. It is empty for unattached addresses.
+	AttachedTo ResourceID
+}
+
+func NewEmptyPublicIPAddress() *PublicIPAddress {
+	p := new(PublicIPAddress)
+	p.Meta.setupEmpty()
+	p.DDoSProtectionPlan.setupEmpty()
+	p.Prefix.setupEmpty()
+	p.IPConfiguration.setupEmpty()
+	p.AttachedTo.setupEmpty()
+	return p
+}
+
+func (p *PublicIPAddress) FromAzure(az *armnetwork.PublicIPAddress) {
+	if az.ID == nil {
+		return
+	}
+	p.Meta.fromID(*az.ID)
+	if az.SKU != nil {
+		if az.SKU.Name != nil {
+			p.SKU = string(*az.SKU.Name)
+		}
+		if az.SKU.Tier != nil {
+			p.SKUTier = string(*az.SKU.Tier)
+		}
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&p.IP, props.IPAddress)
+	if dns := props.DNSSettings; dns != nil {
+		gValFromPtr(&p.FQDN, dns.Fqdn)
+		gValFromPtr(&p.DomainNameLabel, dns.DomainNameLabel)
+		gValFromPtr(&p.ReverseFQDN, dns.ReverseFqdn)
+	}
+	if props.PublicIPAddressVersion != nil {
+		p.Version = string(*props.PublicIPAddressVersion)
+	}
+	if props.PublicIPAllocationMethod != nil {
+		p.AllocationMethod = string(*props.PublicIPAllocationMethod)
+	}
+	if ddos := props.DdosSettings; ddos != nil {
+		if ddos.ProtectionMode != nil {
+			p.DDoSProtectionMode = string(*ddos.ProtectionMode)
+		}
+		if ddos.DdosProtectionPlan != nil && ddos.DdosProtectionPlan.ID != nil {
+			p.DDoSProtectionPlan.fromID(*ddos.DdosProtectionPlan.ID)
+		}
+	}
+	gValFromPtr(&p.IdleTimeoutMinutes, props.IdleTimeoutInMinutes)
+	if props.PublicIPPrefix != nil && props.PublicIPPrefix.ID != nil {
+		p.Prefix.fromID(*props.PublicIPPrefix.ID)
+	}
+	if props.IPConfiguration != nil && props.IPConfiguration.ID != nil {
+		p.IPConfiguration.fromID(*props.IPConfiguration.ID)
+		if owner := owningResourceID(*props.IPConfiguration.ID); owner != "" {
+			p.AttachedTo.fromID(owner)
+		}
+	} else if props.NatGateway != nil && props.NatGateway.ID != nil {
+		p.AttachedTo.fromID(*props.NatGateway.ID)
+	}
+}
+
+// IsAttached is whether the address is in use by any resource
+func (p *PublicIPAddress) IsAttached() bool {
+	return p.AttachedTo.RawID != ""
+}
+
+// Address is the FQDN if there is one and the IP otherwise
+func (p *PublicIPAddress) Address() string {
+	if p.FQDN != "" {
+		return p.FQDN
+	}
+	return p.IP
+}
+
+// owningResourceID strips child resources, such as ipConfigurations, off of
+// a resource ID to get the top level resource under the provider.
+func owningResourceID(id string) string {
+	parts := strings.Split(id, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if strings.EqualFold(parts[i], "providers") {
+			if len(parts) < i+4 {
+				return ""
+			}
+			return strings.Join(parts[:i+4], "/")
+		}
+	}
+	return ""
+}
+
+// PublicIPAddressByID finds the collected PublicIPAddress with the given
+// resource ID.
+func (s *Subscription) PublicIPAddressByID(id *ResourceID) *PublicIPAddress {
+	for _, rg := range s.ResourceGroups {
+		for _, pip := range rg.PublicIPAddresses {
+			if resourceIDsMatch(pip.Meta, *id) {
+				return pip
+			}
+		}
+	}
+	return nil
+}
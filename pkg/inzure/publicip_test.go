@@ -0,0 +1,111 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+const (
+	pipVMID      = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/vm-ip"
+	pipGatewayID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/vpn-ip"
+	pipOrphanID  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/orphan"
+	pipNICID     = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/networkInterfaces/vm-nic"
+)
+
+func testPublicIPAddress(id string, ip string, ipConfig string) *PublicIPAddress {
+	static := armnetwork.IPAllocationMethodStatic
+	standard := armnetwork.PublicIPAddressSKUNameStandard
+	pip := NewEmptyPublicIPAddress()
+	az := &armnetwork.PublicIPAddress{
+		ID:  ptr(id),
+		SKU: &armnetwork.PublicIPAddressSKU{Name: &standard},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			IPAddress:                ptr(ip),
+			PublicIPAllocationMethod: &static,
+		},
+	}
+	if ipConfig != "" {
+		az.Properties.IPConfiguration = &armnetwork.IPConfiguration{ID: ptr(ipConfig)}
+	}
+	pip.FromAzure(az)
+	return pip
+}
+
+func TestPublicIPAddressFromAzure(t *testing.T) {
+	inherited := armnetwork.DdosSettingsProtectionModeVirtualNetworkInherited
+	v4 := armnetwork.IPVersionIPv4
+	pip := NewEmptyPublicIPAddress()
+	pip.FromAzure(&armnetwork.PublicIPAddress{
+		ID: ptr(pipVMID),
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			IPAddress:              ptr("20.1.2.3"),
+			PublicIPAddressVersion: &v4,
+			DNSSettings: &armnetwork.PublicIPAddressDNSSettings{
+				DomainNameLabel: ptr("app"),
+				Fqdn:            ptr("app.eastus.cloudapp.azure.com"),
+				ReverseFqdn:     ptr("app.example.com."),
+			},
+			DdosSettings:    &armnetwork.DdosSettings{ProtectionMode: &inherited},
+			IPConfiguration: &armnetwork.IPConfiguration{ID: ptr(pipNICID + "/ipConfigurations/ipconfig1")},
+		},
+	})
+	if pip.Meta.Tag != PublicIPT || pip.IP != "20.1.2.3" || pip.Version != "IPv4" ||
+		pip.DomainNameLabel != "app" || pip.ReverseFQDN != "app.example.com." ||
+		pip.DDoSProtectionMode != "VirtualNetworkInherited" {
+		t.Fatalf("bad public IP: %+v", pip)
+	}
+	if pip.IPConfiguration.Tag != IPConfigurationT || pip.AttachedTo.Tag != NetworkInterfaceT ||
+		pip.AttachedTo.Name != "vm-nic" || !pip.IsAttached() {
+		t.Fatalf("bad attachment: %+v %+v", pip.IPConfiguration, pip.AttachedTo)
+	}
+	if pip.Address() != "app.eastus.cloudapp.azure.com" {
+		t.Fatalf("bad address: %s", pip.Address())
+	}
+
+	gw := testPublicIPAddress(pipGatewayID, "20.1.2.4",
+		"/subscriptions/"+subId+"/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworkGateways/vpn/ipConfigurations/default")
+	if gw.SKU != "Standard" || gw.AllocationMethod != "Static" || gw.AttachedTo.Tag != VirtualNetworkGatewayT {
+		t.Fatalf("bad gateway public IP: %+v", gw)
+	}
+	if orphan := testPublicIPAddress(pipOrphanID, "20.1.2.5", ""); orphan.IsAttached() {
+		t.Fatalf("expected unattached public IP: %+v", orphan)
+	}
+}
+
+func TestAttackSurfacePublicIPs(t *testing.T) {
+	vmIP := testPublicIPAddress(pipVMID, "20.1.2.3", pipNICID+"/ipConfigurations/ipconfig1")
+	gwIP := testPublicIPAddress(pipGatewayID, "20.1.2.4",
+		"/subscriptions/"+subId+"/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworkGateways/vpn/ipConfigurations/default")
+	orphan := testPublicIPAddress(pipOrphanID, "20.1.2.5", "")
+
+	// The VM's interface only has a reference to the public IP
+	vm := NewEmptyVirtualMachine()
+	nic := NewEmptyNetworkInterface()
+	var ipc IPConfiguration
+	ipc.setupEmpty()
+	ipc.PublicIP.Meta.fromID(pipVMID)
+	nic.IPConfigurations = append(nic.IPConfigurations, ipc)
+	vm.NetworkInterfaces = append(vm.NetworkInterfaces, *nic)
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.PublicIPAddresses = append(rg.PublicIPAddresses, vmIP, gwIP, orphan)
+	rg.VirtualMachines = append(rg.VirtualMachines, vm)
+	sub.ResourceGroups[rgAName] = rg
+
+	as := sub.GetAttackSurface()
+	if len(as.PublicIPs) != 3 {
+		t.Fatalf("expected all public IPs: %v", as.PublicIPs)
+	}
+	if len(as.UnattachedPublicIPs) != 1 || as.UnattachedPublicIPs[0] != "20.1.2.5" {
+		t.Fatalf("bad unattached public IPs: %v", as.UnattachedPublicIPs)
+	}
+	if len(as.VirtualMachines) != 1 || as.VirtualMachines[0] != "20.1.2.3" {
+		t.Fatalf("VM public IP not resolved from inventory: %v", as.VirtualMachines)
+	}
+
+	if sub.PublicIPAddressByID(&ipc.PublicIP.Meta) != vmIP {
+		t.Fatal("public IP not resolved")
+	}
+}
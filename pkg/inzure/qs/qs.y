@@ -24,6 +24,9 @@ import "fmt"
     sel QSField
     field *QSField
     condChain *QSCondition
+    fields []QSField
+    stage QSStage
+    stages []QSStage
     vals []reflect.Value
     qss QSSelector
 
@@ -34,6 +37,7 @@ import "fmt"
 
 %token AND
 %token OR
+%token NOT
 
 %token <s> FIELD
 %token <b> BOOL
@@ -45,11 +49,18 @@ import "fmt"
 %token OBRA
 %token <s> STR
 %token <ub> UNKNOWN_BOOL
+%token ARROW
+%token PIPE
+%token OBRACE
+%token CBRACE
 /*%token <s> IDENT*/
 
 %type <s> AzureName
 %type <sel> Selector
 %type <result> QueryString
+%type <fields> Projection
+%type <stage> Stage
+%type <stages> Stages
 %type <arraySel> ArraySelector
 %type <condChain> Condition
 %type <iface> Literal
@@ -63,8 +74,9 @@ import "fmt"
 
 %left OR
 %left AND
+%right NOT
 
-%start QueryString
+%start Query
 %%
 
 Literal:
@@ -165,6 +177,27 @@ Condition:
             },
         }
     }
+    | Selector CHARS Literal {
+        op := qsOpFromWord($2)
+        if op == QSOpUk {
+            yylex.Error(fmt.Sprintf("%s is not a valid comparison operator", $2))
+        }
+        raw := fmt.Sprintf("%s %s", $1.String(), op.String())
+        v, is := $3.(string)
+        if is {
+            raw = fmt.Sprintf("%s %s", raw, strconv.Quote(v))
+        } else {
+            raw = fmt.Sprintf("%s %v", raw, $3)
+        }
+        $$ = &QSCondition{
+            Raw: raw,
+            Cmp: &QSComparer{
+                Fields: $1,
+                Op: op,
+                To: $3,
+            },
+        }
+    }
     | Selector OP CHARS {
         if $3 == "true" || $3 == "false" {
             val := $3 == "true"
@@ -189,22 +222,33 @@ Condition:
             yylex.Error(fmt.Sprintf("unexpected %v", $3))
         }
     }
+    /* The left hand side becomes the comparer so the tree follows the
+       precedence declared above instead of being flattened into a chain */
     | Condition AND Condition {
-        $1.Raw += " && " + $3.String()
-        $1.PushAnd($3)
+        $$ = &QSCondition{
+            Raw: $1.String() + " && " + $3.String(),
+            Cmp: $1,
+            And: $3,
+        }
     }
     | Condition OR Condition {
-        $1.Raw += " || " + $3.String()
-        $1.PushOr($3)
+        $$ = &QSCondition{
+            Raw: $1.String() + " || " + $3.String(),
+            Cmp: $1,
+            Or: $3,
+        }
+    }
+    | NOT Condition {
+        $$ = &QSCondition{
+            Raw: "!" + $2.String(),
+            Cmp: $2,
+            Not: true,
+        }
     }
     | OPAR Condition CPAR {
-        c := new(QSCondition)
-        *c = *$2
         $$ = &QSCondition{
-            Raw: fmt.Sprintf("(%s)", c.Raw),
-            Cmp: c,
-            And: nil,
-            Or: nil,
+            Raw: fmt.Sprintf("(%s)", $2.Raw),
+            Cmp: $2,
         }
     }
 
@@ -226,6 +270,75 @@ QSSelector:
         }
     }
 
+Projection:
+    Selector {
+        $$ = []QSField{$1}
+    }
+    | Projection ',' Selector {
+        $$ = append($1, $3)
+    }
+
+Stage:
+    CHARS {
+        if $1 != "count" {
+            yylex.Error(fmt.Sprintf("unknown stage %s", $1))
+        }
+        $$ = QSStage{Type: QSStageCount}
+    }
+    | CHARS NUMBER {
+        if $1 != "limit" {
+            yylex.Error(fmt.Sprintf("unknown stage %s", $1))
+        }
+        if $2 < 0 {
+            yylex.Error("limit can't be negative")
+        }
+        $$ = QSStage{Type: QSStageLimit, Limit: int($2)}
+    }
+    | CHARS CHARS Selector {
+        $$ = QSStage{Field: $3}
+        if $2 != "by" {
+            yylex.Error(fmt.Sprintf("expected by after %s", $1))
+        }
+        switch $1 {
+            case "group":
+                $$.Type = QSStageGroupBy
+            case "sort":
+                $$.Type = QSStageSortBy
+            default:
+                yylex.Error(fmt.Sprintf("unknown stage %s", $1))
+        }
+    }
+    | CHARS CHARS Selector CHARS {
+        if $1 != "sort" || $2 != "by" {
+            yylex.Error(fmt.Sprintf("unexpected %s", $4))
+        }
+        $$ = QSStage{Type: QSStageSortBy, Field: $3}
+        switch $4 {
+            case "asc":
+            case "desc":
+                $$.Desc = true
+            default:
+                yylex.Error(fmt.Sprintf("sort order must be asc or desc, not %s", $4))
+        }
+    }
+
+Stages:
+    /* Empty */ {
+        $$ = nil
+    }
+    | Stages PIPE Stage {
+        $$ = append($1, $3)
+    }
+
+Query:
+    QueryString Stages {
+        yylex.(*qsLexer).result.Stages = $2
+    }
+    | QueryString Stages ARROW OBRACE Projection CBRACE {
+        yylex.(*qsLexer).result.Stages = $2
+        yylex.(*qsLexer).result.Projection = $5
+    }
+
 /* TODO: Probably a better way to define this if I was better with yacc? */
 QueryString:
     /* Just a resource */
@@ -46,15 +46,32 @@ getRune:
 	case '"':
 		l.push(r)
 		return l.str(lval)
-	case '!', '=', '<', '>', '~':
+	case '!':
+		// A lone ! negates the following condition, otherwise it is the
+		// start of != or !~
+		r2 := l.nextRune(true)
+		if r2 != '=' && r2 != '~' {
+			if r2 != 0 {
+				l.rewindRune()
+			}
+			return NOT
+		}
+		l.push(r)
+		l.push(r2)
+		return l.op(lval)
+	case '=', '<', '>', '~':
 		l.push(r)
 		return l.op(lval)
 	case '|':
-		r2 := l.nextRune(false)
-		if r2 != '|' {
-			goto abort
+		// || is OR and a single | starts a stage
+		r2 := l.nextRune(true)
+		if r2 == '|' {
+			return OR
 		}
-		return OR
+		if r2 != 0 {
+			l.rewindRune()
+		}
+		return PIPE
 	case '&':
 		r2 := l.nextRune(false)
 		if r2 != '&' {
@@ -74,9 +91,23 @@ getRune:
 		return OPAR
 	case ')':
 		return CPAR
+	case '{':
+		return OBRACE
+	case '}':
+		return CBRACE
 	case ',':
 		return ','
-	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+	case '-':
+		r2 := l.nextRune(true)
+		if r2 == '>' {
+			return ARROW
+		}
+		if r2 != 0 {
+			l.rewindRune()
+		}
+		l.push(r)
+		return l.num(lval)
+	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		l.push(r)
 		return l.num(lval)
 	default:
@@ -137,11 +168,14 @@ func (l *qsLexer) num(lval *yySymType) int {
 	haveDecimal := false
 loop:
 	for l.err == nil {
-		r := l.nextRune(false)
+		r := l.nextRune(true)
 		if unicode.IsSpace(r) {
 			break
 		}
 		switch r {
+		case 0:
+			// EOF is ok
+			break loop
 		case '.':
 			if haveDecimal {
 			}
@@ -149,7 +183,7 @@ loop:
 			fallthrough
 		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 			l.push(r)
-		case ']', ')':
+		case ']', ')', '|':
 			l.rewindRune()
 			break loop
 		default:
@@ -180,9 +214,21 @@ loop:
 		// We're going to basically accept everything except
 		// for known delimiters
 		switch r {
-		case '/', '[', ']', ')':
+		case '/', '[', ']', ')', '{', '|':
 			l.rewindRune()
 			break loop
+		case '-':
+			// Stop before a projection arrow
+			r2 := l.nextRune(true)
+			if r2 == '>' {
+				l.rewindRune()
+				l.rewindRune()
+				break loop
+			}
+			if r2 != 0 {
+				l.rewindRune()
+			}
+			l.push(r)
 		case 0:
 			// EOF is ok
 			break loop
@@ -242,9 +288,12 @@ func (l *qsLexer) field(lval *yySymType) int {
 	l.push(r)
 loop:
 	for l.err == nil {
-		r := l.nextRune(false)
+		r := l.nextRune(true)
 		switch r {
-		case '[', '.', '(':
+		case 0:
+			// EOF is ok for stages
+			break loop
+		case '[', '.', '(', ',', '}', '|':
 			l.rewindRune()
 			break loop
 		default:
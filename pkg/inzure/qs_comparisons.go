@@ -20,6 +20,10 @@ type QSField struct {
 	MethodReturnIndex int
 	MethodArgs        []reflect.Value
 
+	// methodOnInterface is set when the method is called on an interface
+	// field. These have to be looked up on the value itself.
+	methodOnInterface bool
+
 	Next *QSField
 }
 
@@ -115,6 +119,29 @@ func (qsc *QSComparer) getCmpFunc(t reflect.Type) (qsCmpFunc, error) {
 				//if i < end {
 				return nil, errors.New("methods can only come at the end")
 			}
+			if t.Kind() == reflect.Interface {
+				m, has := t.MethodByName(f.Name)
+				if !has {
+					return nil, fmt.Errorf(
+						"type %v has no method %s", t, f.Name,
+					)
+				}
+				f.methodOnInterface = true
+				// Interface methods don't have a receiver in their type
+				if m.Type.NumIn() != len(f.MethodArgs) {
+					return nil, fmt.Errorf(
+						"didn't pass enough method arguments for %s: %d != %d",
+						f.Name, m.Type.NumIn(), len(f.MethodArgs),
+					)
+				}
+				if m.Type.NumOut() == 0 {
+					return nil, fmt.Errorf(
+						"method %s doesn't return anything", f.Name,
+					)
+				}
+				t = m.Type.Out(0)
+				continue
+			}
 			m, has := t.MethodByName(f.Name)
 			if !has {
 				// Try the pointer
@@ -179,6 +206,9 @@ func (qsc *QSComparer) getCmpFunc(t reflect.Type) (qsCmpFunc, error) {
 			}
 		}
 	}
+	if qsc.Op.isNetworkOp() {
+		return qsc.cmpForNetwork(t)
+	}
 	to := reflect.ValueOf(qsc.To)
 	if !t.ConvertibleTo(to.Type()) {
 		return nil, fmt.Errorf("incompatible types: %v and %v", t, to.Type())
@@ -317,15 +347,30 @@ func (qsc *QSComparer) compareMethod(
 			base = base.Addr()
 		}
 	}
-	args := make([]reflect.Value, len(m.MethodArgs)+1)
-	args[0] = base
-	copy(args[1:], m.MethodArgs)
-	retCount := qsc.fun.Type().NumOut()
+	fun := qsc.fun
+	var args []reflect.Value
+	if m.methodOnInterface {
+		// Nil interfaces don't have anything to call
+		if (base.Kind() == reflect.Interface || base.Kind() == reflect.Ptr) && base.IsNil() {
+			return false, nil
+		}
+		fun = base.MethodByName(m.Name)
+		if !fun.IsValid() {
+			return false, fmt.Errorf("type %v has no method %s", base.Type(), m.Name)
+		}
+		args = make([]reflect.Value, len(m.MethodArgs))
+		copy(args, m.MethodArgs)
+	} else {
+		args = make([]reflect.Value, len(m.MethodArgs)+1)
+		args[0] = base
+		copy(args[1:], m.MethodArgs)
+	}
+	retCount := fun.Type().NumOut()
 	checkErr := retCount > 1
-	if err := qsVerifyMethodArgs(qsc.fun, m.MethodReturnIndex, args); err != nil {
+	if err := qsVerifyMethodArgs(fun, m.MethodReturnIndex, args); err != nil {
 		return false, err
 	}
-	ret := qsc.fun.Call(args)
+	ret := fun.Call(args)
 	if !checkErr {
 		return cmpFunc(ret[m.MethodReturnIndex])
 	}
@@ -679,3 +724,186 @@ func (qsc *QSComparer) cmpForUint64(to uint64) (qsCmpFunc, error) {
 		}
 	}, nil
 }
+
+var (
+	qsIPType           = reflect.TypeOf((*AzureIPv4)(nil)).Elem()
+	qsPortType         = reflect.TypeOf((*AzurePort)(nil)).Elem()
+	qsFirewallRuleType = reflect.TypeOf(FirewallRule{})
+)
+
+// qsIsIPType returns whether the network operators treat the type as IPs.
+// FirewallRules are treated as their IPRange.
+func qsIsIPType(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = derefTypePtr(t.Elem())
+	}
+	return t == qsIPType || t == qsFirewallRuleType
+}
+
+// qsIsPortType returns whether the network operators treat the type as ports.
+func qsIsPortType(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t == qsPortType
+}
+
+// qsIPsOf gets all of the AzureIPv4s out of a value that passed qsIsIPType.
+func qsIPsOf(v reflect.Value) []AzureIPv4 {
+	if v.Kind() == reflect.Slice {
+		ips := make([]AzureIPv4, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ips = append(ips, qsIPsOf(v.Index(i))...)
+		}
+		return ips
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	switch val := v.Interface().(type) {
+	case AzureIPv4:
+		if val != nil {
+			return []AzureIPv4{val}
+		}
+	case FirewallRule:
+		if val.IPRange != nil {
+			return []AzureIPv4{val.IPRange}
+		}
+	case *FirewallRule:
+		if val != nil && val.IPRange != nil {
+			return []AzureIPv4{val.IPRange}
+		}
+	}
+	return nil
+}
+
+// qsPortsOf gets all of the AzurePorts out of a value that passed
+// qsIsPortType.
+func qsPortsOf(v reflect.Value) []AzurePort {
+	if v.Kind() == reflect.Slice {
+		ports := make([]AzurePort, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ports = append(ports, qsPortsOf(v.Index(i))...)
+		}
+		return ports
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	if p, is := v.Interface().(AzurePort); is && p != nil {
+		return []AzurePort{p}
+	}
+	return nil
+}
+
+// cmpForNetwork handles the in, contains, and overlaps operators. A slice of
+// IPs or ports is treated as the union of its entries. Only definite answers
+// pass; anything involving a special IP that can't be resolved fails.
+func (qsc *QSComparer) cmpForNetwork(t reflect.Type) (qsCmpFunc, error) {
+	if qsIsIPType(t) {
+		s, is := qsc.To.(string)
+		if !is {
+			return nil, fmt.Errorf("%s on IPs requires a string, not %T", qsc.Op, qsc.To)
+		}
+		to, err := NewCheckedAzureIPv4FromAzure(s)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) (bool, error) {
+			return qsCompareIPs(qsc.Op, qsIPsOf(v), to), nil
+		}, nil
+	}
+	if qsIsPortType(t) {
+		var s string
+		switch c := qsc.To.(type) {
+		case string:
+			s = c
+		case int64:
+			s = strconv.FormatInt(c, 10)
+		default:
+			return nil, fmt.Errorf("%s on ports requires a string or number, not %T", qsc.Op, qsc.To)
+		}
+		to, err := NewCheckedPortFromAzure(s)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) (bool, error) {
+			return qsComparePorts(qsc.Op, qsPortsOf(v), to), nil
+		}, nil
+	}
+	return nil, fmt.Errorf("type %v doesn't support %s comparison", t, qsc.Op)
+}
+
+func qsCompareIPs(op QSOpT, ips []AzureIPv4, to AzureIPv4) bool {
+	if len(ips) == 0 {
+		return false
+	}
+	switch op {
+	case QSOpIn:
+		for _, ip := range ips {
+			if !IPContains(to, ip).True() {
+				return false
+			}
+		}
+		return true
+	case QSOpContains:
+		ranges := make([]AddrRange, 0, len(ips))
+		for _, ip := range ips {
+			if IPContains(ip, to).True() {
+				return true
+			}
+			ranges = append(ranges, ip.AddrRanges()...)
+		}
+		// The value may only be covered by multiple entries together
+		return !to.IsSpecial() && addrRangesContain(ranges, to.AddrRanges())
+	case QSOpOverlaps:
+		for _, ip := range ips {
+			if IPsOverlap(ip, to).True() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func qsComparePorts(op QSOpT, ports []AzurePort, to AzurePort) bool {
+	if len(ports) == 0 {
+		return false
+	}
+	switch op {
+	case QSOpIn:
+		for _, p := range ports {
+			if !PortContains(to, p) {
+				return false
+			}
+		}
+		return true
+	case QSOpContains:
+		for _, p := range ports {
+			if PortContains(p, to) {
+				return true
+			}
+		}
+		// The value may only be covered by multiple entries together
+		for _, want := range to.AllPorts() {
+			found := false
+			for _, p := range ports {
+				if p.Contains(want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case QSOpOverlaps:
+		for _, p := range ports {
+			if PortsOverlap(p, to) {
+				return true
+			}
+		}
+	}
+	return false
+}
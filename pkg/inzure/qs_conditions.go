@@ -2,18 +2,31 @@ package inzure
 
 import "reflect"
 
+// QSCondition is a node in a query string condition tree. Cmp is evaluated
+// first, inverted if Not is set, and then And is evaluated if it passed or
+// Or if it failed. Parenthesized groups and the operands of && and || are
+// themselves QSConditions used as the Cmp of their parent.
 type QSCondition struct {
 	Raw string
 	Cmp IQSComparer
+	Not bool
 	And *QSCondition
 	Or  *QSCondition
 }
 
-func (qsc *QSCondition) Compare(v reflect.Value) (bool, error) {
+func (qsc *QSCondition) cmp(v reflect.Value) (bool, error) {
 	ok, err := qsc.Cmp.Compare(v)
 	if err != nil {
 		return false, err
 	}
+	return ok != qsc.Not, nil
+}
+
+func (qsc *QSCondition) Compare(v reflect.Value) (bool, error) {
+	ok, err := qsc.cmp(v)
+	if err != nil {
+		return false, err
+	}
 	if !ok {
 		if qsc.Or != nil {
 			return qsc.Or.Compare(v)
@@ -25,6 +38,8 @@ func (qsc *QSCondition) Compare(v reflect.Value) (bool, error) {
 	return true, nil
 }
 
+// PushAnd appends ic to the end of the condition's And chain. Note that the
+// parser doesn't use this to build conditions anymore.
 func (qsc *QSCondition) PushAnd(ic *QSCondition) {
 	if qsc.And == nil {
 		qsc.And = ic
@@ -40,6 +55,8 @@ func (qsc *QSCondition) PushAnd(ic *QSCondition) {
 	}
 }
 
+// PushOr appends ic to the end of the condition's Or chain. Note that the
+// parser doesn't use this to build conditions anymore.
 func (qsc *QSCondition) PushOr(ic *QSCondition) {
 	if qsc.Or == nil {
 		qsc.Or = ic
@@ -67,7 +84,7 @@ func (qsc *QSCondition) FilterValue(v reflect.Value) (reflect.Value, error) {
 }
 
 func (qsc *QSCondition) filterSingleValue(v reflect.Value) (reflect.Value, error) {
-	ok, err := qsc.Cmp.Compare(v)
+	ok, err := qsc.cmp(v)
 	if err != nil {
 		return qsNilVal(), err
 	}
@@ -15,8 +15,33 @@ const (
 	QSOpLte
 	QSOpLike
 	QSOpNotLike
+	// QSOpIn, QSOpContains, and QSOpOverlaps work on IPs and ports. They use
+	// range semantics instead of comparing strings.
+	QSOpIn
+	QSOpContains
+	QSOpOverlaps
 )
 
+// qsOpFromWord returns the operator for the word operators or QSOpUk if the
+// word isn't one.
+func qsOpFromWord(s string) QSOpT {
+	switch s {
+	case "in":
+		return QSOpIn
+	case "contains":
+		return QSOpContains
+	case "overlaps":
+		return QSOpOverlaps
+	default:
+		return QSOpUk
+	}
+}
+
+// isNetworkOp returns whether the operator only works on IPs and ports.
+func (op QSOpT) isNetworkOp() bool {
+	return op == QSOpIn || op == QSOpContains || op == QSOpOverlaps
+}
+
 func (op QSOpT) String() string {
 	switch op {
 	case QSOpUk:
@@ -37,6 +62,12 @@ func (op QSOpT) String() string {
 		return "~"
 	case QSOpNotLike:
 		return "!~"
+	case QSOpIn:
+		return "in"
+	case QSOpContains:
+		return "contains"
+	case QSOpOverlaps:
+		return "overlaps"
 	default:
 		return fmt.Sprintf("Unknown Val(%d)", op)
 	}
@@ -69,3 +100,29 @@ func (qsa QSArraySelT) String() string {
 		}
 	}
 }
+
+// QSStageT is the type of a stage applied to the results of a query string
+type QSStageT uint8
+
+const (
+	QSStageUk QSStageT = iota
+	QSStageCount
+	QSStageGroupBy
+	QSStageSortBy
+	QSStageLimit
+)
+
+func (st QSStageT) String() string {
+	switch st {
+	case QSStageCount:
+		return "count"
+	case QSStageGroupBy:
+		return "group by"
+	case QSStageSortBy:
+		return "sort by"
+	case QSStageLimit:
+		return "limit"
+	default:
+		return fmt.Sprintf("Unknown Val(%d)", st)
+	}
+}
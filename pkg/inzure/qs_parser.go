@@ -1,18 +1,23 @@
-//line qs.y:2
+// Code generated by goyacc -o ../qs_parser.go qs.y. DO NOT EDIT.
 
 // If you're editing this as .go source file, it will be overwriten when
 // this code is generated again. Make sure you're editing qs.y!
+//
+//line qs.y:2
 package inzure
 
+import __yyfmt__ "fmt"
+
+//line qs.y:4
+
+// Need this even though we run goimports because they have __yyfmt__ "fmt"
+// auto generated.
 import (
 	"fmt"
-	__yyfmt__ "fmt"
 	"reflect"
 	"strconv"
 	"strings"
-) //line qs.y:4
-// Need this even though we run goimports because they have __yyfmt__ "fmt"
-// auto generated.
+)
 
 //line qs.y:12
 type yySymType struct {
@@ -31,6 +36,9 @@ type yySymType struct {
 	sel       QSField
 	field     *QSField
 	condChain *QSCondition
+	fields    []QSField
+	stage     QSStage
+	stages    []QSStage
 	vals      []reflect.Value
 	qss       QSSelector
 
@@ -40,16 +48,21 @@ type yySymType struct {
 const OP = 57346
 const AND = 57347
 const OR = 57348
-const FIELD = 57349
-const BOOL = 57350
-const CHARS = 57351
-const OPAR = 57352
-const CPAR = 57353
-const NUMBER = 57354
-const CBRA = 57355
-const OBRA = 57356
-const STR = 57357
-const UNKNOWN_BOOL = 57358
+const NOT = 57349
+const FIELD = 57350
+const BOOL = 57351
+const CHARS = 57352
+const OPAR = 57353
+const CPAR = 57354
+const NUMBER = 57355
+const CBRA = 57356
+const OBRA = 57357
+const STR = 57358
+const UNKNOWN_BOOL = 57359
+const ARROW = 57360
+const PIPE = 57361
+const OBRACE = 57362
+const CBRACE = 57363
 
 var yyToknames = [...]string{
 	"$end",
@@ -58,6 +71,7 @@ var yyToknames = [...]string{
 	"OP",
 	"AND",
 	"OR",
+	"NOT",
 	"FIELD",
 	"BOOL",
 	"CHARS",
@@ -68,9 +82,14 @@ var yyToknames = [...]string{
 	"OBRA",
 	"STR",
 	"UNKNOWN_BOOL",
+	"ARROW",
+	"PIPE",
+	"OBRACE",
+	"CBRACE",
 	"'/'",
 	"','",
 }
+
 var yyStatenames = [...]string{}
 
 const yyEofCode = 1
@@ -78,7 +97,7 @@ const yyErrCode = 2
 const yyInitialStackSize = 16
 
 //line yacctab:1
-var yyExca = [...]int{
+var yyExca = [...]int8{
 	-1, 1,
 	1, -1,
 	-2, 0,
@@ -86,71 +105,85 @@ var yyExca = [...]int{
 
 const yyPrivate = 57344
 
-const yyLast = 56
-
-var yyAct = [...]int{
-
-	7, 26, 30, 3, 30, 27, 31, 45, 31, 28,
-	29, 28, 29, 40, 9, 23, 38, 14, 5, 44,
-	39, 2, 6, 34, 21, 48, 20, 46, 22, 41,
-	24, 25, 15, 16, 37, 8, 12, 36, 15, 16,
-	17, 43, 42, 4, 32, 13, 47, 18, 11, 19,
-	33, 15, 13, 35, 1, 10,
+const yyLast = 81
+
+var yyAct = [...]int8{
+	14, 43, 20, 5, 16, 17, 60, 39, 64, 38,
+	65, 58, 26, 9, 3, 13, 10, 59, 47, 25,
+	30, 68, 48, 33, 34, 45, 46, 40, 30, 37,
+	8, 7, 41, 42, 49, 47, 44, 52, 61, 48,
+	30, 35, 45, 46, 57, 36, 27, 28, 55, 27,
+	28, 54, 66, 18, 21, 29, 50, 19, 15, 31,
+	30, 63, 62, 21, 23, 32, 67, 22, 21, 6,
+	56, 12, 21, 27, 1, 51, 53, 4, 11, 24,
+	2,
 }
-var yyPact = [...]int{
 
-	4, -1000, 34, 1, 8, 26, 38, 0, -1000, 27,
-	45, 38, -1000, 14, 26, 38, 38, -1000, -1000, -4,
-	33, -6, 25, -1, -1000, 46, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, 2, -1000, 16, -1000, -1000, 34, -6,
-	5, -1000, -10, -1000, 15, 26, 12, -1000, -1000,
+var yyPact = [...]int16{
+	-8, -1000, -1000, 59, 12, -9, 1, 61, -5, 48,
+	46, -1000, 54, 64, -10, -1000, 41, 55, 46, 46,
+	-1000, 30, -1000, 64, -14, 64, 48, 46, 46, -1000,
+	-1000, 26, 9, -1000, 44, 9, 38, 60, 64, -1000,
+	-11, -1000, 68, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -6, -1000, 24, -1000, -1000, -1000, 64, 59, 9,
+	-7, -1000, -12, -1000, 39, 48, 7, -1000, -1000,
 }
-var yyPgo = [...]int{
 
-	0, 0, 55, 54, 53, 14, 1, 50, 3, 36,
+var yyPgo = [...]int8{
+	0, 0, 5, 80, 79, 78, 77, 76, 4, 1,
+	75, 3, 2, 74,
 }
-var yyR1 = [...]int{
 
-	0, 6, 6, 6, 6, 7, 7, 7, 4, 4,
-	9, 9, 9, 9, 2, 2, 5, 5, 5, 5,
-	5, 1, 8, 8, 3, 3, 3, 3, 3,
+var yyR1 = [...]int8{
+	0, 9, 9, 9, 9, 10, 10, 10, 7, 7,
+	12, 12, 12, 12, 2, 2, 8, 8, 8, 8,
+	8, 8, 8, 1, 11, 11, 4, 4, 5, 5,
+	5, 5, 6, 6, 13, 13, 3, 3, 3, 3,
+	3,
 }
-var yyR2 = [...]int{
 
+var yyR2 = [...]int8{
 	0, 1, 1, 1, 1, 0, 1, 3, 1, 1,
 	4, 7, 4, 1, 1, 2, 3, 3, 3, 3,
-	3, 1, 1, 4, 2, 4, 6, 8, 10,
+	3, 2, 3, 1, 1, 4, 1, 3, 1, 2,
+	3, 4, 0, 3, 2, 6, 2, 4, 6, 8,
+	10,
 }
-var yyChk = [...]int{
 
-	-1000, -3, 17, -8, 9, 17, 14, -1, 9, -5,
-	-2, 10, -9, 7, 17, 5, 6, 13, -9, 4,
-	-5, 10, 14, -1, -5, -5, -6, 9, 15, 16,
-	8, 12, 11, -7, -6, -4, 12, 9, 17, 18,
-	11, 13, -8, -6, 14, 17, 12, -1, 13,
+var yyChk = [...]int16{
+	-1000, -13, -3, 22, -6, -11, 10, 19, 18, 22,
+	15, -5, 10, 20, -1, 10, -8, -2, 7, 11,
+	-12, 8, 13, 10, -4, -2, 22, 5, 6, 14,
+	-12, 4, 10, -8, -8, 11, 15, -2, 23, 21,
+	-1, -8, -8, -9, 10, 16, 17, 9, 13, -9,
+	12, -10, -9, -7, 13, 10, 10, -2, 22, 23,
+	12, 14, -11, -9, 15, 22, 13, -1, 14,
 }
-var yyDef = [...]int{
 
-	0, -2, 0, 24, 22, 0, 0, 25, 21, 0,
-	0, 0, 14, 13, 0, 0, 0, 23, 15, 0,
-	0, 5, 0, 26, 18, 19, 16, 17, 1, 2,
-	3, 4, 20, 0, 6, 0, 8, 9, 0, 0,
-	10, 12, 27, 7, 0, 0, 0, 28, 11,
+var yyDef = [...]int8{
+	0, -2, 32, 0, 34, 36, 24, 0, 0, 0,
+	0, 33, 28, 0, 37, 23, 0, 0, 0, 0,
+	14, 13, 29, 0, 0, 26, 0, 0, 0, 25,
+	15, 0, 0, 21, 0, 5, 0, 30, 0, 35,
+	38, 19, 20, 16, 18, 1, 2, 3, 4, 17,
+	22, 0, 6, 0, 8, 9, 31, 27, 0, 0,
+	10, 12, 39, 7, 0, 0, 0, 40, 11,
 }
-var yyTok1 = [...]int{
 
+var yyTok1 = [...]int8{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 18, 3, 3, 17,
+	3, 3, 3, 3, 23, 3, 3, 22,
 }
-var yyTok2 = [...]int{
 
+var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 }
-var yyTok3 = [...]int{
+
+var yyTok3 = [...]int8{
 	0,
 }
 
@@ -232,9 +265,9 @@ func yyErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := yyPact[state]
+	base := int(yyPact[state])
 	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
-		if n := base + tok; n >= 0 && n < yyLast && yyChk[yyAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -244,13 +277,13 @@ func yyErrorMessage(state, lookAhead int) string {
 
 	if yyDef[state] == -2 {
 		i := 0
-		for yyExca[i] != -1 || yyExca[i+1] != state {
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; yyExca[i] >= 0; i += 2 {
-			tok := yyExca[i]
+			tok := int(yyExca[i])
 			if tok < TOKSTART || yyExca[i+1] == 0 {
 				continue
 			}
@@ -281,30 +314,30 @@ func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = yyTok1[0]
+		token = int(yyTok1[0])
 		goto out
 	}
 	if char < len(yyTok1) {
-		token = yyTok1[char]
+		token = int(yyTok1[char])
 		goto out
 	}
 	if char >= yyPrivate {
 		if char < yyPrivate+len(yyTok2) {
-			token = yyTok2[char-yyPrivate]
+			token = int(yyTok2[char-yyPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(yyTok3); i += 2 {
-		token = yyTok3[i+0]
+		token = int(yyTok3[i+0])
 		if token == char {
-			token = yyTok3[i+1]
+			token = int(yyTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = yyTok2[1] /* unknown char */
+		token = int(yyTok2[1]) /* unknown char */
 	}
 	if yyDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
@@ -359,7 +392,7 @@ yystack:
 	yyS[yyp].yys = yystate
 
 yynewstate:
-	yyn = yyPact[yystate]
+	yyn = int(yyPact[yystate])
 	if yyn <= yyFlag {
 		goto yydefault /* simple state */
 	}
@@ -370,8 +403,8 @@ yynewstate:
 	if yyn < 0 || yyn >= yyLast {
 		goto yydefault
 	}
-	yyn = yyAct[yyn]
-	if yyChk[yyn] == yytoken { /* valid shift */
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
 		yyrcvr.char = -1
 		yytoken = -1
 		yyVAL = yyrcvr.lval
@@ -384,7 +417,7 @@ yynewstate:
 
 yydefault:
 	/* default state action */
-	yyn = yyDef[yystate]
+	yyn = int(yyDef[yystate])
 	if yyn == -2 {
 		if yyrcvr.char < 0 {
 			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
@@ -393,18 +426,18 @@ yydefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if yyExca[xi+0] == -1 && yyExca[xi+1] == yystate {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			yyn = yyExca[xi+0]
+			yyn = int(yyExca[xi+0])
 			if yyn < 0 || yyn == yytoken {
 				break
 			}
 		}
-		yyn = yyExca[xi+1]
+		yyn = int(yyExca[xi+1])
 		if yyn < 0 {
 			goto ret0
 		}
@@ -426,10 +459,10 @@ yydefault:
 
 			/* find a state where "error" is a legal shift action */
 			for yyp >= 0 {
-				yyn = yyPact[yyS[yyp].yys] + yyErrCode
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
 				if yyn >= 0 && yyn < yyLast {
-					yystate = yyAct[yyn] /* simulate a shift of "error" */
-					if yyChk[yystate] == yyErrCode {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
 						goto yystack
 					}
 				}
@@ -465,7 +498,7 @@ yydefault:
 	yypt := yyp
 	_ = yypt // guard against "declared and not used"
 
-	yyp -= yyR2[yyn]
+	yyp -= int(yyR2[yyn])
 	// yyp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if yyp+1 >= len(yyS) {
@@ -476,16 +509,16 @@ yydefault:
 	yyVAL = yyS[yyp+1]
 
 	/* consult goto table to find next state */
-	yyn = yyR1[yyn]
-	yyg := yyPgo[yyn]
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
 	yyj := yyg + yyS[yyp].yys + 1
 
 	if yyj >= yyLast {
-		yystate = yyAct[yyg]
+		yystate = int(yyAct[yyg])
 	} else {
-		yystate = yyAct[yyj]
-		if yyChk[yystate] != -yyn {
-			yystate = yyAct[yyg]
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -493,54 +526,54 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:71
+//line qs.y:83
 		{
 			yyVAL.iface = yyDollar[1].s
 		}
 	case 2:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:72
+//line qs.y:84
 		{
 			yyVAL.iface = yyDollar[1].ub
 		}
 	case 3:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:73
+//line qs.y:85
 		{
 			yyVAL.iface = yyDollar[1].b
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:74
+//line qs.y:86
 		{
 			yyVAL.iface = yyDollar[1].i
 		}
 	case 5:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line qs.y:77
+//line qs.y:89
 		{
 		}
 	case 6:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:79
+//line qs.y:91
 		{
 			yyVAL.vals = append(yyVAL.vals, reflect.ValueOf(yyDollar[1].iface))
 		}
 	case 7:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:82
+//line qs.y:94
 		{
 			yyVAL.vals = append(yyDollar[1].vals, reflect.ValueOf(yyDollar[3].iface))
 		}
 	case 8:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:87
+//line qs.y:99
 		{
 			yyVAL.arraySel = QSArraySelT(yyDollar[1].i)
 		}
 	case 9:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:90
+//line qs.y:102
 		{
 			switch yyDollar[1].s {
 			case "ANY":
@@ -555,7 +588,7 @@ yydefault:
 		}
 	case 10:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line qs.y:104
+//line qs.y:116
 		{
 			yyVAL.field = &QSField{
 				Name:              yyDollar[1].s,
@@ -566,7 +599,7 @@ yydefault:
 		}
 	case 11:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line qs.y:112
+//line qs.y:124
 		{
 			yyVAL.field = &QSField{
 				Name:              yyDollar[1].s,
@@ -577,7 +610,7 @@ yydefault:
 		}
 	case 12:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line qs.y:120
+//line qs.y:132
 		{
 			yyVAL.field = &QSField{
 				Name:     yyDollar[1].s,
@@ -587,7 +620,7 @@ yydefault:
 		}
 	case 13:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:127
+//line qs.y:139
 		{
 			yyVAL.field = &QSField{
 				Name: yyDollar[1].s,
@@ -595,14 +628,14 @@ yydefault:
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:134
+//line qs.y:146
 		{
 			yyVAL.sel = *yyDollar[1].field
 			yyVAL.sel.Next = nil
 		}
 	case 15:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line qs.y:138
+//line qs.y:150
 		{
 			// Adding them on to the end of our linked list
 			f := &yyVAL.sel
@@ -615,7 +648,7 @@ yydefault:
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:150
+//line qs.y:162
 		{
 			// Since we drop quotes when passing the token from the lexer...
 			raw := fmt.Sprintf("%s %s", yyDollar[1].sel.String(), yyDollar[2].op.String())
@@ -636,7 +669,31 @@ yydefault:
 		}
 	case 17:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:168
+//line qs.y:180
+		{
+			op := qsOpFromWord(yyDollar[2].s)
+			if op == QSOpUk {
+				yylex.Error(fmt.Sprintf("%s is not a valid comparison operator", yyDollar[2].s))
+			}
+			raw := fmt.Sprintf("%s %s", yyDollar[1].sel.String(), op.String())
+			v, is := yyDollar[3].iface.(string)
+			if is {
+				raw = fmt.Sprintf("%s %s", raw, strconv.Quote(v))
+			} else {
+				raw = fmt.Sprintf("%s %v", raw, yyDollar[3].iface)
+			}
+			yyVAL.condChain = &QSCondition{
+				Raw: raw,
+				Cmp: &QSComparer{
+					Fields: yyDollar[1].sel,
+					Op:     op,
+					To:     yyDollar[3].iface,
+				},
+			}
+		}
+	case 18:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line qs.y:201
 		{
 			if yyDollar[3].s == "true" || yyDollar[3].s == "false" {
 				val := yyDollar[3].s == "true"
@@ -661,76 +718,179 @@ yydefault:
 				yylex.Error(fmt.Sprintf("unexpected %v", yyDollar[3].s))
 			}
 		}
-	case 18:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:192
-		{
-			yyDollar[1].condChain.Raw += " && " + yyDollar[3].condChain.String()
-			yyDollar[1].condChain.PushAnd(yyDollar[3].condChain)
-		}
 	case 19:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:196
+//line qs.y:227
 		{
-			yyDollar[1].condChain.Raw += " || " + yyDollar[3].condChain.String()
-			yyDollar[1].condChain.PushOr(yyDollar[3].condChain)
+			yyVAL.condChain = &QSCondition{
+				Raw: yyDollar[1].condChain.String() + " && " + yyDollar[3].condChain.String(),
+				Cmp: yyDollar[1].condChain,
+				And: yyDollar[3].condChain,
+			}
 		}
 	case 20:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line qs.y:200
+//line qs.y:234
 		{
-			c := new(QSCondition)
-			*c = *yyDollar[2].condChain
 			yyVAL.condChain = &QSCondition{
-				Raw: fmt.Sprintf("(%s)", c.Raw),
-				Cmp: c,
-				And: nil,
-				Or:  nil,
+				Raw: yyDollar[1].condChain.String() + " || " + yyDollar[3].condChain.String(),
+				Cmp: yyDollar[1].condChain,
+				Or:  yyDollar[3].condChain,
 			}
 		}
 	case 21:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line qs.y:241
+		{
+			yyVAL.condChain = &QSCondition{
+				Raw: "!" + yyDollar[2].condChain.String(),
+				Cmp: yyDollar[2].condChain,
+				Not: true,
+			}
+		}
+	case 22:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line qs.y:248
+		{
+			yyVAL.condChain = &QSCondition{
+				Raw: fmt.Sprintf("(%s)", yyDollar[2].condChain.Raw),
+				Cmp: yyDollar[2].condChain,
+			}
+		}
+	case 23:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:212
+//line qs.y:256
 		{
 			yyVAL.s = yyDollar[1].s
 		}
-	case 22:
+	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line qs.y:217
+//line qs.y:261
 		{
 			yyVAL.qss = QSSelector{
 				Resource: yyDollar[1].s,
 			}
 		}
-	case 23:
+	case 25:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line qs.y:222
+//line qs.y:266
 		{
 			yyVAL.qss = QSSelector{
 				Resource:  yyDollar[1].s,
 				Condition: yyDollar[3].condChain,
 			}
 		}
-	case 24:
+	case 26:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line qs.y:274
+		{
+			yyVAL.fields = []QSField{yyDollar[1].sel}
+		}
+	case 27:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line qs.y:277
+		{
+			yyVAL.fields = append(yyDollar[1].fields, yyDollar[3].sel)
+		}
+	case 28:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line qs.y:282
+		{
+			if yyDollar[1].s != "count" {
+				yylex.Error(fmt.Sprintf("unknown stage %s", yyDollar[1].s))
+			}
+			yyVAL.stage = QSStage{Type: QSStageCount}
+		}
+	case 29:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line qs.y:288
+		{
+			if yyDollar[1].s != "limit" {
+				yylex.Error(fmt.Sprintf("unknown stage %s", yyDollar[1].s))
+			}
+			if yyDollar[2].i < 0 {
+				yylex.Error("limit can't be negative")
+			}
+			yyVAL.stage = QSStage{Type: QSStageLimit, Limit: int(yyDollar[2].i)}
+		}
+	case 30:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line qs.y:297
+		{
+			yyVAL.stage = QSStage{Field: yyDollar[3].sel}
+			if yyDollar[2].s != "by" {
+				yylex.Error(fmt.Sprintf("expected by after %s", yyDollar[1].s))
+			}
+			switch yyDollar[1].s {
+			case "group":
+				yyVAL.stage.Type = QSStageGroupBy
+			case "sort":
+				yyVAL.stage.Type = QSStageSortBy
+			default:
+				yylex.Error(fmt.Sprintf("unknown stage %s", yyDollar[1].s))
+			}
+		}
+	case 31:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line qs.y:311
+		{
+			if yyDollar[1].s != "sort" || yyDollar[2].s != "by" {
+				yylex.Error(fmt.Sprintf("unexpected %s", yyDollar[4].s))
+			}
+			yyVAL.stage = QSStage{Type: QSStageSortBy, Field: yyDollar[3].sel}
+			switch yyDollar[4].s {
+			case "asc":
+			case "desc":
+				yyVAL.stage.Desc = true
+			default:
+				yylex.Error(fmt.Sprintf("sort order must be asc or desc, not %s", yyDollar[4].s))
+			}
+		}
+	case 32:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line qs.y:326
+		{
+			yyVAL.stages = nil
+		}
+	case 33:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line qs.y:329
+		{
+			yyVAL.stages = append(yyDollar[1].stages, yyDollar[3].stage)
+		}
+	case 34:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line qs.y:334
+		{
+			yylex.(*qsLexer).result.Stages = yyDollar[2].stages
+		}
+	case 35:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line qs.y:337
+		{
+			yylex.(*qsLexer).result.Stages = yyDollar[2].stages
+			yylex.(*qsLexer).result.Projection = yyDollar[5].fields
+		}
+	case 36:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line qs.y:232
+//line qs.y:345
 		{
 			yylex.(*qsLexer).result = QueryString{
 				Sel: yyDollar[2].qss,
 			}
 		}
-	case 25:
+	case 37:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line qs.y:238
+//line qs.y:351
 		{
 			yylex.(*qsLexer).result = QueryString{
 				Sel:           yyDollar[2].qss,
 				ResourceGroup: yyDollar[4].s,
 			}
 		}
-	case 26:
+	case 38:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line qs.y:245
+//line qs.y:358
 		{
 			yylex.(*qsLexer).result = QueryString{
 				Sel:           yyDollar[2].qss,
@@ -738,9 +898,9 @@ yydefault:
 				Name:          yyDollar[6].s,
 			}
 		}
-	case 27:
+	case 39:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line qs.y:253
+//line qs.y:366
 		{
 			yylex.(*qsLexer).result = QueryString{
 				Sel:           yyDollar[2].qss,
@@ -751,9 +911,9 @@ yydefault:
 				},
 			}
 		}
-	case 28:
+	case 40:
 		yyDollar = yyS[yypt-10 : yypt+1]
-		//line qs.y:264
+//line qs.y:377
 		{
 			yylex.(*qsLexer).result = QueryString{
 				Sel:           yyDollar[2].qss,
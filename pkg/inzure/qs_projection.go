@@ -0,0 +1,183 @@
+package inzure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QSRecord is a single flattened result of a query string with a projection.
+// The keys are the projected fields without their leading dot, for example
+// `-> {.Meta.Name}` gives the key `Meta.Name`.
+type QSRecord map[string]interface{}
+
+// ProjectionNames returns the keys used in each QSRecord in the order they
+// were given in the projection.
+func (qs *QueryString) ProjectionNames() []string {
+	names := make([]string, len(qs.Projection))
+	for i := range qs.Projection {
+		names[i] = strings.TrimPrefix(qs.Projection[i].String(), ".")
+	}
+	return names
+}
+
+func (qs *QueryString) projectionString() string {
+	if len(qs.Projection) == 0 {
+		return ""
+	}
+	fields := make([]string, len(qs.Projection))
+	for i := range qs.Projection {
+		fields[i] = qs.Projection[i].String()
+	}
+	return fmt.Sprintf(" -> {%s}", strings.Join(fields, ", "))
+}
+
+// Project applies the QueryString's projection to every value in v, which
+// should be the result of ReflectFromParsedQueryString.
+func (qs *QueryString) Project(v reflect.Value) ([]QSRecord, error) {
+	if len(qs.Projection) == 0 {
+		return nil, errors.New("query string has no projection")
+	}
+	names := qs.ProjectionNames()
+	v = derefPtr(v)
+	records := make([]QSRecord, 0)
+	if !v.IsValid() {
+		return records, nil
+	}
+	project := func(e reflect.Value) error {
+		rec := make(QSRecord, len(names))
+		for i := range qs.Projection {
+			val, err := qsProject(e, &qs.Projection[i])
+			if err != nil {
+				return err
+			}
+			rec[names[i]] = val
+		}
+		records = append(records, rec)
+		return nil
+	}
+	if v.Kind() != reflect.Slice {
+		return records, project(v)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := project(v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// ProjectFromQueryString runs the query string and returns only the fields
+// selected by its projection as flat records. For example:
+//
+//	/WebApps[.HTTPSOnly != BoolTrue] -> {.Meta.Name, .DefaultHostname}
+func (s *Subscription) ProjectFromQueryString(qs string) ([]QSRecord, error) {
+	var p QueryString
+	if err := p.Parse(qs); err != nil {
+		return nil, err
+	}
+	if len(p.Projection) == 0 {
+		return nil, fmt.Errorf("query string %s has no projection", qs)
+	}
+	v, err := s.ReflectFromParsedQueryString(&p)
+	if err != nil {
+		return nil, err
+	}
+	return p.Project(v)
+}
+
+// qsProject walks the field selector on v and returns the selected value. Nil
+// values along the way give a nil result. The ANY and ALL array selectors
+// return a slice of the rest of the selector applied to every element.
+func qsProject(v reflect.Value, f *QSField) (interface{}, error) {
+	for ; f != nil; f = f.Next {
+		v = derefPtr(v)
+		if !v.IsValid() {
+			return nil, nil
+		}
+		if f.IsMethod {
+			ret, err := qsCallMethod(v, f)
+			if err != nil {
+				return nil, err
+			}
+			v = ret
+			continue
+		}
+		var err error
+		v, err = qsSelFromStruct(v, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !f.IsArray {
+			continue
+		}
+		v = derefPtr(v)
+		if err := qsCheckArray(v); err != nil {
+			return nil, err
+		}
+		switch f.ArraySel {
+		case QSArraySelLen:
+			return v.Len(), nil
+		case QSArraySelAny, QSArraySelAll:
+			vals := make([]interface{}, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				val, err := qsProject(v.Index(i), f.Next)
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, val)
+			}
+			return vals, nil
+		default:
+			if f.ArraySel < 0 {
+				return nil, fmt.Errorf("%d is a bad array selector", f.ArraySel)
+			}
+			if int(f.ArraySel) >= v.Len() {
+				return nil, nil
+			}
+			v = v.Index(int(f.ArraySel))
+		}
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	return v.Interface(), nil
+}
+
+// qsCallMethod calls the method described by m on v and returns the selected
+// return value.
+func qsCallMethod(v reflect.Value, m *QSField) (reflect.Value, error) {
+	fun := v.MethodByName(m.Name)
+	if !fun.IsValid() {
+		// Try the pointer
+		if v.CanAddr() {
+			fun = v.Addr().MethodByName(m.Name)
+		} else {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			fun = ptr.MethodByName(m.Name)
+		}
+	}
+	if !fun.IsValid() {
+		return qsNilVal(), fmt.Errorf("type %v has no method %s", v.Type(), m.Name)
+	}
+	args := make([]reflect.Value, len(m.MethodArgs))
+	copy(args, m.MethodArgs)
+	if err := qsVerifyMethodArgs(fun, m.MethodReturnIndex, args); err != nil {
+		return qsNilVal(), err
+	}
+	ret := fun.Call(args)
+	for _, r := range ret {
+		if !r.CanInterface() {
+			continue
+		}
+		if err, is := r.Interface().(error); is && err != nil {
+			return qsNilVal(), err
+		}
+	}
+	return ret[m.MethodReturnIndex], nil
+}
@@ -0,0 +1,273 @@
+package inzure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// QSStage is applied to the results of a query string after the resources
+// have been selected. Stages are given after a `|` and run in order:
+//
+//	/StorageAccounts | sort by .Containers[LEN] desc | limit 10
+//	/NetworkSecurityGroups | group by .Meta.ResourceGroupName
+//	/WebApps[.HTTPSOnly != BoolTrue] | count
+//
+// After a group by stage the results are QSGroups, so later stages and the
+// projection select fields on those instead.
+type QSStage struct {
+	Type QSStageT
+	// Field is what to group or sort by
+	Field QSField
+	// Desc sorts in descending order
+	Desc  bool
+	Limit int
+}
+
+func (st *QSStage) String() string {
+	switch st.Type {
+	case QSStageGroupBy:
+		return fmt.Sprintf("%s %s", st.Type, st.Field.String())
+	case QSStageSortBy:
+		s := fmt.Sprintf("%s %s", st.Type, st.Field.String())
+		if st.Desc {
+			s += " desc"
+		}
+		return s
+	case QSStageLimit:
+		return fmt.Sprintf("%s %d", st.Type, st.Limit)
+	default:
+		return st.Type.String()
+	}
+}
+
+// QSGroup is a single group of results from a group by stage.
+type QSGroup struct {
+	Key   interface{}
+	Count int
+	// Items is a slice of the results in this group with the same type as
+	// the query string would have returned without the group by.
+	Items interface{}
+}
+
+var qsGroupType = reflect.TypeOf(QSGroup{})
+
+func (qs *QueryString) stagesString() string {
+	var sb strings.Builder
+	for i := range qs.Stages {
+		sb.WriteString(" | ")
+		sb.WriteString(qs.Stages[i].String())
+	}
+	return sb.String()
+}
+
+// Counts returns whether the query string ends with a count stage.
+func (qs *QueryString) Counts() bool {
+	l := len(qs.Stages)
+	return l > 0 && qs.Stages[l-1].Type == QSStageCount
+}
+
+// Groups returns whether the query string has a group by stage.
+func (qs *QueryString) Groups() bool {
+	for i := range qs.Stages {
+		if qs.Stages[i].Type == QSStageGroupBy {
+			return true
+		}
+	}
+	return false
+}
+
+// validStages checks the stages against the base type of the results and
+// returns the type of the results after every stage.
+func (qs *QueryString) validStages(ty reflect.Type) (reflect.Type, error) {
+	for i := range qs.Stages {
+		st := &qs.Stages[i]
+		switch st.Type {
+		case QSStageCount:
+			if i != len(qs.Stages)-1 {
+				return nil, errors.New("count must be the last stage")
+			}
+		case QSStageGroupBy:
+			if err := validFields(ty, &st.Field); err != nil {
+				return nil, err
+			}
+			ty = qsGroupType
+		case QSStageSortBy:
+			if err := validFields(ty, &st.Field); err != nil {
+				return nil, err
+			}
+		case QSStageLimit:
+			if st.Limit < 0 {
+				return nil, errors.New("limit can't be negative")
+			}
+		default:
+			return nil, fmt.Errorf("unknown stage %s", st.Type)
+		}
+	}
+	return ty, nil
+}
+
+// applyStages runs all of the stages on v which is the value filled in by
+// the query string. A count returns a *int, a group by returns a *[]QSGroup,
+// and everything else returns a pointer to a slice of the original type.
+func (qs *QueryString) applyStages(v reflect.Value) (reflect.Value, error) {
+	if len(qs.Stages) == 0 {
+		return v, nil
+	}
+	if v.Kind() != reflect.Ptr {
+		return qsNilVal(), fmt.Errorf("can't apply stages to nonpointer %v", v.Type())
+	}
+	items := v.Elem()
+	if items.Kind() != reflect.Slice {
+		// A single resource is treated as a list of one
+		one := reflect.MakeSlice(reflect.SliceOf(items.Type()), 0, 1)
+		if items.Kind() != reflect.Ptr || !items.IsNil() {
+			one = reflect.Append(one, items)
+		}
+		items = one
+	} else {
+		// Don't reorder the caller's slice
+		cp := reflect.MakeSlice(items.Type(), items.Len(), items.Len())
+		reflect.Copy(cp, items)
+		items = cp
+	}
+	for i := range qs.Stages {
+		st := &qs.Stages[i]
+		var err error
+		switch st.Type {
+		case QSStageCount:
+			n := items.Len()
+			return reflect.ValueOf(&n), nil
+		case QSStageGroupBy:
+			items, err = qsGroupBy(items, &st.Field)
+		case QSStageSortBy:
+			err = qsSortBy(items, &st.Field, st.Desc)
+		case QSStageLimit:
+			if st.Limit < items.Len() {
+				items = items.Slice(0, st.Limit)
+			}
+		default:
+			err = fmt.Errorf("unknown stage %s", st.Type)
+		}
+		if err != nil {
+			return qsNilVal(), err
+		}
+	}
+	ret := reflect.New(items.Type())
+	ret.Elem().Set(items)
+	return ret, nil
+}
+
+func qsGroupBy(items reflect.Value, f *QSField) (reflect.Value, error) {
+	groups := make([]QSGroup, 0)
+	members := make([]reflect.Value, 0)
+	idx := make(map[interface{}]int)
+	for i := 0; i < items.Len(); i++ {
+		e := items.Index(i)
+		key, err := qsProject(e, f)
+		if err != nil {
+			return qsNilVal(), err
+		}
+		mapKey := qsGroupKey(key)
+		gi, has := idx[mapKey]
+		if !has {
+			gi = len(groups)
+			idx[mapKey] = gi
+			groups = append(groups, QSGroup{Key: key})
+			members = append(members, reflect.MakeSlice(items.Type(), 0, 1))
+		}
+		members[gi] = reflect.Append(members[gi], e)
+	}
+	for i := range groups {
+		groups[i].Count = members[i].Len()
+		groups[i].Items = members[i].Interface()
+	}
+	return reflect.ValueOf(groups), nil
+}
+
+// qsGroupKey is the map key used for a group. Pointers and interfaces, such
+// as AzureIPv4s, are keyed by their String() or the value they point to so
+// equal values end up in the same group instead of being compared by
+// identity. Slices and the like can't be map keys so they're formatted.
+func qsGroupKey(key interface{}) interface{} {
+	if key == nil {
+		return nil
+	}
+	v := reflect.ValueOf(key)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if s, is := v.Interface().(fmt.Stringer); is {
+			return s.String()
+		}
+		v = v.Elem()
+	}
+	if !v.Type().Comparable() {
+		return fmt.Sprintf("%v", v.Interface())
+	}
+	return v.Interface()
+}
+
+func qsSortBy(items reflect.Value, f *QSField, desc bool) error {
+	keys := make([]interface{}, items.Len())
+	for i := range keys {
+		var err error
+		keys[i], err = qsProject(items.Index(i), f)
+		if err != nil {
+			return err
+		}
+	}
+	// Sort an index so the keys move with the items
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if desc {
+			return qsLess(keys[order[j]], keys[order[i]])
+		}
+		return qsLess(keys[order[i]], keys[order[j]])
+	})
+	sorted := reflect.MakeSlice(items.Type(), items.Len(), items.Len())
+	for i, o := range order {
+		sorted.Index(i).Set(items.Index(o))
+	}
+	reflect.Copy(items, sorted)
+	return nil
+}
+
+// qsLess orders the values returned by qsProject. Nil always comes first and
+// values that aren't numbers, strings, or bools are compared as strings.
+func qsLess(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+	switch av.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if bv.CanInt() {
+			return av.Int() < bv.Int()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if bv.CanUint() {
+			return av.Uint() < bv.Uint()
+		}
+	case reflect.Float32, reflect.Float64:
+		if bv.CanFloat() {
+			return av.Float() < bv.Float()
+		}
+	case reflect.String:
+		if bv.Kind() == reflect.String {
+			return av.String() < bv.String()
+		}
+	case reflect.Bool:
+		if bv.Kind() == reflect.Bool {
+			return !av.Bool() && bv.Bool()
+		}
+	}
+	return fmt.Sprintf("%v", a) < fmt.Sprintf("%v", b)
+}
@@ -472,6 +472,48 @@ func TestQSCompareParensTrailing(t *testing.T) {
 	qsPostLexCmpTest(t, s, true)
 }
 
+func TestQSCompareNot(t *testing.T) {
+	tMap := map[string]bool{
+		"/Unused[!.B.U == 1000]":                                       false,
+		"/Unused[!.B.U != 1000]":                                       true,
+		"/Unused[!(.B.U == 1000)]":                                     false,
+		"/Unused[!!(.B.U == 1000)]":                                    true,
+		"/Unused[!(.B.U == 1000 && .B.I64 > 0)]":                       true,
+		"/Unused[!(.B.U == 1000 || .B.I64 > 0)]":                       false,
+		"/Unused[!(.B.U < 0 || .B.I64 > 0) && .B.S == \"Base\"]":       true,
+		"/Unused[.B.U < 0 || !(.B.S !~ \"Ba.*\")]":                     true,
+		"/Unused[!.B.U < 0 && !.B.I64 > 0]":                            true,
+		"/Unused[!(.B.U == 1000 && (.B.I64 > 0 || !.B.U64 > 0))]":      false,
+		"/Unused[.B.U == 1000 && !(.B.UB == BoolFalse || .B.U64 < 0)]": true,
+	}
+	for s, ex := range tMap {
+		qsPostLexCmpTest(t, s, ex)
+	}
+}
+
+func TestQSComparePrecedence(t *testing.T) {
+	tA := ".B.U == 1000"
+	fA := ".B.U < 0"
+	fB := ".B.I64 > 0"
+	fC := ".B.U64 > 0"
+	tMap := map[string]bool{
+		// && binds tighter than || on either side
+		fmt.Sprintf("/Unused[%s || %s && %s]", tA, fA, fB):               true,
+		fmt.Sprintf("/Unused[%s && %s || %s]", fA, fB, tA):               true,
+		fmt.Sprintf("/Unused[%s && %s || %s && %s]", tA, fA, tA, fB):     false,
+		fmt.Sprintf("/Unused[%s && %s || %s && %s]", fA, tA, tA, tA):     true,
+		fmt.Sprintf("/Unused[%s || %s && %s || %s]", fA, tA, fB, fC):     false,
+		fmt.Sprintf("/Unused[%s || %s && %s || %s]", fA, tA, fB, tA):     true,
+		fmt.Sprintf("/Unused[(%s || %s) && (%s || %s)]", fA, tA, fB, tA): true,
+		fmt.Sprintf("/Unused[(%s || %s) && %s || %s]", fA, tA, fB, fC):   false,
+		fmt.Sprintf("/Unused[%s && (%s || %s && %s)]", tA, fA, tA, fB):   false,
+		fmt.Sprintf("/Unused[%s && (%s && %s || %s)]", tA, fA, tA, tA):   true,
+	}
+	for s, ex := range tMap {
+		qsPostLexCmpTest(t, s, ex)
+	}
+}
+
 func TestQSFieldsExported(t *testing.T) {
 	bad := []string{
 		"/Z[.a == BoolTrue]",
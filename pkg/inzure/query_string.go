@@ -13,6 +13,12 @@ type QueryString struct {
 	ResourceGroup string
 	Name          string
 	Subresource   *QueryString
+	// Projection is the list of fields given after -> to select from each
+	// result. This is only set on the top level QueryString.
+	Projection []QSField
+	// Stages are run in order on the results before the projection. This is
+	// only set on the top level QueryString.
+	Stages []QSStage
 
 	finalType reflect.Type
 }
@@ -21,6 +27,10 @@ func (qs *QueryString) String() string {
 	if qs.Raw != "" {
 		return qs.Raw
 	}
+	return qs.pathString() + qs.stagesString() + qs.projectionString()
+}
+
+func (qs *QueryString) pathString() string {
 	s := qs.Sel.String()
 	if qs.ResourceGroup == "" {
 		return s
@@ -41,7 +51,8 @@ func (qs *QueryString) String() string {
 // it represents.
 func (qs *QueryString) Parse(s string) error {
 	l := newLexer(s)
-	if yyParse(l) != 0 {
+	// Errors from grammar actions don't stop the parse
+	if yyParse(l) != 0 || l.err != nil {
 		return l.err
 	}
 	*qs = l.result
@@ -111,6 +122,18 @@ func (qs *QueryString) Validate() error {
 	if !canFind {
 		return errors.New("query string contains invalid selector")
 	}
+	elemTy, err := qs.validStages(getBaseType(ty))
+	if err != nil {
+		return err
+	}
+	if len(qs.Projection) > 0 && qs.Counts() {
+		return errors.New("can't use a projection with count")
+	}
+	for i := range qs.Projection {
+		if err := validFields(elemTy, &qs.Projection[i]); err != nil {
+			return err
+		}
+	}
 	if qs.Sel.Condition == nil {
 		return nil
 	}
@@ -118,36 +141,13 @@ func (qs *QueryString) Validate() error {
 }
 
 func validCondition(ty reflect.Type, cond *QSCondition) error {
-	if cmp, is := cond.Cmp.(*QSComparer); is {
-		checkTy := ty
-		field := &cmp.Fields
-		for field != nil {
-			if field.IsMethod {
-				if !typeHasMethod(checkTy, field.Name, false) {
-					return fmt.Errorf("type %s does not have method %s", checkTy.Name(), field.Name)
-				}
-				// No selectors allowed after a method for now
-				if field.Next != nil {
-					return errors.New("selectors not allowed after method call")
-				}
-				break
-			}
-			sf, has := checkTy.FieldByName(field.Name)
-			if !has {
-				return fmt.Errorf("type %s does not have field %s", checkTy.Name(), field.Name)
-			}
-
-			if field.Next == nil {
-				break
-			}
-
-			field = field.Next
-			if field.IsArray {
-				if sf.Type.Kind() != reflect.Slice {
-					return fmt.Errorf("type %s field %s is not a slice", checkTy.Name(), field.Name)
-				}
-			}
-			checkTy = getBaseType(sf.Type)
+	if sub, is := cond.Cmp.(*QSCondition); is {
+		if err := validCondition(ty, sub); err != nil {
+			return err
+		}
+	} else if cmp, is := cond.Cmp.(*QSComparer); is {
+		if err := validFields(ty, &cmp.Fields); err != nil {
+			return err
 		}
 	}
 	if cond.And != nil {
@@ -161,6 +161,42 @@ func validCondition(ty reflect.Type, cond *QSCondition) error {
 	return nil
 }
 
+func validFields(ty reflect.Type, field *QSField) error {
+	checkTy := ty
+	for field != nil {
+		if field.IsMethod {
+			if !typeHasMethod(checkTy, field.Name, false) {
+				return fmt.Errorf("type %s does not have method %s", checkTy.Name(), field.Name)
+			}
+			// No selectors allowed after a method for now
+			if field.Next != nil {
+				return errors.New("selectors not allowed after method call")
+			}
+			break
+		}
+		if checkTy.Kind() != reflect.Struct {
+			return fmt.Errorf("can't get field %s on nonstruct %s", field.Name, checkTy)
+		}
+		sf, has := checkTy.FieldByName(field.Name)
+		if !has {
+			return fmt.Errorf("type %s does not have field %s", checkTy.Name(), field.Name)
+		}
+
+		if field.Next == nil {
+			break
+		}
+
+		field = field.Next
+		if field.IsArray {
+			if sf.Type.Kind() != reflect.Slice {
+				return fmt.Errorf("type %s field %s is not a slice", checkTy.Name(), field.Name)
+			}
+		}
+		checkTy = getBaseType(sf.Type)
+	}
+	return nil
+}
+
 // GetReturnType returns the reflect.Type that should be returned by this
 // query string when used with a Subscription.
 func (qs *QueryString) GetReturnType() (reflect.Type, bool) {
@@ -171,9 +207,7 @@ func (qs *QueryString) GetReturnType() (reflect.Type, bool) {
 	if qs.Sel.Resource == "" {
 		return reflect.TypeOf(nil), false
 	}
-	var rg ResourceGroup
-	t := reflect.TypeOf(rg)
-	ft, has := t.FieldByName(qs.Sel.Resource)
+	ft, has := qsResourceField(qs.Sel.Resource)
 	if !has {
 		return reflect.TypeOf(nil), false
 	}
@@ -224,14 +258,33 @@ func GetQSFillableValue(qs *QueryString) reflect.Value {
 	return reflect.New(t)
 }
 
+// qsResourceField finds the field for a base resource in a query string.
+// Most resources are fields of the ResourceGroup, but a few, such as
+// RoleDefinitions, live on the Subscription itself.
+func qsResourceField(name string) (reflect.StructField, bool) {
+	var rg ResourceGroup
+	if ft, has := reflect.TypeOf(rg).FieldByName(name); has {
+		return ft, true
+	}
+	return qsSubscriptionField(name)
+}
+
+// qsSubscriptionField finds a resource slice field on the Subscription.
+func qsSubscriptionField(name string) (reflect.StructField, bool) {
+	var s Subscription
+	ft, has := reflect.TypeOf(s).FieldByName(name)
+	if !has || !ft.IsExported() || ft.Type.Kind() != reflect.Slice {
+		return ft, false
+	}
+	return ft, true
+}
+
 // GetQSFillableValueForString returns a reflect.Value that can be filled by
 // the *QueryString methods on a Subscription. You can either give this a full
 // QueryString or the name of a field in a ResourceGroup.
 func GetQSFillableValueForString(qs string) reflect.Value {
 	if !strings.HasPrefix(qs, "/") {
-		var rg ResourceGroup
-		ty := reflect.TypeOf(rg)
-		ft, has := ty.FieldByName(qs)
+		ft, has := qsResourceField(qs)
 		if !has {
 			return reflect.ValueOf(nil)
 		}
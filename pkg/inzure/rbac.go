@@ -0,0 +1,252 @@
+package inzure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+// RolePermission is a set of allowed and excluded actions in a
+// RoleDefinition. Actions are management plane operations and DataActions
+// are data plane operations such as reading blobs.
+type RolePermission struct {
+	Actions        []string `sqlite:"table"`
+	NotActions     []string `sqlite:"table"`
+	DataActions    []string `sqlite:"table"`
+	NotDataActions []string `sqlite:"table"`
+}
+
+func (p *RolePermission) FromAzure(az *armauthorization.Permission) {
+	p.Actions = make([]string, 0, len(az.Actions))
+	p.NotActions = make([]string, 0, len(az.NotActions))
+	p.DataActions = make([]string, 0, len(az.DataActions))
+	p.NotDataActions = make([]string, 0, len(az.NotDataActions))
+	appendStrings(&p.Actions, az.Actions)
+	appendStrings(&p.NotActions, az.NotActions)
+	appendStrings(&p.DataActions, az.DataActions)
+	appendStrings(&p.NotDataActions, az.NotDataActions)
+}
+
+// AllowsAction checks whether the permission grants the given operation,
+// such as Microsoft.Storage/storageAccounts/listkeys/action. Wildcards in
+// the permission are respected.
+func (p *RolePermission) AllowsAction(action string) bool {
+	return rbacActionMatchesAny(action, p.Actions) &&
+		!rbacActionMatchesAny(action, p.NotActions)
+}
+
+// AllowsDataAction is the same as AllowsAction but for DataActions
+func (p *RolePermission) AllowsDataAction(action string) bool {
+	return rbacActionMatchesAny(action, p.DataActions) &&
+		!rbacActionMatchesAny(action, p.NotDataActions)
+}
+
+// rbacActionMatchesAny matches the action against the patterns. Actions are
+// case insensitive and * matches any run of characters.
+func rbacActionMatchesAny(action string, patterns []string) bool {
+	action = strings.ToLower(action)
+	for _, pat := range patterns {
+		if rbacActionMatches(action, strings.ToLower(pat)) {
+			return true
+		}
+	}
+	return false
+}
+
+func rbacActionMatches(action string, pat string) bool {
+	parts := strings.Split(pat, "*")
+	if len(parts) == 1 {
+		return action == pat
+	}
+	if !strings.HasPrefix(action, parts[0]) {
+		return false
+	}
+	action = action[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		idx := strings.Index(action, part)
+		if idx < 0 {
+			return false
+		}
+		action = action[idx+len(part):]
+	}
+	return strings.HasSuffix(action, parts[last])
+}
+
+// RoleDefinition is an Azure RBAC role. Both built in and custom roles are
+// collected.
+type RoleDefinition struct {
+	// Meta.Name is the GUID of the role
+	Meta             ResourceID
+	RoleName         string
+	Description      string
+	IsCustom         bool
+	AssignableScopes []string
+	Permissions      []RolePermission
+}
+
+func NewEmptyRoleDefinition() *RoleDefinition {
+	rd := &RoleDefinition{
+		AssignableScopes: make([]string, 0),
+		Permissions:      make([]RolePermission, 0),
+	}
+	rd.Meta.setupEmpty()
+	return rd
+}
+
+func (rd *RoleDefinition) FromAzure(az *armauthorization.RoleDefinition) {
+	if az.ID != nil {
+		rd.Meta.fromID(*az.ID)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&rd.RoleName, props.RoleName)
+	gValFromPtr(&rd.Description, props.Description)
+	rd.IsCustom = props.RoleType != nil && strings.EqualFold(*props.RoleType, "CustomRole")
+	appendStrings(&rd.AssignableScopes, props.AssignableScopes)
+	gSliceFromPtrSetterPtrs(
+		&rd.Permissions,
+		&props.Permissions,
+		fromAzureSetter[armauthorization.Permission, *RolePermission],
+	)
+}
+
+// AllowsAction checks whether any of the role's permissions grant the
+// action.
+func (rd *RoleDefinition) AllowsAction(action string) bool {
+	for i := range rd.Permissions {
+		if rd.Permissions[i].AllowsAction(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsDataAction checks whether any of the role's permissions grant the
+// data action.
+func (rd *RoleDefinition) AllowsDataAction(action string) bool {
+	for i := range rd.Permissions {
+		if rd.Permissions[i].AllowsDataAction(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAssignment grants a principal a RoleDefinition at a scope. Assignments
+// at the subscription scope or above live on the Subscription and the rest
+// live on the ResourceGroup that contains their scope.
+type RoleAssignment struct {
+	// Meta.Name is the GUID of the assignment
+	Meta ResourceID
+	// Scope is the raw scope which can be a management group, subscription,
+	// resource group, or resource.
+	Scope string
+	// ScopeResource is the parsed Scope
+	ScopeResource  ResourceID
+	RoleDefinition ResourceID
+	// RoleName is filled in from the Subscription's RoleDefinitions when it
+	// is known.
+	RoleName    string
+	PrincipalID string
+	// PrincipalType is User, Group, ServicePrincipal, etc.
+	PrincipalType string
+	Condition     string
+	Description   string
+}
+
+func NewEmptyRoleAssignment() *RoleAssignment {
+	ra := new(RoleAssignment)
+	ra.Meta.setupEmpty()
+	ra.ScopeResource.setupEmpty()
+	ra.RoleDefinition.setupEmpty()
+	return ra
+}
+
+func (ra *RoleAssignment) FromAzure(az *armauthorization.RoleAssignment) {
+	if az.ID != nil {
+		ra.Meta.fromID(*az.ID)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ra.Scope, props.Scope)
+	if ra.Scope != "" {
+		ra.ScopeResource.fromID(ra.Scope)
+	}
+	if props.RoleDefinitionID != nil {
+		ra.RoleDefinition.fromID(*props.RoleDefinitionID)
+	}
+	gValFromPtr(&ra.PrincipalID, props.PrincipalID)
+	if props.PrincipalType != nil {
+		ra.PrincipalType = string(*props.PrincipalType)
+	}
+	gValFromPtr(&ra.Condition, props.Condition)
+	gValFromPtr(&ra.Description, props.Description)
+}
+
+// EffectiveRole is a role a principal has and the assignment that grants it
+type EffectiveRole struct {
+	Assignment *RoleAssignment
+	// Role is nil if the definition wasn't collected
+	Role *RoleDefinition
+}
+
+// RoleDefinitionByID finds the RoleDefinition for the given role definition
+// ID or GUID.
+func (s *Subscription) RoleDefinitionByID(id string) *RoleDefinition {
+	name := id
+	if strings.Contains(id, "/") {
+		var rid ResourceID
+		rid.fromID(id)
+		name = rid.Name
+	}
+	for _, rd := range s.RoleDefinitions {
+		if strings.EqualFold(rd.Meta.Name, name) {
+			return rd
+		}
+	}
+	return nil
+}
+
+// AllRoleAssignments returns the role assignments at every scope in the
+// Subscription.
+func (s *Subscription) AllRoleAssignments() []*RoleAssignment {
+	all := make([]*RoleAssignment, 0, len(s.RoleAssignments))
+	all = append(all, s.RoleAssignments...)
+	for _, rg := range s.ResourceGroups {
+		all = append(all, rg.RoleAssignments...)
+	}
+	return all
+}
+
+// EffectiveRoles resolves all of the roles directly assigned to the given
+// principal ID at any scope in the Subscription.
+//
+// Group memberships aren't known so roles the principal gets through a group
+// are not included.
+func (s *Subscription) EffectiveRoles(principalID string) []EffectiveRole {
+	roles := make([]EffectiveRole, 0)
+	for _, ra := range s.AllRoleAssignments() {
+		if !strings.EqualFold(ra.PrincipalID, principalID) {
+			continue
+		}
+		roles = append(roles, EffectiveRole{
+			Assignment: ra,
+			Role:       s.RoleDefinitionByID(ra.RoleDefinition.Name),
+		})
+	}
+	return roles
+}
+
+// resolveRoleNames fills in the RoleName of all assignments
+func (s *Subscription) resolveRoleNames() {
+	for _, ra := range s.AllRoleAssignments() {
+		if rd := s.RoleDefinitionByID(ra.RoleDefinition.Name); rd != nil {
+			ra.RoleName = rd.RoleName
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+const (
+	readerRoleID      = "/subscriptions/" + subId + "/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"
+	keyReaderRoleID   = "/subscriptions/" + subId + "/providers/Microsoft.Authorization/roleDefinitions/11111111-2222-3333-4444-555555555555"
+	rbacPrincipalID   = "99999999-8888-7777-6666-555555555555"
+	rbacStorageScope  = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Storage/storageAccounts/acct"
+	rbacSubAssignment = "/subscriptions/" + subId + "/providers/Microsoft.Authorization/roleAssignments/a1"
+	rbacRGAssignment  = rbacStorageScope + "/providers/Microsoft.Authorization/roleAssignments/a2"
+)
+
+func testRoleDefinitions() []*RoleDefinition {
+	builtIn := ptr("BuiltInRole")
+	custom := ptr("CustomRole")
+	reader := NewEmptyRoleDefinition()
+	reader.FromAzure(&armauthorization.RoleDefinition{
+		ID: ptr(readerRoleID),
+		Properties: &armauthorization.RoleDefinitionProperties{
+			RoleName:         ptr("Reader"),
+			RoleType:         builtIn,
+			AssignableScopes: []*string{ptr("/")},
+			Permissions: []*armauthorization.Permission{
+				{Actions: []*string{ptr("*/read")}},
+			},
+		},
+	})
+	keyReader := NewEmptyRoleDefinition()
+	keyReader.FromAzure(&armauthorization.RoleDefinition{
+		ID: ptr(keyReaderRoleID),
+		Properties: &armauthorization.RoleDefinitionProperties{
+			RoleName: ptr("Storage Key Reader"),
+			RoleType: custom,
+			Permissions: []*armauthorization.Permission{
+				{
+					Actions:     []*string{ptr("Microsoft.Storage/*")},
+					NotActions:  []*string{ptr("Microsoft.Storage/storageAccounts/delete")},
+					DataActions: []*string{ptr("Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read")},
+				},
+			},
+		},
+	})
+	return []*RoleDefinition{reader, keyReader}
+}
+
+func TestRoleDefinitionFromAzure(t *testing.T) {
+	defs := testRoleDefinitions()
+	reader, keyReader := defs[0], defs[1]
+	if reader.Meta.Tag != RoleDefinitionT || reader.Meta.Name != "acdd72a7-3385-48ef-bd42-f606fba81ae7" ||
+		reader.Meta.ResourceGroupName != "" || reader.IsCustom {
+		t.Fatalf("bad role definition: %+v", reader)
+	}
+	if !keyReader.IsCustom || len(keyReader.Permissions) != 1 {
+		t.Fatalf("bad custom role definition: %+v", keyReader)
+	}
+	if !reader.AllowsAction("Microsoft.Compute/virtualMachines/read") ||
+		reader.AllowsAction("Microsoft.Compute/virtualMachines/write") {
+		t.Fatal("bad wildcard matching on Reader")
+	}
+	if !keyReader.AllowsAction("microsoft.storage/storageAccounts/listKeys/action") ||
+		keyReader.AllowsAction("Microsoft.Storage/storageAccounts/delete") {
+		t.Fatal("bad NotActions handling")
+	}
+	if !keyReader.AllowsDataAction("Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read") ||
+		keyReader.AllowsDataAction("Microsoft.Storage/storageAccounts/blobServices/containers/blobs/write") {
+		t.Fatal("bad DataActions handling")
+	}
+}
+
+func TestRoleAssignmentsAndEffectiveRoles(t *testing.T) {
+	user := armauthorization.PrincipalTypeUser
+	subRA := NewEmptyRoleAssignment()
+	subRA.FromAzure(&armauthorization.RoleAssignment{
+		ID: ptr(rbacSubAssignment),
+		Properties: &armauthorization.RoleAssignmentProperties{
+			Scope:            ptr("/subscriptions/" + subId),
+			RoleDefinitionID: ptr(readerRoleID),
+			PrincipalID:      ptr(rbacPrincipalID),
+			PrincipalType:    &user,
+		},
+	})
+	if subRA.ScopeResource.ResourceGroupName != "" || subRA.PrincipalType != "User" ||
+		subRA.RoleDefinition.Name != "acdd72a7-3385-48ef-bd42-f606fba81ae7" {
+		t.Fatalf("bad subscription role assignment: %+v", subRA)
+	}
+	saRA := NewEmptyRoleAssignment()
+	saRA.FromAzure(&armauthorization.RoleAssignment{
+		ID: ptr(rbacRGAssignment),
+		Properties: &armauthorization.RoleAssignmentProperties{
+			Scope:            ptr(rbacStorageScope),
+			RoleDefinitionID: ptr(keyReaderRoleID),
+			PrincipalID:      ptr(rbacPrincipalID),
+		},
+	})
+	if saRA.ScopeResource.Tag != StorageAccountT || saRA.ScopeResource.ResourceGroupName != "rga" {
+		t.Fatalf("bad resource role assignment scope: %+v", saRA.ScopeResource)
+	}
+
+	sub := NewSubscription(subId)
+	sub.RoleDefinitions = testRoleDefinitions()
+	sub.RoleAssignments = append(sub.RoleAssignments, subRA)
+	rg := NewEmptyResourceGroup()
+	rg.RoleAssignments = append(rg.RoleAssignments, saRA)
+	sub.ResourceGroups[rgAName] = rg
+	sub.resolveRoleNames()
+	if subRA.RoleName != "Reader" || saRA.RoleName != "Storage Key Reader" {
+		t.Fatalf("role names not resolved: %s, %s", subRA.RoleName, saRA.RoleName)
+	}
+
+	roles := sub.EffectiveRoles(rbacPrincipalID)
+	if len(roles) != 2 {
+		t.Fatalf("expected 2 effective roles: %v", roles)
+	}
+	for _, r := range roles {
+		if r.Role == nil {
+			t.Fatalf("role for %s not resolved", r.Assignment.Meta.Name)
+		}
+	}
+	if len(sub.EffectiveRoles("nobody")) != 0 {
+		t.Fatal("unexpected roles for unknown principal")
+	}
+
+	var custom []*RoleDefinition
+	if err := sub.FromQueryString("/RoleDefinitions[.IsCustom == true]", &custom); err != nil {
+		t.Fatal(err)
+	}
+	if len(custom) != 1 || custom[0].RoleName != "Storage Key Reader" {
+		t.Fatalf("bad query string result: %v", custom)
+	}
+	qs, err := custom[0].Meta.QueryString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var single *RoleDefinition
+	if err := sub.FromQueryString(qs, &single); err != nil {
+		t.Fatal(err)
+	}
+	if single != custom[0] {
+		t.Fatalf("bad single query string result for %s: %v", qs, single)
+	}
+	var assignments []*RoleAssignment
+	if err := sub.FromQueryString("/RoleAssignments", &assignments); err != nil {
+		t.Fatal(err)
+	}
+	if len(assignments) != 2 {
+		t.Fatalf("expected 2 role assignments: %v", assignments)
+	}
+}
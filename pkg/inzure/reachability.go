@@ -0,0 +1,405 @@
+package inzure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NSGVerdict is the result of evaluating inbound traffic against a single
+// NetworkSecurityGroup.
+type NSGVerdict struct {
+	// NSG is the NetworkSecurityGroup that was evaluated. This is unset if
+	// there was no NSG associated at this point in the path.
+	NSG ResourceID
+	// Allowed is BoolNotApplicable when there is no NSG to evaluate.
+	Allowed UnknownBool
+	// Rules are the rules that decided the verdict. If the verdict is
+	// BoolUnknown this also contains every rule that may have matched before
+	// the deciding rule.
+	Rules []SecurityRule
+}
+
+// Reachability is the effective result of traffic trying to reach a single
+// IP configuration (or subnet) through every NSG in its path.
+//
+// For inbound traffic Azure evaluates the NSG associated with the subnet
+// first and then the NSG associated with the network interface. Both have to
+// allow the traffic for it to arrive.
+type Reachability struct {
+	// Target is the IP configuration or subnet the traffic was evaluated
+	// against.
+	Target ResourceID
+	// Destination is the address that was used as the traffic's destination.
+	Destination AzureIPv4
+	Allowed     UnknownBool
+	SubnetNSG   NSGVerdict
+	NICNSG      NSGVerdict
+	// Routes are the allowing rules that decided the result as PacketRoutes.
+	// This is empty if the traffic is denied.
+	Routes []PacketRoute
+}
+
+func newNSGVerdict() NSGVerdict {
+	v := NSGVerdict{
+		Allowed: BoolNotApplicable,
+		Rules:   make([]SecurityRule, 0),
+	}
+	v.NSG.setupEmpty()
+	return v
+}
+
+// ubAnd combines UnknownBools where all of them need to be true. False wins
+// over unknown.
+func ubAnd(ubs ...UnknownBool) UnknownBool {
+	var ret UnknownBool = BoolTrue
+	for _, ub := range ubs {
+		if ub.False() {
+			return BoolFalse
+		} else if ub.Unknown() {
+			ret = BoolUnknown
+		}
+	}
+	return ret
+}
+
+func ruleMatchesProtocol(rule SecurityRule, proto SecurityRuleProtocol) UnknownBool {
+	if rule.Protocol == ProtocolUnknown {
+		return BoolUnknown
+	}
+	return UnknownFromBool(
+		rule.Protocol == ProtocolAll || proto == ProtocolAll ||
+			proto == ProtocolUnknown || rule.Protocol == proto,
+	)
+}
+
+func ruleMatchesPort(rule SecurityRule, port AzurePort) UnknownBool {
+	for _, p := range rule.DestPorts {
+		if PortContains(p, port) {
+			return BoolTrue
+		}
+	}
+	return BoolFalse
+}
+
+// ruleMatchesSourcePort checks the source ports of the rule. A nil or empty
+// port means the source port of the traffic isn't known, in which case only
+// rules that allow any source port are a definite match.
+func ruleMatchesSourcePort(rule SecurityRule, port AzurePort) UnknownBool {
+	known := port != nil && port.Size() > 0
+	var ret UnknownBool = BoolFalse
+	for _, p := range rule.SourcePorts {
+		if p.IsStar() || (known && PortContains(p, port)) {
+			return BoolTrue
+		} else if !known || PortsOverlap(p, port) {
+			ret = BoolUnknown
+		}
+	}
+	return ret
+}
+
+func ruleMatchesSource(rule SecurityRule, src AzureIPv4) UnknownBool {
+	var ret UnknownBool = BoolFalse
+	for _, ip := range rule.SourceIPs {
+		contains := IPContains(ip, src)
+		if contains.True() {
+			return BoolTrue
+		} else if contains.Unknown() {
+			ret = BoolUnknown
+		}
+	}
+	return ret
+}
+
+// ruleMatchesDest checks the destination of the rule against an address in a
+// virtual network. ASGs are matched by name against the given ASGs.
+//
+// The destination can be a whole subnet. A rule that only covers part of it
+// is BoolUnknown since it applies to some of the destination but not all.
+func ruleMatchesDest(rule SecurityRule, dst AzureIPv4, asgs []ResourceID) UnknownBool {
+	var ret UnknownBool = BoolFalse
+	for _, ip := range rule.DestIPs {
+		if ip.IsSpecial() {
+			switch ip.GetType() {
+			case AzureAbstractIPVirtualNetwork:
+				// Our destination is always inside of a VNet
+				return BoolTrue
+			case AzureAbstractIPInternet:
+				continue
+			}
+			for _, asg := range asgs {
+				if asgIPName(asg) == ip.String() {
+					return BoolTrue
+				}
+			}
+			// If we were given the ASGs then we know this isn't one of them,
+			// but it could be some other service tag.
+			if len(asgs) == 0 {
+				ret = BoolUnknown
+			}
+			continue
+		}
+		contains := IPContains(ip, dst)
+		if contains.True() {
+			return BoolTrue
+		} else if contains.Unknown() || IPsOverlap(ip, dst).TrueOrUnknown() {
+			ret = BoolUnknown
+		}
+	}
+	return ret
+}
+
+// InboundVerdict evaluates the NSG's inbound rules in priority order for
+// traffic from src on srcPort to dst on the given port and protocol. The asgs
+// are the ApplicationSecurityGroups that the destination belongs to. The
+// srcPort can be nil if the source port isn't known.
+//
+// If no rule matches the traffic is denied, which mirrors Azure's default
+// DenyAllInBound rule.
+func (nsg *NetworkSecurityGroup) InboundVerdict(
+	src AzureIPv4, srcPort AzurePort, dst AzureIPv4, port AzurePort,
+	proto SecurityRuleProtocol, asgs []ResourceID,
+) NSGVerdict {
+	v := newNSGVerdict()
+	v.NSG = nsg.Meta
+
+	sort.Sort(SecurityRules(nsg.InboundRules))
+
+	uncertain := make([]SecurityRule, 0)
+	for _, rule := range nsg.InboundRules {
+		if !rule.Inbound {
+			continue
+		}
+		matches := ubAnd(
+			ruleMatchesProtocol(rule, proto),
+			ruleMatchesPort(rule, port),
+			ruleMatchesSource(rule, src),
+			ruleMatchesSourcePort(rule, srcPort),
+			ruleMatchesDest(rule, dst, asgs),
+		)
+		if matches.Unknown() {
+			uncertain = append(uncertain, rule)
+			continue
+		} else if !matches.True() {
+			continue
+		}
+		v.Allowed = UnknownFromBool(rule.Allows)
+		for _, u := range uncertain {
+			if u.Allows != rule.Allows {
+				v.Allowed = BoolUnknown
+				v.Rules = append(v.Rules, uncertain...)
+				break
+			}
+		}
+		v.Rules = append(v.Rules, rule)
+		return v
+	}
+	v.Allowed = BoolFalse
+	for _, u := range uncertain {
+		if u.Allows {
+			v.Allowed = BoolUnknown
+			v.Rules = append(v.Rules, uncertain...)
+			break
+		}
+	}
+	return v
+}
+
+// routes returns the allowing rules of a verdict as PacketRoutes
+func (v *NSGVerdict) routes() []PacketRoute {
+	prs := make([]PacketRoute, 0, len(v.Rules))
+	for _, rule := range v.Rules {
+		if rule.Allows {
+			prs = append(prs, PacketRouteFromSecurityRuleDests(rule))
+		}
+	}
+	return prs
+}
+
+// EffectiveInboundReachability combines the verdicts of the subnet and NIC
+// NSGs in the order Azure applies them. Either NSG may be nil if none is
+// associated.
+func EffectiveInboundReachability(
+	subnetNSG *NetworkSecurityGroup, nicNSG *NetworkSecurityGroup,
+	src AzureIPv4, srcPort AzurePort, dst AzureIPv4, port AzurePort,
+	proto SecurityRuleProtocol, asgs []ResourceID,
+) Reachability {
+	r := Reachability{
+		Destination: dst,
+		Allowed:     BoolTrue,
+		SubnetNSG:   newNSGVerdict(),
+		NICNSG:      newNSGVerdict(),
+		Routes:      make([]PacketRoute, 0),
+	}
+	r.Target.setupEmpty()
+	if subnetNSG != nil {
+		r.SubnetNSG = subnetNSG.InboundVerdict(src, srcPort, dst, port, proto, asgs)
+		r.Allowed = ubAnd(r.Allowed, r.SubnetNSG.Allowed)
+		// Nothing makes it to the NIC if the subnet denies it.
+		if r.Allowed.False() {
+			return r
+		}
+	}
+	if nicNSG != nil {
+		r.NICNSG = nicNSG.InboundVerdict(src, srcPort, dst, port, proto, asgs)
+		r.Allowed = ubAnd(r.Allowed, r.NICNSG.Allowed)
+	}
+	if r.Allowed.False() {
+		return r
+	}
+	r.Routes = append(r.Routes, r.SubnetNSG.routes()...)
+	r.Routes = append(r.Routes, r.NICNSG.routes()...)
+	if len(r.Routes) == 0 {
+		// Neither level had an NSG so everything is allowed
+		r.Routes = append(r.Routes, AllowsAllPacketRoute())
+	}
+	return r
+}
+
+// InboundReachability determines whether traffic from src on srcPort to the
+// given port can reach the target. The srcPort can be nil if the source port
+// isn't known. The target can be a VirtualMachine, NetworkInterface,
+// IPConfiguration, or Subnet. A result is returned for every IP configuration
+// of the target; subnets return a single result for their whole address
+// range which is BoolUnknown if the deciding rules only cover part of it.
+func (s *Subscription) InboundReachability(
+	target ResourceID, src AzureIPv4, srcPort AzurePort, port AzurePort,
+	proto SecurityRuleProtocol,
+) ([]Reachability, error) {
+	switch target.Tag {
+	case VirtualMachineT:
+		vm := s.findVirtualMachine(target)
+		if vm == nil {
+			return nil, fmt.Errorf("virtual machine %s not found", target.RawID)
+		}
+		ret := make([]Reachability, 0, len(vm.NetworkInterfaces))
+		for i := range vm.NetworkInterfaces {
+			nic := &vm.NetworkInterfaces[i]
+			// The VM may only hold a reference to the NIC
+			if len(nic.IPConfigurations) == 0 {
+				if found := s.findNetworkInterface(nic.Meta); found != nil {
+					nic = found
+				}
+			}
+			ret = append(ret, s.nicReachability(nic, "", src, srcPort, port, proto)...)
+		}
+		return ret, nil
+	case NetworkInterfaceT:
+		nic := s.findNetworkInterface(target)
+		if nic == nil {
+			return nil, fmt.Errorf("network interface %s not found", target.RawID)
+		}
+		return s.nicReachability(nic, "", src, srcPort, port, proto), nil
+	case IPConfigurationT:
+		for _, rg := range s.ResourceGroups {
+			for _, nic := range rg.NetworkInterfaces {
+				for _, ipc := range nic.IPConfigurations {
+					if resourceIDsMatch(ipc.Meta, target) {
+						return s.nicReachability(nic, ipc.Meta.RawID, src, srcPort, port, proto), nil
+					}
+				}
+			}
+		}
+		return nil, fmt.Errorf("ip configuration %s not found", target.RawID)
+	case SubnetT:
+		subnet := s.findSubnet(target)
+		if subnet == nil {
+			return nil, fmt.Errorf("subnet %s not found", target.RawID)
+		}
+		dst := NewAzureIPv4FromAzure(subnet.AddressRange)
+		r := EffectiveInboundReachability(
+			s.findSubnetNSG(subnet.Meta), nil, src, srcPort, dst, port, proto, nil,
+		)
+		r.Target = subnet.Meta
+		return []Reachability{r}, nil
+	default:
+		return nil, fmt.Errorf("can't determine reachability for %s", target.Tag)
+	}
+}
+
+// nicReachability evaluates every IP configuration of the NIC, or only the
+// one with the given ID if it isn't empty.
+func (s *Subscription) nicReachability(
+	nic *NetworkInterface, only string,
+	src AzureIPv4, srcPort AzurePort, port AzurePort, proto SecurityRuleProtocol,
+) []Reachability {
+	nicNSG := s.findNICNSG(nic.Meta)
+	ret := make([]Reachability, 0, len(nic.IPConfigurations))
+	for _, ipc := range nic.IPConfigurations {
+		if only != "" && !strings.EqualFold(only, ipc.Meta.RawID) {
+			continue
+		}
+		dst := NewAzureIPv4FromAzure(ipc.PrivateIP)
+		r := EffectiveInboundReachability(
+			s.findSubnetNSG(ipc.SubnetRef), nicNSG, src, srcPort, dst, port, proto, ipc.ASGRefs,
+		)
+		r.Target = ipc.Meta
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+func resourceIDsMatch(a ResourceID, b ResourceID) bool {
+	return a.RawID != "" && strings.EqualFold(a.RawID, b.RawID)
+}
+
+func (s *Subscription) findVirtualMachine(id ResourceID) *VirtualMachine {
+	for _, rg := range s.ResourceGroups {
+		for _, vm := range rg.VirtualMachines {
+			if resourceIDsMatch(vm.Meta, id) {
+				return vm
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) findNetworkInterface(id ResourceID) *NetworkInterface {
+	for _, rg := range s.ResourceGroups {
+		for _, nic := range rg.NetworkInterfaces {
+			if resourceIDsMatch(nic.Meta, id) {
+				return nic
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) findSubnet(id ResourceID) *Subnet {
+	for _, rg := range s.ResourceGroups {
+		for _, vn := range rg.VirtualNetworks {
+			for i := range vn.Subnets {
+				if resourceIDsMatch(vn.Subnets[i].Meta, id) {
+					return &vn.Subnets[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) findSubnetNSG(subnet ResourceID) *NetworkSecurityGroup {
+	for _, rg := range s.ResourceGroups {
+		for _, nsg := range rg.NetworkSecurityGroups {
+			for _, id := range nsg.Subnets {
+				if resourceIDsMatch(id, subnet) {
+					return nsg
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) findNICNSG(nic ResourceID) *NetworkSecurityGroup {
+	for _, rg := range s.ResourceGroups {
+		for _, nsg := range rg.NetworkSecurityGroups {
+			for _, id := range nsg.NetworkInterfaces {
+				if resourceIDsMatch(id, nic) {
+					return nsg
+				}
+			}
+		}
+	}
+	return nil
+}
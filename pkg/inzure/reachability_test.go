@@ -0,0 +1,208 @@
+package inzure
+
+import "testing"
+
+const (
+	reachTestRG     = "/subscriptions/sub/resourceGroups/rg/providers/"
+	reachTestSubnet = reachTestRG + "Microsoft.Network/virtualNetworks/vnet/subnets/default"
+	reachTestNIC    = reachTestRG + "Microsoft.Network/networkInterfaces/nic"
+	reachTestIPC    = reachTestNIC + "/ipConfigurations/ipconfig1"
+	reachTestASG    = reachTestRG + "Microsoft.Network/applicationSecurityGroups/web-servers"
+)
+
+func reachTestID(id string) ResourceID {
+	var r ResourceID
+	r.FromID(id)
+	return r
+}
+
+func reachTestRule(name string, prio int32, allows bool, src string, dst string, port string) SecurityRule {
+	return SecurityRule{
+		Name:        name,
+		Allows:      allows,
+		Inbound:     true,
+		Priority:    prio,
+		Protocol:    ProtocolAll,
+		SourceIPs:   createIPs(src),
+		SourcePorts: createPorts("*"),
+		DestIPs:     createIPs(dst),
+		DestPorts:   createPorts(port),
+	}
+}
+
+func reachTestSubscription(subnetRules []SecurityRule, nicRules []SecurityRule) *Subscription {
+	sub := NewSubscription("sub")
+	rg := NewEmptyResourceGroup()
+	rg.Meta = reachTestID("/subscriptions/sub/resourceGroups/rg")
+
+	vnet := NewEmptyVirtualNetwork()
+	subnet := Subnet{
+		Meta:         reachTestID(reachTestSubnet),
+		AddressRange: "10.0.0.0/24",
+	}
+	vnet.Subnets = append(vnet.Subnets, subnet)
+	rg.VirtualNetworks = append(rg.VirtualNetworks, vnet)
+
+	nic := NewEmptyNetworkInterface()
+	nic.Meta = reachTestID(reachTestNIC)
+	ipc := IPConfiguration{
+		Meta:      reachTestID(reachTestIPC),
+		PrivateIP: "10.0.0.4",
+		SubnetRef: subnet.Meta,
+		ASGRefs:   []ResourceID{reachTestID(reachTestASG)},
+	}
+	nic.IPConfigurations = append(nic.IPConfigurations, ipc)
+	rg.NetworkInterfaces = append(rg.NetworkInterfaces, nic)
+
+	if subnetRules != nil {
+		nsg := NewEmptyNSG()
+		nsg.Meta = reachTestID(reachTestRG + "Microsoft.Network/networkSecurityGroups/subnet-nsg")
+		nsg.InboundRules = subnetRules
+		nsg.Subnets = append(nsg.Subnets, subnet.Meta)
+		rg.NetworkSecurityGroups = append(rg.NetworkSecurityGroups, nsg)
+	}
+	if nicRules != nil {
+		nsg := NewEmptyNSG()
+		nsg.Meta = reachTestID(reachTestRG + "Microsoft.Network/networkSecurityGroups/nic-nsg")
+		nsg.InboundRules = nicRules
+		nsg.NetworkInterfaces = append(nsg.NetworkInterfaces, nic.Meta)
+		rg.NetworkSecurityGroups = append(rg.NetworkSecurityGroups, nsg)
+	}
+	sub.ResourceGroups["rg"] = rg
+	return &sub
+}
+
+func doReachabilityTest(t *testing.T, sub *Subscription, target string, src string, port string, expected UnknownBool) []Reachability {
+	return doReachabilitySrcPortTest(t, sub, target, src, "", port, expected)
+}
+
+func doReachabilitySrcPortTest(t *testing.T, sub *Subscription, target string, src string, srcPort string, port string, expected UnknownBool) []Reachability {
+	res, err := sub.InboundReachability(
+		reachTestID(target), NewAzureIPv4FromAzure(src), NewPortFromAzure(srcPort),
+		NewPortFromAzure(port), ProtocolTCP,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 result but got %d", len(res))
+	}
+	if res[0].Allowed != expected {
+		t.Fatalf("expected %s from %s:%s to port %s but got %s", expected, src, srcPort, port, res[0].Allowed)
+	}
+	return res
+}
+
+func TestReachabilitySubnetDenyOverridesNIC(t *testing.T) {
+	sub := reachTestSubscription(
+		[]SecurityRule{
+			reachTestRule("DenySSH", 100, false, "*", "*", "22"),
+			reachTestRule("AllowAll", 200, true, "*", "*", "*"),
+		},
+		[]SecurityRule{
+			reachTestRule("AllowSSH", 100, true, "*", "10.0.0.4", "22"),
+		},
+	)
+	res := doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "22", BoolFalse)
+	if res[0].SubnetNSG.Rules[0].Name != "DenySSH" {
+		t.Fatalf("expected DenySSH to decide but got %v", res[0].SubnetNSG.Rules)
+	}
+	if res[0].NICNSG.Allowed != BoolNotApplicable {
+		t.Fatalf("NIC NSG shouldn't be evaluated after subnet deny: %s", res[0].NICNSG.Allowed)
+	}
+	if len(res[0].Routes) != 0 {
+		t.Fatalf("denied traffic shouldn't have routes: %v", res[0].Routes)
+	}
+}
+
+func TestReachabilityNICDeny(t *testing.T) {
+	sub := reachTestSubscription(
+		[]SecurityRule{
+			reachTestRule("AllowAll", 200, true, "*", "*", "*"),
+		},
+		[]SecurityRule{
+			reachTestRule("AllowHTTPS", 100, true, "*", "10.0.0.4", "443"),
+		},
+	)
+	doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "443", BoolTrue)
+	// Nothing in the NIC NSG matches so the implicit deny applies
+	doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "22", BoolFalse)
+}
+
+func TestReachabilityASGDest(t *testing.T) {
+	sub := reachTestSubscription(nil, []SecurityRule{
+		reachTestRule("AllowWeb", 100, true, "*", asgIPName(reachTestID(reachTestASG)), "80"),
+	})
+	res := doReachabilityTest(t, sub, reachTestIPC, "1.2.3.4", "80", BoolTrue)
+	if len(res[0].Routes) != 1 {
+		t.Fatalf("expected a single route but got %v", res[0].Routes)
+	}
+	if !PortContains(res[0].Routes[0].Ports[0], NewPortFromAzure("80")) {
+		t.Fatalf("expected route to port 80 but got %v", res[0].Routes[0])
+	}
+	doReachabilityTest(t, sub, reachTestIPC, "1.2.3.4", "81", BoolFalse)
+}
+
+func TestReachabilitySubnetPartialRule(t *testing.T) {
+	// Only part of the subnet is allowed
+	sub := reachTestSubscription([]SecurityRule{
+		reachTestRule("AllowHost", 100, true, "*", "10.0.0.4", "22"),
+	}, nil)
+	res := doReachabilityTest(t, sub, reachTestSubnet, "1.2.3.4", "22", BoolUnknown)
+	if len(res[0].SubnetNSG.Rules) != 1 || res[0].SubnetNSG.Rules[0].Name != "AllowHost" {
+		t.Fatalf("expected AllowHost in the verdict: %v", res[0].SubnetNSG.Rules)
+	}
+
+	// Only part of the subnet is denied
+	sub = reachTestSubscription([]SecurityRule{
+		reachTestRule("DenySmall", 100, false, "*", "10.0.0.16/28", "22"),
+		reachTestRule("AllowAll", 200, true, "*", "*", "*"),
+	}, nil)
+	doReachabilityTest(t, sub, reachTestSubnet, "1.2.3.4", "22", BoolUnknown)
+
+	// Rules for other subnets don't matter
+	sub = reachTestSubscription([]SecurityRule{
+		reachTestRule("DenyOther", 100, false, "*", "10.1.0.0/28", "22"),
+		reachTestRule("AllowAll", 200, true, "*", "*", "*"),
+	}, nil)
+	doReachabilityTest(t, sub, reachTestSubnet, "1.2.3.4", "22", BoolTrue)
+}
+
+func TestReachabilityNoNSG(t *testing.T) {
+	sub := reachTestSubscription(nil, nil)
+	res := doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "22", BoolTrue)
+	if res[0].SubnetNSG.Allowed != BoolNotApplicable || res[0].NICNSG.Allowed != BoolNotApplicable {
+		t.Fatalf("expected no NSGs to be evaluated: %v", res[0])
+	}
+	doReachabilityTest(t, sub, reachTestSubnet, "1.2.3.4", "22", BoolTrue)
+}
+
+func TestReachabilityUnknownSource(t *testing.T) {
+	sub := reachTestSubscription(nil, []SecurityRule{
+		reachTestRule("AllowFromVNet", 100, true, "VirtualNetwork", "*", "22"),
+	})
+	doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "22", BoolUnknown)
+}
+
+func TestReachabilitySourcePort(t *testing.T) {
+	allow := reachTestRule("AllowFromHighPorts", 100, true, "*", "*", "22")
+	allow.SourcePorts = createPorts("1024-65535")
+	sub := reachTestSubscription(nil, []SecurityRule{allow})
+	doReachabilitySrcPortTest(t, sub, reachTestNIC, "1.2.3.4", "50000", "22", BoolTrue)
+	doReachabilitySrcPortTest(t, sub, reachTestNIC, "1.2.3.4", "80", "22", BoolFalse)
+	// Part of the given source range is allowed
+	doReachabilitySrcPortTest(t, sub, reachTestNIC, "1.2.3.4", "1000-2000", "22", BoolUnknown)
+	// Without a source port we can't know if the rule applies
+	doReachabilityTest(t, sub, reachTestNIC, "1.2.3.4", "22", BoolUnknown)
+}
+
+func TestReachabilityNotFound(t *testing.T) {
+	sub := reachTestSubscription(nil, nil)
+	_, err := sub.InboundReachability(
+		reachTestID(reachTestRG+"Microsoft.Network/networkInterfaces/missing"),
+		NewAzureIPv4FromAzure("1.2.3.4"), nil, NewPortFromAzure("22"), ProtocolTCP,
+	)
+	if err == nil {
+		t.Fatal("expected an error for a missing target")
+	}
+}
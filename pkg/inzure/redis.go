@@ -21,12 +21,16 @@ type RedisServer struct {
 	Firewall          RedisFirewall
 	Subnet            ResourceID
 	MinimumTLSVersion TLSVersion
+	Identity          ManagedIdentity
+	// PublicNetworkAccess is false when the cache can only be reached
+	// through a private endpoint.
+	PublicNetworkAccess UnknownBool
 }
 
 func NewEmptyRedisServer() *RedisServer {
 	var id ResourceID
 	id.setupEmpty()
-	return &RedisServer{
+	r := &RedisServer{
 		Meta:          id,
 		Subnet:        id,
 		Port:          -1,
@@ -34,6 +38,8 @@ func NewEmptyRedisServer() *RedisServer {
 		Configuration: make(map[string]string),
 		Firewall:      make(RedisFirewall, 0),
 	}
+	r.Identity.setupEmpty()
+	return r
 }
 
 func (r *RedisServer) FromAzure(az *armredis.ResourceInfo) {
@@ -41,6 +47,7 @@ func (r *RedisServer) FromAzure(az *armredis.ResourceInfo) {
 		return
 	}
 	r.Meta.fromID(*az.ID)
+	r.Identity.FromAzureRedis(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -61,6 +68,9 @@ func (r *RedisServer) FromAzure(az *armredis.ResourceInfo) {
 		r.Subnet.fromID(*props.SubnetID)
 	}
 	r.MinimumTLSVersion.FromAzureRedis(props.MinimumTLSVersion)
+	if props.PublicNetworkAccess != nil {
+		r.PublicNetworkAccess.FromBool(*props.PublicNetworkAccess == armredis.PublicNetworkAccessEnabled)
+	}
 }
 
 type RedisFirewall []FirewallRule
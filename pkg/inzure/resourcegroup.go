@@ -26,6 +26,28 @@ type ResourceGroup struct {
 	BastionHosts              []*BastionHost
 	Grafanas                  []*Grafana
 	SQLVirtualMachines        []*SQLVirtualMachine
+	KubernetesClusters        []*KubernetesCluster
+	ContainerRegistries       []*ContainerRegistry
+	ApplicationGateways       []*ApplicationGateway
+	AzureFirewalls            []*AzureFirewall
+	FirewallPolicies          []*FirewallPolicy
+	VirtualMachineScaleSets   []*VirtualMachineScaleSet
+	ServiceBusNamespaces      []*ServiceBusNamespace
+	EventHubNamespaces        []*EventHubNamespace
+	PostgresFlexibleServers   []*PostgresFlexibleServer
+	MySQLServers              []*MySQLServer
+	MySQLFlexibleServers      []*MySQLFlexibleServer
+	RoleAssignments           []*RoleAssignment
+	UserAssignedIdentities    []*UserAssignedIdentity
+	PrivateEndpoints          []*PrivateEndpoint
+	PrivateDNSZones           []*PrivateDNSZone
+	RouteTables               []*RouteTable
+	NATGateways               []*NATGateway
+	VirtualNetworkGateways    []*VirtualNetworkGateway
+	PublicIPAddresses         []*PublicIPAddress
+	CDNProfiles               []*CDNProfile
+	FrontDoors                []*FrontDoor
+	TrafficManagerProfiles    []*TrafficManagerProfile
 }
 
 func NewEmptyResourceGroup() *ResourceGroup {
@@ -49,6 +71,28 @@ func NewEmptyResourceGroup() *ResourceGroup {
 		BastionHosts:              make([]*BastionHost, 0),
 		Grafanas:                  make([]*Grafana, 0),
 		SQLVirtualMachines:        make([]*SQLVirtualMachine, 0),
+		KubernetesClusters:        make([]*KubernetesCluster, 0),
+		ContainerRegistries:       make([]*ContainerRegistry, 0),
+		ApplicationGateways:       make([]*ApplicationGateway, 0),
+		AzureFirewalls:            make([]*AzureFirewall, 0),
+		FirewallPolicies:          make([]*FirewallPolicy, 0),
+		VirtualMachineScaleSets:   make([]*VirtualMachineScaleSet, 0),
+		ServiceBusNamespaces:      make([]*ServiceBusNamespace, 0),
+		EventHubNamespaces:        make([]*EventHubNamespace, 0),
+		PostgresFlexibleServers:   make([]*PostgresFlexibleServer, 0),
+		MySQLServers:              make([]*MySQLServer, 0),
+		MySQLFlexibleServers:      make([]*MySQLFlexibleServer, 0),
+		RoleAssignments:           make([]*RoleAssignment, 0),
+		UserAssignedIdentities:    make([]*UserAssignedIdentity, 0),
+		PrivateEndpoints:          make([]*PrivateEndpoint, 0),
+		PrivateDNSZones:           make([]*PrivateDNSZone, 0),
+		RouteTables:               make([]*RouteTable, 0),
+		NATGateways:               make([]*NATGateway, 0),
+		VirtualNetworkGateways:    make([]*VirtualNetworkGateway, 0),
+		PublicIPAddresses:         make([]*PublicIPAddress, 0),
+		CDNProfiles:               make([]*CDNProfile, 0),
+		FrontDoors:                make([]*FrontDoor, 0),
+		TrafficManagerProfiles:    make([]*TrafficManagerProfile, 0),
 	}
 }
 
@@ -64,6 +64,27 @@ const (
 	GrafanaT
 	PrivateEndpointConnectionT
 	SQLVirtualMachineT
+	KubernetesClusterT
+	ContainerRegistryT
+	ApplicationGatewayT
+	AzureFirewallT
+	FirewallPolicyT
+	EventHubNamespaceT
+	PostgresFlexibleServerT
+	MySQLServerT
+	MySQLFlexibleServerT
+	RoleDefinitionT
+	RoleAssignmentT
+	UserAssignedIdentityT
+	PrivateEndpointT
+	PrivateDNSZoneT
+	RouteTableT
+	NATGatewayT
+	VirtualNetworkGatewayT
+	VirtualNetworkPeeringT
+	CDNProfileT
+	FrontDoorT
+	TrafficManagerProfileT
 )
 
 // ParentResource is an intermediate piece of the resource ID string. For
@@ -134,6 +155,27 @@ var tagMap = map[string]AzureResourceTag{
 	"grafana":                    GrafanaT,
 	"privateendpointconnections": PrivateEndpointConnectionT,
 	"sqlvirtualmachines":         SQLVirtualMachineT,
+	"managedclusters":            KubernetesClusterT,
+	"registries":                 ContainerRegistryT,
+	"applicationgateways":        ApplicationGatewayT,
+	"azurefirewalls":             AzureFirewallT,
+	"firewallpolicies":           FirewallPolicyT,
+	// Like "servers", this is shared between MySQL and PostgreSQL and
+	// getEndTag sorts it out.
+	"flexibleservers":        PostgresFlexibleServerT,
+	"roledefinitions":        RoleDefinitionT,
+	"roleassignments":        RoleAssignmentT,
+	"userassignedidentities": UserAssignedIdentityT,
+	"privateendpoints":       PrivateEndpointT,
+	"privatednszones":        PrivateDNSZoneT,
+	"routetables":            RouteTableT,
+	"natgateways":            NATGatewayT,
+	"virtualnetworkgateways": VirtualNetworkGatewayT,
+	"virtualnetworkpeerings": VirtualNetworkPeeringT,
+	// Only Microsoft.Cdn uses plain "profiles" for now
+	"profiles":               CDNProfileT,
+	"frontdoors":             FrontDoorT,
+	"trafficmanagerprofiles": TrafficManagerProfileT,
 }
 
 func tagFrom(name string) AzureResourceTag {
@@ -218,32 +260,43 @@ func (r *ResourceID) fromID(id string) {
 	r.RawID = id
 	scan := bufio.NewScanner(strings.NewReader(id))
 	scan.Split(resourceStringScanFun)
-	// We are guaranteed to always have AT LEAST:
+	// Almost everything has AT LEAST:
 	// /subscriptions/{uuid}
 	// Skip the first which is empty due to preceding /
 	scan.Scan()
-	// Skip the next scan which will just return "subscriptions"
-	scan.Scan()
-	// The next is the actual id
-	scan.Scan()
-	r.Subscription = scan.Text()
-	// Check for a resource group
-	if !scan.Scan() {
-		return
-	}
-	// This is guaranteed to be a resource group id at this point
 	if !scan.Scan() {
 		return
 	}
-	// I'm pretty sure Resource Group names are case insensitive. I make sure to
-	// lower this here because Azure is really great and sometimes gives this
-	// value in all uppercase and sometimes in the original case.
-	r.ResourceGroupName = strings.ToLower(scan.Text())
-	// if we've got nothing else the base thing was actually a resource group
-	if !scan.Scan() {
-		r.Tag = ResourceGroupT
-		r.Name = r.ResourceGroupName
-		return
+	// Things like management group or tenant level role assignments start
+	// with /providers/ and we go straight to the variable part
+	if strings.EqualFold(scan.Text(), "subscriptions") {
+		// The next is the actual id
+		scan.Scan()
+		r.Subscription = scan.Text()
+		// Check for a resource group
+		if !scan.Scan() {
+			return
+		}
+		// Subscription level resources such as role definitions don't have a
+		// resource group
+		if !strings.EqualFold(scan.Text(), "providers") {
+			// This is guaranteed to be a resource group id at this point
+			if !scan.Scan() {
+				return
+			}
+			// I'm pretty sure Resource Group names are case insensitive. I
+			// make sure to lower this here because Azure is really great and
+			// sometimes gives this value in all uppercase and sometimes in
+			// the original case.
+			r.ResourceGroupName = strings.ToLower(scan.Text())
+			// if we've got nothing else the base thing was actually a
+			// resource group
+			if !scan.Scan() {
+				r.Tag = ResourceGroupT
+				r.Name = r.ResourceGroupName
+				return
+			}
+		}
 	}
 	// Store the provider just in case our tag type needs it
 	var provider string
@@ -302,7 +355,22 @@ func (r *ResourceID) ExtractValueForTag(tag string, caseInsensitive bool) string
 }
 
 func getEndTag(t AzureResourceTag, provider string) AzureResourceTag {
-	// For now only DataLakeT should be swapped depending on the provider
+	// For now only DataLakeT, ServiceBusT, and the database servers should
+	// be swapped depending on the provider
+	if t == ServiceBusT && provider == "microsoft.eventhub" {
+		return EventHubNamespaceT
+	}
+	if provider == "microsoft.dbformysql" {
+		switch t {
+		case SQLServerT:
+			return MySQLServerT
+		case PostgresFlexibleServerT:
+			return MySQLFlexibleServerT
+		}
+	}
+	if t == SQLServerT && provider == "microsoft.dbforpostgresql" {
+		return PostgresServerT
+	}
 	if t == DataLakeT {
 		switch provider {
 		case "microsoft.datalakestore":
@@ -355,22 +423,45 @@ func (r *ResourceID) fromClassicURL(url string) {
 }
 
 var qsTagMap = map[AzureResourceTag]string{
-	WebAppT:               "WebApps",
-	NetworkSecurityGroupT: "NetworkSecurityGroups",
-	StorageAccountT:       "StorageAccounts",
-	VirtualMachineT:       "VirtualMachines",
-	VirtualNetworkT:       "VirtualNetworks",
-	DataLakeAnalyticsT:    "DataLakeAnalytics",
-	DataLakeStoreT:        "DataLakeStores",
-	RedisServerT:          "RedisServers",
-	PostgresServerT:       "PostgresServers",
-	SQLServerT:            "SQLServers",
-	KeyVaultT:             "KeyVaults",
-	CosmosDBT:             "CosmosDBs",
-	LoadBalancerT:         "LoadBalancers",
-	ApiServiceT:           "APIServices",
-	BastionHostT:          "BastionHosts",
-	GrafanaT:              "Grafanas",
+	WebAppT:                 "WebApps",
+	NetworkSecurityGroupT:   "NetworkSecurityGroups",
+	StorageAccountT:         "StorageAccounts",
+	VirtualMachineT:         "VirtualMachines",
+	VirtualNetworkT:         "VirtualNetworks",
+	DataLakeAnalyticsT:      "DataLakeAnalytics",
+	DataLakeStoreT:          "DataLakeStores",
+	RedisServerT:            "RedisServers",
+	PostgresServerT:         "PostgresServers",
+	SQLServerT:              "SQLServers",
+	KeyVaultT:               "KeyVaults",
+	CosmosDBT:               "CosmosDBs",
+	LoadBalancerT:           "LoadBalancers",
+	ApiServiceT:             "APIServices",
+	BastionHostT:            "BastionHosts",
+	GrafanaT:                "Grafanas",
+	KubernetesClusterT:      "KubernetesClusters",
+	ContainerRegistryT:      "ContainerRegistries",
+	ApplicationGatewayT:     "ApplicationGateways",
+	AzureFirewallT:          "AzureFirewalls",
+	FirewallPolicyT:         "FirewallPolicies",
+	VirtualMachineScaleSetT: "VirtualMachineScaleSets",
+	ServiceBusT:             "ServiceBusNamespaces",
+	EventHubNamespaceT:      "EventHubNamespaces",
+	PostgresFlexibleServerT: "PostgresFlexibleServers",
+	MySQLServerT:            "MySQLServers",
+	MySQLFlexibleServerT:    "MySQLFlexibleServers",
+	RoleDefinitionT:         "RoleDefinitions",
+	RoleAssignmentT:         "RoleAssignments",
+	UserAssignedIdentityT:   "UserAssignedIdentities",
+	PrivateEndpointT:        "PrivateEndpoints",
+	PrivateDNSZoneT:         "PrivateDNSZones",
+	RouteTableT:             "RouteTables",
+	NATGatewayT:             "NATGateways",
+	VirtualNetworkGatewayT:  "VirtualNetworkGateways",
+	PublicIPT:               "PublicIPAddresses",
+	CDNProfileT:             "CDNProfiles",
+	FrontDoorT:              "FrontDoors",
+	TrafficManagerProfileT:  "TrafficManagerProfiles",
 }
 
 func (r *ResourceID) QueryString() (string, error) {
@@ -378,7 +469,12 @@ func (r *ResourceID) QueryString() (string, error) {
 	if !has {
 		return "", fmt.Errorf("can't create query string for tag: %s", r.Tag.String())
 	}
-	return fmt.Sprintf("/%s/%s/%s", v, r.ResourceGroupName, r.Name), nil
+	rg := r.ResourceGroupName
+	// Subscription level resources can still be found by name in any group
+	if rg == "" {
+		rg = "*"
+	}
+	return fmt.Sprintf("/%s/%s/%s", v, rg, r.Name), nil
 }
 
 // TODO This could be better.
@@ -0,0 +1,382 @@
+package inzure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+//go:generate go run gen/enum.go -type-name RouteNextHopType -prefix NextHop -values Internet,None,VirtualAppliance,VirtualNetworkGateway,VnetLocal -azure-type RouteNextHopType -azure-values RouteNextHopTypeInternet,RouteNextHopTypeNone,RouteNextHopTypeVirtualAppliance,RouteNextHopTypeVirtualNetworkGateway,RouteNextHopTypeVnetLocal -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8
+//go:generate go run gen/enum.go -type-name VirtualNetworkGatewayType -prefix Gateway -values ExpressRoute,LocalGateway,VPN -azure-type VirtualNetworkGatewayType -azure-values VirtualNetworkGatewayTypeExpressRoute,VirtualNetworkGatewayTypeLocalGateway,VirtualNetworkGatewayTypeVPN -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8
+
+// VirtualNetworkPeering connects a VirtualNetwork to a remote one. Peerings
+// are one directional, the remote network has its own peering back.
+type VirtualNetworkPeering struct {
+	Meta                 ResourceID
+	RemoteVirtualNetwork ResourceID
+	RemoteAddressSpaces  []string
+	// State is Initiated, Connected, or Disconnected
+	State                     string
+	AllowVirtualNetworkAccess UnknownBool
+	// AllowForwardedTraffic allows traffic that didn't originate in the
+	// remote network, such as from an NVA or a network peered to it.
+	AllowForwardedTraffic UnknownBool
+	AllowGatewayTransit   UnknownBool
+	UseRemoteGateways     UnknownBool
+}
+
+func (p *VirtualNetworkPeering) setupEmpty() {
+	p.Meta.setupEmpty()
+	p.RemoteVirtualNetwork.setupEmpty()
+	p.RemoteAddressSpaces = make([]string, 0)
+}
+
+func (p *VirtualNetworkPeering) FromAzure(az *armnetwork.VirtualNetworkPeering) {
+	p.setupEmpty()
+	if az.ID != nil {
+		p.Meta.fromID(*az.ID)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.RemoteVirtualNetwork != nil && props.RemoteVirtualNetwork.ID != nil {
+		p.RemoteVirtualNetwork.fromID(*props.RemoteVirtualNetwork.ID)
+	}
+	if props.RemoteAddressSpace != nil {
+		appendStrings(&p.RemoteAddressSpaces, props.RemoteAddressSpace.AddressPrefixes)
+	}
+	if props.PeeringState != nil {
+		p.State = string(*props.PeeringState)
+	}
+	p.AllowVirtualNetworkAccess.FromBoolPtr(props.AllowVirtualNetworkAccess)
+	p.AllowForwardedTraffic.FromBoolPtr(props.AllowForwardedTraffic)
+	p.AllowGatewayTransit.FromBoolPtr(props.AllowGatewayTransit)
+	p.UseRemoteGateways.FromBoolPtr(props.UseRemoteGateways)
+}
+
+// Route is a user defined route in a RouteTable
+type Route struct {
+	Name          string
+	AddressPrefix string
+	NextHopType   RouteNextHopType
+	// NextHopIP is only set for VirtualAppliance routes
+	NextHopIP string
+}
+
+func (r *Route) FromAzure(az *armnetwork.Route) {
+	gValFromPtr(&r.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&r.AddressPrefix, props.AddressPrefix)
+	r.NextHopType.FromAzure(props.NextHopType)
+	gValFromPtr(&r.NextHopIP, props.NextHopIPAddress)
+}
+
+// IsDefault is whether this route overrides the default route to the
+// internet.
+func (r *Route) IsDefault() bool {
+	return r.AddressPrefix == "0.0.0.0/0"
+}
+
+// RouteTable holds user defined routes and is associated with subnets
+type RouteTable struct {
+	Meta                       ResourceID
+	Routes                     []Route
+	DisableBGPRoutePropagation UnknownBool
+	Subnets                    []ResourceID
+}
+
+func NewEmptyRouteTable() *RouteTable {
+	rt := &RouteTable{
+		Routes:  make([]Route, 0),
+		Subnets: make([]ResourceID, 0),
+	}
+	rt.Meta.setupEmpty()
+	return rt
+}
+
+func (rt *RouteTable) FromAzure(az *armnetwork.RouteTable) {
+	if az.ID == nil {
+		return
+	}
+	rt.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	rt.DisableBGPRoutePropagation.FromBoolPtr(props.DisableBgpRoutePropagation)
+	gSliceFromPtrSetterPtrs(
+		&rt.Routes,
+		&props.Routes,
+		fromAzureSetter[armnetwork.Route, *Route],
+	)
+	for _, sn := range props.Subnets {
+		if sn != nil && sn.ID != nil {
+			var id ResourceID
+			id.fromID(*sn.ID)
+			rt.Subnets = append(rt.Subnets, id)
+		}
+	}
+}
+
+// DefaultRoute returns the route for 0.0.0.0/0 if the table overrides it.
+// This usually forces internet bound traffic through a firewall or NVA.
+func (rt *RouteTable) DefaultRoute() *Route {
+	for i := range rt.Routes {
+		if rt.Routes[i].IsDefault() {
+			return &rt.Routes[i]
+		}
+	}
+	return nil
+}
+
+// NATGateway provides outbound internet access for subnets from a fixed set
+// of public IPs.
+type NATGateway struct {
+	Meta               ResourceID
+	IdleTimeoutMinutes int32
+	PublicIPs          []ResourceID
+	PublicIPPrefixes   []ResourceID
+	Subnets            []ResourceID
+}
+
+func NewEmptyNATGateway() *NATGateway {
+	ng := &NATGateway{
+		PublicIPs:        make([]ResourceID, 0),
+		PublicIPPrefixes: make([]ResourceID, 0),
+		Subnets:          make([]ResourceID, 0),
+	}
+	ng.Meta.setupEmpty()
+	return ng
+}
+
+func (ng *NATGateway) FromAzure(az *armnetwork.NatGateway) {
+	if az.ID == nil {
+		return
+	}
+	ng.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&ng.IdleTimeoutMinutes, props.IdleTimeoutInMinutes)
+	appendSubResourceIDs(&ng.PublicIPs, props.PublicIPAddresses)
+	appendSubResourceIDs(&ng.PublicIPs, props.PublicIPAddressesV6)
+	appendSubResourceIDs(&ng.PublicIPPrefixes, props.PublicIPPrefixes)
+	appendSubResourceIDs(&ng.PublicIPPrefixes, props.PublicIPPrefixesV6)
+	appendSubResourceIDs(&ng.Subnets, props.Subnets)
+}
+
+func appendSubResourceIDs(into *[]ResourceID, from []*armnetwork.SubResource) {
+	for _, sr := range from {
+		if sr != nil && sr.ID != nil {
+			var id ResourceID
+			id.fromID(*sr.ID)
+			*into = append(*into, id)
+		}
+	}
+}
+
+// VirtualNetworkGatewayIPConfiguration places a gateway in a subnet,
+// usually GatewaySubnet, with an optional public IP.
+type VirtualNetworkGatewayIPConfiguration struct {
+	Name      string
+	Subnet    ResourceID
+	PublicIP  ResourceID
+	PrivateIP string
+}
+
+func (c *VirtualNetworkGatewayIPConfiguration) FromAzure(az *armnetwork.VirtualNetworkGatewayIPConfiguration) {
+	c.Subnet.setupEmpty()
+	c.PublicIP.setupEmpty()
+	gValFromPtr(&c.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.Subnet != nil && props.Subnet.ID != nil {
+		c.Subnet.fromID(*props.Subnet.ID)
+	}
+	if props.PublicIPAddress != nil && props.PublicIPAddress.ID != nil {
+		c.PublicIP.fromID(*props.PublicIPAddress.ID)
+	}
+	gValFromPtr(&c.PrivateIP, props.PrivateIPAddress)
+}
+
+// VirtualNetworkGatewayConnection is a site to site, VNet to VNet, or
+// ExpressRoute connection of a VirtualNetworkGateway.
+type VirtualNetworkGatewayConnection struct {
+	Meta ResourceID
+	// ConnectionType is IPsec, Vnet2Vnet, ExpressRoute, or VPNClient
+	ConnectionType string
+	// Status is Connected, Connecting, NotConnected, or Unknown
+	Status string
+	// Remote is the other side of the connection which is a local network
+	// gateway, another virtual network gateway, or an ExpressRoute circuit
+	// peering.
+	Remote    ResourceID
+	EnableBGP UnknownBool
+}
+
+func (c *VirtualNetworkGatewayConnection) FromAzure(az *armnetwork.VirtualNetworkGatewayConnectionListEntity) {
+	c.Meta.setupEmpty()
+	c.Remote.setupEmpty()
+	if az.ID != nil {
+		c.Meta.fromID(*az.ID)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.ConnectionType != nil {
+		c.ConnectionType = string(*props.ConnectionType)
+	}
+	if props.ConnectionStatus != nil {
+		c.Status = string(*props.ConnectionStatus)
+	}
+	c.EnableBGP.FromBoolPtr(props.EnableBgp)
+	switch {
+	case props.Peer != nil && props.Peer.ID != nil:
+		c.Remote.fromID(*props.Peer.ID)
+	case props.LocalNetworkGateway2 != nil && props.LocalNetworkGateway2.ID != nil:
+		c.Remote.fromID(*props.LocalNetworkGateway2.ID)
+	case props.VirtualNetworkGateway2 != nil && props.VirtualNetworkGateway2.ID != nil:
+		c.Remote.fromID(*props.VirtualNetworkGateway2.ID)
+	}
+}
+
+// VirtualNetworkGateway is a VPN or ExpressRoute gateway for a
+// VirtualNetwork.
+type VirtualNetworkGateway struct {
+	Meta             ResourceID
+	GatewayType      VirtualNetworkGatewayType
+	VPNType          string
+	SKU              string
+	ActiveActive     UnknownBool
+	EnableBGP        UnknownBool
+	IPConfigurations []VirtualNetworkGatewayIPConfiguration
+	// PointToSiteAddressPools are the client address pools for point to site
+	// VPN users. This is empty if point to site isn't configured.
+	PointToSiteAddressPools []string
+	PointToSiteProtocols    []string
+	// PointToSiteAuthTypes is some of Certificate, Radius, and AAD
+	PointToSiteAuthTypes []string
+	Connections          []VirtualNetworkGatewayConnection
+}
+
+func NewEmptyVirtualNetworkGateway() *VirtualNetworkGateway {
+	gw := &VirtualNetworkGateway{
+		IPConfigurations:        make([]VirtualNetworkGatewayIPConfiguration, 0),
+		PointToSiteAddressPools: make([]string, 0),
+		PointToSiteProtocols:    make([]string, 0),
+		PointToSiteAuthTypes:    make([]string, 0),
+		Connections:             make([]VirtualNetworkGatewayConnection, 0),
+	}
+	gw.Meta.setupEmpty()
+	return gw
+}
+
+func (gw *VirtualNetworkGateway) FromAzure(az *armnetwork.VirtualNetworkGateway) {
+	if az.ID == nil {
+		return
+	}
+	gw.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gw.GatewayType.FromAzure(props.GatewayType)
+	if props.VPNType != nil {
+		gw.VPNType = string(*props.VPNType)
+	}
+	if props.SKU != nil && props.SKU.Name != nil {
+		gw.SKU = string(*props.SKU.Name)
+	}
+	gw.ActiveActive.FromBoolPtr(props.Active)
+	gw.EnableBGP.FromBoolPtr(props.EnableBgp)
+	gSliceFromPtrSetterPtrs(
+		&gw.IPConfigurations,
+		&props.IPConfigurations,
+		fromAzureSetter[armnetwork.VirtualNetworkGatewayIPConfiguration, *VirtualNetworkGatewayIPConfiguration],
+	)
+	if p2s := props.VPNClientConfiguration; p2s != nil {
+		if p2s.VPNClientAddressPool != nil {
+			appendStrings(&gw.PointToSiteAddressPools, p2s.VPNClientAddressPool.AddressPrefixes)
+		}
+		for _, p := range p2s.VPNClientProtocols {
+			if p != nil {
+				gw.PointToSiteProtocols = append(gw.PointToSiteProtocols, string(*p))
+			}
+		}
+		for _, t := range p2s.VPNAuthenticationTypes {
+			if t != nil {
+				gw.PointToSiteAuthTypes = append(gw.PointToSiteAuthTypes, string(*t))
+			}
+		}
+	}
+}
+
+// VirtualNetworkByID finds the collected VirtualNetwork with the given
+// resource ID. This can be used to resolve the remote side of a
+// VirtualNetworkPeering.
+func (s *Subscription) VirtualNetworkByID(id *ResourceID) *VirtualNetwork {
+	for _, rg := range s.ResourceGroups {
+		for _, vn := range rg.VirtualNetworks {
+			if resourceIDsMatch(vn.Meta, *id) {
+				return vn
+			}
+		}
+	}
+	return nil
+}
+
+// RouteTableByID finds the collected RouteTable with the given resource ID
+func (s *Subscription) RouteTableByID(id *ResourceID) *RouteTable {
+	for _, rg := range s.ResourceGroups {
+		for _, rt := range rg.RouteTables {
+			if resourceIDsMatch(rt.Meta, *id) {
+				return rt
+			}
+		}
+	}
+	return nil
+}
+
+// NATGatewayByID finds the collected NATGateway with the given resource ID
+func (s *Subscription) NATGatewayByID(id *ResourceID) *NATGateway {
+	for _, rg := range s.ResourceGroups {
+		for _, ng := range rg.NATGateways {
+			if resourceIDsMatch(ng.Meta, *id) {
+				return ng
+			}
+		}
+	}
+	return nil
+}
+
+// VirtualNetworkGatewaysForVNet finds the gateways deployed into the given
+// VirtualNetwork.
+func (s *Subscription) VirtualNetworkGatewaysForVNet(vnet *ResourceID) []*VirtualNetworkGateway {
+	gws := make([]*VirtualNetworkGateway, 0)
+	for _, rg := range s.ResourceGroups {
+		for _, gw := range rg.VirtualNetworkGateways {
+			for _, ipc := range gw.IPConfigurations {
+				if subnetInVNet(&ipc.Subnet, vnet) {
+					gws = append(gws, gw)
+					break
+				}
+			}
+		}
+	}
+	return gws
+}
+
+// subnetInVNet checks whether the subnet ID belongs to the VirtualNetwork
+func subnetInVNet(subnet *ResourceID, vnet *ResourceID) bool {
+	return subnet.Subscription == vnet.Subscription &&
+		subnet.ResourceGroupName == vnet.ResourceGroupName &&
+		strings.EqualFold(subnet.ExtractValueForTag("virtualnetworks", true), vnet.Name)
+}
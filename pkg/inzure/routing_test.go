@@ -0,0 +1,223 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+const (
+	routingVNetID   = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/spoke"
+	routingHubID    = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/hub"
+	routingSubnetID = routingVNetID + "/subnets/app"
+	routeTableID    = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/routeTables/force-tunnel"
+	natGatewayID    = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/natGateways/nat"
+)
+
+func TestVirtualNetworkPeeringsAndSubnetRefs(t *testing.T) {
+	connected := armnetwork.VirtualNetworkPeeringStateConnected
+	vn := NewEmptyVirtualNetwork()
+	vn.FromAzure(&armnetwork.VirtualNetwork{
+		ID: ptr(routingVNetID),
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			VirtualNetworkPeerings: []*armnetwork.VirtualNetworkPeering{
+				{
+					ID: ptr(routingVNetID + "/virtualNetworkPeerings/to-hub"),
+					Properties: &armnetwork.VirtualNetworkPeeringPropertiesFormat{
+						RemoteVirtualNetwork:  &armnetwork.SubResource{ID: ptr(routingHubID)},
+						RemoteAddressSpace:    &armnetwork.AddressSpace{AddressPrefixes: []*string{ptr("10.0.0.0/16")}},
+						PeeringState:          &connected,
+						AllowForwardedTraffic: ptr(true),
+						UseRemoteGateways:     ptr(true),
+					},
+				},
+			},
+		},
+	})
+	if len(vn.Peerings) != 1 {
+		t.Fatalf("expected a peering: %+v", vn)
+	}
+	p := vn.Peerings[0]
+	if p.Meta.Tag != VirtualNetworkPeeringT || p.Meta.Name != "to-hub" || p.RemoteVirtualNetwork.Name != "hub" ||
+		p.State != "Connected" || !p.AllowForwardedTraffic.True() || !p.UseRemoteGateways.True() ||
+		!p.AllowGatewayTransit.Unknown() || len(p.RemoteAddressSpaces) != 1 {
+		t.Fatalf("bad peering: %+v", p)
+	}
+
+	var sn Subnet
+	sn.setupEmpty()
+	sn.FromAzure(&armnetwork.Subnet{
+		ID: ptr(routingSubnetID),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefix: ptr("10.1.0.0/24"),
+			RouteTable:    &armnetwork.RouteTable{ID: ptr(routeTableID)},
+			NatGateway:    &armnetwork.SubResource{ID: ptr(natGatewayID)},
+		},
+	})
+	if sn.RouteTable.Tag != RouteTableT || sn.NATGateway.Tag != NATGatewayT {
+		t.Fatalf("bad subnet refs: %+v", sn)
+	}
+	vn.Subnets = append(vn.Subnets, sn)
+
+	b, err := json.Marshal(vn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded VirtualNetwork
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Peerings) != 1 || decoded.Peerings[0].RemoteVirtualNetwork.RawID != routingHubID ||
+		decoded.Subnets[0].RouteTable.Name != "force-tunnel" {
+		t.Fatalf("bad JSON round trip: %+v", decoded)
+	}
+}
+
+func TestRouteTableAndNATGateway(t *testing.T) {
+	appliance := armnetwork.RouteNextHopTypeVirtualAppliance
+	local := armnetwork.RouteNextHopTypeVnetLocal
+	rt := NewEmptyRouteTable()
+	rt.FromAzure(&armnetwork.RouteTable{
+		ID: ptr(routeTableID),
+		Properties: &armnetwork.RouteTablePropertiesFormat{
+			DisableBgpRoutePropagation: ptr(true),
+			Routes: []*armnetwork.Route{
+				{
+					Name: ptr("local"),
+					Properties: &armnetwork.RoutePropertiesFormat{
+						AddressPrefix: ptr("10.1.0.0/16"),
+						NextHopType:   &local,
+					},
+				},
+				{
+					Name: ptr("default"),
+					Properties: &armnetwork.RoutePropertiesFormat{
+						AddressPrefix:    ptr("0.0.0.0/0"),
+						NextHopType:      &appliance,
+						NextHopIPAddress: ptr("10.0.0.4"),
+					},
+				},
+			},
+			Subnets: []*armnetwork.Subnet{{ID: ptr(routingSubnetID)}},
+		},
+	})
+	def := rt.DefaultRoute()
+	if def == nil || def.Name != "default" || def.NextHopType != NextHopVirtualAppliance || def.NextHopIP != "10.0.0.4" {
+		t.Fatalf("bad default route: %+v", def)
+	}
+	if !rt.DisableBGPRoutePropagation.True() || len(rt.Subnets) != 1 || rt.Subnets[0].Tag != SubnetT {
+		t.Fatalf("bad route table: %+v", rt)
+	}
+
+	ng := NewEmptyNATGateway()
+	ng.FromAzure(&armnetwork.NatGateway{
+		ID: ptr(natGatewayID),
+		Properties: &armnetwork.NatGatewayPropertiesFormat{
+			IdleTimeoutInMinutes: ptr[int32](4),
+			PublicIPAddresses: []*armnetwork.SubResource{
+				{ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/nat-ip")},
+			},
+			Subnets: []*armnetwork.SubResource{{ID: ptr(routingSubnetID)}},
+		},
+	})
+	if ng.IdleTimeoutMinutes != 4 || len(ng.PublicIPs) != 1 || ng.PublicIPs[0].Tag != PublicIPT || len(ng.Subnets) != 1 {
+		t.Fatalf("bad NAT gateway: %+v", ng)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.RouteTables = append(rg.RouteTables, rt)
+	rg.NATGateways = append(rg.NATGateways, ng)
+	// Query strings use the lower cased resource group name from the ID
+	sub.ResourceGroups[rt.Meta.ResourceGroupName] = rg
+
+	var id ResourceID
+	id.fromID(routeTableID)
+	if sub.RouteTableByID(&id) != rt {
+		t.Fatal("route table not resolved")
+	}
+	id.fromID(natGatewayID)
+	if sub.NATGatewayByID(&id) != ng {
+		t.Fatal("NAT gateway not resolved")
+	}
+	qs, err := rt.Meta.QueryString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var single *RouteTable
+	if err := sub.FromQueryString(qs, &single); err != nil {
+		t.Fatal(err)
+	}
+	if single != rt {
+		t.Fatalf("bad query string result for %s: %v", qs, single)
+	}
+}
+
+func TestVirtualNetworkGatewayFromAzure(t *testing.T) {
+	vpn := armnetwork.VirtualNetworkGatewayTypeVPN
+	routeBased := armnetwork.VPNTypeRouteBased
+	sku := armnetwork.VirtualNetworkGatewaySKUNameVPNGw1
+	openVPN := armnetwork.VPNClientProtocolOpenVPN
+	aad := armnetwork.VPNAuthenticationTypeAAD
+	gw := NewEmptyVirtualNetworkGateway()
+	gw.FromAzure(&armnetwork.VirtualNetworkGateway{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworkGateways/vpn"),
+		Properties: &armnetwork.VirtualNetworkGatewayPropertiesFormat{
+			GatewayType: &vpn,
+			VPNType:     &routeBased,
+			SKU:         &armnetwork.VirtualNetworkGatewaySKU{Name: &sku},
+			EnableBgp:   ptr(false),
+			IPConfigurations: []*armnetwork.VirtualNetworkGatewayIPConfiguration{
+				{
+					Name: ptr("default"),
+					Properties: &armnetwork.VirtualNetworkGatewayIPConfigurationPropertiesFormat{
+						Subnet:          &armnetwork.SubResource{ID: ptr(routingHubID + "/subnets/GatewaySubnet")},
+						PublicIPAddress: &armnetwork.SubResource{ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/publicIPAddresses/vpn-ip")},
+					},
+				},
+			},
+			VPNClientConfiguration: &armnetwork.VPNClientConfiguration{
+				VPNClientAddressPool:   &armnetwork.AddressSpace{AddressPrefixes: []*string{ptr("172.16.0.0/24")}},
+				VPNClientProtocols:     []*armnetwork.VPNClientProtocol{&openVPN},
+				VPNAuthenticationTypes: []*armnetwork.VPNAuthenticationType{&aad},
+			},
+		},
+	})
+	if gw.GatewayType != GatewayVPN || gw.VPNType != "RouteBased" || gw.SKU != "VpnGw1" || !gw.EnableBGP.False() {
+		t.Fatalf("bad gateway: %+v", gw)
+	}
+	if len(gw.PointToSiteAddressPools) != 1 || gw.PointToSiteProtocols[0] != "OpenVPN" || gw.PointToSiteAuthTypes[0] != "AAD" {
+		t.Fatalf("bad point to site configuration: %+v", gw)
+	}
+
+	ipsec := armnetwork.VirtualNetworkGatewayConnectionTypeIPsec
+	var c VirtualNetworkGatewayConnection
+	c.FromAzure(&armnetwork.VirtualNetworkGatewayConnectionListEntity{
+		ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/connections/office"),
+		Properties: &armnetwork.VirtualNetworkGatewayConnectionListEntityPropertiesFormat{
+			ConnectionType: &ipsec,
+			LocalNetworkGateway2: &armnetwork.VirtualNetworkConnectionGatewayReference{
+				ID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/localNetworkGateways/office"),
+			},
+		},
+	})
+	gw.Connections = append(gw.Connections, c)
+	if c.ConnectionType != "IPsec" || c.Remote.Name != "office" {
+		t.Fatalf("bad connection: %+v", c)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.VirtualNetworkGateways = append(rg.VirtualNetworkGateways, gw)
+	sub.ResourceGroups[rgAName] = rg
+	var hub, spoke ResourceID
+	hub.fromID(routingHubID)
+	spoke.fromID(routingVNetID)
+	if gws := sub.VirtualNetworkGatewaysForVNet(&hub); len(gws) != 1 || gws[0] != gw {
+		t.Fatalf("gateway not found for hub: %v", gws)
+	}
+	if gws := sub.VirtualNetworkGatewaysForVNet(&spoke); len(gws) != 0 {
+		t.Fatalf("unexpected gateway for spoke: %v", gws)
+	}
+}
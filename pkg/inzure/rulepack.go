@@ -0,0 +1,62 @@
+package inzure
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CISAzureFoundationsRulePack is the name of the built in rule pack for the
+// CIS Microsoft Azure Foundations Benchmark.
+const CISAzureFoundationsRulePack = "cis-azure-foundations-2.0.0"
+
+//go:embed rulepacks/*.yaml
+var builtinRulePacks embed.FS
+
+// RulePack is a named and versioned collection of Rules.
+type RulePack struct {
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description" yaml:"description"`
+	Rules       []Rule `json:"rules" yaml:"rules"`
+}
+
+// BuiltinRulePacks returns the names of all rule packs that ship with inzure.
+func BuiltinRulePacks() []string {
+	entries, err := builtinRulePacks.ReadDir("rulepacks")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadBuiltinRulePack loads one of the rule packs returned by
+// BuiltinRulePacks.
+func LoadBuiltinRulePack(name string) (*RulePack, error) {
+	b, err := builtinRulePacks.ReadFile(path.Join("rulepacks", name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("no built in rule pack %s", name)
+	}
+	pack := new(RulePack)
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(pack); err != nil {
+		return nil, fmt.Errorf("rule pack %s: %v", name, err)
+	}
+	for i := range pack.Rules {
+		if err := pack.Rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rule pack %s: %v", name, err)
+		}
+	}
+	return pack, nil
+}
@@ -0,0 +1,426 @@
+package inzure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is how important a Finding is.
+type Severity uint8
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityInfo
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	switch strings.ToLower(string(b)) {
+	case "info", "informational":
+		*s = SeverityInfo
+	case "low":
+		*s = SeverityLow
+	case "medium":
+		*s = SeverityMedium
+	case "high":
+		*s = SeverityHigh
+	case "critical":
+		*s = SeverityCritical
+	case "", "unknown":
+		*s = SeverityUnknown
+	default:
+		return fmt.Errorf("unknown severity `%s`", string(b))
+	}
+	return nil
+}
+
+// Rule is a single declarative check over inzure data. Every resource that
+// is returned by any of the Queries produces a Finding, so queries should be
+// written to select the resources that are in violation.
+//
+// Rules are usually loaded from YAML or JSON files:
+//
+//	id: storage-https-only
+//	title: Storage accounts allow HTTP
+//	severity: medium
+//	description: Storage accounts that allow HTTP traffic expose data in transit.
+//	remediation: Enable "Secure transfer required" on the storage account.
+//	queries:
+//	  - /StorageAccounts[.HTTPSOnly != BoolTrue]
+type Rule struct {
+	ID          string   `json:"id" yaml:"id"`
+	Title       string   `json:"title" yaml:"title"`
+	Severity    Severity `json:"severity" yaml:"severity"`
+	Description string   `json:"description" yaml:"description"`
+	Remediation string   `json:"remediation" yaml:"remediation"`
+	Queries     []string `json:"queries" yaml:"queries"`
+	// Controls are the identifiers of the benchmark or framework controls
+	// this rule maps to.
+	Controls []string `json:"controls,omitempty" yaml:"controls"`
+	// NotEvaluable is set to the reason a rule can't be checked with the
+	// data inzure collects. These rules have no queries and always produce a
+	// single Finding with the FindingNotEvaluable status so they aren't
+	// silently dropped from reports.
+	NotEvaluable string `json:"not_evaluable,omitempty" yaml:"not_evaluable"`
+}
+
+// Validate makes sure the Rule has an ID and that all of its query strings
+// are valid.
+func (r *Rule) Validate() error {
+	if r.ID == "" {
+		return errors.New("rule is missing an id")
+	}
+	if r.NotEvaluable != "" {
+		if len(r.Queries) != 0 {
+			return fmt.Errorf("rule %s is not evaluable but has queries", r.ID)
+		}
+		return nil
+	}
+	if len(r.Queries) == 0 {
+		return fmt.Errorf("rule %s has no queries", r.ID)
+	}
+	for _, q := range r.Queries {
+		var qs QueryString
+		if err := qs.Parse(q); err != nil {
+			return fmt.Errorf("rule %s has a bad query `%s`: %v", r.ID, q, err)
+		}
+		if err := qs.Validate(); err != nil {
+			return fmt.Errorf("rule %s has a bad query `%s`: %v", r.ID, q, err)
+		}
+		if qs.Counts() || qs.Groups() {
+			return fmt.Errorf("rule %s query `%s` must return resources and can't count or group", r.ID, q)
+		}
+	}
+	return nil
+}
+
+// FindingStatus is the outcome that a Finding represents.
+type FindingStatus uint8
+
+const (
+	// FindingFail means a resource violates the rule.
+	FindingFail FindingStatus = iota
+	// FindingNotEvaluable means the rule couldn't be checked with the
+	// collected data.
+	FindingNotEvaluable
+)
+
+func (fs FindingStatus) String() string {
+	switch fs {
+	case FindingFail:
+		return "fail"
+	case FindingNotEvaluable:
+		return "not evaluable"
+	default:
+		return "unknown"
+	}
+}
+
+func (fs FindingStatus) MarshalText() ([]byte, error) {
+	return []byte(fs.String()), nil
+}
+
+func (fs *FindingStatus) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "fail":
+		*fs = FindingFail
+	case "not evaluable":
+		*fs = FindingNotEvaluable
+	default:
+		return fmt.Errorf("unknown finding status `%s`", string(b))
+	}
+	return nil
+}
+
+// Finding is a single resource that matched a Rule.
+type Finding struct {
+	RuleID      string
+	Title       string
+	Status      FindingStatus
+	Severity    Severity
+	Description string
+	Remediation string
+	Controls    []string
+	// Reason is why the rule couldn't be evaluated if the Status is
+	// FindingNotEvaluable.
+	Reason string
+	// Resource is the resource the finding is about. For subresources without
+	// a ResourceID of their own (such as Containers) this is the parent
+	// resource.
+	Resource ResourceID
+	// QueryString is the query string for the specific resource that matched
+	// if one could be determined.
+	QueryString string
+	// Query is the rule's query string that matched the resource.
+	Query string
+}
+
+func newFinding(r *Rule, query string) Finding {
+	f := Finding{
+		RuleID:      r.ID,
+		Title:       r.Title,
+		Severity:    r.Severity,
+		Description: r.Description,
+		Remediation: r.Remediation,
+		Controls:    r.Controls,
+		Query:       query,
+	}
+	f.Resource.setupEmpty()
+	return f
+}
+
+// LoadRules reads one or more Rules from the reader. The data can either be a
+// single rule, a list of rules, or a RulePack in YAML or JSON.
+func LoadRules(r io.Reader) ([]Rule, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	// An empty document
+	if len(node.Content) == 0 {
+		return make([]Rule, 0), nil
+	}
+	rules := make([]Rule, 0)
+	doc := node.Content[0]
+	if doc.Kind == yaml.SequenceNode {
+		err = node.Decode(&rules)
+	} else if isRulePackNode(doc) {
+		var pack RulePack
+		err = node.Decode(&pack)
+		rules = append(rules, pack.Rules...)
+	} else {
+		var rule Rule
+		err = node.Decode(&rule)
+		rules = append(rules, rule)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func isRulePackNode(n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode {
+		return false
+	}
+	// Mapping nodes alternate keys and values
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == "rules" {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRulesFromFile loads the Rules from the given YAML or JSON file.
+func LoadRulesFromFile(fname string) ([]Rule, error) {
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := LoadRules(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	return rules, nil
+}
+
+// LoadRulesFromDir loads every .yaml, .yml, and .json file in the given
+// directory as Rules.
+func LoadRulesFromDir(dir string) ([]Rule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		loaded, err := LoadRulesFromFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, loaded...)
+	}
+	return rules, nil
+}
+
+// RuleEngine runs a set of Rules against Subscriptions.
+type RuleEngine struct {
+	rules []Rule
+}
+
+// NewRuleEngine creates a RuleEngine with the given Rules. An error is
+// returned if any of the Rules are invalid or if two Rules share an ID.
+func NewRuleEngine(rules ...Rule) (*RuleEngine, error) {
+	e := &RuleEngine{
+		rules: make([]Rule, 0, len(rules)),
+	}
+	for _, r := range rules {
+		if err := e.AddRule(r); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// AddRule adds a Rule to the engine.
+func (e *RuleEngine) AddRule(r Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	for _, have := range e.rules {
+		if have.ID == r.ID {
+			return fmt.Errorf("duplicate rule id %s", r.ID)
+		}
+	}
+	e.rules = append(e.rules, r)
+	return nil
+}
+
+// Rules returns the Rules in the engine.
+func (e *RuleEngine) Rules() []Rule {
+	return e.rules
+}
+
+// Run evaluates every Rule against the Subscription. Findings are sorted by
+// severity with the most severe first.
+func (e *RuleEngine) Run(s *Subscription) ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for i := range e.rules {
+		found, err := e.rules[i].Evaluate(s)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, found...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings, nil
+}
+
+// Evaluate runs the Rule's queries against the Subscription. A resource that
+// is returned by more than one query only produces a single Finding.
+func (r *Rule) Evaluate(s *Subscription) ([]Finding, error) {
+	findings := make([]Finding, 0)
+	if r.NotEvaluable != "" {
+		f := newFinding(r, "")
+		f.Status = FindingNotEvaluable
+		f.Reason = r.NotEvaluable
+		return append(findings, f), nil
+	}
+	seen := make(map[string]struct{})
+	for _, q := range r.Queries {
+		v, err := s.ReflectFromQueryString(q)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s query `%s` failed: %v", r.ID, q, err)
+		}
+		v = derefPtr(v)
+		add := func(e reflect.Value) {
+			f := newFinding(r, q)
+			findingResource(&f, e)
+			key := f.Resource.RawID + "|" + f.QueryString
+			if _, has := seen[key]; has {
+				return
+			}
+			seen[key] = struct{}{}
+			findings = append(findings, f)
+		}
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				add(v.Index(i))
+			}
+		} else if v.IsValid() {
+			add(v)
+		}
+	}
+	return findings, nil
+}
+
+type queryStringer interface {
+	QueryString() string
+}
+
+// findingResource fills in the resource information of the Finding from the
+// value that was returned by the query.
+func findingResource(f *Finding, v reflect.Value) {
+	v = derefPtr(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	if v.CanAddr() {
+		if qs, ok := v.Addr().Interface().(queryStringer); ok {
+			f.QueryString = qs.QueryString()
+		}
+	}
+	// Resources have a Meta field and subresources generally point back to
+	// their parent with a ResourceID field.
+	if meta := v.FieldByName("Meta"); meta.IsValid() {
+		if id, ok := meta.Interface().(ResourceID); ok {
+			f.Resource = id
+		}
+	} else {
+		for i := 0; i < v.NumField(); i++ {
+			if id, ok := v.Field(i).Interface().(ResourceID); ok {
+				f.Resource = id
+				break
+			}
+		}
+	}
+	if f.QueryString == "" {
+		if qs, err := f.Resource.QueryString(); err == nil {
+			f.QueryString = qs
+		} else if qs, err := ToQueryString(v.Interface()); err == nil {
+			f.QueryString = qs
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package inzure
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRuleYAML = `
+id: storage-https
+title: Storage allows HTTP
+severity: medium
+description: Storage accounts should only allow HTTPS
+remediation: Enable secure transfer
+queries:
+  - /StorageAccounts[.HTTPSOnly != BoolTrue]
+`
+
+const testRulesJSON = `[
+	{
+		"id": "public-containers",
+		"severity": "high",
+		"queries": ["/StorageAccounts/*/*/Containers[.Access != 1]"]
+	},
+	{
+		"id": "storage-all",
+		"severity": "info",
+		"queries": ["/StorageAccounts", "/StorageAccounts[.HTTPSOnly == BoolTrue]"]
+	}
+]`
+
+func rulesTestSubscription() *Subscription {
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+
+	good := NewEmptyStorageAccount()
+	good.Meta = newResourceId(rgAName, StorageAccountT, "good")
+	good.Meta.RawID = "/good"
+	good.HTTPSOnly = BoolTrue
+
+	bad := NewEmptyStorageAccount()
+	bad.Meta = newResourceId(rgAName, StorageAccountT, "bad")
+	bad.Meta.RawID = "/bad"
+	bad.HTTPSOnly = BoolFalse
+	bad.Containers = append(bad.Containers,
+		Container{Name: "private", StorageAccount: bad.Meta, Access: ContainerPermissionPrivate},
+		Container{Name: "public", StorageAccount: bad.Meta, Access: ContainerPermissionBlob},
+	)
+
+	rg.StorageAccounts = append(rg.StorageAccounts, good, bad)
+	sub.ResourceGroups[rgAName] = rg
+	return &sub
+}
+
+func TestLoadRulesYAML(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(testRuleYAML))
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule but got %d", len(rules))
+	}
+	r := rules[0]
+	if r.ID != "storage-https" || r.Severity != SeverityMedium || len(r.Queries) != 1 {
+		t.Fatalf("rule wasn't loaded correctly: %+v", r)
+	}
+}
+
+func TestLoadRulesJSON(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(testRulesJSON))
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules but got %d", len(rules))
+	}
+	if rules[0].Severity != SeverityHigh || rules[1].Severity != SeverityInfo {
+		t.Fatalf("bad severities: %s %s", rules[0].Severity, rules[1].Severity)
+	}
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	bad := []string{
+		"id: x\nseverity: bad\nqueries: [/StorageAccounts]",
+		"severity: low\nqueries: [/StorageAccounts]",
+		"id: x\nqueries: []",
+		"id: x\nqueries: [/NotAResource]",
+	}
+	for _, s := range bad {
+		if _, err := LoadRules(strings.NewReader(s)); err == nil {
+			t.Fatalf("expected an error loading `%s`", s)
+		}
+	}
+}
+
+func TestRuleEngineDuplicateID(t *testing.T) {
+	r := Rule{ID: "a", Queries: []string{"/StorageAccounts"}}
+	if _, err := NewRuleEngine(r, r); err == nil {
+		t.Fatal("expected duplicate rule IDs to fail")
+	}
+}
+
+func TestRuleEngineRun(t *testing.T) {
+	yamlRules, err := LoadRules(strings.NewReader(testRuleYAML))
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	jsonRules, err := LoadRules(strings.NewReader(testRulesJSON))
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	engine, err := NewRuleEngine(append(yamlRules, jsonRules...)...)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	findings, err := engine.Run(rulesTestSubscription())
+	if err != nil {
+		t.Fatalf("failed to run rules: %v", err)
+	}
+	// 1 public container, 1 HTTP storage account, 2 storage accounts that
+	// should only be reported once each.
+	if len(findings) != 4 {
+		t.Fatalf("expected 4 findings but got %d: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.RuleID != "public-containers" {
+		t.Fatalf("expected highest severity first but got %s", f.RuleID)
+	}
+	if f.Resource.RawID != "/bad" {
+		t.Fatalf("expected the parent storage account but got %s", f.Resource.RawID)
+	}
+	if f.QueryString != "/StorageAccounts/rgA/bad/Containers/public" {
+		t.Fatalf("bad query string for finding: %s", f.QueryString)
+	}
+	f = findings[1]
+	if f.RuleID != "storage-https" || f.QueryString != "/StorageAccounts/rgA/bad" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	if f.Remediation != "Enable secure transfer" {
+		t.Fatalf("finding didn't carry the rule's remediation: %+v", f)
+	}
+}
+
+func TestNotEvaluableRule(t *testing.T) {
+	r := Rule{ID: "ne", NotEvaluable: "no data"}
+	findings, err := r.Evaluate(rulesTestSubscription())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Status != FindingNotEvaluable || findings[0].Reason != "no data" {
+		t.Fatalf("expected a single not evaluable finding: %+v", findings)
+	}
+	r.Queries = []string{"/StorageAccounts"}
+	if err := r.Validate(); err == nil {
+		t.Fatal("not evaluable rules with queries should be invalid")
+	}
+}
+
+func TestCISRulePack(t *testing.T) {
+	found := false
+	for _, name := range BuiltinRulePacks() {
+		if name == CISAzureFoundationsRulePack {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%s wasn't in the built in packs: %v", CISAzureFoundationsRulePack, BuiltinRulePacks())
+	}
+	pack, err := LoadBuiltinRulePack(CISAzureFoundationsRulePack)
+	if err != nil {
+		t.Fatalf("failed to load pack: %v", err)
+	}
+	if pack.Version != "2.0.0" {
+		t.Fatalf("unexpected pack version %s", pack.Version)
+	}
+	engine, err := NewRuleEngine(pack.Rules...)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	sub := rulesTestSubscription()
+	rg := sub.ResourceGroups[rgAName]
+	good := rg.StorageAccounts[0]
+	good.KeyExpirationPeriodInDays = 90
+	good.SASExpirationPeriod = "00.01:00:00"
+	good.Firewall.DefaultAllow = BoolFalse
+	good.BlobSoftDelete.Enabled = BoolTrue
+	good.AllowBlobPublicAccess = BoolFalse
+	good.PrivateEndpoints = append(good.PrivateEndpoints, newResourceId(rgAName, PrivateEndpointT, "good-pe"))
+
+	nsg := NewEmptyNSG()
+	nsg.Meta = newResourceId(rgAName, NetworkSecurityGroupT, "ssh")
+	nsg.Meta.RawID = "/ssh"
+	nsg.InboundRules = append(nsg.InboundRules, SecurityRule{
+		Name:        "AllowSSH",
+		Allows:      true,
+		Inbound:     true,
+		Priority:    100,
+		Protocol:    ProtocolTCP,
+		SourceIPs:   createIPs("*"),
+		SourcePorts: createPorts("*"),
+		DestIPs:     createIPs("*"),
+		DestPorts:   createPorts("22"),
+	}, SecurityRule{
+		Name:        "DenyAllInBound",
+		Allows:      false,
+		Inbound:     true,
+		Priority:    65500,
+		Protocol:    ProtocolAll,
+		SourceIPs:   createIPs("*"),
+		SourcePorts: createPorts("*"),
+		DestIPs:     createIPs("*"),
+		DestPorts:   createPorts("*"),
+	})
+	rg.NetworkSecurityGroups = append(rg.NetworkSecurityGroups, nsg)
+
+	sql := NewEmptySQLServer()
+	sql.Meta = newResourceId(rgAName, SQLServerT, "sql")
+	sql.Meta.RawID = "/sql"
+	sql.Firewall = append(sql.Firewall, FirewallRule{
+		Name:           "all",
+		IPRange:        NewAzureIPv4FromRange("0.0.0.0", "255.255.255.255"),
+		AllowsAllAzure: BoolFalse,
+	})
+	rg.SQLServers = append(rg.SQLServers, sql)
+
+	wa := NewEmptyWebApp()
+	wa.Meta = newResourceId(rgAName, WebAppT, "app")
+	wa.Meta.RawID = "/app"
+	wa.HTTPSOnly = BoolTrue
+	wa.MinTLSVersion = TLSVersionOneTwo
+	wa.FTPState = FTPStateAll
+	wa.Identity.setNone()
+	rg.WebApps = append(rg.WebApps, wa)
+
+	kv := NewEmptyKeyVault()
+	kv.Meta = newResourceId(rgAName, KeyVaultT, "kv")
+	kv.Meta.RawID = "/kv"
+	kv.Firewall.DefaultAllow = BoolFalse
+	kv.SoftDeleteEnabled = BoolTrue
+	kv.PurgeProtection = BoolTrue
+	kv.RBACAuthorization = BoolFalse
+	kv.Keys = append(kv.Keys, KeyVaultKey{
+		Name:           "rotated",
+		KeyVault:       kv.Meta,
+		Attributes:     KeyVaultItemAttributes{Enabled: BoolTrue, NeverExpires: BoolFalse},
+		RotationPolicy: KeyVaultKeyRotationPolicy{AutoRotate: BoolTrue},
+	}, KeyVaultKey{
+		Name:           "forever",
+		KeyVault:       kv.Meta,
+		Attributes:     KeyVaultItemAttributes{Enabled: BoolTrue, NeverExpires: BoolTrue},
+		RotationPolicy: KeyVaultKeyRotationPolicy{AutoRotate: BoolFalse},
+	})
+	kv.Secrets = append(kv.Secrets, KeyVaultSecret{
+		Name:       "disabled",
+		KeyVault:   kv.Meta,
+		Attributes: KeyVaultItemAttributes{Enabled: BoolFalse, NeverExpires: BoolTrue},
+	})
+	rg.KeyVaults = append(rg.KeyVaults, kv)
+
+	cosmos := NewEmptyCosmosDB()
+	cosmos.Meta = newResourceId(rgAName, CosmosDBT, "cosmos")
+	cosmos.Meta.RawID = "/cosmos"
+	cosmos.Firewall.IPs = append(cosmos.Firewall.IPs, NewAzureIPv4FromAzure("10.0.0.0/8"))
+	rg.CosmosDBs = append(rg.CosmosDBs, cosmos)
+
+	pg := NewEmptyPostgresServer()
+	pg.Meta = newResourceId(rgAName, PostgresServerT, "pg")
+	pg.Meta.RawID = "/pg"
+	pg.SSLEnforced = BoolTrue
+	rg.PostgresServers = append(rg.PostgresServers, pg)
+
+	pgFlex := NewEmptyPostgresFlexibleServer()
+	pgFlex.Meta = newResourceId(rgAName, PostgresFlexibleServerT, "pgflex")
+	pgFlex.Meta.RawID = "/pgflex"
+	pgFlex.SSLEnforced = BoolFalse
+	pgFlex.Firewall = append(pgFlex.Firewall, FirewallRule{
+		Name:           "AllowAllAzureServicesAndResourcesWithinAzureIps",
+		IPRange:        NewAzureIPv4FromRange("0.0.0.0", "0.0.0.0"),
+		AllowsAllAzure: BoolTrue,
+	})
+	rg.PostgresFlexibleServers = append(rg.PostgresFlexibleServers, pgFlex)
+
+	mysql := NewEmptyMySQLServer()
+	mysql.Meta = newResourceId(rgAName, MySQLServerT, "mysql")
+	mysql.Meta.RawID = "/mysql"
+	mysql.SSLEnforced = BoolTrue
+	mysql.MinimumTLSVersion = TLSVersionOneTwo
+	rg.MySQLServers = append(rg.MySQLServers, mysql)
+
+	mysqlFlex := NewEmptyMySQLFlexibleServer()
+	mysqlFlex.Meta = newResourceId(rgAName, MySQLFlexibleServerT, "mysqlflex")
+	mysqlFlex.Meta.RawID = "/mysqlflex"
+	mysqlFlex.SSLEnforced = BoolTrue
+	mysqlFlex.MinimumTLSVersion = TLSVersionOneOne
+	rg.MySQLFlexibleServers = append(rg.MySQLFlexibleServers, mysqlFlex)
+
+	findings, err := engine.Run(sub)
+	if err != nil {
+		t.Fatalf("failed to run pack: %v", err)
+	}
+	byRule := make(map[string][]Finding)
+	for _, f := range findings {
+		byRule[f.RuleID] = append(byRule[f.RuleID], f)
+	}
+	expect := map[string]int{
+		"CIS-3.1":   1,
+		"CIS-3.3":   1,
+		"CIS-3.6":   1,
+		"CIS-3.7":   1,
+		"CIS-3.8":   1,
+		"CIS-3.10":  1,
+		"CIS-3.11":  1,
+		"CIS-3.17":  1,
+		"CIS-6.1":   0,
+		"CIS-6.2":   1,
+		"CIS-4.1.2": 1,
+		"CIS-4.3.1": 1,
+		"CIS-4.3.7": 1,
+		"CIS-4.4.1": 0,
+		"CIS-4.4.2": 1,
+		"CIS-4.5.1": 0,
+		"CIS-4.5.2": 1,
+		"CIS-8.1":   1,
+		"CIS-8.3":   0,
+		"CIS-8.5":   0,
+		"CIS-8.6":   1,
+		"CIS-8.7":   0,
+		"CIS-8.8":   1,
+		"CIS-9.2":   0,
+		"CIS-9.3":   0,
+		"CIS-9.5":   1,
+		"CIS-9.10":  1,
+	}
+	for id, n := range expect {
+		if len(byRule[id]) != n {
+			t.Fatalf("expected %d findings for %s but got %d: %+v", n, id, len(byRule[id]), byRule[id])
+		}
+	}
+	ne := byRule["CIS-1"]
+	if len(ne) != 1 || ne[0].Status != FindingNotEvaluable {
+		t.Fatalf("expected CIS-1 to be not evaluable: %+v", ne)
+	}
+}
@@ -0,0 +1,239 @@
+package inzure
+
+import "fmt"
+
+const (
+	// SARIFVersion is the version of SARIF that is output
+	SARIFVersion = "2.1.0"
+	// SARIFSchema is the JSON schema for SARIFVersion
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF logical location kinds used for findings. The RawID of the resource
+// is always a "resource" and the inzure query string uses a custom kind.
+const (
+	SARIFLocationKindResource    = "resource"
+	SARIFLocationKindQueryString = "inzureQueryString"
+)
+
+// SARIFLog is the top level object of a SARIF file. Only the parts of the
+// format that inzure uses are defined.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules,omitempty"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFRuleConfiguration struct {
+	Level string `json:"level,omitempty"`
+}
+
+type SARIFRule struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage           `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage           `json:"fullDescription,omitempty"`
+	Help                 *SARIFMessage           `json:"help,omitempty"`
+	DefaultConfiguration *SARIFRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+}
+
+type SARIFLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+type SARIFLocation struct {
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations"`
+}
+
+type SARIFResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Kind                string                 `json:"kind"`
+	Level               string                 `json:"level"`
+	Message             SARIFMessage           `json:"message"`
+	Locations           []SARIFLocation        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFLevel maps the Severity to a SARIF result level.
+func (s Severity) SARIFLevel() string {
+	switch s {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	case SeverityLow, SeverityInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// NewSARIFLog converts the Findings to a SARIF log with a single run. The
+// given Rules are used to describe the rules in the tool's driver and any
+// rule that is only referenced by a Finding is added as well.
+func NewSARIFLog(rules []Rule, findings []Finding) *SARIFLog {
+	driver := SARIFDriver{
+		Name:           "inzure",
+		Version:        LibVersion,
+		InformationURI: "https://github.com/ivision-research/inzure",
+		Rules:          make([]SARIFRule, 0, len(rules)),
+	}
+	ruleIdx := make(map[string]int)
+	for i := range rules {
+		ruleIdx[rules[i].ID] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule(&rules[i]))
+	}
+	results := make([]SARIFResult, 0, len(findings))
+	for _, f := range findings {
+		idx, has := ruleIdx[f.RuleID]
+		if !has {
+			idx = len(driver.Rules)
+			ruleIdx[f.RuleID] = idx
+			driver.Rules = append(driver.Rules, sarifRule(&Rule{
+				ID:          f.RuleID,
+				Title:       f.Title,
+				Severity:    f.Severity,
+				Description: f.Description,
+				Remediation: f.Remediation,
+				Controls:    f.Controls,
+			}))
+		}
+		results = append(results, f.SARIFResult(idx))
+	}
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs: []SARIFRun{
+			{
+				Tool:    SARIFTool{Driver: driver},
+				Results: results,
+			},
+		},
+	}
+}
+
+func sarifRule(r *Rule) SARIFRule {
+	sr := SARIFRule{
+		ID: r.ID,
+		DefaultConfiguration: &SARIFRuleConfiguration{
+			Level: r.Severity.SARIFLevel(),
+		},
+		Properties: map[string]interface{}{
+			"severity": r.Severity.String(),
+		},
+	}
+	if r.Title != "" {
+		sr.ShortDescription = &SARIFMessage{Text: r.Title}
+	}
+	if r.Description != "" {
+		sr.FullDescription = &SARIFMessage{Text: r.Description}
+	}
+	if r.Remediation != "" {
+		sr.Help = &SARIFMessage{Text: r.Remediation}
+	}
+	if len(r.Controls) > 0 {
+		sr.Properties["controls"] = r.Controls
+	}
+	if len(r.Queries) > 0 {
+		sr.Properties["queries"] = r.Queries
+	}
+	return sr
+}
+
+// SARIFResult converts the Finding to a SARIF result. The ruleIdx is the
+// index of the Finding's rule in the tool driver's rules.
+func (f *Finding) SARIFResult(ruleIdx int) SARIFResult {
+	res := SARIFResult{
+		RuleID:    f.RuleID,
+		RuleIndex: ruleIdx,
+		Kind:      "fail",
+		Level:     f.Severity.SARIFLevel(),
+		Properties: map[string]interface{}{
+			"severity": f.Severity.String(),
+		},
+	}
+	if f.Status == FindingNotEvaluable {
+		// SARIF requires the level to be none for anything other than fail
+		res.Kind = "open"
+		res.Level = "none"
+		res.Message.Text = fmt.Sprintf("%s: not evaluable: %s", f.RuleID, f.Reason)
+		return res
+	}
+	title := f.Title
+	if title == "" {
+		title = f.RuleID
+	}
+	loc := SARIFLocation{
+		LogicalLocations: make([]SARIFLogicalLocation, 0, 2),
+	}
+	name := f.QueryString
+	if f.Resource.RawID != "" {
+		name = f.Resource.RawID
+		loc.LogicalLocations = append(loc.LogicalLocations, SARIFLogicalLocation{
+			Name:               f.Resource.Name,
+			FullyQualifiedName: f.Resource.RawID,
+			Kind:               SARIFLocationKindResource,
+		})
+	}
+	if f.QueryString != "" {
+		loc.LogicalLocations = append(loc.LogicalLocations, SARIFLogicalLocation{
+			FullyQualifiedName: f.QueryString,
+			Kind:               SARIFLocationKindQueryString,
+		})
+	}
+	if len(loc.LogicalLocations) > 0 {
+		res.Locations = []SARIFLocation{loc}
+		res.PartialFingerprints = map[string]string{
+			"inzureResource/v1": f.RuleID + ":" + name,
+		}
+	}
+	res.Message.Text = fmt.Sprintf("%s: %s", title, name)
+	if f.Query != "" {
+		res.Properties["query"] = f.Query
+	}
+	return res
+}
+
+// SARIFFromQueryString runs the query string against the Subscription and
+// reports every returned resource as a SARIF result with the given Severity.
+// The query string itself is used as the rule ID.
+func SARIFFromQueryString(s *Subscription, qs string, sev Severity) (*SARIFLog, error) {
+	r := Rule{
+		ID:       qs,
+		Severity: sev,
+		Queries:  []string{qs},
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	findings, err := r.Evaluate(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewSARIFLog([]Rule{r}, findings), nil
+}
@@ -0,0 +1,96 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSeveritySARIFLevel(t *testing.T) {
+	expected := map[Severity]string{
+		SeverityCritical: "error",
+		SeverityHigh:     "error",
+		SeverityMedium:   "warning",
+		SeverityLow:      "note",
+		SeverityInfo:     "note",
+		SeverityUnknown:  "none",
+	}
+	for sev, level := range expected {
+		if sev.SARIFLevel() != level {
+			t.Fatalf("expected %s to be %s but got %s", sev, level, sev.SARIFLevel())
+		}
+	}
+}
+
+func TestSARIFFromFindings(t *testing.T) {
+	rules := []Rule{
+		{ID: "public", Title: "Public container", Severity: SeverityHigh, Queries: []string{"/StorageAccounts/*/*/Containers[.Access > 1]"}},
+		{ID: "ne", Title: "Can't check", NotEvaluable: "no data"},
+	}
+	engine, err := NewRuleEngine(rules...)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	findings, err := engine.Run(rulesTestSubscription())
+	if err != nil {
+		t.Fatalf("failed to run rules: %v", err)
+	}
+	log := NewSARIFLog(engine.Rules(), findings)
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("bad log: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("expected 2 rules and results: %+v", run)
+	}
+
+	res := run.Results[0]
+	if res.RuleID != "public" || res.RuleIndex != 0 || res.Level != "error" || res.Kind != "fail" {
+		t.Fatalf("bad result: %+v", res)
+	}
+	if len(res.Locations) != 1 || len(res.Locations[0].LogicalLocations) != 2 {
+		t.Fatalf("expected resource and query string locations: %+v", res.Locations)
+	}
+	locs := res.Locations[0].LogicalLocations
+	if locs[0].FullyQualifiedName != "/bad" || locs[0].Kind != SARIFLocationKindResource {
+		t.Fatalf("bad resource location: %+v", locs[0])
+	}
+	if locs[1].FullyQualifiedName != "/StorageAccounts/rgA/bad/Containers/public" ||
+		locs[1].Kind != SARIFLocationKindQueryString {
+		t.Fatalf("bad query string location: %+v", locs[1])
+	}
+
+	res = run.Results[1]
+	if res.RuleID != "ne" || res.RuleIndex != 1 || res.Kind != "open" || res.Level != "none" {
+		t.Fatalf("bad not evaluable result: %+v", res)
+	}
+
+	b, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if generic["$schema"] != SARIFSchema || generic["version"] != SARIFVersion {
+		t.Fatalf("missing schema or version: %s", string(b))
+	}
+}
+
+func TestSARIFFromQueryString(t *testing.T) {
+	qs := "/StorageAccounts[.HTTPSOnly != BoolTrue]"
+	log, err := SARIFFromQueryString(rulesTestSubscription(), qs, SeverityMedium)
+	if err != nil {
+		t.Fatalf("failed to create SARIF: %v", err)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 1 {
+		t.Fatalf("expected a single result: %+v", run.Results)
+	}
+	if run.Results[0].RuleID != qs || run.Results[0].Level != "warning" {
+		t.Fatalf("bad result: %+v", run.Results[0])
+	}
+	if _, err := SARIFFromQueryString(rulesTestSubscription(), "/Nope", SeverityLow); err == nil {
+		t.Fatal("expected an error for a bad query string")
+	}
+}
@@ -0,0 +1,314 @@
+package inzure
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Service Bus and Event Hubs share the same namespace, authorization rule,
+// and network rule set schemas. There isn't an SDK module for either in use
+// here so these are the pieces of the REST API that we care about.
+//
+// TODO: Switch to armservicebus and armeventhub once they're dependencies.
+
+const (
+	serviceBusAPIVersion = "2021-11-01"
+	eventHubAPIVersion   = "2024-01-01"
+)
+
+type azMessagingNamespace struct {
+	ID       *string            `json:"id"`
+	Identity *azManagedIdentity `json:"identity"`
+	SKU      *struct {
+		Name *string `json:"name"`
+	} `json:"sku"`
+	Properties *struct {
+		ServiceBusEndpoint  *string `json:"serviceBusEndpoint"`
+		DisableLocalAuth    *bool   `json:"disableLocalAuth"`
+		MinimumTLSVersion   *string `json:"minimumTlsVersion"`
+		PublicNetworkAccess *string `json:"publicNetworkAccess"`
+		KafkaEnabled        *bool   `json:"kafkaEnabled"`
+	} `json:"properties"`
+}
+
+type azMessagingAuthorizationRule struct {
+	Name       *string `json:"name"`
+	Properties *struct {
+		Rights []*string `json:"rights"`
+	} `json:"properties"`
+}
+
+type azMessagingNetworkRuleSet struct {
+	Properties *struct {
+		DefaultAction               *string `json:"defaultAction"`
+		PublicNetworkAccess         *string `json:"publicNetworkAccess"`
+		TrustedServiceAccessEnabled *bool   `json:"trustedServiceAccessEnabled"`
+		IPRules                     []*struct {
+			IPMask *string `json:"ipMask"`
+			Action *string `json:"action"`
+		} `json:"ipRules"`
+		VirtualNetworkRules []*struct {
+			Subnet *struct {
+				ID *string `json:"id"`
+			} `json:"subnet"`
+		} `json:"virtualNetworkRules"`
+	} `json:"properties"`
+}
+
+// ServiceBusNamespace is an Azure Service Bus namespace.
+type ServiceBusNamespace struct {
+	Meta ResourceID
+	SKU  string
+	// FQDN is the host clients connect to
+	FQDN string
+	// DisableLocalAuth is whether SAS keys are disabled in favor of Entra ID
+	DisableLocalAuth   UnknownBool
+	MinimumTLSVersion  TLSVersion
+	AuthorizationRules []MessagingAuthorizationRule
+	Firewall           MessagingFirewall
+	Identity           ManagedIdentity
+}
+
+func NewEmptyServiceBusNamespace() *ServiceBusNamespace {
+	ns := &ServiceBusNamespace{
+		AuthorizationRules: make([]MessagingAuthorizationRule, 0),
+	}
+	ns.Meta.setupEmpty()
+	ns.Firewall.setupEmpty()
+	ns.Identity.setupEmpty()
+	return ns
+}
+
+func (ns *ServiceBusNamespace) fromAzure(az *azMessagingNamespace) {
+	ns.MinimumTLSVersion = TLSVersionUnknown
+	if az.ID == nil {
+		return
+	}
+	ns.Meta.FromID(*az.ID)
+	ns.Identity.fromAzureData(az.Identity)
+	if az.SKU != nil {
+		gValFromPtr(&ns.SKU, az.SKU.Name)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	ns.FQDN = messagingEndpointHost(props.ServiceBusEndpoint)
+	ns.DisableLocalAuth.FromBoolPtr(props.DisableLocalAuth)
+	ns.MinimumTLSVersion.FromAzureVersionString(props.MinimumTLSVersion)
+	ns.Firewall.PublicNetworkAccess = messagingPublicNetworkAccess(props.PublicNetworkAccess)
+}
+
+// EventHubNamespace is an Azure Event Hubs namespace.
+type EventHubNamespace struct {
+	Meta ResourceID
+	SKU  string
+	// FQDN is the host clients connect to
+	FQDN string
+	// DisableLocalAuth is whether SAS keys are disabled in favor of Entra ID
+	DisableLocalAuth  UnknownBool
+	MinimumTLSVersion TLSVersion
+	// KafkaEnabled is whether the Kafka endpoint on port 9093 is enabled
+	KafkaEnabled       UnknownBool
+	AuthorizationRules []MessagingAuthorizationRule
+	Firewall           MessagingFirewall
+	Identity           ManagedIdentity
+}
+
+func NewEmptyEventHubNamespace() *EventHubNamespace {
+	ns := &EventHubNamespace{
+		AuthorizationRules: make([]MessagingAuthorizationRule, 0),
+	}
+	ns.Meta.setupEmpty()
+	ns.Firewall.setupEmpty()
+	ns.Identity.setupEmpty()
+	return ns
+}
+
+func (ns *EventHubNamespace) fromAzure(az *azMessagingNamespace) {
+	ns.MinimumTLSVersion = TLSVersionUnknown
+	if az.ID == nil {
+		return
+	}
+	ns.Meta.FromID(*az.ID)
+	ns.Identity.fromAzureData(az.Identity)
+	if az.SKU != nil {
+		gValFromPtr(&ns.SKU, az.SKU.Name)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	ns.FQDN = messagingEndpointHost(props.ServiceBusEndpoint)
+	ns.DisableLocalAuth.FromBoolPtr(props.DisableLocalAuth)
+	ns.MinimumTLSVersion.FromAzureVersionString(props.MinimumTLSVersion)
+	ns.KafkaEnabled.FromBoolPtr(props.KafkaEnabled)
+	ns.Firewall.PublicNetworkAccess = messagingPublicNetworkAccess(props.PublicNetworkAccess)
+}
+
+// messagingEndpointHost pulls the host out of an endpoint such as
+// https://example.servicebus.windows.net:443/
+func messagingEndpointHost(endpoint *string) string {
+	if endpoint == nil {
+		return ""
+	}
+	u, err := url.Parse(*endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func messagingPublicNetworkAccess(pna *string) UnknownBool {
+	if pna == nil {
+		return BoolUnknown
+	}
+	switch strings.ToLower(*pna) {
+	case "enabled":
+		return BoolTrue
+	case "disabled":
+		return BoolFalse
+	default:
+		// SecuredByPerimeter depends on a Network Security Perimeter
+		return BoolUnknown
+	}
+}
+
+// MessagingAuthorizationRule is a shared access policy on a Service Bus or
+// Event Hubs namespace. Anyone with one of the rule's keys has these claims.
+type MessagingAuthorizationRule struct {
+	Name   string
+	Listen bool
+	Send   bool
+	Manage bool
+}
+
+func (r *MessagingAuthorizationRule) fromAzure(az *azMessagingAuthorizationRule) {
+	gValFromPtr(&r.Name, az.Name)
+	if az.Properties == nil {
+		return
+	}
+	for _, right := range az.Properties.Rights {
+		if right == nil {
+			continue
+		}
+		switch strings.ToLower(*right) {
+		case "listen":
+			r.Listen = true
+		case "send":
+			r.Send = true
+		case "manage":
+			r.Manage = true
+		}
+	}
+}
+
+// MessagingFirewall is the network rule set of a Service Bus or Event Hubs
+// namespace.
+type MessagingFirewall struct {
+	PublicNetworkAccess UnknownBool
+	DefaultAllow        UnknownBool
+	// TrustedServiceAccess is whether trusted Azure services bypass the rules
+	TrustedServiceAccess UnknownBool
+	IPRules              IPCollection
+	VNetRules            []ResourceID
+}
+
+func (f *MessagingFirewall) setupEmpty() {
+	f.IPRules = make(IPCollection, 0)
+	f.VNetRules = make([]ResourceID, 0)
+}
+
+func (f *MessagingFirewall) fromAzure(az *azMessagingNetworkRuleSet) {
+	// Without a rule set every network is allowed
+	if az == nil || az.Properties == nil {
+		f.DefaultAllow = BoolTrue
+		return
+	}
+	props := az.Properties
+	if props.DefaultAction != nil {
+		f.DefaultAllow.FromBool(strings.EqualFold(*props.DefaultAction, "Allow"))
+	} else {
+		f.DefaultAllow = BoolTrue
+	}
+	if props.PublicNetworkAccess != nil && f.PublicNetworkAccess.Unknown() {
+		f.PublicNetworkAccess = messagingPublicNetworkAccess(props.PublicNetworkAccess)
+	}
+	f.TrustedServiceAccess.FromBoolPtr(props.TrustedServiceAccessEnabled)
+	for _, rule := range props.IPRules {
+		if rule == nil || rule.IPMask == nil {
+			continue
+		}
+		// Allow is the only action
+		if rule.Action != nil && !strings.EqualFold(*rule.Action, "Allow") {
+			continue
+		}
+		f.IPRules = append(f.IPRules, NewAzureIPv4FromAzure(*rule.IPMask))
+	}
+	for _, rule := range props.VirtualNetworkRules {
+		if rule == nil || rule.Subnet == nil || rule.Subnet.ID == nil {
+			continue
+		}
+		var id ResourceID
+		id.FromID(*rule.Subnet.ID)
+		f.VNetRules = append(f.VNetRules, id)
+	}
+}
+
+func (f MessagingFirewall) AllowsIPToPortString(ip, port string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPToPortFromString(f, ip, port)
+}
+
+func (f MessagingFirewall) AllowsIPString(ip string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPFromString(f, ip)
+}
+
+func (f MessagingFirewall) AllowsIP(chk AzureIPv4) (UnknownBool, []PacketRoute, error) {
+	if f.PublicNetworkAccess.False() {
+		return BoolFalse, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+	}
+	uncertain := false
+	for _, ip := range f.IPRules {
+		contains := IPContains(ip, chk)
+		if contains.True() {
+			return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+		} else if contains.Unknown() {
+			uncertain = true
+		}
+	}
+	if uncertain || !f.DefaultAllow.False() {
+		return BoolUnknown, nil, nil
+	}
+	return BoolFalse, nil, nil
+}
+
+// AllowsIPToPort is the same as AllowsIP since the rules apply to every
+// protocol the namespace supports.
+func (f MessagingFirewall) AllowsIPToPort(ip AzureIPv4, _ AzurePort) (UnknownBool, []PacketRoute, error) {
+	return f.AllowsIP(ip)
+}
+
+func (f MessagingFirewall) RespectsAllowlist(wl FirewallAllowlist) (UnknownBool, []IPPort, error) {
+	if wl.AllPorts == nil {
+		return BoolUnknown, nil, BadAllowlist
+	}
+	if f.PublicNetworkAccess.False() {
+		return BoolTrue, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolFalse, []IPPort{{
+			IP:   NewAzureIPv4FromAzure("*"),
+			Port: NewPortFromAzure("*"),
+		}}, nil
+	}
+	if len(f.IPRules) == 0 {
+		if f.DefaultAllow.False() {
+			return BoolTrue, nil, nil
+		}
+		return BoolUnknown, nil, nil
+	}
+	return f.IPRules.RespectsAllowlist(wl)
+}
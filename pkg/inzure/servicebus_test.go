@@ -0,0 +1,107 @@
+package inzure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const eventHubID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.EventHub/namespaces/hubs"
+
+func TestServiceBusNamespaceFromAzure(t *testing.T) {
+	var az azMessagingNamespace
+	err := json.Unmarshal([]byte(`{
+		"id": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.ServiceBus/namespaces/bus",
+		"sku": {"name": "Premium"},
+		"properties": {
+			"serviceBusEndpoint": "https://bus.servicebus.windows.net:443/",
+			"disableLocalAuth": false,
+			"minimumTlsVersion": "1.0",
+			"publicNetworkAccess": "Enabled"
+		}
+	}`), &az)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := NewEmptyServiceBusNamespace()
+	ns.fromAzure(&az)
+	if ns.Meta.Tag != ServiceBusT || ns.Meta.Name != "bus" || ns.SKU != "Premium" {
+		t.Fatalf("bad meta: %+v", ns)
+	}
+	if ns.FQDN != "bus.servicebus.windows.net" {
+		t.Fatalf("bad FQDN: %s", ns.FQDN)
+	}
+	if !ns.DisableLocalAuth.False() || ns.MinimumTLSVersion != TLSVersionOneZero || !ns.Firewall.PublicNetworkAccess.True() {
+		t.Fatalf("bad properties: %+v", ns)
+	}
+
+	var rule azMessagingAuthorizationRule
+	err = json.Unmarshal([]byte(`{"name": "RootManageSharedAccessKey", "properties": {"rights": ["Listen", "Manage", "Send"]}}`), &rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r MessagingAuthorizationRule
+	r.fromAzure(&rule)
+	if !r.Listen || !r.Send || !r.Manage || r.Name != "RootManageSharedAccessKey" {
+		t.Fatalf("bad authorization rule: %+v", r)
+	}
+}
+
+func TestEventHubNamespaceTag(t *testing.T) {
+	var id ResourceID
+	id.FromID(eventHubID)
+	if id.Tag != EventHubNamespaceT {
+		t.Fatalf("expected an Event Hubs namespace tag, got %s", id.Tag)
+	}
+}
+
+func TestMessagingFirewall(t *testing.T) {
+	var rs azMessagingNetworkRuleSet
+	err := json.Unmarshal([]byte(`{
+		"properties": {
+			"defaultAction": "Deny",
+			"trustedServiceAccessEnabled": true,
+			"ipRules": [{"ipMask": "10.0.0.0/8", "action": "Allow"}],
+			"virtualNetworkRules": [{"subnet": {"id": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/vnet/subnets/default"}}]
+		}
+	}`), &rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := NewEmptyEventHubNamespace()
+	ns.fromAzure(&azMessagingNamespace{ID: ptr(eventHubID)})
+	ns.Firewall.fromAzure(&rs)
+	if !ns.Firewall.TrustedServiceAccess.True() || len(ns.Firewall.VNetRules) != 1 {
+		t.Fatalf("bad firewall: %+v", ns.Firewall)
+	}
+
+	var fw Firewall = ns.Firewall
+	if allowed, _, _ := fw.AllowsIPString("10.1.2.3"); !allowed.True() {
+		t.Fatalf("expected 10.1.2.3 to be allowed: %v", allowed)
+	}
+	if allowed, _, _ := fw.AllowsIPString("8.8.8.8"); !allowed.False() {
+		t.Fatalf("expected 8.8.8.8 to be denied: %v", allowed)
+	}
+
+	// No rule set means everything is allowed
+	var open MessagingFirewall
+	open.setupEmpty()
+	open.fromAzure(nil)
+	if allowed, _, _ := open.AllowsIPString("8.8.8.8"); !allowed.True() {
+		t.Fatalf("expected 8.8.8.8 to be allowed: %v", allowed)
+	}
+
+	ns.FQDN = "hubs.servicebus.windows.net"
+	closed := NewEmptyServiceBusNamespace()
+	closed.FQDN = "closed.servicebus.windows.net"
+	closed.Firewall.PublicNetworkAccess = BoolFalse
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.EventHubNamespaces = append(rg.EventHubNamespaces, ns)
+	rg.ServiceBusNamespaces = append(rg.ServiceBusNamespaces, closed)
+	sub.ResourceGroups[rgAName] = rg
+	as := sub.GetAttackSurface()
+	if len(as.EventHubNamespaces) != 1 || len(as.ServiceBusNamespaces) != 0 {
+		t.Fatalf("bad attack surface: %v %v", as.EventHubNamespaces, as.ServiceBusNamespaces)
+	}
+}
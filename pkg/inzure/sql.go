@@ -15,17 +15,23 @@ type SQLServer struct {
 	Firewall  FirewallRules
 	Databases []*SQLDatabase
 	Subnets   []ResourceID
+	Identity  ManagedIdentity
+	// PublicNetworkAccess being false means the server is only reachable
+	// through private endpoints.
+	PublicNetworkAccess UnknownBool
 }
 
 func NewEmptySQLServer() *SQLServer {
 	var id ResourceID
 	id.setupEmpty()
-	return &SQLServer{
+	s := &SQLServer{
 		Meta:      id,
 		Firewall:  FirewallRules(make([]FirewallRule, 0)),
 		Databases: make([]*SQLDatabase, 0),
 		Subnets:   make([]ResourceID, 0),
 	}
+	s.Identity.setupEmpty()
+	return s
 }
 
 func (s *SQLServer) addVNetRule(az *armsql.VirtualNetworkRule) {
@@ -43,6 +49,7 @@ func (s *SQLServer) FromAzure(az *armsql.Server) {
 		return
 	}
 	s.Meta.fromID(*az.ID)
+	s.Identity.FromAzureSQL(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -56,6 +63,9 @@ func (s *SQLServer) FromAzure(az *armsql.Server) {
 	if props.Version != nil {
 		s.Version = *props.Version
 	}
+	if props.PublicNetworkAccess != nil {
+		s.PublicNetworkAccess.FromBool(*props.PublicNetworkAccess == armsql.ServerNetworkAccessFlagEnabled)
+	}
 }
 
 type SQLDatabase struct {
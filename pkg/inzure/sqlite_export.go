@@ -0,0 +1,604 @@
+package inzure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ExportSQLite writes the given subscriptions into db, which should be a
+// SQLite database. The caller is responsible for importing a driver.
+//
+// Every slice in a ResourceGroup gets its own table named after the field in
+// snake case, for example `StorageAccounts` becomes `storage_accounts`. Rows
+// are keyed by their `ResourceID.RawID` in the `id` column and every table
+// has a `subscription` column, a `parent_id` column, and an `idx` column.
+// Top level resources use their resource group's RawID as their parent.
+//
+// Nested structs are flattened into the parent's columns and slices of
+// structs are given their own child tables named after the parent table and
+// the field, for example `storage_accounts_containers` or
+// `sql_servers_firewall`. Children without a RawID of their own are keyed by
+// their parent's id, the field, and their index. Enums, IPs, and ports are
+// stored as their string values and any other slices or maps are stored as
+// JSON, unless the field is tagged with `sqlite:"table"` in which case it
+// gets a child table with the elements in its `value` column.
+//
+// Slices on the Subscription go in the same table as their ResourceGroup
+// counterparts. Those without one, like `RoleDefinitions`, get their own
+// table named after the field.
+//
+// Exporting a subscription that already exists in the database replaces all
+// of its rows, so multiple subscriptions can be loaded into one database.
+func ExportSQLite(ctx context.Context, db *sql.DB, subs ...*Subscription) error {
+	e := newSQLExporter()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := e.export(ctx, tx, subs); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+var (
+	sqlTimeType       = reflect.TypeOf(time.Time{})
+	sqlResourceIDType = reflect.TypeOf(ResourceID{})
+	sqlStringerType   = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	sqlRGType         = reflect.TypeOf(ResourceGroup{})
+	sqlSubType        = reflect.TypeOf(Subscription{})
+)
+
+const sqlBaseSchema = `
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+	"id" TEXT PRIMARY KEY,
+	"alias" TEXT,
+	"audit_date" TEXT
+);
+CREATE TABLE IF NOT EXISTS "resource_groups" (
+	"id" TEXT PRIMARY KEY,
+	"subscription" TEXT,
+	"name" TEXT
+);
+`
+
+type sqlColumn struct {
+	name  string
+	typ   string
+	index []int
+	json  bool
+}
+
+type sqlChild struct {
+	field string
+	index []int
+	table *sqlTable
+}
+
+type sqlTable struct {
+	name     string
+	elem     reflect.Type
+	meta     []int
+	columns  []sqlColumn
+	children []sqlChild
+	used     map[string]struct{}
+	insert   *sql.Stmt
+}
+
+type sqlTopTable struct {
+	field int
+	table *sqlTable
+}
+
+type sqlExporter struct {
+	tables []*sqlTable
+	rg     []sqlTopTable
+	sub    []sqlTopTable
+}
+
+func newSQLExporter() *sqlExporter {
+	e := &sqlExporter{
+		tables: make([]*sqlTable, 0),
+		rg:     make([]sqlTopTable, 0),
+		sub:    make([]sqlTopTable, 0),
+	}
+	for i := 0; i < sqlRGType.NumField(); i++ {
+		f := sqlRGType.Field(i)
+		elem, ok := sqlStructElem(f.Type)
+		if !ok {
+			continue
+		}
+		e.rg = append(e.rg, sqlTopTable{
+			field: i,
+			table: e.newTable(toSnakeCase(f.Name), elem, []reflect.Type{elem}),
+		})
+	}
+	for i := 0; i < sqlSubType.NumField(); i++ {
+		f := sqlSubType.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		elem, ok := sqlStructElem(f.Type)
+		if !ok {
+			continue
+		}
+		t := e.tableForType(elem)
+		if t == nil {
+			t = e.newTable(toSnakeCase(f.Name), elem, []reflect.Type{elem})
+		}
+		e.sub = append(e.sub, sqlTopTable{field: i, table: t})
+	}
+	return e
+}
+
+// tableForType returns the ResourceGroup table that holds the given type.
+// This is used for resources that live directly on the Subscription.
+func (e *sqlExporter) tableForType(ty reflect.Type) *sqlTable {
+	for _, top := range e.rg {
+		if top.table.elem == ty {
+			return top.table
+		}
+	}
+	return nil
+}
+
+func (e *sqlExporter) newTable(name string, ty reflect.Type, stack []reflect.Type) *sqlTable {
+	t := &sqlTable{
+		name:     name,
+		elem:     ty,
+		columns:  make([]sqlColumn, 0),
+		children: make([]sqlChild, 0),
+		used: map[string]struct{}{
+			"id": {}, "subscription": {}, "parent_id": {}, "idx": {},
+		},
+	}
+	e.tables = append(e.tables, t)
+	if f, ok := ty.FieldByName("Meta"); ok && f.Type == sqlResourceIDType && len(f.Index) == 1 {
+		t.meta = f.Index
+		t.used["resource_group"] = struct{}{}
+		t.used["name"] = struct{}{}
+	}
+	e.addFields(t, ty, "", nil, stack)
+	return t
+}
+
+// newValueTable makes a table for a slice of scalars. The elements go in the
+// `value` column.
+func (e *sqlExporter) newValueTable(name string, ty reflect.Type, typ string) *sqlTable {
+	t := &sqlTable{
+		name:     name,
+		elem:     ty,
+		columns:  make([]sqlColumn, 0, 1),
+		children: make([]sqlChild, 0),
+		used: map[string]struct{}{
+			"id": {}, "subscription": {}, "parent_id": {}, "idx": {},
+		},
+	}
+	e.tables = append(e.tables, t)
+	t.addColumn("value", typ, nil, false)
+	return t
+}
+
+func (e *sqlExporter) addFields(t *sqlTable, ty reflect.Type, prefix string, index []int, stack []reflect.Type) {
+	for i := 0; i < ty.NumField(); i++ {
+		f := ty.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("json") == "-" {
+			continue
+		}
+		if index == nil && t.meta != nil && i == t.meta[0] {
+			continue
+		}
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+		name := prefix + toSnakeCase(f.Name)
+		base := f.Type
+		if base.Kind() == reflect.Ptr {
+			base = base.Elem()
+		}
+		if typ, ok := sqlScalarType(f.Type); ok {
+			t.addColumn(name, typ, idx, false)
+		} else if base.Kind() == reflect.Struct && !sqlInStack(base, stack) {
+			p := name + "_"
+			if f.Anonymous {
+				p = prefix
+			}
+			e.addFields(t, base, p, idx, append(stack, base))
+		} else if elem, ok := sqlStructElem(f.Type); ok && !sqlInStack(elem, stack) {
+			child := e.newTable(t.name+"_"+name, elem, append(stack, elem))
+			t.children = append(t.children, sqlChild{field: name, index: idx, table: child})
+		} else if typ, ok := sqlValueTableType(f); ok {
+			child := e.newValueTable(t.name+"_"+name, f.Type.Elem(), typ)
+			t.children = append(t.children, sqlChild{field: name, index: idx, table: child})
+		} else {
+			t.addColumn(name, "TEXT", idx, true)
+		}
+	}
+}
+
+func (t *sqlTable) addColumn(name, typ string, index []int, json bool) {
+	uniq := name
+	for n := 2; ; n++ {
+		if _, has := t.used[uniq]; !has {
+			break
+		}
+		uniq = fmt.Sprintf("%s_%d", name, n)
+	}
+	t.used[uniq] = struct{}{}
+	t.columns = append(t.columns, sqlColumn{name: uniq, typ: typ, index: index, json: json})
+}
+
+func (t *sqlTable) columnNames() []string {
+	names := []string{"id", "subscription", "parent_id", "idx"}
+	if t.meta != nil {
+		names = append(names, "resource_group", "name")
+	}
+	for _, c := range t.columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func (t *sqlTable) createSQL() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE IF NOT EXISTS %s (\n", sqlQuote(t.name))
+	sb.WriteString("\t\"id\" TEXT PRIMARY KEY,\n\t\"subscription\" TEXT,\n\t\"parent_id\" TEXT,\n\t\"idx\" INTEGER")
+	if t.meta != nil {
+		sb.WriteString(",\n\t\"resource_group\" TEXT,\n\t\"name\" TEXT")
+	}
+	for _, c := range t.columns {
+		fmt.Fprintf(&sb, ",\n\t%s %s", sqlQuote(c.name), c.typ)
+	}
+	sb.WriteString("\n);\n")
+	fmt.Fprintf(
+		&sb, "CREATE INDEX IF NOT EXISTS %s ON %s (\"parent_id\");\n",
+		sqlQuote(t.name+"_parent_id"), sqlQuote(t.name),
+	)
+	return sb.String()
+}
+
+func (t *sqlTable) insertSQL() string {
+	names := t.columnNames()
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = sqlQuote(n)
+	}
+	return fmt.Sprintf(
+		"INSERT OR REPLACE INTO %s (%s) VALUES (?%s)",
+		sqlQuote(t.name), strings.Join(quoted, ", "), strings.Repeat(", ?", len(names)-1),
+	)
+}
+
+func (e *sqlExporter) export(ctx context.Context, tx *sql.Tx, subs []*Subscription) error {
+	if _, err := tx.ExecContext(ctx, sqlBaseSchema); err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+	for _, t := range e.tables {
+		if _, err := tx.ExecContext(ctx, t.createSQL()); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", t.name, err)
+		}
+		stmt, err := tx.PrepareContext(ctx, t.insertSQL())
+		if err != nil {
+			return fmt.Errorf("failed to prepare insert for %s: %w", t.name, err)
+		}
+		defer stmt.Close()
+		t.insert = stmt
+	}
+	for _, sub := range subs {
+		if err := e.exportSubscription(ctx, tx, sub); err != nil {
+			return fmt.Errorf("failed to export subscription %s: %w", sub, err)
+		}
+	}
+	return nil
+}
+
+func (e *sqlExporter) exportSubscription(ctx context.Context, tx *sql.Tx, sub *Subscription) error {
+	if sub.ID == "" {
+		return fmt.Errorf("subscription has no ID")
+	}
+	// Remove anything from a previous export of this subscription
+	if _, err := tx.ExecContext(ctx, `DELETE FROM "resource_groups" WHERE "subscription" = ?`, sub.ID); err != nil {
+		return err
+	}
+	for _, t := range e.tables {
+		q := fmt.Sprintf(`DELETE FROM %s WHERE "subscription" = ?`, sqlQuote(t.name))
+		if _, err := tx.ExecContext(ctx, q, sub.ID); err != nil {
+			return err
+		}
+	}
+	var audit interface{}
+	if !sub.AuditDate.IsZero() {
+		audit = sub.AuditDate.Format(time.RFC3339)
+	}
+	_, err := tx.ExecContext(
+		ctx, `INSERT OR REPLACE INTO "subscriptions" ("id", "alias", "audit_date") VALUES (?, ?, ?)`,
+		sub.ID, sub.Alias, audit,
+	)
+	if err != nil {
+		return err
+	}
+	for name, rg := range sub.ResourceGroups {
+		if rg == nil {
+			continue
+		}
+		rgID := rg.Meta.RawID
+		if rgID == "" {
+			rgID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", sub.ID, name)
+		}
+		_, err := tx.ExecContext(
+			ctx, `INSERT OR REPLACE INTO "resource_groups" ("id", "subscription", "name") VALUES (?, ?, ?)`,
+			rgID, sub.ID, name,
+		)
+		if err != nil {
+			return err
+		}
+		rgv := reflect.ValueOf(rg).Elem()
+		for _, top := range e.rg {
+			if err := e.insertAll(ctx, top.table, rgv.Field(top.field), sub.ID, rgID, top.table.name); err != nil {
+				return err
+			}
+		}
+	}
+	// Resources that live directly on the subscription, like classic storage
+	// accounts, go in the same tables as their resource group counterparts.
+	sv := reflect.ValueOf(sub).Elem()
+	parent := "/subscriptions/" + sub.ID
+	for _, top := range e.sub {
+		field := toSnakeCase(sqlSubType.Field(top.field).Name)
+		if err := e.insertAll(ctx, top.table, sv.Field(top.field), sub.ID, parent, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *sqlExporter) insertAll(ctx context.Context, t *sqlTable, slice reflect.Value, sub, parent, field string) error {
+	for i := 0; i < slice.Len(); i++ {
+		v := derefPtr(slice.Index(i))
+		if !v.IsValid() {
+			continue
+		}
+		fallback := fmt.Sprintf("%s/%s/%d", parent, field, i)
+		if err := e.insert(ctx, t, v, sub, parent, i, fallback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *sqlExporter) insert(ctx context.Context, t *sqlTable, v reflect.Value, sub, parent string, idx int, fallback string) error {
+	id := fallback
+	args := make([]interface{}, 0, len(t.columns)+6)
+	args = append(args, nil, sub, parent, idx)
+	if t.meta != nil {
+		meta := v.FieldByIndex(t.meta).Interface().(ResourceID)
+		if meta.RawID != "" {
+			id = meta.RawID
+		}
+		args = append(args, meta.ResourceGroupName, meta.Name)
+	}
+	args[0] = id
+	for _, c := range t.columns {
+		// Value tables have a single column for the element itself
+		fv := v
+		if c.index != nil {
+			var err error
+			fv, err = v.FieldByIndexErr(c.index)
+			if err != nil {
+				// A nil pointer along the way
+				args = append(args, nil)
+				continue
+			}
+		}
+		val, err := sqlValue(fv, c.json)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.name, c.name, err)
+		}
+		args = append(args, val)
+	}
+	if _, err := t.insert.ExecContext(ctx, args...); err != nil {
+		return fmt.Errorf("failed to insert into %s: %w", t.name, err)
+	}
+	for _, child := range t.children {
+		cv, err := v.FieldByIndexErr(child.index)
+		if err != nil {
+			continue
+		}
+		if err := e.insertAll(ctx, child.table, cv, sub, id, child.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlScalarType returns the SQLite column type for types that fit in a single
+// column without JSON.
+func sqlScalarType(ty reflect.Type) (string, bool) {
+	if ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	switch {
+	case ty == sqlTimeType || ty == sqlResourceIDType:
+		return "TEXT", true
+	case ty.Kind() == reflect.Interface:
+		return "TEXT", ty.Implements(sqlStringerType)
+	case ty.Kind() == reflect.Struct || ty.Kind() == reflect.Slice ||
+		ty.Kind() == reflect.Map || ty.Kind() == reflect.Array:
+		return "", false
+	case sqlIsStringer(ty):
+		return "TEXT", true
+	}
+	switch ty.Kind() {
+	case reflect.String:
+		return "TEXT", true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER", true
+	case reflect.Float32, reflect.Float64:
+		return "REAL", true
+	}
+	return "", false
+}
+
+// sqlValueTableType returns the column type for a slice of scalars tagged
+// with `sqlite:"table"`.
+func sqlValueTableType(f reflect.StructField) (string, bool) {
+	if f.Tag.Get("sqlite") != "table" || f.Type.Kind() != reflect.Slice {
+		return "", false
+	}
+	return sqlScalarType(f.Type.Elem())
+}
+
+// sqlStructElem returns the struct type of ty's elements if it is a slice of
+// structs or struct pointers that should get its own table.
+func sqlStructElem(ty reflect.Type) (reflect.Type, bool) {
+	if ty.Kind() != reflect.Slice {
+		return nil, false
+	}
+	elem := ty.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct || elem == sqlTimeType || elem == sqlResourceIDType {
+		return nil, false
+	}
+	return elem, true
+}
+
+func sqlIsStringer(ty reflect.Type) bool {
+	return ty.Implements(sqlStringerType) || reflect.PtrTo(ty).Implements(sqlStringerType)
+}
+
+func sqlInStack(ty reflect.Type, stack []reflect.Type) bool {
+	for _, s := range stack {
+		if s == ty {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlValue converts v to a value that can be inserted into SQLite.
+func sqlValue(v reflect.Value, asJSON bool) (interface{}, error) {
+	if asJSON {
+		return sqlJSONValue(v)
+	}
+	v = derefPtr(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	ty := v.Type()
+	switch {
+	case ty == sqlTimeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil, nil
+		}
+		return t.Format(time.RFC3339), nil
+	case ty == sqlResourceIDType:
+		if id := v.Interface().(ResourceID); id.RawID != "" {
+			return id.RawID, nil
+		}
+		return nil, nil
+	case ty.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return sqlStringOf(v.Elem()), nil
+	case ty.Kind() != reflect.String && sqlIsStringer(ty):
+		return sqlStringOf(v), nil
+	}
+	switch ty.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	return nil, fmt.Errorf("can't store %v in a column", ty)
+}
+
+// sqlJSONValue encodes v as JSON. Slices of things with a string value, like
+// IPs and ports, are encoded as a list of those strings.
+func sqlJSONValue(v reflect.Value) (interface{}, error) {
+	v = derefPtr(v)
+	if !v.IsValid() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil() {
+		return nil, nil
+	}
+	var enc interface{} = v.Interface()
+	if v.Kind() == reflect.Slice {
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		stringer := elem.Kind() != reflect.Struct && elem.Kind() != reflect.String && sqlIsStringer(elem)
+		if elem == sqlResourceIDType || stringer {
+			strs := make([]interface{}, v.Len())
+			for i := range strs {
+				strs[i], _ = sqlValue(v.Index(i), false)
+			}
+			enc = strs
+		}
+	}
+	b, err := json.Marshal(enc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func sqlStringOf(v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	if s, ok := ptr.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func sqlQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// toSnakeCase converts a Go field name to snake case. Initialisms are kept
+// together, including plural ones, so `HTTPSOnly` becomes `https_only` and
+// `APIs` becomes `apis`.
+func toSnakeCase(s string) string {
+	rs := []rune(s)
+	var sb strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			split := unicode.IsLower(prev) || unicode.IsDigit(prev)
+			if !split && unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+				plural := rs[i+1] == 's' && (i+2 == len(rs) || unicode.IsUpper(rs[i+2]))
+				split = !plural
+			}
+			if split {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,24 @@
+package inzure
+
+import (
+	"testing"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	expected := map[string]string{
+		"StorageAccounts":    "storage_accounts",
+		"HTTPSOnly":          "https_only",
+		"MinTLSVersion":      "min_tls_version",
+		"APIs":               "apis",
+		"StaticIPs":          "static_ips",
+		"IPsAllowed":         "ips_allowed",
+		"SQLVirtualMachines": "sql_virtual_machines",
+		"FQDN":               "fqdn",
+		"Meta":               "meta",
+	}
+	for in, out := range expected {
+		if got := toSnakeCase(in); got != out {
+			t.Fatalf("expected %s to be %s but got %s", in, out, got)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type SQLVirtualMachine struct {
 	KeyVaultCredentialName    string
 	StorageAccountURL         string
 	StorageContainerName      string
+	Identity                  ManagedIdentity
 }
 
 func (it *SQLVirtualMachine) UnmarshalJSON(js []byte) error {
@@ -77,11 +78,13 @@ func (it *SQLVirtualMachine) UnmarshalJSON(js []byte) error {
 func NewEmptySQLVirtualMachine() *SQLVirtualMachine {
 	var rid ResourceID
 	rid.setupEmpty()
-	return &SQLVirtualMachine{
+	it := &SQLVirtualMachine{
 		Meta:            rid,
 		GroupResourceId: rid,
 		Port:            NewPortFromUint16(uint16(1433)),
 	}
+	it.Identity.setupEmpty()
+	return it
 }
 
 func (it *SQLVirtualMachine) FromAzure(az *azsqlvm.SQLVirtualMachine) {
@@ -89,6 +92,7 @@ func (it *SQLVirtualMachine) FromAzure(az *azsqlvm.SQLVirtualMachine) {
 		return
 	}
 	it.Meta.FromID(*az.ID)
+	it.Identity.FromAzureSQLVM(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -3,6 +3,8 @@ package inzure
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 //go:generate go run gen/enum.go -prefix ContainerPermission -values Private,Blob,Container -azure-type PublicAccess -azure-values PublicAccessNone,PublicAccessBlob,PublicAccessContainer -azure-import github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage
@@ -20,30 +22,32 @@ import (
 )
 
 var (
-	blobURLFmt string
+	blobURLFmt  string
+	queueURLFmt string
+	tableURLFmt string
 )
 
 func init() {
+	storageSuffix := "core.windows.net"
 	envName := os.Getenv("AZURE_ENVIRONMENT")
 	if envName != "" {
 		// TODO: Note only PublicCloud and ChinaCloud are confirmed to be correct
 		// here.
 		switch envName {
 		case "AZUREPUBLICCLOUD":
-			blobURLFmt = "https://%s.blob.core.windows.net/%s"
 		case "AZUREUSGOVERNMENTCLOUD":
-			blobURLFmt = "https://%s.blob.core.usgovcloudapi.net/%s"
+			storageSuffix = "core.usgovcloudapi.net"
 		case "AZURECHINACLOUD":
-			blobURLFmt = "https://%s.blob.core.chinacloudapi.cn/%s"
+			storageSuffix = "core.chinacloudapi.cn"
 		case "AZUREGERMANCLOUD":
-			blobURLFmt = "https://%s.blob.core.cloudapi.de/%s"
+			storageSuffix = "core.cloudapi.de"
 		default:
 			fmt.Fprintln(os.Stderr, "[WARNING] Unrecognized environment in AZURE_ENVIRONMENT", envName)
-			blobURLFmt = "https://%s.blob.core.windows.net/%s"
 		}
-	} else {
-		blobURLFmt = "https://%s.blob.core.windows.net/%s"
 	}
+	blobURLFmt = "https://%s.blob." + storageSuffix + "/%s"
+	queueURLFmt = "https://%s.queue." + storageSuffix + "/%s"
+	tableURLFmt = "https://%s.table." + storageSuffix + "/%s"
 }
 
 // StorageEncryption specifies which services are encrypted in the storage
@@ -117,8 +121,165 @@ func (fis *FilesIdentitySettings) FromAzure(az *armstorage.AzureFilesIdentityBas
 	fis.ADProperties.FromAzure(az.ActiveDirectoryProperties)
 }
 
-// StorageAccount contains the Container, Queue, and File types associated
-// with the given account.
+// StorageRetentionPolicy is a soft delete setting on the blob service.
+type StorageRetentionPolicy struct {
+	Enabled UnknownBool
+	Days    int32
+}
+
+func (rp *StorageRetentionPolicy) FromAzure(az *armstorage.DeleteRetentionPolicy) {
+	if az == nil {
+		rp.Enabled = BoolFalse
+		return
+	}
+	rp.Enabled.FromBoolPtr(az.Enabled)
+	gValFromPtr(&rp.Days, az.Days)
+}
+
+// StorageResourceAccessRule allows Azure resource instances through the
+// storage account firewall. The ResourceID can contain wildcards.
+type StorageResourceAccessRule struct {
+	ResourceID string
+	TenantID   string
+}
+
+func (r *StorageResourceAccessRule) FromAzure(az *armstorage.ResourceAccessRule) {
+	gValFromPtr(&r.ResourceID, az.ResourceID)
+	gValFromPtr(&r.TenantID, az.TenantID)
+}
+
+// StorageAccountFirewall is the network rule set of a storage account.
+type StorageAccountFirewall struct {
+	PublicNetworkAccess UnknownBool
+	DefaultAllow        UnknownBool
+	// AllowAzureServices is whether trusted Azure services bypass the rules
+	AllowAzureServices UnknownBool
+	AllowLogging       UnknownBool
+	AllowMetrics       UnknownBool
+	IPRules            IPCollection
+	VNetRules          []ResourceID
+	// ResourceAccessRules are resource instances allowed through the firewall
+	ResourceAccessRules []StorageResourceAccessRule
+}
+
+func (f *StorageAccountFirewall) setupEmpty() {
+	f.IPRules = make(IPCollection, 0)
+	f.VNetRules = make([]ResourceID, 0)
+	f.ResourceAccessRules = make([]StorageResourceAccessRule, 0)
+}
+
+func (f *StorageAccountFirewall) FromAzure(az *armstorage.NetworkRuleSet) {
+	f.setupEmpty()
+	// Without a rule set every network is allowed
+	if az == nil {
+		f.DefaultAllow = BoolTrue
+		return
+	}
+	if az.DefaultAction != nil {
+		f.DefaultAllow.FromBool(*az.DefaultAction == armstorage.DefaultActionAllow)
+	} else {
+		f.DefaultAllow = BoolTrue
+	}
+	if az.Bypass != nil {
+		f.AllowAzureServices = BoolFalse
+		f.AllowLogging = BoolFalse
+		f.AllowMetrics = BoolFalse
+		for _, b := range strings.Split(string(*az.Bypass), ",") {
+			switch armstorage.Bypass(strings.TrimSpace(b)) {
+			case armstorage.BypassAzureServices:
+				f.AllowAzureServices = BoolTrue
+			case armstorage.BypassLogging:
+				f.AllowLogging = BoolTrue
+			case armstorage.BypassMetrics:
+				f.AllowMetrics = BoolTrue
+			}
+		}
+	}
+	for _, rule := range az.IPRules {
+		if rule == nil || rule.IPAddressOrRange == nil {
+			continue
+		}
+		// Allow is the only action
+		if rule.Action != nil && !strings.EqualFold(*rule.Action, "Allow") {
+			continue
+		}
+		f.IPRules = append(f.IPRules, NewAzureIPv4FromAzure(*rule.IPAddressOrRange))
+	}
+	for _, rule := range az.VirtualNetworkRules {
+		if rule == nil || rule.VirtualNetworkResourceID == nil {
+			continue
+		}
+		var id ResourceID
+		id.FromID(*rule.VirtualNetworkResourceID)
+		f.VNetRules = append(f.VNetRules, id)
+	}
+	gSliceFromPtrSetterPtrs(
+		&f.ResourceAccessRules,
+		&az.ResourceAccessRules,
+		fromAzureSetter[armstorage.ResourceAccessRule, *StorageResourceAccessRule],
+	)
+}
+
+func (f StorageAccountFirewall) AllowsIPToPortString(ip, port string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPToPortFromString(f, ip, port)
+}
+
+func (f StorageAccountFirewall) AllowsIPString(ip string) (UnknownBool, []PacketRoute, error) {
+	return FirewallAllowsIPFromString(f, ip)
+}
+
+func (f StorageAccountFirewall) AllowsIP(chk AzureIPv4) (UnknownBool, []PacketRoute, error) {
+	if f.PublicNetworkAccess.False() {
+		return BoolFalse, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+	}
+	uncertain := false
+	for _, ip := range f.IPRules {
+		contains := IPContains(ip, chk)
+		if contains.True() {
+			return BoolTrue, []PacketRoute{AllowsAllPacketRoute()}, nil
+		} else if contains.Unknown() {
+			uncertain = true
+		}
+	}
+	if uncertain || !f.DefaultAllow.False() {
+		return BoolUnknown, nil, nil
+	}
+	return BoolFalse, nil, nil
+}
+
+// AllowsIPToPort is the same as AllowsIP since the rules apply to every
+// storage service endpoint.
+func (f StorageAccountFirewall) AllowsIPToPort(ip AzureIPv4, _ AzurePort) (UnknownBool, []PacketRoute, error) {
+	return f.AllowsIP(ip)
+}
+
+func (f StorageAccountFirewall) RespectsAllowlist(wl FirewallAllowlist) (UnknownBool, []IPPort, error) {
+	if wl.AllPorts == nil {
+		return BoolUnknown, nil, BadAllowlist
+	}
+	if f.PublicNetworkAccess.False() {
+		return BoolTrue, nil, nil
+	}
+	if f.DefaultAllow.True() {
+		return BoolFalse, []IPPort{{
+			IP:   NewAzureIPv4FromAzure("*"),
+			Port: NewPortFromAzure("*"),
+		}}, nil
+	}
+	if len(f.IPRules) == 0 {
+		if f.DefaultAllow.False() {
+			return BoolTrue, nil, nil
+		}
+		return BoolUnknown, nil, nil
+	}
+	return f.IPRules.RespectsAllowlist(wl)
+}
+
+// StorageAccount contains the Container, Queue, Table, and File types
+// associated with the given account.
 //
 // This type is intended to contain information about both classical and
 // managed storage accounts. Classical storage accounts may have less
@@ -134,20 +295,94 @@ type StorageAccount struct {
 
 	PublicNetworkAccessEnabled bool
 	DefaultToOAuth             bool
+	Firewall                   StorageAccountFirewall
+
+	// AllowSharedKeyAccess is whether the account keys (and SAS tokens
+	// signed with them) can be used. Azure treats a missing value as true.
+	AllowSharedKeyAccess UnknownBool
+	// AllowBlobPublicAccess is whether containers are allowed to be made
+	// public at all. When this is false the Container Access is ignored.
+	AllowBlobPublicAccess UnknownBool
+
+	// SASExpirationPeriod is the recommended maximum lifetime of a SAS in
+	// the DD.HH:MM:SS format. It is empty if there is no SAS policy.
+	SASExpirationPeriod string
+	// SASExpirationAction is what Azure does when the period is exceeded
+	SASExpirationAction string
+	// KeyExpirationPeriodInDays is how often the account keys should be
+	// rotated. Zero means there is no key policy.
+	KeyExpirationPeriodInDays int32
+
+	BlobSoftDelete      StorageRetentionPolicy
+	ContainerSoftDelete StorageRetentionPolicy
+	BlobVersioning      UnknownBool
+
+	// PrivateEndpoints have an approved connection to the account
+	PrivateEndpoints []ResourceID
 
 	Containers []Container
 	FileShares []FileShare
+	Queues     []Queue
+	Tables     []Table
 
 	FilesIdentitySettings FilesIdentitySettings
+	Identity              ManagedIdentity
 
 	key string
 }
 
 func NewEmptyStorageAccount() *StorageAccount {
-	return &StorageAccount{
+	sa := &StorageAccount{
 		Containers: make([]Container, 0),
 		FileShares: make([]FileShare, 0),
+		Queues:     make([]Queue, 0),
+		Tables:     make([]Table, 0),
+
+		PrivateEndpoints: make([]ResourceID, 0),
 	}
+	sa.Firewall.setupEmpty()
+	sa.Identity.setupEmpty()
+	return sa
+}
+
+// SASExpiresWithin is whether the account's SAS policy limits SAS lifetimes
+// to at most the given number of seconds. Accounts without a SAS policy
+// don't limit SAS lifetimes at all.
+func (sa *StorageAccount) SASExpiresWithin(seconds int64) UnknownBool {
+	if sa.SASExpirationPeriod == "" {
+		return BoolFalse
+	}
+	d, err := parseSASExpirationPeriod(sa.SASExpirationPeriod)
+	if err != nil {
+		return BoolUnknown
+	}
+	return UnknownFromBool(d <= time.Duration(seconds)*time.Second)
+}
+
+// parseSASExpirationPeriod parses Azure's DD.HH:MM:SS SAS expiration period
+func parseSASExpirationPeriod(p string) (time.Duration, error) {
+	var days int64
+	if dot := strings.Index(p, "."); dot != -1 {
+		var err error
+		days, err = strconv.ParseInt(p[:dot], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		p = p[dot+1:]
+	}
+	parts := strings.Split(p, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("bad SAS expiration period %s", p)
+	}
+	d := time.Duration(days) * 24 * time.Hour
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
 }
 
 type FileShareAccessPolicy struct {
@@ -167,6 +402,18 @@ func (fsap *FileShareAccessPolicy) FromAzure(az *armstorage.SignedIdentifier) {
 	}
 }
 
+// fromAzureTable loads a Table stored access policy which has the same
+// fields as the File Share ones.
+func (fsap *FileShareAccessPolicy) fromAzureTable(az *armstorage.TableSignedIdentifier) {
+	gValFromPtr(&fsap.ID, az.ID)
+	pol := az.AccessPolicy
+	if pol != nil {
+		gValFromPtr(&fsap.Permissions, pol.Permission)
+		gValFromPtr(&fsap.StartTime, pol.StartTime)
+		gValFromPtr(&fsap.ExpiryTime, pol.ExpiryTime)
+	}
+}
+
 type FileShare struct {
 	Name           string
 	StorageAccount ResourceID
@@ -231,7 +478,87 @@ func (c *Container) SetURL(sa *StorageAccount) {
 	c.URL = fmt.Sprintf(blobURLFmt, sa.Meta.Name, c.Name)
 }
 
-// Queue TODO
+type Queue struct {
+	Name           string
+	StorageAccount ResourceID
+	URL            string
+	Metadata       map[string]string
+}
+
+func (q *Queue) QueryString() string {
+	sa, err := q.StorageAccount.QueryString()
+	if err != nil {
+		return ""
+	}
+	return sa + "/Queues/" + q.Name
+}
+
+func (q *Queue) FromAzure(az *armstorage.ListQueue) {
+	gValFromPtr(&q.Name, az.Name)
+	q.Metadata = make(map[string]string)
+	props := az.QueueProperties
+	if props != nil {
+		for k, v := range props.Metadata {
+			if v != nil {
+				q.Metadata[k] = *v
+			}
+		}
+	}
+}
+
+// SetURL sets the URL using the Queue's name and the StorageAccount.
+func (q *Queue) SetURL(sa *StorageAccount) {
+	q.URL = fmt.Sprintf(queueURLFmt, sa.Meta.Name, q.Name)
+}
+
+type Table struct {
+	Name           string
+	StorageAccount ResourceID
+	URL            string
+	AccessPolicies []FileShareAccessPolicy
+}
+
+func (t *Table) QueryString() string {
+	sa, err := t.StorageAccount.QueryString()
+	if err != nil {
+		return ""
+	}
+	return sa + "/Tables/" + t.Name
+}
+
+func (t *Table) FromAzure(az *armstorage.Table) {
+	gValFromPtr(&t.Name, az.Name)
+	t.AccessPolicies = make([]FileShareAccessPolicy, 0)
+	props := az.TableProperties
+	if props != nil {
+		gSliceFromPtrSetterPtrs(
+			&t.AccessPolicies,
+			&props.SignedIdentifiers,
+			(*FileShareAccessPolicy).fromAzureTable,
+		)
+	}
+}
+
+// SetURL sets the URL using the Table's name and the StorageAccount.
+func (t *Table) SetURL(sa *StorageAccount) {
+	t.URL = fmt.Sprintf(tableURLFmt, sa.Meta.Name, t.Name)
+}
+
+// FromAzureBlobService loads the data protection settings of the blob
+// service.
+func (sa *StorageAccount) FromAzureBlobService(az *armstorage.BlobServiceProperties) {
+	if az == nil || az.BlobServiceProperties == nil {
+		return
+	}
+	props := az.BlobServiceProperties
+	sa.BlobSoftDelete.FromAzure(props.DeleteRetentionPolicy)
+	sa.ContainerSoftDelete.FromAzure(props.ContainerDeleteRetentionPolicy)
+	if props.IsVersioningEnabled == nil {
+		sa.BlobVersioning = BoolFalse
+	} else {
+		sa.BlobVersioning.FromBool(*props.IsVersioningEnabled)
+	}
+}
 
 func (sa *StorageAccount) FromAzure(acc *armstorage.Account) {
 	sa.Meta.setupEmpty()
@@ -239,6 +566,7 @@ func (sa *StorageAccount) FromAzure(acc *armstorage.Account) {
 		sa.Meta.fromID(*acc.ID)
 	}
 	sa.Kind.FromAzure(acc.Kind)
+	sa.Identity.FromAzureStorage(acc.Identity)
 	if acc.Properties != nil {
 		if acc.Properties.PublicNetworkAccess != nil {
 			sa.PublicNetworkAccessEnabled = *acc.Properties.PublicNetworkAccess == armstorage.PublicNetworkAccessEnabled
@@ -254,6 +582,33 @@ func (sa *StorageAccount) FromAzure(acc *armstorage.Account) {
 			gValFromPtr(&sa.CustomDomain, cd.Name)
 		}
 		sa.FilesIdentitySettings.FromAzure(acc.Properties.AzureFilesIdentityBasedAuthentication)
+		sa.Firewall.FromAzure(acc.Properties.NetworkRuleSet)
+		sa.Firewall.PublicNetworkAccess.FromBool(sa.PublicNetworkAccessEnabled)
+		if acc.Properties.AllowSharedKeyAccess == nil {
+			sa.AllowSharedKeyAccess = BoolTrue
+		} else {
+			sa.AllowSharedKeyAccess.FromBool(*acc.Properties.AllowSharedKeyAccess)
+		}
+		sa.AllowBlobPublicAccess.FromBoolPtr(acc.Properties.AllowBlobPublicAccess)
+		if sas := acc.Properties.SasPolicy; sas != nil {
+			gValFromPtr(&sa.SASExpirationPeriod, sas.SasExpirationPeriod)
+			if sas.ExpirationAction != nil {
+				sa.SASExpirationAction = string(*sas.ExpirationAction)
+			}
+		}
+		if kp := acc.Properties.KeyPolicy; kp != nil {
+			gValFromPtr(&sa.KeyExpirationPeriodInDays, kp.KeyExpirationPeriodInDays)
+		}
+		for _, pec := range acc.Properties.PrivateEndpointConnections {
+			if pec == nil || pec.Properties == nil || pec.Properties.PrivateEndpoint == nil {
+				continue
+			}
+			var status string
+			if cs := pec.Properties.PrivateLinkServiceConnectionState; cs != nil && cs.Status != nil {
+				status = string(*cs.Status)
+			}
+			appendApprovedPrivateEndpoint(&sa.PrivateEndpoints, pec.Properties.PrivateEndpoint.ID, status)
+		}
 	}
 	sa.Containers = make([]Container, 0)
 }
@@ -0,0 +1,145 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+const storageAccountID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Storage/storageAccounts/acct"
+
+func TestStorageAccountFromAzure(t *testing.T) {
+	deny := armstorage.DefaultActionDeny
+	bypass := armstorage.Bypass("Logging, AzureServices")
+	action := armstorage.ExpirationActionLog
+	approved := armstorage.PrivateEndpointServiceConnectionStatusApproved
+	pending := armstorage.PrivateEndpointServiceConnectionStatusPending
+	sa := NewEmptyStorageAccount()
+	sa.FromAzure(&armstorage.Account{
+		ID: ptr(storageAccountID),
+		Properties: &armstorage.AccountProperties{
+			AllowBlobPublicAccess: ptr(false),
+			SasPolicy: &armstorage.SasPolicy{
+				ExpirationAction:    &action,
+				SasExpirationPeriod: ptr("1.00:00:00"),
+			},
+			KeyPolicy: &armstorage.KeyPolicy{KeyExpirationPeriodInDays: ptr[int32](90)},
+			PrivateEndpointConnections: []*armstorage.PrivateEndpointConnection{
+				{Properties: &armstorage.PrivateEndpointConnectionProperties{
+					PrivateEndpoint:                   &armstorage.PrivateEndpoint{ID: ptr(peID)},
+					PrivateLinkServiceConnectionState: &armstorage.PrivateLinkServiceConnectionState{Status: &approved},
+				}},
+				{Properties: &armstorage.PrivateEndpointConnectionProperties{
+					PrivateEndpoint:                   &armstorage.PrivateEndpoint{ID: ptr(peID + "2")},
+					PrivateLinkServiceConnectionState: &armstorage.PrivateLinkServiceConnectionState{Status: &pending},
+				}},
+			},
+			NetworkRuleSet: &armstorage.NetworkRuleSet{
+				DefaultAction: &deny,
+				Bypass:        &bypass,
+				IPRules: []*armstorage.IPRule{
+					{IPAddressOrRange: ptr("10.0.0.0/8"), Action: ptr("Allow")},
+				},
+				VirtualNetworkRules: []*armstorage.VirtualNetworkRule{
+					{VirtualNetworkResourceID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Network/virtualNetworks/vnet/subnets/default")},
+				},
+				ResourceAccessRules: []*armstorage.ResourceAccessRule{
+					{ResourceID: ptr("/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Synapse/workspaces/*"), TenantID: ptr("tenant")},
+				},
+			},
+		},
+	})
+	if !sa.AllowSharedKeyAccess.True() || !sa.AllowBlobPublicAccess.False() {
+		t.Fatalf("bad access settings: %+v", sa)
+	}
+	if sa.SASExpirationPeriod != "1.00:00:00" || sa.SASExpirationAction != "Log" || sa.KeyExpirationPeriodInDays != 90 {
+		t.Fatalf("bad SAS/key policy: %+v", sa)
+	}
+	if len(sa.PrivateEndpoints) != 1 || sa.PrivateEndpoints[0].Tag != PrivateEndpointT {
+		t.Fatalf("bad private endpoints: %+v", sa.PrivateEndpoints)
+	}
+	if !sa.SASExpiresWithin(86400).True() || !sa.SASExpiresWithin(3600).False() {
+		t.Fatalf("bad SAS expiration check for %s", sa.SASExpirationPeriod)
+	}
+	fw := sa.Firewall
+	if !fw.AllowAzureServices.True() || !fw.AllowLogging.True() || !fw.AllowMetrics.False() {
+		t.Fatalf("bad bypass: %+v", fw)
+	}
+	if len(fw.VNetRules) != 1 || fw.VNetRules[0].Tag != SubnetT || len(fw.ResourceAccessRules) != 1 {
+		t.Fatalf("bad firewall rules: %+v", fw)
+	}
+	if allowed, _, _ := fw.AllowsIPString("10.1.2.3"); !allowed.True() {
+		t.Fatalf("expected 10.1.2.3 to be allowed: %v", allowed)
+	}
+	if allowed, _, _ := fw.AllowsIPString("8.8.8.8"); !allowed.False() {
+		t.Fatalf("expected 8.8.8.8 to be denied: %v", allowed)
+	}
+
+	// No rule set and public network access means everything is allowed
+	open := NewEmptyStorageAccount()
+	open.FromAzure(&armstorage.Account{ID: ptr(storageAccountID), Properties: &armstorage.AccountProperties{}})
+	if allowed, _, _ := open.Firewall.AllowsIPString("8.8.8.8"); !allowed.True() {
+		t.Fatalf("expected 8.8.8.8 to be allowed: %v", allowed)
+	}
+
+	sa.FromAzureBlobService(&armstorage.BlobServiceProperties{
+		BlobServiceProperties: &armstorage.BlobServicePropertiesProperties{
+			DeleteRetentionPolicy: &armstorage.DeleteRetentionPolicy{Enabled: ptr(true), Days: ptr[int32](7)},
+			IsVersioningEnabled:   ptr(true),
+		},
+	})
+	if !sa.BlobSoftDelete.Enabled.True() || sa.BlobSoftDelete.Days != 7 ||
+		!sa.ContainerSoftDelete.Enabled.False() || !sa.BlobVersioning.True() {
+		t.Fatalf("bad blob service properties: %+v", sa)
+	}
+}
+
+func TestStorageAccountQueuesAndTables(t *testing.T) {
+	sa := NewEmptyStorageAccount()
+	sa.FromAzure(&armstorage.Account{ID: ptr(storageAccountID)})
+
+	var q Queue
+	q.FromAzure(&armstorage.ListQueue{
+		Name:            ptr("jobs"),
+		QueueProperties: &armstorage.ListQueueProperties{Metadata: map[string]*string{"owner": ptr("ops")}},
+	})
+	q.SetURL(sa)
+	q.StorageAccount = sa.Meta
+	if q.URL != "https://acct.queue.core.windows.net/jobs" || q.Metadata["owner"] != "ops" {
+		t.Fatalf("bad queue: %+v", q)
+	}
+
+	var tbl Table
+	tbl.FromAzure(&armstorage.Table{
+		Name: ptr("events"),
+		TableProperties: &armstorage.TableProperties{
+			SignedIdentifiers: []*armstorage.TableSignedIdentifier{
+				{ID: ptr("read"), AccessPolicy: &armstorage.TableAccessPolicy{Permission: ptr("r")}},
+			},
+		},
+	})
+	tbl.SetURL(sa)
+	tbl.StorageAccount = sa.Meta
+	if tbl.URL != "https://acct.table.core.windows.net/events" || len(tbl.AccessPolicies) != 1 ||
+		tbl.AccessPolicies[0].Permissions != "r" {
+		t.Fatalf("bad table: %+v", tbl)
+	}
+	sa.Queues = append(sa.Queues, q)
+	sa.Tables = append(sa.Tables, tbl)
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.StorageAccounts = append(rg.StorageAccounts, sa)
+	sub.ResourceGroups[rgAName] = rg
+
+	var into []Table
+	if err := sub.FromQueryString("/StorageAccounts/*/*/Tables", &into); err != nil {
+		t.Fatal(err)
+	}
+	if len(into) != 1 || into[0].Name != "events" {
+		t.Fatalf("bad query string result: %v", into)
+	}
+	if tbl.QueryString() != "/StorageAccounts/rga/acct/Tables/events" {
+		t.Fatalf("bad query string: %s", tbl.QueryString())
+	}
+}
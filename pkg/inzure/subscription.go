@@ -31,41 +31,77 @@ const (
 	TargetPostgres
 	TargetBastionHosts
 	TargetGrafanas
+	TargetKubernetes
+	TargetContainerRegistries
+	TargetApplicationGateways
+	TargetAzureFirewalls
+	TargetVirtualMachineScaleSets
+	TargetServiceBus
+	TargetEventHubs
+	TargetMySQL
+	TargetRBAC
+	TargetManagedIdentities
+	TargetPrivateEndpoints
+	TargetEdge
 )
 
 const (
 	// TargetSearchUnsetString is the string value for TargetSearchUnset
-	TargetSearchUnsetString     = "TargetSearchUnset"
-	TargetStorageAccountsString = "storage"
-	TargetNetworkString         = "network"
-	TargetAppServiceString      = "apps"
-	TargetDataLakesString       = "datalakes"
-	TargetSQLString             = "sql"
-	TargetRedisString           = "redis"
-	TargetAPIsString            = "apis"
-	TargetKeyVaultsString       = "keyvaults"
-	TargetCosmosDBsString       = "cosmosdbs"
-	TargetLoadBalancersString   = "loadbalancers"
-	TargetPostgresString        = "postgres"
-	TargetBastionHostsString    = "bastionhosts"
-	TargetGrafanasString        = "grafanas"
+	TargetSearchUnsetString             = "TargetSearchUnset"
+	TargetStorageAccountsString         = "storage"
+	TargetNetworkString                 = "network"
+	TargetAppServiceString              = "apps"
+	TargetDataLakesString               = "datalakes"
+	TargetSQLString                     = "sql"
+	TargetRedisString                   = "redis"
+	TargetAPIsString                    = "apis"
+	TargetKeyVaultsString               = "keyvaults"
+	TargetCosmosDBsString               = "cosmosdbs"
+	TargetLoadBalancersString           = "loadbalancers"
+	TargetPostgresString                = "postgres"
+	TargetBastionHostsString            = "bastionhosts"
+	TargetGrafanasString                = "grafanas"
+	TargetKubernetesString              = "aks"
+	TargetContainerRegistriesString     = "containerregistries"
+	TargetApplicationGatewaysString     = "appgateways"
+	TargetAzureFirewallsString          = "firewalls"
+	TargetVirtualMachineScaleSetsString = "vmss"
+	TargetServiceBusString              = "servicebus"
+	TargetEventHubsString               = "eventhubs"
+	TargetMySQLString                   = "mysql"
+	TargetRBACString                    = "rbac"
+	TargetManagedIdentitiesString       = "identities"
+	TargetPrivateEndpointsString        = "privateendpoints"
+	TargetEdgeString                    = "edge"
 )
 
 // AvailableTargets is a map containing all available targets for easy lookup
 var AvailableTargets = map[string]SearchTarget{
-	TargetStorageAccountsString: TargetStorageAccounts,
-	TargetNetworkString:         TargetNetwork,
-	TargetAppServiceString:      TargetAppService,
-	TargetDataLakesString:       TargetDataLakes,
-	TargetSQLString:             TargetSQL,
-	TargetRedisString:           TargetRedis,
-	TargetAPIsString:            TargetAPIs,
-	TargetKeyVaultsString:       TargetKeyVaults,
-	TargetCosmosDBsString:       TargetCosmosDBs,
-	TargetLoadBalancersString:   TargetLoadBalancers,
-	TargetPostgresString:        TargetPostgres,
-	TargetBastionHostsString:    TargetBastionHosts,
-	TargetGrafanasString:        TargetGrafanas,
+	TargetStorageAccountsString:         TargetStorageAccounts,
+	TargetNetworkString:                 TargetNetwork,
+	TargetAppServiceString:              TargetAppService,
+	TargetDataLakesString:               TargetDataLakes,
+	TargetSQLString:                     TargetSQL,
+	TargetRedisString:                   TargetRedis,
+	TargetAPIsString:                    TargetAPIs,
+	TargetKeyVaultsString:               TargetKeyVaults,
+	TargetCosmosDBsString:               TargetCosmosDBs,
+	TargetLoadBalancersString:           TargetLoadBalancers,
+	TargetPostgresString:                TargetPostgres,
+	TargetBastionHostsString:            TargetBastionHosts,
+	TargetGrafanasString:                TargetGrafanas,
+	TargetKubernetesString:              TargetKubernetes,
+	TargetContainerRegistriesString:     TargetContainerRegistries,
+	TargetApplicationGatewaysString:     TargetApplicationGateways,
+	TargetAzureFirewallsString:          TargetAzureFirewalls,
+	TargetVirtualMachineScaleSetsString: TargetVirtualMachineScaleSets,
+	TargetServiceBusString:              TargetServiceBus,
+	TargetEventHubsString:               TargetEventHubs,
+	TargetMySQLString:                   TargetMySQL,
+	TargetRBACString:                    TargetRBAC,
+	TargetManagedIdentitiesString:       TargetManagedIdentities,
+	TargetPrivateEndpointsString:        TargetPrivateEndpoints,
+	TargetEdgeString:                    TargetEdge,
 }
 
 // SubscriptionID is just a combined UUID and optional Alias for a
@@ -112,6 +148,14 @@ type Subscription struct {
 
 	ClassicStorageAccounts []*StorageAccount
 
+	// RoleDefinitions are all of the roles that can be assigned in the
+	// subscription.
+	RoleDefinitions []*RoleDefinition
+	// RoleAssignments are assignments at the subscription scope or above.
+	// Assignments scoped to a resource group or resource are on the
+	// ResourceGroup.
+	RoleAssignments []*RoleAssignment
+
 	quiet         bool
 	classicKey    []byte
 	searchTargets map[SearchTarget]struct{}
@@ -142,6 +186,8 @@ func NewSubscriptionWithAlias(id, alias string) Subscription {
 		ResourceGroups:         make(map[string]*ResourceGroup),
 		searchTargets:          make(map[SearchTarget]struct{}),
 		ClassicStorageAccounts: make([]*StorageAccount, 0),
+		RoleDefinitions:        make([]*RoleDefinition, 0),
+		RoleAssignments:        make([]*RoleAssignment, 0),
 	}
 }
 
@@ -298,6 +344,88 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 						g.PostgresServers = append(g.PostgresServers, serv)
 					}
 				}(rg)
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Postgres flexible servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] Postgres flexible servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for serv := range azure.GetPostgresFlexibleServers(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found Postgres flexible server `%s`\n", serv.Meta.Name)
+						g.PostgresFlexibleServers = append(g.PostgresFlexibleServers, serv)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetMySQL]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] MySQL servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] MySQL servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for serv := range azure.GetMySQLServers(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found MySQL server `%s`\n", serv.Meta.Name)
+						g.MySQLServers = append(g.MySQLServers, serv)
+					}
+				}(rg)
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] MySQL flexible servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] MySQL flexible servers in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for serv := range azure.GetMySQLFlexibleServers(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found MySQL flexible server `%s`\n", serv.Meta.Name)
+						g.MySQLFlexibleServers = append(g.MySQLFlexibleServers, serv)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetManagedIdentities]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] User assigned identities in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] User assigned identities in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for uai := range azure.GetUserAssignedIdentities(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found user assigned identity `%s`\n", uai.Meta.Name)
+						g.UserAssignedIdentities = append(g.UserAssignedIdentities, uai)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetNetwork]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Virtual network gateways in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] Virtual network gateways in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for gw := range azure.GetVirtualNetworkGateways(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found virtual network gateway `%s`\n", gw.Meta.Name)
+						g.VirtualNetworkGateways = append(g.VirtualNetworkGateways, gw)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetPrivateEndpoints]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Private endpoints in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] Private endpoints in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for pe := range azure.GetPrivateEndpoints(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found private endpoint `%s`\n", pe.Meta.Name)
+						g.PrivateEndpoints = append(g.PrivateEndpoints, pe)
+					}
+				}(rg)
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Private DNS zones in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] Private DNS zones in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for z := range azure.GetPrivateDNSZones(ctx, g.Meta.Subscription, g.Meta.Name, ec) {
+						s.log("Found private DNS zone `%s`\n", z.Meta.Name)
+						g.PrivateDNSZones = append(g.PrivateDNSZones, z)
+					}
+				}(rg)
 			}
 
 			if _, do := s.searchTargets[TargetSQL]; do {
@@ -365,6 +493,128 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 				}(rg)
 			}
 
+			if _, do := s.searchTargets[TargetKubernetes]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Kubernetes clusters in `%s`\n", s)
+					defer s.log("[End] Kubernetes clusters in `%s`\n", s)
+					defer wg.Done()
+					for kc := range azure.GetKubernetesClusters(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Kubernetes cluster `%s`\n", kc.Meta.Name)
+						g.KubernetesClusters = append(g.KubernetesClusters, kc)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetContainerRegistries]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Container registries in `%s`\n", s)
+					defer s.log("[End] Container registries in `%s`\n", s)
+					defer wg.Done()
+					for cr := range azure.GetContainerRegistries(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found container registry `%s`\n", cr.Meta.Name)
+						g.ContainerRegistries = append(g.ContainerRegistries, cr)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetApplicationGateways]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Application Gateways in `%s`\n", s)
+					defer s.log("[End] Application Gateways in `%s`\n", s)
+					defer wg.Done()
+					for ag := range azure.GetApplicationGateways(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Application Gateway `%s`\n", ag.Meta.Name)
+						g.ApplicationGateways = append(g.ApplicationGateways, ag)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetAzureFirewalls]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Azure Firewalls in `%s`\n", s)
+					defer s.log("[End] Azure Firewalls in `%s`\n", s)
+					defer wg.Done()
+					for fw := range azure.GetAzureFirewalls(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Azure Firewall `%s`\n", fw.Meta.Name)
+						g.AzureFirewalls = append(g.AzureFirewalls, fw)
+					}
+				}(rg)
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Firewall Policies in `%s`\n", s)
+					defer s.log("[End] Firewall Policies in `%s`\n", s)
+					defer wg.Done()
+					for fp := range azure.GetFirewallPolicies(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Firewall Policy `%s`\n", fp.Meta.Name)
+						g.FirewallPolicies = append(g.FirewallPolicies, fp)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetVirtualMachineScaleSets]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] VM Scale Sets in `%s`\n", s)
+					defer s.log("[End] VM Scale Sets in `%s`\n", s)
+					defer wg.Done()
+					for vmss := range azure.GetVirtualMachineScaleSets(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found VM Scale Set `%s`\n", vmss.Meta.Name)
+						g.VirtualMachineScaleSets = append(g.VirtualMachineScaleSets, vmss)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetServiceBus]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Service Bus namespaces in `%s`\n", s)
+					defer s.log("[End] Service Bus namespaces in `%s`\n", s)
+					defer wg.Done()
+					for ns := range azure.GetServiceBusNamespaces(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Service Bus namespace `%s`\n", ns.Meta.Name)
+						g.ServiceBusNamespaces = append(g.ServiceBusNamespaces, ns)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetEventHubs]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Event Hubs namespaces in `%s`\n", s)
+					defer s.log("[End] Event Hubs namespaces in `%s`\n", s)
+					defer wg.Done()
+					for ns := range azure.GetEventHubNamespaces(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Event Hubs namespace `%s`\n", ns.Meta.Name)
+						g.EventHubNamespaces = append(g.EventHubNamespaces, ns)
+					}
+				}(rg)
+			}
+
+			if _, do := s.searchTargets[TargetEdge]; do {
+				wg.Add(1)
+				go func(g *ResourceGroup) {
+					s.log("[Begin] Front Doors, CDN, and Traffic Manager profiles in `%s`/`%s`\n", s, g.Meta.Name)
+					defer s.log("[End] Front Doors, CDN, and Traffic Manager profiles in `%s`/`%s`\n", s, g.Meta.Name)
+					defer wg.Done()
+					for p := range azure.GetCDNProfiles(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found CDN profile `%s`\n", p.Meta.Name)
+						g.CDNProfiles = append(g.CDNProfiles, p)
+					}
+					for fd := range azure.GetFrontDoors(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Front Door `%s`\n", fd.Meta.Name)
+						g.FrontDoors = append(g.FrontDoors, fd)
+					}
+					for tm := range azure.GetTrafficManagerProfiles(ctx, s.ID, g.Meta.Name, ec) {
+						s.log("Found Traffic Manager profile `%s`\n", tm.Meta.Name)
+						g.TrafficManagerProfiles = append(g.TrafficManagerProfiles, tm)
+					}
+				}(rg)
+			}
+
 			if _, do := s.searchTargets[TargetBastionHosts]; do {
 				wg.Add(1)
 				go func(g *ResourceGroup) {
@@ -411,6 +661,10 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 	var vms []*VirtualMachine
 	var ifaces []*NetworkInterface
 	var asgs []*ApplicationSecurityGroup
+	var routeTables []*RouteTable
+	var natGateways []*NATGateway
+	var publicIPs []*PublicIPAddress
+	var roleAssignments []*RoleAssignment
 
 	if _, ok := s.searchTargets[TargetNetwork]; ok {
 		asgs = make([]*ApplicationSecurityGroup, 0, 5)
@@ -418,6 +672,9 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 		vnets = make([]*VirtualNetwork, 0, 5)
 		vms = make([]*VirtualMachine, 0, 5)
 		ifaces = make([]*NetworkInterface, 0, 5)
+		routeTables = make([]*RouteTable, 0, 5)
+		natGateways = make([]*NATGateway, 0, 5)
+		publicIPs = make([]*PublicIPAddress, 0, 5)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -469,6 +726,58 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 				asgs = append(asgs, asg)
 			}
 		}()
+		wg.Add(1)
+		go func() {
+			s.log("[Begin] Route Tables in `%s`\n", s)
+			defer s.log("[End] Route Tables in `%s`\n", s)
+			defer wg.Done()
+			for rt := range azure.GetRouteTables(ctx, s.ID, ec) {
+				s.log("Found route table `%s`\n", rt.Meta.Name)
+				routeTables = append(routeTables, rt)
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			s.log("[Begin] NAT Gateways in `%s`\n", s)
+			defer s.log("[End] NAT Gateways in `%s`\n", s)
+			defer wg.Done()
+			for ng := range azure.GetNATGateways(ctx, s.ID, ec) {
+				s.log("Found NAT gateway `%s`\n", ng.Meta.Name)
+				natGateways = append(natGateways, ng)
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			s.log("[Begin] Public IP Addresses in `%s`\n", s)
+			defer s.log("[End] Public IP Addresses in `%s`\n", s)
+			defer wg.Done()
+			for pip := range azure.GetPublicIPAddresses(ctx, s.ID, ec) {
+				s.log("Found public IP address `%s`\n", pip.Meta.Name)
+				publicIPs = append(publicIPs, pip)
+			}
+		}()
+	}
+
+	if _, ok := s.searchTargets[TargetRBAC]; ok {
+		roleAssignments = make([]*RoleAssignment, 0, 5)
+		wg.Add(1)
+		go func() {
+			s.log("[Begin] Role Definitions in `%s`\n", s)
+			defer s.log("[End] Role Definitions in `%s`\n", s)
+			defer wg.Done()
+			for rd := range azure.GetRoleDefinitions(ctx, s.ID, ec) {
+				s.RoleDefinitions = append(s.RoleDefinitions, rd)
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			s.log("[Begin] Role Assignments in `%s`\n", s)
+			defer s.log("[End] Role Assignments in `%s`\n", s)
+			defer wg.Done()
+			for ra := range azure.GetRoleAssignments(ctx, s.ID, ec) {
+				roleAssignments = append(roleAssignments, ra)
+			}
+		}()
 	}
 
 	s.log("Waiting for subscription search to finish\n")
@@ -508,6 +817,28 @@ func (s *Subscription) SearchAllTargets(ctx context.Context, ec chan<- error) {
 		rg := s.ResourceGroups[asg.Meta.ResourceGroupName]
 		rg.ApplicationSecurityGroups = append(rg.ApplicationSecurityGroups, asg)
 	}
+	for _, rt := range routeTables {
+		rg := s.ResourceGroups[rt.Meta.ResourceGroupName]
+		rg.RouteTables = append(rg.RouteTables, rt)
+	}
+	for _, ng := range natGateways {
+		rg := s.ResourceGroups[ng.Meta.ResourceGroupName]
+		rg.NATGateways = append(rg.NATGateways, ng)
+	}
+	for _, pip := range publicIPs {
+		rg := s.ResourceGroups[pip.Meta.ResourceGroupName]
+		rg.PublicIPAddresses = append(rg.PublicIPAddresses, pip)
+	}
+	for _, ra := range roleAssignments {
+		// Assignments above the resource group level stay on the subscription
+		rg, ok := s.ResourceGroups[ra.ScopeResource.ResourceGroupName]
+		if !ok || ra.ScopeResource.ResourceGroupName == "" {
+			s.RoleAssignments = append(s.RoleAssignments, ra)
+			continue
+		}
+		rg.RoleAssignments = append(rg.RoleAssignments, ra)
+	}
+	s.resolveRoleNames()
 	s.log("Waiting to gather all URLs\n")
 }
 
@@ -44,8 +44,10 @@ func (s *Subscription) ReflectFromParsedQueryString(p *QueryString) (reflect.Val
 	if !v.IsValid() {
 		return reflect.ValueOf(nil), fmt.Errorf("bad query string %s", p.Raw)
 	}
-	err := s.valueFromQueryString(p, v)
-	return v, err
+	if err := s.valueFromQueryString(p, v); err != nil {
+		return v, err
+	}
+	return p.applyStages(v)
 }
 
 // ValueFromQueryString is the same as FromQueryString
@@ -62,7 +64,22 @@ func (s *Subscription) ValueFromQueryString(qs string, v reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	return s.valueFromQueryString(&p, v)
+	if err := s.valueFromQueryString(&p, v); err != nil {
+		return err
+	}
+	if len(p.Stages) == 0 {
+		return nil
+	}
+	// Only stages that keep the type can be loaded back into v
+	res, err := p.applyStages(v)
+	if err != nil {
+		return err
+	}
+	if res.Type() != v.Type() {
+		return fmt.Errorf("can't load the result of %s into %v, use ReflectFromQueryString", qs, v.Type())
+	}
+	v.Elem().Set(res.Elem())
+	return nil
 }
 
 func (s *Subscription) valueFromQueryString(qs *QueryString, v reflect.Value) error {
@@ -241,7 +258,10 @@ func (s *Subscription) baseValueFromQueryString(qs *QueryString, v reflect.Value
 		}
 	} else {
 		// TODO: Magic
-		values = make([]reflect.Value, 0, 10)
+		values, err = s.getAllOfTypeInSubscription(qs, qs.Sel.Resource)
+		if err != nil {
+			return err
+		}
 		for _, rg := range s.ResourceGroups {
 			nv, err := getAllOfType(qs, qs.Sel.Resource, rg)
 			if err != nil {
@@ -350,6 +370,11 @@ func (s *Subscription) fillWithAllOfType(
 	if err := checkSlice(ts, ind); err != nil {
 		return err
 	}
+	values, err := s.getAllOfTypeInSubscription(qs, ts)
+	if err != nil {
+		return err
+	}
+	ind.Set(reflect.Append(ind, values...))
 	for _, rg := range s.ResourceGroups {
 		values, err := getAllOfType(qs, ts, rg)
 		if err != nil {
@@ -387,6 +412,20 @@ func typeSliceToValues(qs *QueryString, val reflect.Value) ([]reflect.Value, err
 // getAllOfType returns all of the given resources of a given type in the
 // resource group.
 func getAllOfType(p *QueryString, ts string, rg *ResourceGroup) ([]reflect.Value, error) {
-	v := reflect.ValueOf(*rg)
-	return typeSliceToValues(p, v.FieldByName(ts))
+	v := reflect.ValueOf(*rg).FieldByName(ts)
+	// Subscription level resources aren't in resource groups
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return typeSliceToValues(p, v)
+}
+
+// getAllOfTypeInSubscription returns all of the given resources of a given
+// type that live directly on the Subscription rather than in a resource
+// group.
+func (s *Subscription) getAllOfTypeInSubscription(p *QueryString, ts string) ([]reflect.Value, error) {
+	if _, has := qsSubscriptionField(ts); !has {
+		return nil, nil
+	}
+	return typeSliceToValues(p, reflect.ValueOf(*s).FieldByName(ts))
 }
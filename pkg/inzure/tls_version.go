@@ -1,11 +1,12 @@
 package inzure
 
-//go:generate go run gen/enum.go -prefix TLSVersion -values OneZero,OneOne,OneTwo -no-string
+//go:generate go run gen/enum.go -prefix TLSVersion -values OneZero,OneOne,OneTwo,OneThree -no-string
 
 import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/redis/armredis"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 )
@@ -62,6 +63,25 @@ func (t *TLSVersion) FromAzureRedis(az *armredis.TLSVersion) {
 
 }
 
+func (t *TLSVersion) FromAzureAppGateway(az *armnetwork.ApplicationGatewaySSLProtocol) {
+	if az == nil {
+		*t = TLSVersionUnknown
+		return
+	}
+	switch *az {
+	case armnetwork.ApplicationGatewaySSLProtocolTLSv10:
+		*t = TLSVersionOneZero
+	case armnetwork.ApplicationGatewaySSLProtocolTLSv11:
+		*t = TLSVersionOneOne
+	case armnetwork.ApplicationGatewaySSLProtocolTLSv12:
+		*t = TLSVersionOneTwo
+	case armnetwork.ApplicationGatewaySSLProtocolTLSv13:
+		*t = TLSVersionOneThree
+	default:
+		*t = TLSVersionUnknown
+	}
+}
+
 func (t TLSVersion) String() string {
 	switch t {
 	case TLSVersionOneZero:
@@ -70,6 +90,8 @@ func (t TLSVersion) String() string {
 		return "TLSv1.1"
 	case TLSVersionOneTwo:
 		return "TLSv1.2"
+	case TLSVersionOneThree:
+		return "TLSv1.3"
 	default:
 		return "TLSvUnkown"
 	}
@@ -83,7 +105,68 @@ func TLSVersionFromString(s string) TLSVersion {
 		return TLSVersionOneOne
 	case "tlsv1.2":
 		return TLSVersionOneTwo
+	case "tlsv1.3":
+		return TLSVersionOneThree
 	default:
 		return TLSVersionUnknown
 	}
 }
+
+// FromAzureVersionString handles the plain "1.2" style strings that some
+// resource providers use for their minimum TLS version.
+func (t *TLSVersion) FromAzureVersionString(az *string) {
+	if az == nil {
+		*t = TLSVersionUnknown
+		return
+	}
+	switch *az {
+	case "1.0":
+		*t = TLSVersionOneZero
+	case "1.1":
+		*t = TLSVersionOneOne
+	case "1.2":
+		*t = TLSVersionOneTwo
+	case "1.3":
+		*t = TLSVersionOneThree
+	default:
+		*t = TLSVersionUnknown
+	}
+}
+
+// FromAzureMySQL handles the minimalTlsVersion property of MySQL single
+// servers.
+func (t *TLSVersion) FromAzureMySQL(az *string) {
+	if az == nil {
+		*t = TLSVersionUnknown
+		return
+	}
+	switch *az {
+	case "TLS1_0", "TLSEnforcementDisabled":
+		*t = TLSVersionOneZero
+	case "TLS1_1":
+		*t = TLSVersionOneOne
+	case "TLS1_2":
+		*t = TLSVersionOneTwo
+	default:
+		*t = TLSVersionUnknown
+	}
+}
+
+// FromAzureServerParameter handles server parameters such as MySQL's
+// tls_version which can be a comma separated list of allowed versions like
+// "TLSv1.2,TLSv1.3". The lowest allowed version is used.
+func (t *TLSVersion) FromAzureServerParameter(az *string) {
+	*t = TLSVersionUnknown
+	if az == nil {
+		return
+	}
+	for _, v := range strings.Split(*az, ",") {
+		ver := TLSVersionFromString(strings.TrimSpace(v))
+		if ver == TLSVersionUnknown {
+			continue
+		}
+		if *t == TLSVersionUnknown || ver < *t {
+			*t = ver
+		}
+	}
+}
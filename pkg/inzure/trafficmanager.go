@@ -0,0 +1,123 @@
+package inzure
+
+import (
+	"strings"
+)
+
+// Traffic Manager isn't covered by an SDK module in use here so these are the
+// pieces of the REST API that we care about.
+//
+// TODO: Switch to armtrafficmanager once it's a dependency.
+const trafficManagerAPIVersion = "2022-04-01"
+
+type azTrafficManagerProfile struct {
+	ID         *string `json:"id"`
+	Properties *struct {
+		ProfileStatus        *string `json:"profileStatus"`
+		TrafficRoutingMethod *string `json:"trafficRoutingMethod"`
+		DNSConfig            *struct {
+			FQDN *string `json:"fqdn"`
+		} `json:"dnsConfig"`
+		Endpoints []*struct {
+			Name       *string `json:"name"`
+			Type       *string `json:"type"`
+			Properties *struct {
+				Target                *string `json:"target"`
+				TargetResourceID      *string `json:"targetResourceId"`
+				EndpointStatus        *string `json:"endpointStatus"`
+				EndpointMonitorStatus *string `json:"endpointMonitorStatus"`
+			} `json:"properties"`
+		} `json:"endpoints"`
+	} `json:"properties"`
+}
+
+// TrafficManagerEndpoint is one of the targets a Traffic Manager profile
+// resolves to.
+type TrafficManagerEndpoint struct {
+	Name string
+	// Type is Azure, External, or Nested
+	Type string
+	// Target is the DNS name or IP address the profile resolves to
+	Target string
+	// TargetResource is set for Azure and Nested endpoints
+	TargetResource ResourceID
+	Enabled        UnknownBool
+	MonitorStatus  string
+}
+
+// TrafficManagerProfile is a DNS based load balancer
+type TrafficManagerProfile struct {
+	Meta ResourceID
+	// FQDN is the profile's name under trafficmanager.net
+	FQDN          string
+	RoutingMethod string
+	Enabled       UnknownBool
+	Endpoints     []TrafficManagerEndpoint
+}
+
+func NewEmptyTrafficManagerProfile() *TrafficManagerProfile {
+	tm := &TrafficManagerProfile{
+		Endpoints: make([]TrafficManagerEndpoint, 0),
+	}
+	tm.Meta.setupEmpty()
+	return tm
+}
+
+func (tm *TrafficManagerProfile) fromAzure(az *azTrafficManagerProfile) {
+	if az.ID == nil {
+		return
+	}
+	tm.Meta.fromID(*az.ID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	gValFromPtr(&tm.RoutingMethod, props.TrafficRoutingMethod)
+	tm.Enabled = edgeEnabledState(props.ProfileStatus)
+	if props.DNSConfig != nil {
+		gValFromPtr(&tm.FQDN, props.DNSConfig.FQDN)
+	}
+	for _, aze := range props.Endpoints {
+		if aze == nil {
+			continue
+		}
+		var e TrafficManagerEndpoint
+		e.TargetResource.setupEmpty()
+		gValFromPtr(&e.Name, aze.Name)
+		if aze.Type != nil {
+			e.Type = trafficManagerEndpointType(*aze.Type)
+		}
+		if ep := aze.Properties; ep != nil {
+			gValFromPtr(&e.Target, ep.Target)
+			gValFromPtr(&e.MonitorStatus, ep.EndpointMonitorStatus)
+			e.Enabled = edgeEnabledState(ep.EndpointStatus)
+			if ep.TargetResourceID != nil {
+				e.TargetResource.fromID(*ep.TargetResourceID)
+			}
+		}
+		tm.Endpoints = append(tm.Endpoints, e)
+	}
+}
+
+// trafficManagerEndpointType turns the endpoint's resource type, such as
+// Microsoft.Network/trafficManagerProfiles/azureEndpoints, into Azure,
+// External, or Nested.
+func trafficManagerEndpointType(t string) string {
+	t = t[strings.LastIndex(t, "/")+1:]
+	t = strings.TrimSuffix(t, "Endpoints")
+	if t == "" {
+		return t
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// Targets are the enabled endpoints' targets
+func (tm *TrafficManagerProfile) Targets() []string {
+	targets := make([]string, 0, len(tm.Endpoints))
+	for _, e := range tm.Endpoints {
+		if e.Target != "" && !e.Enabled.False() {
+			targets = append(targets, e.Target)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,57 @@
+package inzure
+
+import (
+	"testing"
+)
+
+func TestTrafficManagerProfileFromAzure(t *testing.T) {
+	tm := NewEmptyTrafficManagerProfile()
+	tm.fromAzure(testUnmarshal[azTrafficManagerProfile](t, `{
+		"id": "/subscriptions/`+subId+`/resourceGroups/rgA/providers/Microsoft.Network/trafficManagerProfiles/global",
+		"properties": {
+			"profileStatus": "Enabled",
+			"trafficRoutingMethod": "Priority",
+			"dnsConfig": {"relativeName": "global", "fqdn": "global.trafficmanager.net"},
+			"endpoints": [
+				{
+					"name": "primary",
+					"type": "Microsoft.Network/trafficManagerProfiles/azureEndpoints",
+					"properties": {
+						"target": "`+afdOriginHost+`",
+						"targetResourceId": "`+afdOriginApp+`",
+						"endpointStatus": "Enabled",
+						"endpointMonitorStatus": "Online"
+					}
+				},
+				{
+					"name": "legacy",
+					"type": "Microsoft.Network/trafficManagerProfiles/externalEndpoints",
+					"properties": {"target": "legacy.example.com", "endpointStatus": "Disabled"}
+				}
+			]
+		}
+	}`))
+	if tm.Meta.Tag != TrafficManagerProfileT || tm.FQDN != "global.trafficmanager.net" ||
+		tm.RoutingMethod != "Priority" || !tm.Enabled.True() || len(tm.Endpoints) != 2 {
+		t.Fatalf("bad profile: %+v", tm)
+	}
+	primary := tm.Endpoints[0]
+	if primary.Type != "Azure" || primary.TargetResource.Tag != WebAppT || primary.MonitorStatus != "Online" {
+		t.Fatalf("bad Azure endpoint: %+v", primary)
+	}
+	if tm.Endpoints[1].Type != "External" || !tm.Endpoints[1].Enabled.False() {
+		t.Fatalf("bad external endpoint: %+v", tm.Endpoints[1])
+	}
+	if targets := tm.Targets(); len(targets) != 1 || targets[0] != afdOriginHost {
+		t.Fatalf("disabled endpoint in targets: %v", targets)
+	}
+
+	sub := NewSubscription(subId)
+	rg := NewEmptyResourceGroup()
+	rg.TrafficManagerProfiles = append(rg.TrafficManagerProfiles, tm)
+	sub.ResourceGroups[rgAName] = rg
+	as := sub.GetAttackSurface()
+	if len(as.TrafficManagers) != 1 || as.TrafficManagers[0].Hostnames[0] != tm.FQDN || len(as.TrafficManagers[0].Origins) != 1 {
+		t.Fatalf("bad Traffic Manager attack surface: %+v", as.TrafficManagers)
+	}
+}
@@ -24,6 +24,7 @@ type VirtualMachine struct {
 	CustomData              string
 	OsType                  OsType
 	Disks                   []VMDisk
+	Identity                ManagedIdentity
 }
 
 // DiskEncryption holds the location of an encryption key and whether that key
@@ -55,6 +56,7 @@ func NewEmptyVirtualMachine() *VirtualMachine {
 	}
 	vm.Meta.setupEmpty()
 	vm.PrimaryNetworkInterface.setupEmpty()
+	vm.Identity.setupEmpty()
 	return vm
 }
 
@@ -76,6 +78,7 @@ func (vm *VirtualMachine) FromAzure(az *armcompute.VirtualMachine) {
 	if az.ID != nil {
 		vm.Meta.fromID(*az.ID)
 	}
+	vm.Identity.FromAzureVM(az.Identity)
 	props := az.Properties
 	if props == nil {
 		return
@@ -170,42 +173,56 @@ func (vm *VirtualMachine) loadOSProfile(os *armcompute.OSProfile) {
 		if win.EnableAutomaticUpdates != nil {
 			vm.AutomaticUpdates = UnknownFromBool(*win.EnableAutomaticUpdates)
 		}
-		if win.WinRM != nil && win.WinRM.Listeners != nil {
-			for _, rml := range win.WinRM.Listeners {
-				var url string
-				if rml.CertificateURL != nil {
-					url = *rml.CertificateURL
-				}
-				isHttps := BoolUnknown
-				if rml.Protocol != nil {
-					isHttps = UnknownFromBool(*rml.Protocol == armcompute.ProtocolTypesHTTPS)
-				}
-				vm.WindowsRMListeners = append(
-					vm.WindowsRMListeners,
-					WindowsRMListener{
-						IsHTTPS:        isHttps,
-						CertificateURL: url,
-					},
-				)
-			}
-		}
+		vm.WindowsRMListeners = append(vm.WindowsRMListeners, windowsRMListenersFromAzure(win)...)
 	} else if os.LinuxConfiguration != nil {
 
 		vm.OsType = OsTypeLinux
 
 		lin := os.LinuxConfiguration
 		vm.DisablePasswordAuth.FromBoolPtr(lin.DisablePasswordAuthentication)
-		if lin.SSH != nil && lin.SSH.PublicKeys != nil {
-			for _, pk := range lin.SSH.PublicKeys {
-				var pubKey SSHPublicKey
-				if pk.Path != nil {
-					pubKey.Path = *pk.Path
-				}
-				if pk.KeyData != nil {
-					pubKey.PublicKey = *pk.KeyData
-				}
-				vm.SSHKeys = append(vm.SSHKeys, pubKey)
-			}
+		vm.SSHKeys = append(vm.SSHKeys, sshKeysFromAzure(lin)...)
+	}
+}
+
+func windowsRMListenersFromAzure(win *armcompute.WindowsConfiguration) []WindowsRMListener {
+	listeners := make([]WindowsRMListener, 0)
+	if win.WinRM == nil || win.WinRM.Listeners == nil {
+		return listeners
+	}
+	for _, rml := range win.WinRM.Listeners {
+		var url string
+		if rml.CertificateURL != nil {
+			url = *rml.CertificateURL
+		}
+		isHttps := BoolUnknown
+		if rml.Protocol != nil {
+			isHttps = UnknownFromBool(*rml.Protocol == armcompute.ProtocolTypesHTTPS)
+		}
+		listeners = append(
+			listeners,
+			WindowsRMListener{
+				IsHTTPS:        isHttps,
+				CertificateURL: url,
+			},
+		)
+	}
+	return listeners
+}
+
+func sshKeysFromAzure(lin *armcompute.LinuxConfiguration) []SSHPublicKey {
+	keys := make([]SSHPublicKey, 0)
+	if lin.SSH == nil || lin.SSH.PublicKeys == nil {
+		return keys
+	}
+	for _, pk := range lin.SSH.PublicKeys {
+		var pubKey SSHPublicKey
+		if pk.Path != nil {
+			pubKey.Path = *pk.Path
+		}
+		if pk.KeyData != nil {
+			pubKey.PublicKey = *pk.KeyData
 		}
+		keys = append(keys, pubKey)
 	}
+	return keys
 }
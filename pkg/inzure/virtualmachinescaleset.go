@@ -0,0 +1,286 @@
+package inzure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+// VirtualMachineScaleSet holds the data for a VM Scale Set. The security
+// settings come from the scale set's VM profile which applies to every
+// instance. The actual network interfaces, and therefore public IPs, are on
+// each of the Instances.
+type VirtualMachineScaleSet struct {
+	Meta                ResourceID
+	SKU                 string
+	Capacity            int64
+	OrchestrationMode   string
+	UpgradeMode         string
+	ComputerNamePrefix  string
+	AdminUser           string
+	DisablePasswordAuth UnknownBool
+	SSHKeys             []SSHPublicKey
+	AutomaticUpdates    UnknownBool
+	// AutomaticOSUpgrades is whether the scale set rolls out new OS images
+	// automatically
+	AutomaticOSUpgrades            UnknownBool
+	WindowsRMListeners             []WindowsRMListener
+	CustomData                     string
+	OsType                         OsType
+	EncryptionAtHost               UnknownBool
+	NetworkInterfaceConfigurations []VMSSNetworkInterfaceConfiguration
+	Instances                      []VMSSInstance
+	Identity                       ManagedIdentity
+}
+
+func NewEmptyVirtualMachineScaleSet() *VirtualMachineScaleSet {
+	vmss := &VirtualMachineScaleSet{
+		SSHKeys:                        make([]SSHPublicKey, 0),
+		WindowsRMListeners:             make([]WindowsRMListener, 0),
+		NetworkInterfaceConfigurations: make([]VMSSNetworkInterfaceConfiguration, 0),
+		Instances:                      make([]VMSSInstance, 0),
+	}
+	vmss.Meta.setupEmpty()
+	vmss.Identity.setupEmpty()
+	return vmss
+}
+
+// VMSSNetworkInterfaceConfiguration is the template for a network interface
+// created on each instance of a scale set.
+type VMSSNetworkInterfaceConfiguration struct {
+	Name                 string
+	Primary              UnknownBool
+	NetworkSecurityGroup ResourceID
+	IPConfigurations     []VMSSIPConfiguration
+}
+
+// VMSSIPConfiguration is the template for an IP configuration on each
+// instance's network interface.
+type VMSSIPConfiguration struct {
+	Name   string
+	Subnet ResourceID
+	// PublicIPPerInstance is whether every instance gets its own public IP
+	PublicIPPerInstance            UnknownBool
+	PublicIPDomainNameLabel        string
+	LoadBalancerBackendPools       []ResourceID
+	LoadBalancerInboundNATPools    []ResourceID
+	ApplicationGatewayBackendPools []ResourceID
+	ASGRefs                        []ResourceID
+}
+
+// VMSSInstance is a single VM in a scale set. The NetworkInterfaces are
+// only references until they're filled in with the instance's actual
+// interfaces.
+type VMSSInstance struct {
+	Meta              ResourceID
+	InstanceID        string
+	ComputerName      string
+	NetworkInterfaces []NetworkInterface
+}
+
+func (vmss *VirtualMachineScaleSet) FromAzure(az *armcompute.VirtualMachineScaleSet) {
+	vmss.OsType = OsTypeUnknown
+	if az.ID == nil {
+		return
+	}
+	vmss.Meta.FromID(*az.ID)
+	vmss.Identity.FromAzureVMSS(az.Identity)
+	if az.SKU != nil {
+		gValFromPtr(&vmss.SKU, az.SKU.Name)
+		gValFromPtr(&vmss.Capacity, az.SKU.Capacity)
+	}
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.OrchestrationMode != nil {
+		vmss.OrchestrationMode = string(*props.OrchestrationMode)
+	}
+	if up := props.UpgradePolicy; up != nil {
+		if up.Mode != nil {
+			vmss.UpgradeMode = string(*up.Mode)
+		}
+		if up.AutomaticOSUpgradePolicy != nil {
+			vmss.AutomaticOSUpgrades.FromBoolPtr(up.AutomaticOSUpgradePolicy.EnableAutomaticOSUpgrade)
+		} else {
+			vmss.AutomaticOSUpgrades = BoolFalse
+		}
+	}
+	profile := props.VirtualMachineProfile
+	if profile == nil {
+		return
+	}
+	if profile.OSProfile != nil {
+		vmss.loadOSProfile(profile.OSProfile)
+	}
+	if vmss.OsType.IsUnknown() && profile.StorageProfile != nil && profile.StorageProfile.OSDisk != nil {
+		vmss.OsType.FromAzure(profile.StorageProfile.OSDisk.OSType)
+	}
+	if profile.SecurityProfile != nil {
+		vmss.EncryptionAtHost.FromBoolPtr(profile.SecurityProfile.EncryptionAtHost)
+	}
+	if profile.NetworkProfile != nil {
+		gSliceFromPtrSetterPtrs(
+			&vmss.NetworkInterfaceConfigurations,
+			&profile.NetworkProfile.NetworkInterfaceConfigurations,
+			func(nic *VMSSNetworkInterfaceConfiguration, az *armcompute.VirtualMachineScaleSetNetworkConfiguration) {
+				nic.FromAzure(az)
+			},
+		)
+	}
+}
+
+func (vmss *VirtualMachineScaleSet) loadOSProfile(os *armcompute.VirtualMachineScaleSetOSProfile) {
+	gValFromPtr(&vmss.ComputerNamePrefix, os.ComputerNamePrefix)
+	gValFromPtr(&vmss.AdminUser, os.AdminUsername)
+	gValFromPtr(&vmss.CustomData, os.CustomData)
+
+	if os.WindowsConfiguration != nil {
+		vmss.OsType = OsTypeWindows
+		win := os.WindowsConfiguration
+		vmss.DisablePasswordAuth = BoolFalse
+		vmss.AutomaticUpdates.FromBoolPtr(win.EnableAutomaticUpdates)
+		vmss.WindowsRMListeners = append(vmss.WindowsRMListeners, windowsRMListenersFromAzure(win)...)
+	} else if os.LinuxConfiguration != nil {
+		vmss.OsType = OsTypeLinux
+		lin := os.LinuxConfiguration
+		vmss.DisablePasswordAuth.FromBoolPtr(lin.DisablePasswordAuthentication)
+		vmss.SSHKeys = append(vmss.SSHKeys, sshKeysFromAzure(lin)...)
+	}
+}
+
+func (nic *VMSSNetworkInterfaceConfiguration) FromAzure(az *armcompute.VirtualMachineScaleSetNetworkConfiguration) {
+	nic.NetworkSecurityGroup.setupEmpty()
+	nic.IPConfigurations = make([]VMSSIPConfiguration, 0)
+	if az == nil {
+		return
+	}
+	gValFromPtr(&nic.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	nic.Primary.FromBoolPtr(props.Primary)
+	if props.NetworkSecurityGroup != nil {
+		gValFromPtrFromAzure(&nic.NetworkSecurityGroup, props.NetworkSecurityGroup.ID)
+	}
+	gSliceFromPtrSetterPtrs(&nic.IPConfigurations, &props.IPConfigurations, func(ipc *VMSSIPConfiguration, az *armcompute.VirtualMachineScaleSetIPConfiguration) {
+		ipc.FromAzure(az)
+	})
+}
+
+func (ipc *VMSSIPConfiguration) FromAzure(az *armcompute.VirtualMachineScaleSetIPConfiguration) {
+	ipc.Subnet.setupEmpty()
+	ipc.LoadBalancerBackendPools = make([]ResourceID, 0)
+	ipc.LoadBalancerInboundNATPools = make([]ResourceID, 0)
+	ipc.ApplicationGatewayBackendPools = make([]ResourceID, 0)
+	ipc.ASGRefs = make([]ResourceID, 0)
+	if az == nil {
+		return
+	}
+	gValFromPtr(&ipc.Name, az.Name)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.Subnet != nil {
+		gValFromPtrFromAzure(&ipc.Subnet, props.Subnet.ID)
+	}
+	if pip := props.PublicIPAddressConfiguration; pip != nil {
+		ipc.PublicIPPerInstance = BoolTrue
+		if pip.Properties != nil && pip.Properties.DNSSettings != nil {
+			gValFromPtr(&ipc.PublicIPDomainNameLabel, pip.Properties.DNSSettings.DomainNameLabel)
+		}
+	} else {
+		ipc.PublicIPPerInstance = BoolFalse
+	}
+	vmssSubResourceIDs(&ipc.LoadBalancerBackendPools, props.LoadBalancerBackendAddressPools)
+	vmssSubResourceIDs(&ipc.LoadBalancerInboundNATPools, props.LoadBalancerInboundNatPools)
+	vmssSubResourceIDs(&ipc.ApplicationGatewayBackendPools, props.ApplicationGatewayBackendAddressPools)
+	vmssSubResourceIDs(&ipc.ASGRefs, props.ApplicationSecurityGroups)
+}
+
+func vmssSubResourceIDs(into *[]ResourceID, from []*armcompute.SubResource) {
+	for _, sr := range from {
+		if sr != nil && sr.ID != nil {
+			var id ResourceID
+			id.FromID(*sr.ID)
+			*into = append(*into, id)
+		}
+	}
+}
+
+func (inst *VMSSInstance) FromAzure(az *armcompute.VirtualMachineScaleSetVM) {
+	inst.Meta.setupEmpty()
+	inst.NetworkInterfaces = make([]NetworkInterface, 0)
+	if az.ID == nil {
+		return
+	}
+	inst.Meta.FromID(*az.ID)
+	gValFromPtr(&inst.InstanceID, az.InstanceID)
+	props := az.Properties
+	if props == nil {
+		return
+	}
+	if props.OSProfile != nil {
+		gValFromPtr(&inst.ComputerName, props.OSProfile.ComputerName)
+	}
+	if props.NetworkProfile != nil {
+		for _, aziface := range props.NetworkProfile.NetworkInterfaces {
+			if aziface != nil && aziface.ID != nil {
+				var ni NetworkInterface
+				ni.setupEmpty()
+				ni.Meta.FromID(*aziface.ID)
+				inst.NetworkInterfaces = append(inst.NetworkInterfaces, ni)
+			}
+		}
+	}
+}
+
+// SetNetworkInterface replaces the reference to the given interface on
+// whichever instance it belongs to with the full interface. It returns false
+// if the interface doesn't belong to any instance.
+func (vmss *VirtualMachineScaleSet) SetNetworkInterface(ni *NetworkInterface) bool {
+	for i := range vmss.Instances {
+		ifaces := vmss.Instances[i].NetworkInterfaces
+		for j := range ifaces {
+			if strings.EqualFold(ifaces[j].Meta.RawID, ni.Meta.RawID) {
+				ifaces[j] = *ni
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPublicIP fills in the address of any instance IP configuration that
+// references the given public IP. Listing network interfaces for a scale set
+// only gives references to the per instance public IPs.
+func (vmss *VirtualMachineScaleSet) SetPublicIP(pip *PublicIP) {
+	for i := range vmss.Instances {
+		for j := range vmss.Instances[i].NetworkInterfaces {
+			ipcs := vmss.Instances[i].NetworkInterfaces[j].IPConfigurations
+			for k := range ipcs {
+				if strings.EqualFold(ipcs[k].PublicIP.Meta.RawID, pip.Meta.RawID) {
+					ipcs[k].PublicIP = *pip
+				}
+			}
+		}
+	}
+}
+
+// PublicAddresses gives the FQDN, or IP if there is no FQDN, of every public
+// IP on the scale set's instances.
+func (vmss *VirtualMachineScaleSet) PublicAddresses() []string {
+	addrs := make([]string, 0)
+	for _, inst := range vmss.Instances {
+		for _, nic := range inst.NetworkInterfaces {
+			for _, ipc := range nic.IPConfigurations {
+				if addr := ipc.PublicIP.address(); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,132 @@
+package inzure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v8"
+)
+
+const vmssID = "/subscriptions/" + subId + "/resourceGroups/rgA/providers/Microsoft.Compute/virtualMachineScaleSets/web"
+
+func TestVirtualMachineScaleSetFromAzure(t *testing.T) {
+	vmss := NewEmptyVirtualMachineScaleSet()
+	vmss.FromAzure(&armcompute.VirtualMachineScaleSet{
+		ID:  ptr(vmssID),
+		SKU: &armcompute.SKU{Name: ptr("Standard_B1s"), Capacity: ptr[int64](2)},
+		Properties: &armcompute.VirtualMachineScaleSetProperties{
+			VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{
+				OSProfile: &armcompute.VirtualMachineScaleSetOSProfile{
+					ComputerNamePrefix: ptr("web"),
+					AdminUsername:      ptr("azureuser"),
+					CustomData:         ptr("IyEvYmluL3NoCg=="),
+					LinuxConfiguration: &armcompute.LinuxConfiguration{
+						DisablePasswordAuthentication: ptr(false),
+						SSH: &armcompute.SSHConfiguration{
+							PublicKeys: []*armcompute.SSHPublicKey{
+								{Path: ptr("/home/azureuser/.ssh/authorized_keys"), KeyData: ptr("ssh-rsa AAAA")},
+							},
+						},
+					},
+				},
+				NetworkProfile: &armcompute.VirtualMachineScaleSetNetworkProfile{
+					NetworkInterfaceConfigurations: []*armcompute.VirtualMachineScaleSetNetworkConfiguration{
+						{
+							Name: ptr("nic"),
+							Properties: &armcompute.VirtualMachineScaleSetNetworkConfigurationProperties{
+								Primary: ptr(true),
+								IPConfigurations: []*armcompute.VirtualMachineScaleSetIPConfiguration{
+									{
+										Name: ptr("ipconfig"),
+										Properties: &armcompute.VirtualMachineScaleSetIPConfigurationProperties{
+											PublicIPAddressConfiguration: &armcompute.VirtualMachineScaleSetPublicIPAddressConfiguration{
+												Name: ptr("pip"),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if vmss.Meta.Tag != VirtualMachineScaleSetT || vmss.Meta.Name != "web" || vmss.Capacity != 2 {
+		t.Fatalf("bad meta: %+v", vmss)
+	}
+	if vmss.OsType != OsTypeLinux || !vmss.DisablePasswordAuth.False() || len(vmss.SSHKeys) != 1 {
+		t.Fatalf("bad os profile: %+v", vmss)
+	}
+	if vmss.CustomData == "" || vmss.AdminUser != "azureuser" {
+		t.Fatalf("bad os profile: %+v", vmss)
+	}
+	ipc := vmss.NetworkInterfaceConfigurations[0].IPConfigurations[0]
+	if !ipc.PublicIPPerInstance.True() {
+		t.Fatalf("expected public IP per instance: %+v", ipc)
+	}
+}
+
+func TestVirtualMachineScaleSetInstances(t *testing.T) {
+	nicID := vmssID + "/virtualMachines/0/networkInterfaces/nic"
+	pipID := nicID + "/ipConfigurations/ipconfig/publicIPAddresses/pip"
+
+	vmss := NewEmptyVirtualMachineScaleSet()
+	vmss.Meta.FromID(vmssID)
+
+	var inst VMSSInstance
+	inst.FromAzure(&armcompute.VirtualMachineScaleSetVM{
+		ID:         ptr(vmssID + "/virtualMachines/0"),
+		InstanceID: ptr("0"),
+		Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+			NetworkProfile: &armcompute.NetworkProfile{
+				NetworkInterfaces: []*armcompute.NetworkInterfaceReference{{ID: ptr(nicID)}},
+			},
+		},
+	})
+	vmss.Instances = append(vmss.Instances, inst)
+
+	// Azure isn't consistent with casing across APIs
+	ni := NewEmptyNetworkInterface()
+	ni.FromAzure(&armnetwork.Interface{
+		ID: ptr(vmssID + "/virtualMachines/0/networkInterfaces/NIC"),
+		Properties: &armnetwork.InterfacePropertiesFormat{
+			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+				{
+					ID: ptr(nicID + "/ipConfigurations/ipconfig"),
+					Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+						PrivateIPAddress: ptr("10.0.0.4"),
+						PublicIPAddress:  &armnetwork.PublicIPAddress{ID: ptr(pipID)},
+					},
+				},
+			},
+		},
+	})
+	if !vmss.SetNetworkInterface(ni) {
+		t.Fatal("expected the interface to belong to the instance")
+	}
+	if len(vmss.PublicAddresses()) != 0 {
+		t.Fatalf("public IP shouldn't have an address yet: %v", vmss.PublicAddresses())
+	}
+
+	var pip PublicIP
+	pip.setupEmpty()
+	pip.FromAzure(&armnetwork.PublicIPAddress{
+		ID:         ptr(pipID),
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: ptr("20.1.2.3")},
+	})
+	vmss.SetPublicIP(&pip)
+	addrs := vmss.PublicAddresses()
+	if len(addrs) != 1 || addrs[0] != "20.1.2.3" {
+		t.Fatalf("bad public addresses: %v", addrs)
+	}
+
+	rg := NewEmptyResourceGroup()
+	rg.VirtualMachineScaleSets = append(rg.VirtualMachineScaleSets, vmss)
+	sub := NewSubscription(subId)
+	sub.ResourceGroups[rgAName] = rg
+	as := sub.GetAttackSurface()
+	if len(as.VirtualMachineScaleSets) != 1 || as.VirtualMachineScaleSets[0] != "20.1.2.3" {
+		t.Fatalf("bad attack surface: %v", as.VirtualMachineScaleSets)
+	}
+}
@@ -144,14 +144,18 @@ type Function struct {
 	ConfigURL      string
 	SecretsURL     string
 	URL            string
+	// Identity is the identity of the function app the function runs in
+	Identity ManagedIdentity
 }
 
 func NewEmptyFunction() *Function {
 	var id ResourceID
 	id.setupEmpty()
-	return &Function{
+	f := &Function{
 		Meta: id,
 	}
+	f.Identity.setupEmpty()
+	return f
 }
 
 type bindingIntermediate struct {
@@ -375,6 +379,14 @@ type WebAppIPRestriction struct {
 	FirewallRule
 	Priority int32
 	Allow    UnknownBool
+	// ServiceTag is set when the rule matches a service tag, such as
+	// AzureFrontDoor.Backend, rather than an IP range.
+	ServiceTag string
+	// Subnet is set for VNet rules
+	Subnet ResourceID
+	// FrontDoorIDs are the X-Azure-FDID header values the rule requires, if
+	// any.
+	FrontDoorIDs []string
 }
 
 func (ipr *WebAppIPRestriction) UnmarshalJSON(b []byte) error {
@@ -382,28 +394,45 @@ func (ipr *WebAppIPRestriction) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	ptrs := struct {
-		Priority *int32
-		Allow    *UnknownBool
+		Priority     *int32
+		Allow        *UnknownBool
+		ServiceTag   *string
+		Subnet       *ResourceID
+		FrontDoorIDs *[]string
 	}{
-		Priority: &ipr.Priority,
-		Allow:    &ipr.Allow,
+		Priority:     &ipr.Priority,
+		Allow:        &ipr.Allow,
+		ServiceTag:   &ipr.ServiceTag,
+		Subnet:       &ipr.Subnet,
+		FrontDoorIDs: &ipr.FrontDoorIDs,
 	}
 	return json.Unmarshal(b, &ptrs)
 }
 
 func (ipr *WebAppIPRestriction) FromAzure(az *armappservice.IPSecurityRestriction) {
+	ipr.Subnet.setupEmpty()
+	ipr.FrontDoorIDs = make([]string, 0)
 	a := az.Action
 	if a != nil {
 		ipr.Allow.FromBool(strings.ToLower(*a) == "allow")
 	}
 	gValFromPtr(&ipr.Priority, az.Priority)
 	gValFromPtr(&ipr.Name, az.Name)
+	for k, v := range az.Headers {
+		if strings.EqualFold(k, "x-azure-fdid") {
+			appendStrings(&ipr.FrontDoorIDs, v)
+		}
+	}
+	if az.Tag != nil && *az.Tag == armappservice.IPFilterTagServiceTag && az.IPAddress != nil {
+		ipr.ServiceTag = *az.IPAddress
+	}
 	if az.IPAddress == nil {
 		// Probably a VNet rule
 		if az.VnetSubnetResourceID == nil {
 			// Ok, fine, it isn't that either.
 			return
 		}
+		ipr.Subnet.fromID(*az.VnetSubnetResourceID)
 		ipr.IPRange = NewAzureIPv4FromAzure(*az.VnetSubnetResourceID)
 	} else {
 		ipr.IPRange = NewAzureIPv4FromAzure(*az.IPAddress)
@@ -439,6 +468,9 @@ type WebApp struct {
 	HostnamesDisabled        UnknownBool
 	HTTP2Enabled             UnknownBool
 	HTTPSOnly                UnknownBool
+	// PublicNetworkAccess is unknown when the app doesn't set it, in which
+	// case Azure disables public access once there is a private endpoint.
+	PublicNetworkAccess      UnknownBool
 	MinTLSVersion            TLSVersion
 	SCMMinTLSVersion         TLSVersion
 	Language                 WebAppLanguage
@@ -457,12 +489,17 @@ type WebApp struct {
 	Functions                []Function
 	Firewall                 WebAppIPFirewall
 	SCMFirewall              WebAppIPFirewall
+	// FirewallDefaultAllow and SCMFirewallDefaultAllow are whether traffic
+	// that doesn't match any of the firewall's rules is allowed.
+	FirewallDefaultAllow     UnknownBool
+	SCMFirewallDefaultAllow  UnknownBool
+	Identity                 ManagedIdentity
 }
 
 func NewEmptyWebApp() *WebApp {
 	var id ResourceID
 	id.setupEmpty()
-	return &WebApp{
+	w := &WebApp{
 		Meta:                     id,
 		OutboundIPAddresses:      make(IPCollection, 0),
 		EnabledHosts:             make([]WebHost, 0),
@@ -472,6 +509,58 @@ func NewEmptyWebApp() *WebApp {
 		SCMFirewall:              make(WebAppIPFirewall, 0),
 		ClientCertExclusionPaths: make([]string, 0),
 	}
+	w.Identity.setupEmpty()
+	return w
+}
+
+// webAppConfigAPIVersion is new enough to have the IP restriction default
+// actions, which armappservice doesn't have. They were only added to the
+// later major versions of armappservice, and moving to one of those means
+// porting every App Service collector, so the config is read directly until
+// then.
+const webAppConfigAPIVersion = "2023-01-01"
+
+// azWebAppConfig is the site's config/web resource. The properties are a
+// SiteConfig plus the fields that are missing from armappservice.
+type azWebAppConfig struct {
+	Properties json.RawMessage `json:"properties"`
+}
+
+type azWebAppDefaultActions struct {
+	IPSecurityRestrictionsDefaultAction    *string `json:"ipSecurityRestrictionsDefaultAction"`
+	SCMIPSecurityRestrictionsDefaultAction *string `json:"scmIpSecurityRestrictionsDefaultAction"`
+}
+
+func (w *WebApp) fromAzureConfig(az *azWebAppConfig) error {
+	if len(az.Properties) == 0 {
+		return nil
+	}
+	var conf armappservice.SiteConfig
+	if err := json.Unmarshal(az.Properties, &conf); err != nil {
+		return err
+	}
+	w.fillConfigInfo(&conf)
+	var actions azWebAppDefaultActions
+	if err := json.Unmarshal(az.Properties, &actions); err != nil {
+		return err
+	}
+	w.FirewallDefaultAllow = webAppDefaultAllow(actions.IPSecurityRestrictionsDefaultAction)
+	w.SCMFirewallDefaultAllow = webAppDefaultAllow(actions.SCMIPSecurityRestrictionsDefaultAction)
+	return nil
+}
+
+func webAppDefaultAllow(action *string) UnknownBool {
+	if action == nil {
+		return BoolUnknown
+	}
+	switch strings.ToLower(*action) {
+	case "allow":
+		return BoolTrue
+	case "deny":
+		return BoolFalse
+	default:
+		return BoolUnknown
+	}
 }
 
 func (w *WebApp) fillConfigInfo(conf *armappservice.SiteConfig) {
@@ -522,6 +611,7 @@ func (w *WebApp) fillConfigInfo(conf *armappservice.SiteConfig) {
 		sort.Sort(w.SCMFirewall)
 	}
 
+	w.PublicNetworkAccess = dbEnabledString(conf.PublicNetworkAccess)
 	w.Language.FromAzureSiteConfig(conf)
 	w.HasLocalSQL.FromBoolPtr(conf.LocalMySQLEnabled)
 	w.HTTPLogging.FromBoolPtr(conf.HTTPLoggingEnabled)
@@ -565,6 +655,7 @@ func (w *WebApp) FromAzure(aw *armappservice.Site) {
 		w.Meta.setupEmpty()
 	}
 
+	w.Identity.FromAzureWeb(aw.Identity)
 	props := aw.Properties
 	if props == nil {
 		return